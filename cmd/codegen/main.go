@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chapar-rest/chapar/internal/codegen"
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/repository"
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+var (
+	language    = flag.String("l", "go", "language of the generated code, use -list to see the supported ones")
+	requestPath = flag.String("p", "request.yaml", "path to the request file")
+	envPath     = flag.String("e", "", "path to the environment file used to resolve the variables")
+	list        = flag.Bool("list", false, "list the supported languages")
+)
+
+func main() {
+	flag.Parse()
+
+	if *list {
+		for _, g := range codegen.Generators() {
+			fmt.Printf("%-12s %s\n", g.ID, g.Name)
+		}
+		return
+	}
+
+	req, err := repository.LoadFromYaml[domain.Request](*requestPath)
+	if err != nil {
+		fmt.Printf("Error loading request: %v\n", err)
+		os.Exit(1)
+	}
+
+	if req.Spec.HTTP == nil || req.Spec.HTTP.Request == nil {
+		fmt.Println("Error: only http requests are supported, grpc requests need their proto files loaded in the app")
+		os.Exit(1)
+	}
+
+	scopes := variables.Scopes{Request: req.Spec.HTTP.Request.Variables}
+	if *envPath != "" {
+		env, err := repository.LoadFromYaml[domain.Environment](*envPath)
+		if err != nil {
+			fmt.Printf("Error loading environment: %v\n", err)
+			os.Exit(1)
+		}
+		scopes.Environment = &env.Spec
+	}
+
+	engine, warnings := scopes.Resolve()
+	warnings = append(warnings, variables.ApplyToHTTPRequest(engine, req.Spec.HTTP)...)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", w)
+	}
+
+	code, err := codegen.Generate(*language, req.Spec.HTTP)
+	if err != nil {
+		fmt.Printf("Error generating code: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(code)
+}
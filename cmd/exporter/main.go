@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/exporter"
+	"github.com/chapar-rest/chapar/internal/repository"
+	"github.com/chapar-rest/chapar/internal/state"
+)
+
+var (
+	format   = flag.String("t", "postman", "format of the output (postman, openapi or environment)")
+	filePath = flag.String("p", "", "path to the directory of the collection, or to the file of the environment")
+	output   = flag.String("o", "", "path to the output file, the output is printed when empty")
+)
+
+func main() {
+	flag.Parse()
+
+	var (
+		data   []byte
+		report *convert.Report
+		err    error
+	)
+
+	switch *format {
+	case "postman", "openapi":
+		col, err := repository.LoadCollection(*filePath)
+		if err != nil {
+			fmt.Printf("Error loading collection: %v\n", err)
+			os.Exit(1)
+		}
+
+		if *format == "postman" {
+			data, report, err = exporter.ExportPostmanCollection(col)
+		} else {
+			data, report, err = exporter.ExportOpenAPI(col)
+		}
+		if err != nil {
+			fmt.Printf("Error exporting collection: %v\n", err)
+			os.Exit(1)
+		}
+	case "environment":
+		env, err := loadEffectiveEnvironment(*filePath)
+		if err != nil {
+			fmt.Printf("Error loading environment: %v\n", err)
+			os.Exit(1)
+		}
+
+		if data, err = exporter.ExportPostmanEnvironment(env); err != nil {
+			fmt.Printf("Error exporting environment: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Unknown format %s\n", *format)
+		os.Exit(1)
+	}
+
+	if !report.IsEmpty() {
+		fmt.Fprintf(os.Stderr, "Export report:\n%s\n", report)
+	}
+
+	if *output == "" {
+		fmt.Println(string(data))
+		return
+	}
+
+	if err = os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadEffectiveEnvironment loads the environment with the values it inherits, the environments it extends
+// are looked up in the same directory as the app does.
+func loadEffectiveEnvironment(path string) (*domain.Environment, error) {
+	env, err := repository.LoadFromYaml[domain.Environment](path)
+	if err != nil {
+		return nil, err
+	}
+
+	environments := state.NewEnvironments(nil)
+	environments.AddEnvironment(env, state.SourceFile)
+
+	files, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".yaml" || filepath.Join(filepath.Dir(path), file.Name()) == filepath.Clean(path) {
+			continue
+		}
+
+		// files which are not environments can not be the base of one
+		base, err := repository.LoadFromYaml[domain.Environment](filepath.Join(filepath.Dir(path), file.Name()))
+		if err != nil || base.MetaData.ID == "" {
+			continue
+		}
+		environments.AddEnvironment(base, state.SourceFile)
+	}
+
+	return environments.GetEffectiveEnvironment(env.MetaData.ID)
+}
@@ -4,26 +4,81 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/chapar-rest/chapar/internal/importer"
 )
 
 var (
-	fileType = flag.String("t", "collection", "type of input file (collection or environment)")
-	filePath = flag.String("p", "example.json", "path to the input file")
+	fileType = flag.String("t", "collection", "type of input file (collection, environment, openapi, insomnia, bruno or har)")
+	filePath = flag.String("p", "example.json", "path to the input file, or to the directory of a bruno collection")
+	entries  = flag.String("entries", "", "comma separated indexes of the har entries to import, all of them when empty")
 )
 
 func main() {
 	flag.Parse()
 
+	var (
+		result *importer.Result
+		err    error
+	)
+
 	if *fileType == "collection" {
-		if err := importer.ImportPostmanCollectionFromFile(*filePath); err != nil {
+		if result, err = importer.ImportPostmanCollectionFromFile(*filePath); err != nil {
 			fmt.Printf("Error importing Postman collection: %v\n", err)
 			os.Exit(1)
 		}
 	} else if *fileType == "environment" {
-		if err := importer.ImportPostmanEnvironmentFromFile(*filePath); err != nil {
+		if result, err = importer.ImportPostmanEnvironmentFromFile(*filePath); err != nil {
 			fmt.Printf("Error importing Postman environment	: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *fileType == "openapi" {
+		if result, err = importer.ImportOpenAPIFromFile(*filePath); err != nil {
+			fmt.Printf("Error importing OpenAPI document: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *fileType == "insomnia" {
+		if result, err = importer.ImportInsomniaFromFile(*filePath); err != nil {
+			fmt.Printf("Error importing Insomnia export: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *fileType == "bruno" {
+		if result, err = importer.ImportBrunoCollection(*filePath); err != nil {
+			fmt.Printf("Error importing Bruno collection: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *fileType == "har" {
+		keep, err := parseEntries(*entries)
+		if err != nil {
+			fmt.Printf("Error parsing entries: %v\n", err)
+			os.Exit(1)
+		}
+
+		if result, err = importer.ImportHARFromFile(*filePath, keep); err != nil {
+			fmt.Printf("Error importing HAR: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if result != nil && !result.Report.IsEmpty() {
+		fmt.Printf("Not imported:\n%s\n", result.Report)
+	}
+}
+
+func parseEntries(v string) ([]int, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	var out []int
+	for _, s := range strings.Split(v, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
 		}
+		out = append(out, i)
 	}
+	return out, nil
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/headless"
+	"github.com/chapar-rest/chapar/internal/loadtest"
+)
+
+var (
+	collectionName = flag.String("collection", "", "name of the collection of the active workspace to run, its requests are sent in order in each iteration")
+	requestName    = flag.String("request", "", "name of the request to run, a request of a collection can be named as collection/request")
+	envName        = flag.String("env", "", "name of the environment to resolve the variables with")
+	vus            = flag.Int("vus", 1, "number of virtual users")
+	rampUp         = flag.Duration("ramp-up", 0, "time over which the virtual users are started")
+	duration       = flag.Duration("duration", 0, "how long to run, such as 30s")
+	iterations     = flag.Int("iterations", 0, "number of iterations over all virtual users, one per user when it and the duration are not set")
+	rate           = flag.Float64("rate", 0, "maximum requests per second, 0 is unlimited")
+	jsonPath       = flag.String("json", "", "path to write the JSON report to")
+	htmlPath       = flag.String("html", "", "path to write the HTML report to")
+)
+
+func main() {
+	flag.Parse()
+
+	if (*collectionName == "") == (*requestName == "") {
+		fmt.Println("Either -collection or -request is required")
+		os.Exit(1)
+	}
+
+	ws, err := headless.Load()
+	if err != nil {
+		fmt.Printf("Error loading workspace: %v\n", err)
+		os.Exit(1)
+	}
+
+	targets, err := getTargets(ws)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	envID, err := ws.EnvironmentID(*envName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// the virtual users share a copy of the environment, the values set by the post-request actions are not saved
+	env, err := ws.Egress.NewRunEnvironment(envID)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	send := func(id string) (any, error) {
+		return ws.Egress.SendWithEnvironment(id, env)
+	}
+
+	runner := loadtest.New(send, targets, loadtest.Options{
+		VirtualUsers: *vus,
+		RampUp:       *rampUp,
+		Duration:     *duration,
+		Iterations:   *iterations,
+		RateLimit:    *rate,
+	})
+	runner.SetOnProgress(func(r *loadtest.Report) {
+		fmt.Printf("%d requests, %.1f req/s, %.1f%% errors, p90 %.1fms\n", r.Count, r.Throughput, r.ErrorRate*100, r.Latency.P90)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := runner.Run(ctx)
+	if err != nil {
+		fmt.Printf("Error running load test: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(report)
+
+	if err := writeReports(report); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func getTargets(ws *headless.Workspace) ([]loadtest.Target, error) {
+	if *requestName != "" {
+		req, err := ws.Request(*requestName)
+		if err != nil {
+			return nil, err
+		}
+		return []loadtest.Target{{ID: req.MetaData.ID, Name: req.MetaData.Name}}, nil
+	}
+
+	col, err := ws.Collection(*collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]loadtest.Target, 0, len(col.Spec.Requests))
+	for _, req := range col.Spec.Requests {
+		targets = append(targets, loadtest.Target{ID: req.MetaData.ID, Name: req.MetaData.Name})
+	}
+	return targets, nil
+}
+
+func writeReports(report *loadtest.Report) error {
+	if *jsonPath != "" {
+		data, err := report.JSON()
+		if err != nil {
+			return fmt.Errorf("error creating JSON report: %w", err)
+		}
+		if err := os.WriteFile(*jsonPath, data, 0644); err != nil {
+			return fmt.Errorf("error writing JSON report: %w", err)
+		}
+	}
+
+	if *htmlPath != "" {
+		data, err := report.HTML()
+		if err != nil {
+			return fmt.Errorf("error creating HTML report: %w", err)
+		}
+		if err := os.WriteFile(*htmlPath, data, 0644); err != nil {
+			return fmt.Errorf("error writing HTML report: %w", err)
+		}
+	}
+
+	if *jsonPath != "" || *htmlPath != "" {
+		fmt.Println("Reports written to", strings.Trim(*jsonPath+" "+*htmlPath, " "))
+	}
+	return nil
+}
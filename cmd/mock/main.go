@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/grpc"
+	"github.com/chapar-rest/chapar/internal/mock"
+	"github.com/chapar-rest/chapar/internal/repository"
+)
+
+var (
+	collectionPath = flag.String("p", "", "path to the directory of the collection to serve")
+	protoFiles     = flag.String("proto", "", "comma separated proto files, serves their services with grpc instead of the collection")
+	examplesPath   = flag.String("examples", "", "path to a JSON file of the grpc examples keyed by method, such as /helloworld.Greeter/SayHello")
+	addr           = flag.String("addr", "localhost:8090", "address to listen on")
+	latency        = flag.Duration("latency", 0, "latency added to every response")
+	jitter         = flag.Duration("jitter", 0, "random latency added on top of the latency")
+	errorRate      = flag.Float64("error-rate", 0, "share of the requests, between 0 and 1, answered with an error")
+	errorStatus    = flag.Int("error-status", 500, "status of the injected http errors")
+	template       = flag.Bool("template", false, "render the request variables such as {{request.params.id}} in the examples")
+)
+
+func main() {
+	flag.Parse()
+
+	opts := mock.Options{
+		Latency:     *latency,
+		Jitter:      *jitter,
+		ErrorRate:   *errorRate,
+		ErrorStatus: *errorStatus,
+		Template:    *template,
+	}
+
+	srv, err := newServer(opts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	srv.Log().SetOnEntry(func(e mock.LogEntry) {
+		fmt.Println(e)
+	})
+
+	if err := srv.Start(*addr); err != nil {
+		fmt.Printf("Error starting mock server: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Mock server listening on %s\n", srv.Addr())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	<-sig
+
+	if err := srv.Stop(); err != nil {
+		fmt.Printf("Error stopping mock server: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newServer(opts mock.Options) (mock.Runner, error) {
+	if *protoFiles != "" {
+		importPaths, fileNames := grpc.GetImportPaths(nil, strings.Split(*protoFiles, ","))
+		files, err := grpc.ProtoFilesFromDisk(importPaths, fileNames)
+		if err != nil {
+			return nil, fmt.Errorf("error loading proto files: %w", err)
+		}
+
+		examples := make(map[string]string)
+		if *examplesPath != "" {
+			data, err := os.ReadFile(*examplesPath)
+			if err != nil {
+				return nil, fmt.Errorf("error reading examples: %w", err)
+			}
+
+			raw := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(data, &raw); err != nil {
+				return nil, fmt.Errorf("error parsing examples: %w", err)
+			}
+			for k, v := range raw {
+				examples[k] = string(v)
+			}
+		}
+
+		return mock.NewGRPCServer(files, examples, opts), nil
+	}
+
+	col, err := repository.LoadCollection(*collectionPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading collection: %w", err)
+	}
+	return mock.NewServer(col, opts), nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/headless"
+	"github.com/chapar-rest/chapar/internal/reporter"
+)
+
+var (
+	collectionName = flag.String("collection", "", "name of the collection of the active workspace to run, its requests are sent in order")
+	requestName    = flag.String("request", "", "name of the request to run, a request of a collection can be named as collection/request")
+	envName        = flag.String("env", "", "name of the environment to resolve the variables with")
+	dataPath       = flag.String("data", "", "path to a CSV or JSON iteration data file, the requests are run once per row with its columns as variables")
+	delay          = flag.Duration("delay", 0, "pause between two requests, such as 500ms")
+	stopOnFailure  = flag.Bool("stop-on-failure", false, "end the run at the first failed request")
+	retries        = flag.Int("retries", 0, "number of times a request is sent again when its status is one of -retry-status")
+	retryStatus    = flag.String("retry-status", "502,503,504", "comma separated statuses to retry, http status codes or grpc code names")
+	backoff        = flag.Duration("backoff", time.Second, "wait before the first retry, it doubles for each of the next ones")
+	maxSteps       = flag.Int("max-steps", 0, "maximum requests sent in an iteration when the next requests loop, 0 is 100 times the number of requests")
+	skip           = flag.String("skip", "", "comma separated names of the requests of the collection to skip")
+	only           = flag.String("only", "", "comma separated names of the requests of the collection to run, the others are skipped")
+
+	reports reportFlags
+)
+
+func init() {
+	flag.Var(&reports, "report", "reporter and the path to write its report to, such as junit=results.xml, can be repeated, the reporters are "+
+		strings.Join(reporter.Names(), ", "))
+}
+
+// reportFlags are the reports to write by reporter name.
+type reportFlags []reportFlag
+
+type reportFlag struct {
+	reporter reporter.Reporter
+	path     string
+}
+
+func (r *reportFlags) String() string {
+	return ""
+}
+
+func (r *reportFlags) Set(value string) error {
+	name, path, ok := strings.Cut(value, "=")
+	if !ok || path == "" {
+		return fmt.Errorf("report must be reporter=path, such as junit=results.xml")
+	}
+
+	rep, err := reporter.Get(name)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, reportFlag{reporter: rep, path: path})
+	return nil
+}
+
+func main() {
+	flag.Parse()
+
+	if (*collectionName == "") == (*requestName == "") {
+		fmt.Println("Either -collection or -request is required")
+		os.Exit(1)
+	}
+
+	ws, err := headless.Load()
+	if err != nil {
+		fmt.Printf("Error loading workspace: %v\n", err)
+		os.Exit(1)
+	}
+
+	id, err := getID(ws)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	opts := egress.RunOptions{
+		Delay:         *delay,
+		StopOnFailure: *stopOnFailure,
+		Retries:       *retries,
+		RetryStatuses: splitList(*retryStatus),
+		Backoff:       *backoff,
+		MaxSteps:      *maxSteps,
+	}
+	if opts.EnvironmentID, err = ws.EnvironmentID(*envName); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if opts.Skip, err = requestIDs(ws, id, *skip); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if opts.Only, err = requestIDs(ws, id, *only); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *dataPath != "" {
+		data, err := os.ReadFile(*dataPath)
+		if err != nil {
+			fmt.Printf("Error reading iteration data: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.Data, err = egress.ParseIterationData(data); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := ws.Egress.Run(ctx, id, opts, func(r *egress.RequestResult) {
+		fmt.Println(r)
+	})
+	if err != nil {
+		fmt.Printf("Error running %s: %v\n", *collectionName+*requestName, err)
+		os.Exit(1)
+	}
+
+	printIterations(result)
+	if result.Stopped != "" {
+		fmt.Println("Run " + result.Stopped)
+	}
+	if err := writeReports(result); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if result.Failures() > 0 || result.Stopped != "" {
+		os.Exit(1)
+	}
+}
+
+func getID(ws *headless.Workspace) (string, error) {
+	if *requestName != "" {
+		req, err := ws.Request(*requestName)
+		if err != nil {
+			return "", err
+		}
+		return req.MetaData.ID, nil
+	}
+
+	col, err := ws.Collection(*collectionName)
+	if err != nil {
+		return "", err
+	}
+	return col.MetaData.ID, nil
+}
+
+// requestIDs returns the ids of the requests with the comma separated names in the collection with the id,
+// or of the request with the id.
+func requestIDs(ws *headless.Workspace, id, names string) ([]string, error) {
+	requests := []*domain.Request{ws.Requests.GetRequest(id)}
+	if col := ws.Requests.GetCollection(id); col != nil {
+		requests = col.Spec.Requests
+	}
+
+	var ids []string
+	for _, name := range splitList(names) {
+		found := false
+		for _, req := range requests {
+			if req != nil && (req.MetaData.Name == name || req.MetaData.ID == name) {
+				ids = append(ids, req.MetaData.ID)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("request %s not found in %s", name, *collectionName+*requestName)
+		}
+	}
+	return ids, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func printIterations(result *egress.RunResult) {
+	fmt.Printf("\n%s: %d iterations in %s, %d failed requests\n", result.Name, len(result.Iterations), result.Duration.Round(time.Millisecond), result.Failures())
+	for _, it := range result.Iterations {
+		status := "passed"
+		if failed := it.Failures(); failed > 0 {
+			status = fmt.Sprintf("%d failed", failed)
+		}
+		fmt.Printf("#%d %s %s\n", it.Index+1, formatData(it.Data), status)
+	}
+}
+
+func formatData(data []domain.KeyValue) string {
+	if len(data) == 0 {
+		return "-"
+	}
+
+	values := make([]string, 0, len(data))
+	for _, kv := range data {
+		values = append(values, kv.Key+"="+kv.Value)
+	}
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+func writeReports(result *egress.RunResult) error {
+	for _, r := range reports {
+		f, err := os.Create(r.path)
+		if err != nil {
+			return fmt.Errorf("error creating report: %w", err)
+		}
+
+		err = r.reporter.Report(f, result)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("error writing report %s: %w", r.path, err)
+		}
+		fmt.Println("Report written to", r.path)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+var ErrUnknownLanguage = errors.New("unknown language")
+
+// Generator renders http requests to the code sending them in a language.
+type Generator struct {
+	// ID is the name of the generator used by the cli.
+	ID   string
+	Name string
+	// Lexer is the name of the lexer highlighting the generated code.
+	Lexer string
+
+	generate func(r *request) string
+}
+
+var generators = []Generator{
+	{ID: "go", Name: "Go (net/http)", Lexer: "go", generate: generateGo},
+	{ID: "python", Name: "Python (requests)", Lexer: "python", generate: generatePython},
+	{ID: "javascript", Name: "JavaScript (fetch)", Lexer: "javascript", generate: generateFetch},
+	{ID: "axios", Name: "Node (axios)", Lexer: "javascript", generate: generateAxios},
+	{ID: "java", Name: "Java (HttpClient)", Lexer: "java", generate: generateJava},
+	{ID: "csharp", Name: "C# (HttpClient)", Lexer: "csharp", generate: generateCSharp},
+	{ID: "php", Name: "PHP (cURL)", Lexer: "php", generate: generatePHP},
+	{ID: "httpie", Name: "HTTPie", Lexer: "bash", generate: generateHTTPie},
+}
+
+// Generators returns the generators of http requests.
+func Generators() []Generator {
+	return append([]Generator(nil), generators...)
+}
+
+// Generate renders the request with the generator of the id, the variables of the spec should be resolved already.
+func Generate(id string, spec *domain.HTTPRequestSpec) (string, error) {
+	for _, g := range generators {
+		if g.ID == id {
+			return g.Generate(spec), nil
+		}
+	}
+	return "", ErrUnknownLanguage
+}
+
+func (g Generator) Generate(spec *domain.HTTPRequestSpec) string {
+	return g.generate(newRequest(spec))
+}
+
+const (
+	bodyNone = iota
+	bodyRaw
+	bodyURLEncoded
+	bodyMultipart
+	bodyBinary
+)
+
+// request is the spec reduced to what the generators render, the disabled items are dropped and the auth
+// is converted to headers.
+type request struct {
+	method  string
+	url     string
+	headers []domain.KeyValue
+	// contentType is the content type of the body, it is not part of the headers.
+	contentType string
+
+	body     int
+	raw      string
+	form     []domain.KeyValue
+	fields   []field
+	filePath string
+}
+
+// field is a part of a multipart body, file is the path of the file to send when it is not empty.
+type field struct {
+	key   string
+	value string
+	file  string
+}
+
+func (f field) fileName() string {
+	return filepath.Base(f.file)
+}
+
+func newRequest(spec *domain.HTTPRequestSpec) *request {
+	r := &request{method: strings.ToUpper(spec.Method), url: spec.URL}
+	if r.method == "" {
+		r.method = domain.RequestMethodGET
+	}
+
+	req := spec.Request
+	if req == nil {
+		return r
+	}
+
+	for _, p := range req.PathParams {
+		if p.Enable {
+			r.url = strings.ReplaceAll(r.url, "{"+p.Key+"}", p.Value)
+		}
+	}
+
+	for _, h := range req.Headers {
+		if !h.Enable || h.Key == "" {
+			continue
+		}
+		if strings.EqualFold(h.Key, "Content-Type") {
+			r.contentType = h.Value
+			continue
+		}
+		r.headers = append(r.headers, h)
+	}
+
+	switch req.Auth.Type {
+	case domain.AuthTypeBasic:
+		if a := req.Auth.BasicAuth; a != nil {
+			r.addHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password)))
+		}
+	case domain.AuthTypeToken:
+		if a := req.Auth.TokenAuth; a != nil {
+			r.addHeader("Authorization", "Bearer "+a.Token)
+		}
+	case domain.AuthTypeAPIKey:
+		if a := req.Auth.APIKeyAuth; a != nil && a.Key != "" {
+			r.addHeader(a.Key, a.Value)
+		}
+	}
+
+	defaultType := ""
+	body := req.Body
+	switch body.Type {
+	case domain.BodyTypeJSON, domain.BodyTypeXML, domain.BodyTypeText:
+		if body.Data != "" {
+			r.body = bodyRaw
+			r.raw = body.Data
+			defaultType = map[string]string{
+				domain.BodyTypeJSON: "application/json",
+				domain.BodyTypeXML:  "application/xml",
+				domain.BodyTypeText: "text/plain",
+			}[body.Type]
+		}
+	case domain.BodyTypeUrlencoded:
+		for _, kv := range body.URLEncoded {
+			if kv.Enable {
+				r.form = append(r.form, kv)
+			}
+		}
+		if len(r.form) > 0 {
+			r.body = bodyURLEncoded
+			defaultType = "application/x-www-form-urlencoded"
+		}
+	case domain.BodyTypeFormData:
+		for _, f := range body.FormData.Fields {
+			if !f.Enable {
+				continue
+			}
+			if f.Type == domain.FormFieldTypeFile {
+				for _, file := range f.Files {
+					r.fields = append(r.fields, field{key: f.Key, file: file})
+				}
+				continue
+			}
+			r.fields = append(r.fields, field{key: f.Key, value: f.Value})
+		}
+		if len(r.fields) > 0 {
+			r.body = bodyMultipart
+		}
+	case domain.BodyTypeBinary:
+		if body.BinaryFilePath != "" {
+			r.body = bodyBinary
+			r.filePath = body.BinaryFilePath
+			defaultType = "application/octet-stream"
+		}
+	}
+
+	switch {
+	case r.body == bodyNone || r.body == bodyMultipart:
+		// the content type of a multipart body carries its boundary, the libraries set it
+		r.contentType = ""
+	case r.contentType == "":
+		r.contentType = defaultType
+	}
+
+	return r
+}
+
+func (r *request) addHeader(key, value string) {
+	r.headers = append(r.headers, domain.KeyValue{Key: key, Value: value, Enable: true})
+}
+
+// headersWithContentType returns the headers followed by the content type of the body, if any.
+func (r *request) headersWithContentType() []domain.KeyValue {
+	if r.contentType == "" {
+		return r.headers
+	}
+	return append(append([]domain.KeyValue(nil), r.headers...), domain.KeyValue{Key: "Content-Type", Value: r.contentType, Enable: true})
+}
+
+// encodedForm returns the url encoded body, in the order of its fields.
+func (r *request) encodedForm() string {
+	pairs := make([]string, 0, len(r.form))
+	for _, kv := range r.form {
+		pairs = append(pairs, url.QueryEscape(kv.Key)+"="+url.QueryEscape(kv.Value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+func (r *request) hasFiles() bool {
+	for _, f := range r.fields {
+		if f.file != "" {
+			return true
+		}
+	}
+	return r.body == bodyBinary
+}
+
+// quote returns a double quoted string literal, which is valid in python, javascript, java and c#.
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// shellQuote quotes the value for a posix shell unless it only has safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@,+=%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// comment makes the text safe to be put in a /* */ comment.
+func comment(s string) string {
+	return strings.ReplaceAll(s, "*/", "* /")
+}
@@ -0,0 +1,162 @@
+package codegen
+
+import (
+	"flag"
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+var requests = map[string]*domain.HTTPRequestSpec{
+	"json": {
+		Method: "POST",
+		URL:    "https://api.example.com/users/{id}?verbose=true",
+		Request: &domain.HTTPRequest{
+			Headers: []domain.KeyValue{
+				{Key: "Content-Type", Value: "application/json", Enable: true},
+				{Key: "X-Request-ID", Value: "it's 42", Enable: true},
+				{Key: "X-Disabled", Value: "1"},
+			},
+			PathParams: []domain.KeyValue{{Key: "id", Value: "7", Enable: true}},
+			Body:       domain.Body{Type: domain.BodyTypeJSON, Data: "{\n  \"name\": \"john\"\n}"},
+			Auth:       domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: "secret"}},
+		},
+	},
+	"urlencoded": {
+		Method: "PUT",
+		URL:    "https://api.example.com/login",
+		Request: &domain.HTTPRequest{
+			Body: domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: []domain.KeyValue{
+				{Key: "user", Value: "john doe", Enable: true},
+				{Key: "remember", Value: "yes", Enable: true},
+			}},
+			Auth: domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: "john", Password: "pa$$"}},
+		},
+	},
+	"multipart": {
+		Method: "POST",
+		URL:    "https://api.example.com/upload",
+		Request: &domain.HTTPRequest{
+			Headers: []domain.KeyValue{{Key: "Content-Type", Value: "multipart/form-data", Enable: true}},
+			Body: domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: []domain.FormField{
+				{Key: "title", Value: "holiday", Type: domain.FormFieldTypeText, Enable: true},
+				{Key: "photo", Type: domain.FormFieldTypeFile, Files: []string{"/tmp/beach.png"}, Enable: true},
+			}}},
+			Auth: domain.Auth{Type: domain.AuthTypeAPIKey, APIKeyAuth: &domain.APIKeyAuth{Key: "X-API-Key", Value: "k"}},
+		},
+	},
+	"binary": {
+		Method: "PATCH",
+		URL:    "https://api.example.com/files/report",
+		Request: &domain.HTTPRequest{
+			Body: domain.Body{Type: domain.BodyTypeBinary, BinaryFilePath: "/tmp/report.pdf"},
+		},
+	},
+	"get": {
+		Method: "GET",
+		URL:    "https://api.example.com/users?page=2",
+		Request: &domain.HTTPRequest{
+			Headers: []domain.KeyValue{{Key: "Content-Type", Value: "application/json", Enable: true}},
+		},
+	},
+}
+
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run the tests with -update to create the golden files", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match the golden file, got:\n%s", name, got)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for name, spec := range requests {
+		for _, g := range Generators() {
+			got := g.Generate(spec)
+			if g.ID == "go" {
+				if _, err := format.Source([]byte(got)); err != nil {
+					t.Errorf("%s/go is not valid Go: %v\n%s", name, err, got)
+				}
+			}
+			checkGolden(t, filepath.Join(name, g.ID), got)
+		}
+	}
+
+	if _, err := Generate("cobol", requests["get"]); err != ErrUnknownLanguage {
+		t.Fatalf("expected ErrUnknownLanguage, got %v", err)
+	}
+}
+
+func TestGenerateGRPC(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("greeter/v1/greeter.proto"),
+		Package:    proto.String("greeter.v1"),
+		Dependency: []string{"google/protobuf/empty.proto"},
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/gen/greeter/v1;greeterv1")},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("HelloRequest"), Field: []*descriptorpb.FieldDescriptorProto{{
+				Name: proto.String("name"), Number: proto.Int32(1), JsonName: proto.String("name"),
+				Type:  descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			}}},
+			{Name: proto.String("HelloReply")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("SayHello"), InputType: proto.String(".greeter.v1.HelloRequest"), OutputType: proto.String(".greeter.v1.HelloReply")},
+				{Name: proto.String("Chat"), InputType: proto.String(".greeter.v1.HelloRequest"), OutputType: proto.String(".greeter.v1.HelloReply"), ClientStreaming: proto.Bool(true), ServerStreaming: proto.Bool(true)},
+				{Name: proto.String("Ping"), InputType: proto.String(".google.protobuf.Empty"), OutputType: proto.String(".greeter.v1.HelloReply"), ServerStreaming: proto.Bool(true)},
+			},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &domain.GRPCRequestSpec{
+		Metadata:   []domain.KeyValue{{Key: "x-user", Value: "1", Enable: true}},
+		Auth:       domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: "secret"}},
+		ServerInfo: domain.ServerInfo{Address: "localhost:50051"},
+		Settings:   domain.Settings{Insecure: true, TimeoutMilliseconds: 5000},
+		Body:       `{"name": "world"}`,
+	}
+
+	methods := fd.Services().Get(0).Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		got := GenerateGRPC(spec, md)
+		if _, err := format.Source([]byte(got)); err != nil {
+			t.Errorf("%s is not valid Go: %v\n%s", md.Name(), err, got)
+		}
+		checkGolden(t, filepath.Join("grpc", string(md.Name())), got)
+	}
+}
@@ -0,0 +1,66 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func generateCSharp(r *request) string {
+	usings := []string{"System", "System.Net.Http"}
+	if r.contentType != "" {
+		usings = append(usings, "System.Net.Http.Headers")
+	}
+	if r.body == bodyURLEncoded {
+		usings = append(usings, "System.Collections.Generic")
+	}
+	if r.hasFiles() {
+		usings = append(usings, "System.IO")
+	}
+
+	sort.Strings(usings)
+
+	var b strings.Builder
+	for _, u := range usings {
+		fmt.Fprintf(&b, "using %s;\n", u)
+	}
+
+	b.WriteString("\nvar client = new HttpClient();\n")
+	fmt.Fprintf(&b, "var request = new HttpRequestMessage(new HttpMethod(%s), %s);\n", quote(r.method), quote(r.url))
+	for _, h := range r.headers {
+		fmt.Fprintf(&b, "request.Headers.TryAddWithoutValidation(%s, %s);\n", quote(h.Key), quote(h.Value))
+	}
+
+	switch r.body {
+	case bodyRaw:
+		fmt.Fprintf(&b, "request.Content = new StringContent(%s);\n", quote(r.raw))
+	case bodyURLEncoded:
+		b.WriteString("request.Content = new FormUrlEncodedContent(new[]\n{\n")
+		for _, kv := range r.form {
+			fmt.Fprintf(&b, "    new KeyValuePair<string, string>(%s, %s),\n", quote(kv.Key), quote(kv.Value))
+		}
+		b.WriteString("});\n")
+	case bodyMultipart:
+		b.WriteString("var content = new MultipartFormDataContent();\n")
+		for _, f := range r.fields {
+			if f.file != "" {
+				fmt.Fprintf(&b, "content.Add(new StreamContent(File.OpenRead(%s)), %s, %s);\n", quote(f.file), quote(f.key), quote(f.fileName()))
+				continue
+			}
+			fmt.Fprintf(&b, "content.Add(new StringContent(%s), %s);\n", quote(f.value), quote(f.key))
+		}
+		b.WriteString("request.Content = content;\n")
+	case bodyBinary:
+		fmt.Fprintf(&b, "request.Content = new StreamContent(File.OpenRead(%s));\n", quote(r.filePath))
+	}
+
+	// the content type is a header of the content rather than of the request
+	if r.contentType != "" {
+		fmt.Fprintf(&b, "request.Content.Headers.ContentType = MediaTypeHeaderValue.Parse(%s);\n", quote(r.contentType))
+	}
+
+	b.WriteString("\nvar response = await client.SendAsync(request);\n")
+	b.WriteString("Console.WriteLine((int)response.StatusCode);\n")
+	b.WriteString("Console.WriteLine(await response.Content.ReadAsStringAsync());\n")
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func generateGo(r *request) string {
+	imports := map[string]bool{"fmt": true, "io": true, "net/http": true}
+	var b strings.Builder
+
+	body := "nil"
+	switch r.body {
+	case bodyRaw:
+		imports["strings"] = true
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n\n", goString(r.raw))
+		body = "body"
+	case bodyURLEncoded:
+		imports["net/url"] = true
+		imports["strings"] = true
+		b.WriteString("\tform := url.Values{}\n")
+		for _, kv := range r.form {
+			fmt.Fprintf(&b, "\tform.Add(%s, %s)\n", goString(kv.Key), goString(kv.Value))
+		}
+		b.WriteString("\tbody := strings.NewReader(form.Encode())\n\n")
+		body = "body"
+	case bodyMultipart:
+		imports["bytes"] = true
+		imports["mime/multipart"] = true
+		b.WriteString("\tbody := &bytes.Buffer{}\n")
+		b.WriteString("\tw := multipart.NewWriter(body)\n")
+		for _, f := range r.fields {
+			if f.file != "" {
+				fmt.Fprintf(&b, "\tif err := addFile(w, %s, %s); err != nil {\n\t\tpanic(err)\n\t}\n", goString(f.key), goString(f.file))
+				continue
+			}
+			fmt.Fprintf(&b, "\tif err := w.WriteField(%s, %s); err != nil {\n\t\tpanic(err)\n\t}\n", goString(f.key), goString(f.value))
+		}
+		b.WriteString("\tif err := w.Close(); err != nil {\n\t\tpanic(err)\n\t}\n\n")
+		body = "body"
+	case bodyBinary:
+		imports["os"] = true
+		fmt.Fprintf(&b, "\tbody, err := os.Open(%s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tdefer body.Close()\n\n", goString(r.filePath))
+		body = "body"
+	}
+
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", goString(r.method), goString(r.url), body)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, h := range r.headersWithContentType() {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", goString(h.Key), goString(h.Value))
+	}
+	if r.body == bodyMultipart {
+		b.WriteString("\treq.Header.Set(\"Content-Type\", w.FormDataContentType())\n")
+	}
+
+	b.WriteString(`
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(res.Status)
+	fmt.Println(string(data))
+}
+`)
+
+	addFile := r.body == bodyMultipart && r.hasFiles()
+	if addFile {
+		imports["os"] = true
+		imports["path/filepath"] = true
+		b.WriteString(`
+func addFile(w *multipart.Writer, field, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	part, err := w.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(part, file)
+	return err
+}
+`)
+	}
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	out.WriteString("package main\n\nimport (\n")
+	for _, name := range names {
+		fmt.Fprintf(&out, "\t%q\n", name)
+	}
+	out.WriteString(")\n\nfunc main() {\n")
+	out.WriteString(b.String())
+	return out.String()
+}
+
+// goString returns a raw string literal, easier to read, for the strings with quotes or new lines which can be one.
+func goString(s string) string {
+	if strings.ContainsAny(s, "\n\"\\") && !strings.ContainsAny(s, "`\r") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
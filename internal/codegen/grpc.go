@@ -0,0 +1,233 @@
+package codegen
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// GenerateGRPC renders the Go client invoking the method with the stubs generated by protoc-gen-go and
+// protoc-gen-go-grpc, the variables of the spec should be resolved already.
+func GenerateGRPC(spec *domain.GRPCRequestSpec, md protoreflect.MethodDescriptor) string {
+	imports := map[string]string{
+		"context":                "",
+		"fmt":                    "",
+		"log":                    "",
+		"google.golang.org/grpc": "",
+		"google.golang.org/protobuf/encoding/protojson": "",
+	}
+
+	pkg, alias := goImport(md.ParentFile(), "pb")
+	imports[pkg] = alias
+	input := alias + "." + goName(md.Input())
+	if inputPkg, inputAlias := goImport(md.Input().ParentFile(), ""); inputPkg != pkg {
+		imports[inputPkg] = inputAlias
+		input = inputAlias + "." + goName(md.Input())
+	}
+
+	var b strings.Builder
+	var opts []string
+	if spec.Settings.Insecure {
+		imports["google.golang.org/grpc/credentials/insecure"] = ""
+		opts = append(opts, "grpc.WithTransportCredentials(insecure.NewCredentials())")
+	} else {
+		imports["crypto/tls"] = ""
+		imports["google.golang.org/grpc/credentials"] = ""
+		config := "&tls.Config{}"
+		if spec.Settings.NameOverride != "" {
+			config = fmt.Sprintf("&tls.Config{ServerName: %s}", goString(spec.Settings.NameOverride))
+		}
+		opts = append(opts, fmt.Sprintf("grpc.WithTransportCredentials(credentials.NewTLS(%s))", config))
+	}
+	if spec.Settings.Authority != "" {
+		opts = append(opts, fmt.Sprintf("grpc.WithAuthority(%s)", goString(spec.Settings.Authority)))
+	}
+
+	fmt.Fprintf(&b, "\tconn, err := grpc.NewClient(%s, %s)\n", goString(spec.ServerInfo.Address), strings.Join(opts, ", "))
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\tdefer conn.Close()\n\n")
+	fmt.Fprintf(&b, "\tclient := %s.New%sClient(conn)\n\n", alias, goCamelCase(string(md.Parent().Name())))
+
+	if spec.Settings.TimeoutMilliseconds > 0 {
+		imports["time"] = ""
+		fmt.Fprintf(&b, "\tctx, cancel := context.WithTimeout(context.Background(), %d*time.Millisecond)\n\tdefer cancel()\n", spec.Settings.TimeoutMilliseconds)
+	} else {
+		b.WriteString("\tctx := context.Background()\n")
+	}
+
+	metadata := grpcMetadata(spec)
+	if len(metadata) > 0 {
+		imports["google.golang.org/grpc/metadata"] = ""
+		b.WriteString("\tctx = metadata.AppendToOutgoingContext(ctx,\n")
+		for _, kv := range metadata {
+			fmt.Fprintf(&b, "\t\t%s, %s,\n", goString(kv.Key), goString(kv.Value))
+		}
+		b.WriteString("\t)\n")
+	}
+
+	fmt.Fprintf(&b, "\n\treq := &%s{}\n", input)
+	if strings.TrimSpace(spec.Body) != "" {
+		fmt.Fprintf(&b, "\tif err := protojson.Unmarshal([]byte(%s), req); err != nil {\n\t\tlog.Fatal(err)\n\t}\n", goString(spec.Body))
+	}
+	b.WriteString("\n")
+
+	method := goCamelCase(string(md.Name()))
+	const receive = `	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(protojson.Format(res))
+	}
+`
+	switch {
+	case md.IsStreamingClient() && md.IsStreamingServer():
+		imports["io"] = ""
+		fmt.Fprintf(&b, "\tstream, err := client.%s(ctx)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n", method)
+		b.WriteString("\tif err := stream.Send(req); err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+		b.WriteString("\tif err := stream.CloseSend(); err != nil {\n\t\tlog.Fatal(err)\n\t}\n\n")
+		b.WriteString(receive)
+	case md.IsStreamingClient():
+		fmt.Fprintf(&b, "\tstream, err := client.%s(ctx)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n", method)
+		b.WriteString("\tif err := stream.Send(req); err != nil {\n\t\tlog.Fatal(err)\n\t}\n\n")
+		b.WriteString("\tres, err := stream.CloseAndRecv()\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+		b.WriteString("\tfmt.Println(protojson.Format(res))\n")
+	case md.IsStreamingServer():
+		imports["io"] = ""
+		fmt.Fprintf(&b, "\tstream, err := client.%s(ctx, req)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\n", method)
+		b.WriteString(receive)
+	default:
+		fmt.Fprintf(&b, "\tres, err := client.%s(ctx, req)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n", method)
+		b.WriteString("\tfmt.Println(protojson.Format(res))\n")
+	}
+	b.WriteString("}\n")
+
+	// the standard library first, then the other packages
+	var std, others []string
+	for name := range imports {
+		if strings.Contains(strings.SplitN(name, "/", 2)[0], ".") {
+			others = append(others, name)
+		} else {
+			std = append(std, name)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+
+	var out strings.Builder
+	out.WriteString("package main\n\nimport (\n")
+	for _, name := range std {
+		fmt.Fprintf(&out, "\t%q\n", name)
+	}
+	out.WriteString("\n")
+	for _, name := range others {
+		if name == pkg {
+			fmt.Fprintf(&out, "\t%s %q // generated from %s\n", alias, name, md.ParentFile().Path())
+			continue
+		}
+		if alias := imports[name]; alias != "" && alias != path.Base(name) {
+			fmt.Fprintf(&out, "\t%s %q\n", alias, name)
+			continue
+		}
+		fmt.Fprintf(&out, "\t%q\n", name)
+	}
+	out.WriteString(")\n\n")
+	out.WriteString("func main() {\n")
+	out.WriteString(b.String())
+	return out.String()
+}
+
+// grpcMetadata returns the metadata of the request along with the one of its auth, as the service sends them.
+func grpcMetadata(spec *domain.GRPCRequestSpec) []domain.KeyValue {
+	var out []domain.KeyValue
+	for _, m := range spec.Metadata {
+		if m.Enable && m.Key != "" {
+			out = append(out, m)
+		}
+	}
+
+	auth := spec.Auth
+	switch auth.Type {
+	case domain.AuthTypeToken:
+		if auth.TokenAuth != nil {
+			out = append(out, domain.KeyValue{Key: "authorization", Value: "Bearer " + auth.TokenAuth.Token, Enable: true})
+		}
+	case domain.AuthTypeBasic:
+		if auth.BasicAuth != nil {
+			out = append(out, domain.KeyValue{Key: "authorization", Value: "Basic " + auth.BasicAuth.Username + ":" + auth.BasicAuth.Password, Enable: true})
+		}
+	case domain.AuthTypeAPIKey:
+		if auth.APIKeyAuth != nil && auth.APIKeyAuth.Key != "" {
+			out = append(out, domain.KeyValue{Key: auth.APIKeyAuth.Key, Value: auth.APIKeyAuth.Value, Enable: true})
+		}
+	}
+	return out
+}
+
+// goImport returns the import path of the Go package of the proto file and its alias, a placeholder path is
+// returned when the file has no go_package option.
+func goImport(fd protoreflect.FileDescriptor, alias string) (string, string) {
+	var goPackage string
+	if opts, ok := fd.Options().(*descriptorpb.FileOptions); ok {
+		goPackage = opts.GetGoPackage()
+	}
+
+	importPath, name, ok := strings.Cut(goPackage, ";")
+	if importPath == "" {
+		importPath = "example.com/module/" + strings.TrimSuffix(fd.Path(), ".proto")
+	}
+	if !ok {
+		name = path.Base(importPath)
+	}
+	if alias == "" {
+		alias = strings.NewReplacer("-", "", ".", "").Replace(name)
+	}
+	return importPath, alias
+}
+
+// goName returns the name of the Go type generated for the message.
+func goName(desc protoreflect.Descriptor) string {
+	name := strings.TrimPrefix(string(desc.FullName()), string(desc.ParentFile().Package())+".")
+	return goCamelCase(name)
+}
+
+// goCamelCase converts the name of a proto declaration to the name protoc-gen-go uses for it.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isLower(s[i+1]):
+			// skip over '.' in ".{{lowercase}}"
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+			// skip over '_' in "_{{lowercase}}"
+		case c >= '0' && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
@@ -0,0 +1,41 @@
+package codegen
+
+import (
+	"strings"
+)
+
+func generateHTTPie(r *request) string {
+	args := []string{"http"}
+	switch r.body {
+	case bodyRaw:
+		args = append(args, "--raw "+shellQuote(r.raw))
+	case bodyURLEncoded:
+		args = append(args, "--form")
+	case bodyMultipart:
+		args = append(args, "--multipart")
+	}
+
+	args = append(args, r.method, shellQuote(r.url))
+	for _, h := range r.headersWithContentType() {
+		args = append(args, shellQuote(h.Key+":"+h.Value))
+	}
+
+	switch r.body {
+	case bodyURLEncoded:
+		for _, kv := range r.form {
+			args = append(args, shellQuote(kv.Key+"="+kv.Value))
+		}
+	case bodyMultipart:
+		for _, f := range r.fields {
+			if f.file != "" {
+				args = append(args, shellQuote(f.key+"@"+f.file))
+				continue
+			}
+			args = append(args, shellQuote(f.key+"="+f.value))
+		}
+	case bodyBinary:
+		args = append(args, "< "+shellQuote(r.filePath))
+	}
+
+	return strings.Join(args, " \\\n  ") + "\n"
+}
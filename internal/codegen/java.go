@@ -0,0 +1,82 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func generateJava(r *request) string {
+	imports := map[string]bool{
+		"java.net.URI":               true,
+		"java.net.http.HttpClient":   true,
+		"java.net.http.HttpRequest":  true,
+		"java.net.http.HttpResponse": true,
+	}
+	var b strings.Builder
+
+	publisher := "HttpRequest.BodyPublishers.noBody()"
+	switch r.body {
+	case bodyRaw:
+		publisher = "HttpRequest.BodyPublishers.ofString(" + quote(r.raw) + ")"
+	case bodyURLEncoded:
+		publisher = "HttpRequest.BodyPublishers.ofString(" + quote(r.encodedForm()) + ")"
+	case bodyMultipart:
+		imports["java.nio.charset.StandardCharsets"] = true
+		imports["java.util.ArrayList"] = true
+		imports["java.util.List"] = true
+		b.WriteString("        String boundary = \"ChaparFormBoundary\";\n")
+		b.WriteString("        List<byte[]> parts = new ArrayList<>();\n")
+		for _, f := range r.fields {
+			if f.file != "" {
+				imports["java.nio.file.Files"] = true
+				imports["java.nio.file.Path"] = true
+				disposition := fmt.Sprintf("\r\nContent-Disposition: form-data; name=%q; filename=%q\r\n\r\n", f.key, f.fileName())
+				fmt.Fprintf(&b, "        parts.add((\"--\" + boundary + %s).getBytes(StandardCharsets.UTF_8));\n", quote(disposition))
+				fmt.Fprintf(&b, "        parts.add(Files.readAllBytes(Path.of(%s)));\n", quote(f.file))
+				b.WriteString("        parts.add(\"\\r\\n\".getBytes(StandardCharsets.UTF_8));\n")
+				continue
+			}
+			part := fmt.Sprintf("\r\nContent-Disposition: form-data; name=%q\r\n\r\n%s\r\n", f.key, f.value)
+			fmt.Fprintf(&b, "        parts.add((\"--\" + boundary + %s).getBytes(StandardCharsets.UTF_8));\n", quote(part))
+		}
+		b.WriteString("        parts.add((\"--\" + boundary + \"--\\r\\n\").getBytes(StandardCharsets.UTF_8));\n\n")
+		publisher = "HttpRequest.BodyPublishers.ofByteArrays(parts)"
+	case bodyBinary:
+		imports["java.nio.file.Path"] = true
+		publisher = "HttpRequest.BodyPublishers.ofFile(Path.of(" + quote(r.filePath) + "))"
+	}
+
+	b.WriteString("        HttpClient client = HttpClient.newHttpClient();\n")
+	b.WriteString("        HttpRequest request = HttpRequest.newBuilder()\n")
+	fmt.Fprintf(&b, "            .uri(URI.create(%s))\n", quote(r.url))
+	for _, h := range r.headersWithContentType() {
+		fmt.Fprintf(&b, "            .header(%s, %s)\n", quote(h.Key), quote(h.Value))
+	}
+	if r.body == bodyMultipart {
+		b.WriteString("            .header(\"Content-Type\", \"multipart/form-data; boundary=\" + boundary)\n")
+	}
+	fmt.Fprintf(&b, "            .method(%s, %s)\n", quote(r.method), publisher)
+	b.WriteString("            .build();\n\n")
+	b.WriteString("        HttpResponse<String> response = client.send(request, HttpResponse.BodyHandlers.ofString());\n")
+	b.WriteString("        System.out.println(response.statusCode());\n")
+	b.WriteString("        System.out.println(response.body());\n")
+
+	names := make([]string, 0, len(imports))
+	for name, used := range imports {
+		if used {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&out, "import %s;\n", name)
+	}
+	out.WriteString("\npublic class Main {\n")
+	out.WriteString("    public static void main(String[] args) throws Exception {\n")
+	out.WriteString(b.String())
+	out.WriteString("    }\n}\n")
+	return out.String()
+}
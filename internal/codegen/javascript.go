@@ -0,0 +1,115 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+func generateFetch(r *request) string {
+	var b strings.Builder
+
+	body := ""
+	switch r.body {
+	case bodyRaw:
+		body = "  body: " + quote(r.raw) + ",\n"
+	case bodyURLEncoded:
+		b.WriteString("const body = new URLSearchParams();\n")
+		for _, kv := range r.form {
+			fmt.Fprintf(&b, "body.append(%s, %s);\n", quote(kv.Key), quote(kv.Value))
+		}
+		b.WriteString("\n")
+		body = "  body,\n"
+	case bodyMultipart:
+		b.WriteString("const body = new FormData();\n")
+		for _, f := range r.fields {
+			if f.file != "" {
+				fmt.Fprintf(&b, "body.append(%s, new Blob([/* content of %s */]), %s);\n", quote(f.key), comment(f.file), quote(f.fileName()))
+				continue
+			}
+			fmt.Fprintf(&b, "body.append(%s, %s);\n", quote(f.key), quote(f.value))
+		}
+		b.WriteString("\n")
+		body = "  body,\n"
+	case bodyBinary:
+		fmt.Fprintf(&b, "const body = new Blob([/* content of %s */]);\n\n", comment(r.filePath))
+		body = "  body,\n"
+	}
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", quote(r.url))
+	fmt.Fprintf(&b, "  method: %s,\n", quote(r.method))
+	if headers := r.headersWithContentType(); len(headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h.Key), quote(h.Value))
+		}
+		b.WriteString("  },\n")
+	}
+	b.WriteString(body)
+	b.WriteString("});\n\n")
+	b.WriteString("console.log(response.status);\nconsole.log(await response.text());\n")
+	return b.String()
+}
+
+func generateAxios(r *request) string {
+	var b strings.Builder
+	b.WriteString("const axios = require(\"axios\");\n")
+	if r.hasFiles() {
+		b.WriteString("const fs = require(\"fs\");\n")
+	}
+	if r.body == bodyMultipart {
+		b.WriteString("const FormData = require(\"form-data\");\n")
+	}
+	b.WriteString("\n")
+
+	data := ""
+	switch r.body {
+	case bodyRaw:
+		data = "  data: " + quote(r.raw) + ",\n"
+	case bodyURLEncoded:
+		b.WriteString("const data = new URLSearchParams();\n")
+		for _, kv := range r.form {
+			fmt.Fprintf(&b, "data.append(%s, %s);\n", quote(kv.Key), quote(kv.Value))
+		}
+		b.WriteString("\n")
+		data = "  data,\n"
+	case bodyMultipart:
+		b.WriteString("const data = new FormData();\n")
+		for _, f := range r.fields {
+			if f.file != "" {
+				fmt.Fprintf(&b, "data.append(%s, fs.createReadStream(%s));\n", quote(f.key), quote(f.file))
+				continue
+			}
+			fmt.Fprintf(&b, "data.append(%s, %s);\n", quote(f.key), quote(f.value))
+		}
+		b.WriteString("\n")
+		data = "  data,\n"
+	case bodyBinary:
+		fmt.Fprintf(&b, "const data = fs.readFileSync(%s);\n\n", quote(r.filePath))
+		data = "  data,\n"
+	}
+
+	b.WriteString("axios({\n")
+	fmt.Fprintf(&b, "  method: %s,\n", quote(strings.ToLower(r.method)))
+	fmt.Fprintf(&b, "  url: %s,\n", quote(r.url))
+	if headers := r.headersWithContentType(); len(headers) > 0 || r.body == bodyMultipart {
+		b.WriteString("  headers: {\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h.Key), quote(h.Value))
+		}
+		if r.body == bodyMultipart {
+			b.WriteString("    ...data.getHeaders(),\n")
+		}
+		b.WriteString("  },\n")
+	}
+	b.WriteString(data)
+	b.WriteString(`})
+  .then((response) => {
+    console.log(response.status);
+    console.log(response.data);
+  })
+  .catch((error) => {
+    console.error(error);
+  });
+`)
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+func generatePHP(r *request) string {
+	var b strings.Builder
+	b.WriteString("<?php\n\n$curl = curl_init();\n\n")
+	b.WriteString("curl_setopt_array($curl, [\n")
+	fmt.Fprintf(&b, "    CURLOPT_URL => %s,\n", phpString(r.url))
+	b.WriteString("    CURLOPT_RETURNTRANSFER => true,\n")
+	fmt.Fprintf(&b, "    CURLOPT_CUSTOMREQUEST => %s,\n", phpString(r.method))
+
+	if headers := r.headersWithContentType(); len(headers) > 0 {
+		b.WriteString("    CURLOPT_HTTPHEADER => [\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "        %s,\n", phpString(h.Key+": "+h.Value))
+		}
+		b.WriteString("    ],\n")
+	}
+
+	switch r.body {
+	case bodyRaw:
+		fmt.Fprintf(&b, "    CURLOPT_POSTFIELDS => %s,\n", phpString(r.raw))
+	case bodyURLEncoded:
+		fmt.Fprintf(&b, "    CURLOPT_POSTFIELDS => %s,\n", phpString(r.encodedForm()))
+	case bodyMultipart:
+		b.WriteString("    CURLOPT_POSTFIELDS => [\n")
+		for _, f := range r.fields {
+			if f.file != "" {
+				fmt.Fprintf(&b, "        %s => new CURLFile(%s),\n", phpString(f.key), phpString(f.file))
+				continue
+			}
+			fmt.Fprintf(&b, "        %s => %s,\n", phpString(f.key), phpString(f.value))
+		}
+		b.WriteString("    ],\n")
+	case bodyBinary:
+		fmt.Fprintf(&b, "    CURLOPT_POSTFIELDS => file_get_contents(%s),\n", phpString(r.filePath))
+	}
+
+	b.WriteString("]);\n\n")
+	b.WriteString("$response = curl_exec($curl);\n")
+	b.WriteString("$status = curl_getinfo($curl, CURLINFO_HTTP_CODE);\n")
+	b.WriteString("curl_close($curl);\n\n")
+	b.WriteString("echo $status . \"\\n\";\n")
+	b.WriteString("echo $response;\n")
+	return b.String()
+}
+
+// phpString returns a single quoted string, its content is not interpolated.
+func phpString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,54 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+func generatePython(r *request) string {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", quote(r.url))
+
+	args := []string{"url"}
+	if headers := r.headersWithContentType(); len(headers) > 0 {
+		b.WriteString("\nheaders = {\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h.Key), quote(h.Value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+
+	switch r.body {
+	case bodyRaw:
+		fmt.Fprintf(&b, "\npayload = %s\n", quote(r.raw))
+		args = append(args, "data=payload")
+	case bodyURLEncoded:
+		b.WriteString("\npayload = [\n")
+		for _, kv := range r.form {
+			fmt.Fprintf(&b, "    (%s, %s),\n", quote(kv.Key), quote(kv.Value))
+		}
+		b.WriteString("]\n")
+		args = append(args, "data=payload")
+	case bodyMultipart:
+		// a None file name sends the field as a plain value
+		b.WriteString("\nfiles = [\n")
+		for _, f := range r.fields {
+			if f.file != "" {
+				fmt.Fprintf(&b, "    (%s, (%s, open(%s, \"rb\"))),\n", quote(f.key), quote(f.fileName()), quote(f.file))
+				continue
+			}
+			fmt.Fprintf(&b, "    (%s, (None, %s)),\n", quote(f.key), quote(f.value))
+		}
+		b.WriteString("]\n")
+		args = append(args, "files=files")
+	case bodyBinary:
+		fmt.Fprintf(&b, "\npayload = open(%s, \"rb\")\n", quote(r.filePath))
+		args = append(args, "data=payload")
+	}
+
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s, %s)\n\n", quote(r.method), strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\nprint(response.text)\n")
+	return b.String()
+}
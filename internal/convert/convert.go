@@ -0,0 +1,74 @@
+// Package convert holds what the importers and the exporters of other tools' formats share.
+package convert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report lists the parts of an imported or exported item which could not be converted.
+type Report struct {
+	Items []ReportItem
+}
+
+type ReportItem struct {
+	// Path is where the item was found, such as the folders or the collection and the name of a request.
+	Path   string
+	Reason string
+}
+
+func (r *Report) Add(path, format string, args ...any) {
+	r.Items = append(r.Items, ReportItem{Path: path, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) IsEmpty() bool {
+	return r == nil || len(r.Items) == 0
+}
+
+// String returns a line per item.
+func (r *Report) String() string {
+	if r.IsEmpty() {
+		return ""
+	}
+
+	lines := make([]string, 0, len(r.Items))
+	for _, item := range r.Items {
+		lines = append(lines, fmt.Sprintf("%s: %s", item.Path, item.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// postmanVariables pairs the Postman dynamic variables with the functions with the same behaviour, the
+// Postman fakers such as {{$randomFirstName}} are registered with their own names. When several Postman
+// variables map to the same function the first one is used to export it.
+var postmanVariables = [][2]string{
+	{"{{$guid}}", "{{$uuid}}"},
+	{"{{$randomUUID}}", "{{$uuid}}"},
+	{"{{$isoTimestamp}}", "{{$now}}"},
+	{"{{$randomInt}}", "{{$randInt 0 1000}}"},
+}
+
+var fromPostman, toPostman = postmanReplacers()
+
+func postmanReplacers() (*strings.Replacer, *strings.Replacer) {
+	var from, to []string
+	seen := make(map[string]bool)
+	for _, pair := range postmanVariables {
+		from = append(from, pair[0], pair[1])
+		if !seen[pair[1]] {
+			to = append(to, pair[1], pair[0])
+			seen[pair[1]] = true
+		}
+	}
+	return strings.NewReplacer(from...), strings.NewReplacer(to...)
+}
+
+// FromPostmanVariables replaces the Postman dynamic variables with the matching functions.
+func FromPostmanVariables(s string) string {
+	return fromPostman.Replace(s)
+}
+
+// ToPostmanVariables replaces the functions which have a Postman dynamic variable with the same behaviour.
+func ToPostmanVariables(s string) string {
+	return toPostman.Replace(s)
+}
@@ -0,0 +1,33 @@
+package convert
+
+import "testing"
+
+func TestPostmanVariables(t *testing.T) {
+	postman := `{"id":"{{$guid}}","uuid":"{{$randomUUID}}","at":"{{$isoTimestamp}}","n":"{{$randomInt}}","name":"{{$randomFirstName}}"}`
+
+	imported := FromPostmanVariables(postman)
+	want := `{"id":"{{$uuid}}","uuid":"{{$uuid}}","at":"{{$now}}","n":"{{$randInt 0 1000}}","name":"{{$randomFirstName}}"}`
+	if imported != want {
+		t.Fatalf("FromPostmanVariables() = %s, want %s", imported, want)
+	}
+
+	exported := ToPostmanVariables(imported)
+	want = `{"id":"{{$guid}}","uuid":"{{$guid}}","at":"{{$isoTimestamp}}","n":"{{$randomInt}}","name":"{{$randomFirstName}}"}`
+	if exported != want {
+		t.Fatalf("ToPostmanVariables() = %s, want %s", exported, want)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var r *Report
+	if !r.IsEmpty() || r.String() != "" {
+		t.Fatal("nil report should be empty")
+	}
+
+	r = &Report{}
+	r.Add("col/req", "auth %s is not supported", "ntlm")
+	r.Add("col", "scripts are dropped")
+	if got, want := r.String(), "col/req: auth ntlm is not supported\ncol: scripts are dropped"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,450 @@
+package curl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+var ErrNotCurl = errors.New("not a curl command")
+
+// valueFlags are the flags which take a value, the ones which are not converted are skipped along with their value.
+var valueFlags = map[string]bool{
+	"-X": true, "--request": true,
+	"-H": true, "--header": true,
+	"-d": true, "--data": true, "--data-ascii": true, "--data-raw": true, "--data-binary": true, "--data-urlencode": true,
+	"-F": true, "--form": true, "--form-string": true,
+	"-u": true, "--user": true,
+	"-b": true, "--cookie": true,
+	"-A": true, "--user-agent": true,
+	"-e": true, "--referer": true,
+	"--url": true,
+	"-o":    true, "--output": true,
+	"-m": true, "--max-time": true, "--connect-timeout": true,
+	"--retry": true, "-w": true, "--write-out": true,
+	"-x": true, "--proxy": true,
+	"-c": true, "--cookie-jar": true,
+	"-E": true, "--cert": true, "--key": true, "--cacert": true,
+	"-T": true, "--upload-file": true,
+	"--resolve": true, "--connect-to": true, "--limit-rate": true,
+}
+
+// IsCommand reports whether the text is a curl command.
+func IsCommand(text string) bool {
+	text = strings.TrimSpace(text)
+	return text == "curl" || strings.HasPrefix(text, "curl ") || strings.HasPrefix(text, "curl\t") || strings.HasPrefix(text, "curl\\")
+}
+
+type command struct {
+	method  string
+	url     string
+	headers []domain.KeyValue
+	data    []string
+	binary  string
+	encoded bool
+	form    []domain.FormField
+	user    string
+	get     bool
+	head    bool
+}
+
+// Parse converts a curl command to an http request named after its method and path.
+// The -k and --compressed flags are accepted as a no-op, http requests have no tls settings of their
+// own and the responses are decompressed anyway.
+func Parse(text string) (*domain.Request, error) {
+	if !IsCommand(text) {
+		return nil, ErrNotCurl
+	}
+
+	args, err := split(text)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := &command{}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if cmd.url == "" {
+				cmd.url = arg
+			}
+			continue
+		}
+
+		name, value, hasValue := arg, "", false
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue = strings.Cut(arg, "=")
+		case len(arg) > 2:
+			// short flags can be combined such as -sSL or be followed by their value such as -XPOST
+			for j := 1; j < len(arg); j++ {
+				flag := "-" + string(arg[j])
+				if valueFlags[flag] {
+					name = flag
+					value, hasValue = arg[j+1:], j+1 < len(arg)
+					break
+				}
+				name = flag
+				cmd.flag(name, "")
+			}
+			if !valueFlags[name] {
+				continue
+			}
+		}
+
+		if !valueFlags[name] {
+			cmd.flag(name, "")
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		cmd.flag(name, value)
+	}
+
+	if cmd.url == "" {
+		return nil, errors.New("curl command has no url")
+	}
+
+	return cmd.request(), nil
+}
+
+func (c *command) flag(name, value string) {
+	switch name {
+	case "-X", "--request":
+		c.method = strings.ToUpper(value)
+	case "-H", "--header":
+		key, val, _ := strings.Cut(value, ":")
+		c.headers = append(c.headers, keyValue(strings.TrimSpace(key), strings.TrimSpace(val)))
+	case "-d", "--data", "--data-ascii", "--data-raw":
+		c.data = append(c.data, value)
+	case "--data-binary":
+		if strings.HasPrefix(value, "@") {
+			c.binary = value[1:]
+			return
+		}
+		c.data = append(c.data, value)
+	case "--data-urlencode":
+		c.data = append(c.data, urlEncode(value))
+		c.encoded = true
+	case "-F", "--form", "--form-string":
+		c.form = append(c.form, formField(value, name == "--form-string"))
+	case "-u", "--user":
+		c.user = value
+	case "-b", "--cookie":
+		// without a = the value is the name of a file to read the cookies from
+		if strings.Contains(value, "=") {
+			c.headers = append(c.headers, keyValue("Cookie", value))
+		}
+	case "-A", "--user-agent":
+		c.headers = append(c.headers, keyValue("User-Agent", value))
+	case "-e", "--referer":
+		c.headers = append(c.headers, keyValue("Referer", value))
+	case "--url":
+		c.url = value
+	case "-G", "--get":
+		c.get = true
+	case "-I", "--head":
+		c.head = true
+	}
+}
+
+func (c *command) request() *domain.Request {
+	rawURL := c.url
+	if !strings.Contains(rawURL, "://") && !strings.HasPrefix(rawURL, "{{") {
+		rawURL = "http://" + rawURL
+	}
+
+	base, query := splitURL(rawURL)
+	if c.get && len(c.data) > 0 {
+		_, values := splitURL("?" + strings.Join(c.data, "&"))
+		query = append(query, values...)
+		c.data = nil
+	}
+
+	httpReq := &domain.HTTPRequest{
+		Headers:     make([]domain.KeyValue, 0, len(c.headers)),
+		PathParams:  make([]domain.KeyValue, 0),
+		QueryParams: query,
+		Body:        domain.Body{Type: domain.BodyTypeNone},
+		Auth:        domain.Auth{Type: domain.AuthTypeNone},
+	}
+
+	contentType := ""
+	for _, h := range c.headers {
+		if strings.EqualFold(h.Key, "Authorization") && setAuth(&httpReq.Auth, h.Value) {
+			continue
+		}
+		if strings.EqualFold(h.Key, "Content-Type") {
+			contentType = strings.ToLower(h.Value)
+			// the multipart boundary is set when the body is built
+			if len(c.form) > 0 {
+				continue
+			}
+		}
+		httpReq.Headers = append(httpReq.Headers, h)
+	}
+
+	if c.user != "" {
+		username, password, _ := strings.Cut(c.user, ":")
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: username, Password: password}}
+	}
+
+	data := strings.Join(c.data, "&")
+	switch {
+	case len(c.form) > 0:
+		httpReq.Body = domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: c.form}}
+	case c.binary != "":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeBinary, BinaryFilePath: c.binary}
+	case len(c.data) == 0:
+	case strings.Contains(contentType, "json") || (contentType == "" && json.Valid([]byte(data))):
+		httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: data}
+	case strings.Contains(contentType, "xml"):
+		httpReq.Body = domain.Body{Type: domain.BodyTypeXML, Data: data}
+	case strings.Contains(contentType, "x-www-form-urlencoded") || (contentType == "" && (c.encoded || isForm(data))):
+		_, values := splitURL("?" + data)
+		httpReq.Body = domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: values}
+	default:
+		httpReq.Body = domain.Body{Type: domain.BodyTypeText, Data: data}
+	}
+
+	method := c.method
+	if method == "" {
+		switch {
+		case c.head:
+			method = domain.RequestMethodHEAD
+		case httpReq.Body.Type != domain.BodyTypeNone:
+			method = domain.RequestMethodPOST
+		default:
+			method = domain.RequestMethodGET
+		}
+	}
+
+	name := method + " /"
+	if u, err := url.Parse(base); err == nil && u.Path != "" {
+		name = method + " " + u.Path
+	}
+
+	req := domain.NewHTTPRequest(name)
+	req.Spec.HTTP = &domain.HTTPRequestSpec{
+		Method:  method,
+		URL:     joinQuery(base, query),
+		Request: httpReq,
+	}
+	return req
+}
+
+// setAuth converts the value of an authorization header to the auth of the request, it reports
+// whether the scheme is supported.
+func setAuth(auth *domain.Auth, value string) bool {
+	scheme, credentials, _ := strings.Cut(strings.TrimSpace(value), " ")
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		*auth = domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: strings.TrimSpace(credentials)}}
+		return true
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+		if err != nil {
+			return false
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		*auth = domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: username, Password: password}}
+		return true
+	}
+	return false
+}
+
+// formField converts the value of a -F flag, name=@path is a file and name=<path reads the value from a file
+// which is converted to a file field as well.
+func formField(value string, literal bool) domain.FormField {
+	key, val, _ := strings.Cut(value, "=")
+	field := domain.FormField{ID: uuid.NewString(), Type: domain.FormFieldTypeText, Key: key, Value: val, Enable: true}
+	if literal {
+		return field
+	}
+
+	if strings.HasPrefix(val, "@") || strings.HasPrefix(val, "<") {
+		path, _, _ := strings.Cut(val[1:], ";")
+		field.Type = domain.FormFieldTypeFile
+		field.Value = ""
+		field.Files = []string{strings.Trim(path, `"`)}
+		return field
+	}
+
+	// drop the ;type= and ;filename= options
+	if v, _, ok := strings.Cut(val, ";type="); ok {
+		field.Value = v
+	}
+	return field
+}
+
+// urlEncode encodes the value of a --data-urlencode flag the way curl does.
+func urlEncode(value string) string {
+	if name, content, ok := strings.Cut(value, "="); ok {
+		if name == "" {
+			return url.QueryEscape(content)
+		}
+		return name + "=" + url.QueryEscape(content)
+	}
+	return url.QueryEscape(value)
+}
+
+// isForm reports whether the data looks like name=value pairs.
+func isForm(data string) bool {
+	for _, pair := range strings.Split(data, "&") {
+		key, _, ok := strings.Cut(pair, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \n\t{}[]\"") {
+			return false
+		}
+	}
+	return true
+}
+
+func keyValue(key, value string) domain.KeyValue {
+	return domain.KeyValue{ID: uuid.NewString(), Key: key, Value: value, Enable: true}
+}
+
+// splitURL splits the query of the url into params, the fragment is dropped.
+func splitURL(raw string) (string, []domain.KeyValue) {
+	raw, _, _ = strings.Cut(raw, "#")
+	base, query, _ := strings.Cut(raw, "?")
+
+	params := make([]domain.KeyValue, 0)
+	if query == "" {
+		return base, params
+	}
+
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		params = append(params, keyValue(key, value))
+	}
+	return base, params
+}
+
+// joinQuery writes the params to the url unescaped, so the variables in them are kept as they are.
+func joinQuery(base string, params []domain.KeyValue) string {
+	pairs := make([]string, 0, len(params))
+	for _, p := range params {
+		if p.Enable {
+			pairs = append(pairs, p.Key+"="+p.Value)
+		}
+	}
+	if len(pairs) == 0 {
+		return base
+	}
+	return base + "?" + strings.Join(pairs, "&")
+}
+
+// split splits the command to its arguments the way a posix shell does, it supports single and double
+// quotes, $” strings and lines continued with a backslash.
+func split(text string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inWord  bool
+	)
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 >= len(runes) {
+				continue
+			}
+			next := runes[i+1]
+			i++
+			// a line continuation, pasted to a single line editor the new line is replaced with a space
+			if next == '\n' || next == '\r' || (next == ' ' && !inWord) {
+				continue
+			}
+			current.WriteRune(next)
+			inWord = true
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+			inWord = true
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			end := i + 2
+			for ; end < len(runes) && runes[end] != '\''; end++ {
+				if runes[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(runes) {
+				return nil, errors.New("unterminated quote")
+			}
+			current.WriteString(unescapeANSI(string(runes[i+2 : end])))
+			i = end
+			inWord = true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated quote")
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeANSI replaces the common escapes of a $” string.
+func unescapeANSI(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "\r", `\'`, "'", `\"`, `"`, `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,123 @@
+package curl
+
+import (
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestParse(t *testing.T) {
+	cmd := `curl 'https://api.example.com/users?page=2&q=a%20b' \
+  -X PUT \
+  -H 'Content-Type: application/json' \
+  -H "Authorization: Bearer tok" \
+  --cookie 'session=abc' \
+  --data-raw '{"name": "it'\''s"}' \
+  -k --compressed`
+
+	req, err := Parse(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := req.Spec.HTTP
+	if spec.Method != "PUT" || spec.URL != "https://api.example.com/users?page=2&q=a b" {
+		t.Fatalf("unexpected method or url: %s %s", spec.Method, spec.URL)
+	}
+	if len(spec.Request.QueryParams) != 2 || spec.Request.QueryParams[1].Value != "a b" {
+		t.Fatalf("unexpected query params: %+v", spec.Request.QueryParams)
+	}
+	if spec.Request.Auth.Type != domain.AuthTypeToken || spec.Request.Auth.TokenAuth.Token != "tok" {
+		t.Fatalf("unexpected auth: %+v", spec.Request.Auth)
+	}
+	if len(spec.Request.Headers) != 2 || spec.Request.Headers[1].Key != "Cookie" {
+		t.Fatalf("unexpected headers: %+v", spec.Request.Headers)
+	}
+	if spec.Request.Body.Type != domain.BodyTypeJSON || spec.Request.Body.Data != `{"name": "it's"}` {
+		t.Fatalf("unexpected body: %+v", spec.Request.Body)
+	}
+}
+
+func TestParseForms(t *testing.T) {
+	req, err := Parse(`curl -sSL -u user:pass -F name=john -F 'avatar=@/tmp/me.png;type=image/png' example.com/upload`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := req.Spec.HTTP
+	if spec.Method != "POST" || spec.URL != "http://example.com/upload" {
+		t.Fatalf("unexpected method or url: %s %s", spec.Method, spec.URL)
+	}
+	if spec.Request.Auth.Type != domain.AuthTypeBasic || spec.Request.Auth.BasicAuth.Password != "pass" {
+		t.Fatalf("unexpected auth: %+v", spec.Request.Auth)
+	}
+	fields := spec.Request.Body.FormData.Fields
+	if len(fields) != 2 || fields[1].Type != domain.FormFieldTypeFile || fields[1].Files[0] != "/tmp/me.png" {
+		t.Fatalf("unexpected form fields: %+v", fields)
+	}
+
+	req, err = Parse(`curl -d a=1 -d b=2 https://example.com`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := req.Spec.HTTP.Request.Body; body.Type != domain.BodyTypeUrlencoded || len(body.URLEncoded) != 2 {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+
+	if _, err := Parse(`wget https://example.com`); err != ErrNotCurl {
+		t.Fatalf("expected ErrNotCurl, got %v", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	spec := &domain.HTTPRequestSpec{
+		Method: "POST",
+		URL:    "https://example.com/users/{id}",
+		Request: &domain.HTTPRequest{
+			Headers:    []domain.KeyValue{{Key: "X-Trace", Value: "it's", Enable: true}, {Key: "X-Off", Value: "1"}},
+			PathParams: []domain.KeyValue{{Key: "id", Value: "7", Enable: true}},
+			Body:       domain.Body{Type: domain.BodyTypeJSON, Data: `{"a": 1}`},
+			Auth:       domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: "u", Password: "p w"}},
+		},
+	}
+
+	cmd := FromHTTPRequest(spec)
+	req, err := Parse(cmd)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, cmd)
+	}
+
+	got := req.Spec.HTTP
+	if got.Method != "POST" || got.URL != "https://example.com/users/7" {
+		t.Fatalf("unexpected method or url: %s %s\n%s", got.Method, got.URL, cmd)
+	}
+	if len(got.Request.Headers) != 1 || got.Request.Headers[0].Value != "it's" {
+		t.Fatalf("unexpected headers: %+v\n%s", got.Request.Headers, cmd)
+	}
+	if got.Request.Auth.BasicAuth == nil || got.Request.Auth.BasicAuth.Password != "p w" {
+		t.Fatalf("unexpected auth: %+v\n%s", got.Request.Auth, cmd)
+	}
+	if got.Request.Body.Data != `{"a": 1}` {
+		t.Fatalf("unexpected body: %+v\n%s", got.Request.Body, cmd)
+	}
+}
+
+func TestFromGRPCRequest(t *testing.T) {
+	spec := &domain.GRPCRequestSpec{
+		LasSelectedMethod: "/helloworld.Greeter/SayHello",
+		Metadata:          []domain.KeyValue{{Key: "x-user", Value: "1", Enable: true}},
+		ServerInfo:        domain.ServerInfo{Address: "localhost:50051", ServerReflection: true},
+		Settings:          domain.Settings{Insecure: true},
+		Body:              `{"name": "world"}`,
+	}
+
+	want := `grpcurl \
+  -plaintext \
+  -H 'x-user: 1' \
+  -d '{"name": "world"}' \
+  localhost:50051 \
+  helloworld.Greeter/SayHello`
+	if got := FromGRPCRequest(spec); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
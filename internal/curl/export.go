@@ -0,0 +1,168 @@
+package curl
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// FromHTTPRequest returns the curl command sending the request, the variables of the spec should be resolved already.
+func FromHTTPRequest(spec *domain.HTTPRequestSpec) string {
+	req := spec.Request
+	if req == nil {
+		req = &domain.HTTPRequest{}
+	}
+
+	address := spec.URL
+	for _, p := range req.PathParams {
+		if p.Enable {
+			address = strings.ReplaceAll(address, "{"+p.Key+"}", p.Value)
+		}
+	}
+
+	args := []string{"curl"}
+	if spec.Method != "" && spec.Method != domain.RequestMethodGET {
+		args = append(args, "-X "+spec.Method)
+	}
+	args = append(args, quote(address))
+
+	for _, h := range req.Headers {
+		if h.Enable && h.Key != "" {
+			args = append(args, "-H "+quote(h.Key+": "+h.Value))
+		}
+	}
+
+	switch req.Auth.Type {
+	case domain.AuthTypeBasic:
+		if req.Auth.BasicAuth != nil {
+			args = append(args, "-u "+quote(req.Auth.BasicAuth.Username+":"+req.Auth.BasicAuth.Password))
+		}
+	case domain.AuthTypeToken:
+		if req.Auth.TokenAuth != nil {
+			args = append(args, "-H "+quote("Authorization: Bearer "+req.Auth.TokenAuth.Token))
+		}
+	case domain.AuthTypeAPIKey:
+		if req.Auth.APIKeyAuth != nil {
+			args = append(args, "-H "+quote(req.Auth.APIKeyAuth.Key+": "+req.Auth.APIKeyAuth.Value))
+		}
+	}
+
+	body := req.Body
+	switch body.Type {
+	case domain.BodyTypeJSON, domain.BodyTypeXML, domain.BodyTypeText:
+		if body.Data != "" {
+			args = append(args, "--data-raw "+quote(body.Data))
+		}
+	case domain.BodyTypeUrlencoded:
+		for _, kv := range body.URLEncoded {
+			if kv.Enable {
+				args = append(args, "--data-urlencode "+quote(kv.Key+"="+kv.Value))
+			}
+		}
+	case domain.BodyTypeFormData:
+		for _, f := range body.FormData.Fields {
+			if !f.Enable {
+				continue
+			}
+			if f.Type == domain.FormFieldTypeFile {
+				for _, file := range f.Files {
+					args = append(args, "-F "+quote(f.Key+"=@"+file))
+				}
+				continue
+			}
+			args = append(args, "-F "+quote(f.Key+"="+f.Value))
+		}
+	case domain.BodyTypeBinary:
+		if body.BinaryFilePath != "" {
+			args = append(args, "--data-binary "+quote("@"+body.BinaryFilePath))
+		}
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// FromGRPCRequest returns the grpcurl command invoking the selected method of the request, the variables of the
+// spec should be resolved already. grpcurl only speaks native gRPC, the command ignores the protocol of the server.
+func FromGRPCRequest(spec *domain.GRPCRequestSpec) string {
+	args := []string{"grpcurl"}
+
+	settings := spec.Settings
+	if settings.Insecure {
+		args = append(args, "-plaintext")
+	}
+	if settings.Authority != "" {
+		args = append(args, "-authority "+quote(settings.Authority))
+	}
+	if settings.NameOverride != "" {
+		args = append(args, "-servername "+quote(settings.NameOverride))
+	}
+	if settings.RootCertFile != "" {
+		args = append(args, "-cacert "+quote(settings.RootCertFile))
+	}
+	if settings.ClientCertFile != "" {
+		args = append(args, "-cert "+quote(settings.ClientCertFile), "-key "+quote(settings.ClientKeyFile))
+	}
+	if settings.TimeoutMilliseconds > 0 {
+		args = append(args, "-max-time "+strconv.FormatFloat(float64(settings.TimeoutMilliseconds)/1000, 'f', -1, 64))
+	}
+	if settings.MaxReceiveMessageSize > 0 {
+		args = append(args, "-max-msg-sz "+strconv.Itoa(settings.MaxReceiveMessageSize))
+	}
+
+	if !spec.ServerInfo.ServerReflection {
+		seen := make(map[string]bool)
+		for _, file := range spec.ServerInfo.ProtoFiles {
+			if dir := filepath.Dir(file); !seen[dir] {
+				seen[dir] = true
+				args = append(args, "-import-path "+quote(dir))
+			}
+		}
+		for _, file := range spec.ServerInfo.ProtoFiles {
+			args = append(args, "-proto "+quote(filepath.Base(file)))
+		}
+	}
+
+	for _, m := range spec.Metadata {
+		if m.Enable && m.Key != "" {
+			args = append(args, "-H "+quote(m.Key+": "+m.Value))
+		}
+	}
+
+	// the same metadata the service sends for the auth of the request
+	switch spec.Auth.Type {
+	case domain.AuthTypeToken:
+		if spec.Auth.TokenAuth != nil {
+			args = append(args, "-H "+quote("Authorization: Bearer "+spec.Auth.TokenAuth.Token))
+		}
+	case domain.AuthTypeBasic:
+		if spec.Auth.BasicAuth != nil {
+			args = append(args, "-H "+quote(fmt.Sprintf("Authorization: Basic %s:%s", spec.Auth.BasicAuth.Username, spec.Auth.BasicAuth.Password)))
+		}
+	case domain.AuthTypeAPIKey:
+		if spec.Auth.APIKeyAuth != nil {
+			args = append(args, "-H "+quote(spec.Auth.APIKeyAuth.Key+": "+spec.Auth.APIKeyAuth.Value))
+		}
+	}
+
+	if strings.TrimSpace(spec.Body) != "" {
+		args = append(args, "-d "+quote(spec.Body))
+	}
+
+	args = append(args, quote(spec.ServerInfo.Address))
+	if method := strings.TrimPrefix(spec.LasSelectedMethod, "/"); method != "" {
+		args = append(args, quote(method))
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// quote quotes the value for a posix shell unless it only has safe characters.
+func quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@,+=%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
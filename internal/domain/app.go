@@ -24,6 +24,8 @@ type KeyValue struct {
 	Key    string `yaml:"key"`
 	Value  string `yaml:"value"`
 	Enable bool   `yaml:"enable"`
+	// Secret values are kept in the encrypted vault and are not written to the yaml files.
+	Secret bool `yaml:"secret,omitempty"`
 }
 
 // CompareKeyValues compares two slices of KeyValue and returns true if they are equal
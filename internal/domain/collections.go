@@ -1,6 +1,10 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
 
 type Collection struct {
 	ApiVersion string   `yaml:"apiVersion"`
@@ -13,6 +17,11 @@ type Collection struct {
 
 type ColSpec struct {
 	Requests []*Request `yaml:"requests"`
+	// Order is the ids of the requests in the order they are listed and run, the requests missing from it
+	// come after them.
+	Order []string `yaml:"order,omitempty"`
+	// Variables are shared by the requests of the collection.
+	Variables []KeyValue `yaml:"variables,omitempty"`
 }
 
 func (c *Collection) Clone() *Collection {
@@ -24,7 +33,9 @@ func (c *Collection) Clone() *Collection {
 			Name: c.MetaData.Name,
 		},
 		Spec: ColSpec{
-			Requests: make([]*Request, len(c.Spec.Requests)),
+			Requests:  make([]*Request, len(c.Spec.Requests)),
+			Variables: append([]KeyValue(nil), c.Spec.Variables...),
+			Order:     append([]string(nil), c.Spec.Order...),
 		},
 		FilePath: c.FilePath,
 	}
@@ -69,3 +80,51 @@ func (c *Collection) FindRequestByID(id string) *Request {
 	}
 	return nil
 }
+
+// MoveRequest moves the request with the id to the index and stores the new order of the requests.
+func (c *Collection) MoveRequest(id string, index int) bool {
+	from := -1
+	for i, r := range c.Spec.Requests {
+		if r.MetaData.ID == id {
+			from = i
+			break
+		}
+	}
+	if from == -1 {
+		return false
+	}
+
+	index = max(0, min(index, len(c.Spec.Requests)-1))
+	req := c.Spec.Requests[from]
+	c.Spec.Requests = append(c.Spec.Requests[:from], c.Spec.Requests[from+1:]...)
+	c.Spec.Requests = append(c.Spec.Requests[:index], append([]*Request{req}, c.Spec.Requests[index:]...)...)
+
+	c.Spec.Order = make([]string, 0, len(c.Spec.Requests))
+	for _, r := range c.Spec.Requests {
+		c.Spec.Order = append(c.Spec.Order, r.MetaData.ID)
+	}
+	return true
+}
+
+// SortRequests sorts the requests in the stored order.
+func (c *Collection) SortRequests() {
+	if len(c.Spec.Order) == 0 {
+		return
+	}
+
+	position := make(map[string]int, len(c.Spec.Order))
+	for i, id := range c.Spec.Order {
+		position[id] = i
+	}
+
+	rank := func(r *Request) int {
+		if i, ok := position[r.MetaData.ID]; ok {
+			return i
+		}
+		return len(position)
+	}
+
+	sort.SliceStable(c.Spec.Requests, func(i, j int) bool {
+		return rank(c.Spec.Requests[i]) < rank(c.Spec.Requests[j])
+	})
+}
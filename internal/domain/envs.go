@@ -1,8 +1,6 @@
 package domain
 
 import (
-	"strings"
-
 	"github.com/google/uuid"
 )
 
@@ -15,12 +13,24 @@ type Environment struct {
 }
 
 type EnvSpec struct {
-	Values []KeyValue `yaml:"values"`
+	// Extends is the id of the base environment, its values are inherited unless the environment overrides them.
+	Extends string     `yaml:"extends,omitempty"`
+	Values  []KeyValue `yaml:"values"`
+}
+
+// EffectiveValue is a value of an environment after the values of its base environments are merged in.
+type EffectiveValue struct {
+	KeyValue
+	// Source is the id of the environment which defines the value.
+	Source string
+	// Overridden is true when the value overrides a value of a base environment.
+	Overridden bool
 }
 
 func (e *EnvSpec) Clone() EnvSpec {
 	clone := EnvSpec{
-		Values: make([]KeyValue, len(e.Values)),
+		Extends: e.Extends,
+		Values:  make([]KeyValue, len(e.Values)),
 	}
 
 	for i, v := range e.Values {
@@ -29,12 +39,25 @@ func (e *EnvSpec) Clone() EnvSpec {
 			Key:    v.Key,
 			Value:  v.Value,
 			Enable: v.Enable,
+			Secret: v.Secret,
 		}
 	}
 
 	return clone
 }
 
+// WithoutSecrets returns a copy of the spec with the values of the secrets removed.
+func (e *EnvSpec) WithoutSecrets() EnvSpec {
+	out := EnvSpec{Extends: e.Extends, Values: make([]KeyValue, len(e.Values))}
+	for i, v := range e.Values {
+		if v.Secret {
+			v.Value = ""
+		}
+		out.Values[i] = v
+	}
+	return out
+}
+
 func NewEnvironment(name string) *Environment {
 	return &Environment{
 		ApiVersion: ApiVersion,
@@ -56,7 +79,7 @@ func CompareEnvValue(a, b KeyValue) bool {
 		return false
 	}
 
-	if a.Key != b.Key || a.Value != b.Value || a.Enable != b.Enable || a.ID != b.ID {
+	if a.Key != b.Key || a.Value != b.Value || a.Enable != b.Enable || a.ID != b.ID || a.Secret != b.Secret {
 		return false
 	}
 
@@ -93,49 +116,3 @@ func (e *Environment) SetKey(key string, value string) {
 		Enable: true,
 	})
 }
-
-func (e *Environment) ApplyToGRPCRequest(req *GRPCRequestSpec) {
-	if e == nil || req == nil {
-		return
-	}
-
-	for _, kv := range e.Spec.Values {
-		if strings.Contains(req.ServerInfo.Address, "{{"+kv.Key+"}}") {
-			req.ServerInfo.Address = strings.ReplaceAll(req.ServerInfo.Address, "{{"+kv.Key+"}}", kv.Value)
-		}
-
-		if strings.Contains(req.Body, "{{"+kv.Key+"}}") {
-			req.Body = strings.ReplaceAll(req.Body, "{{"+kv.Key+"}}", kv.Value)
-		}
-
-		for i, kv := range req.Metadata {
-			if strings.Contains(kv.Value, "{{"+kv.Key+"}}") {
-				req.Metadata[i].Value = strings.ReplaceAll(kv.Value, "{{"+kv.Key+"}}", kv.Value)
-			}
-		}
-
-		if req.Auth != (Auth{}) {
-			if req.Auth.APIKeyAuth != nil {
-				if strings.Contains(req.Auth.APIKeyAuth.Key, "{{"+kv.Key+"}}") {
-					req.Auth.APIKeyAuth.Key = strings.ReplaceAll(req.Auth.APIKeyAuth.Key, "{{"+kv.Key+"}}", kv.Value)
-				}
-			}
-
-			if req.Auth.BasicAuth != nil {
-				if strings.Contains(req.Auth.BasicAuth.Username, "{{"+kv.Key+"}}") {
-					req.Auth.BasicAuth.Username = strings.ReplaceAll(req.Auth.BasicAuth.Username, "{{"+kv.Key+"}}", kv.Value)
-				}
-
-				if strings.Contains(req.Auth.BasicAuth.Password, "{{"+kv.Key+"}}") {
-					req.Auth.BasicAuth.Password = strings.ReplaceAll(req.Auth.BasicAuth.Password, "{{"+kv.Key+"}}", kv.Value)
-				}
-			}
-
-			if req.Auth.TokenAuth != nil {
-				if strings.Contains(req.Auth.TokenAuth.Token, "{{"+kv.Key+"}}") {
-					req.Auth.TokenAuth.Token = strings.ReplaceAll(req.Auth.TokenAuth.Token, "{{"+kv.Key+"}}", kv.Value)
-				}
-			}
-		}
-	}
-}
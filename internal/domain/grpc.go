@@ -19,6 +19,9 @@ type GRPCRequestSpec struct {
 
 	PreRequest  PreRequest  `yaml:"preRequest"`
 	PostRequest PostRequest `yaml:"postRequest"`
+
+	// Variables are local to the request and take precedence over the variables of the other scopes.
+	Variables []KeyValue `yaml:"variables,omitempty"`
 }
 
 type GRPCService struct {
@@ -26,8 +29,32 @@ type GRPCService struct {
 	Methods []GRPCMethod
 }
 
+// FullName returns the fully-qualified name of the service, e.g. pkg.UserService, taken from the full names
+// of its methods as Name is the short name. It returns Name when the service has no methods.
+func (s GRPCService) FullName() string {
+	for _, m := range s.Methods {
+		if name, _, ok := strings.Cut(strings.TrimPrefix(m.FullName, "/"), "/"); ok && name != "" {
+			return name
+		}
+	}
+	return s.Name
+}
+
+const (
+	GRPCProtocolGRPC    = "grpc"
+	GRPCProtocolGRPCWeb = "grpc-web"
+	GRPCProtocolConnect = "connect"
+)
+
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
 type ServerInfo struct {
 	Address string `yaml:"address"`
+	// Protocol is the wire protocol used to invoke methods, empty means native gRPC.
+	Protocol string `yaml:"protocol"`
 
 	ServerReflection bool     `yaml:"serverReflection"`
 	ProtoFiles       []string `yaml:"protoFiles"`
@@ -37,6 +64,23 @@ type Settings struct {
 	Insecure            bool   `yaml:"insecure"`
 	TimeoutMilliseconds int    `yaml:"timeoutMilliseconds"`
 	NameOverride        string `yaml:"nameOverride"`
+	// StrictMode rejects request bodies with fields unknown to the method input message.
+	StrictMode bool `yaml:"strictMode"`
+
+	// Compression is the name of the compressor used for the request messages, empty means no compression.
+	Compression string `yaml:"compression"`
+	// MaxSendMessageSize and MaxReceiveMessageSize are in bytes, zero keeps the gRPC defaults.
+	MaxSendMessageSize    int `yaml:"maxSendMessageSize"`
+	MaxReceiveMessageSize int `yaml:"maxReceiveMessageSize"`
+
+	// KeepaliveTimeMilliseconds enables client keepalive pings when it is greater than zero.
+	KeepaliveTimeMilliseconds    int  `yaml:"keepaliveTimeMilliseconds"`
+	KeepaliveTimeoutMilliseconds int  `yaml:"keepaliveTimeoutMilliseconds"`
+	KeepalivePermitWithoutStream bool `yaml:"keepalivePermitWithoutStream"`
+
+	// Authority overrides the :authority pseudo header of the requests.
+	Authority    string `yaml:"authority"`
+	WaitForReady bool   `yaml:"waitForReady"`
 
 	RootCertFile   string `yaml:"rootCertFile"`
 	ClientCertFile string `yaml:"clientCertFile"`
@@ -58,6 +102,8 @@ type GRPCResponseDetail struct {
 	Duration   time.Duration
 	Size       int
 	Error      error
+	// ErrorDetails is the JSON of the status details sent along with Error.
+	ErrorDetails string
 
 	StatueCode int
 	Status     string
@@ -93,7 +139,8 @@ func NewGRPCRequest(name string) *Request {
 			GRPC: &GRPCRequestSpec{
 				LasSelectedMethod: "",
 				ServerInfo: ServerInfo{
-					Address: "localhost:8090",
+					Address:  "localhost:8090",
+					Protocol: GRPCProtocolGRPC,
 				},
 				Settings: Settings{
 					Insecure: true,
@@ -120,6 +167,10 @@ func CompareGRPCRequestSpecs(a, b *GRPCRequestSpec) bool {
 		return false
 	}
 
+	if !CompareKeyValues(a.Variables, b.Variables) {
+		return false
+	}
+
 	if !CompareAuth(a.Auth, b.Auth) {
 		return false
 	}
@@ -161,6 +212,15 @@ func CompareSettings(a, b Settings) bool {
 	if a.Insecure != b.Insecure ||
 		a.TimeoutMilliseconds != b.TimeoutMilliseconds ||
 		a.NameOverride != b.NameOverride ||
+		a.StrictMode != b.StrictMode ||
+		a.Compression != b.Compression ||
+		a.MaxSendMessageSize != b.MaxSendMessageSize ||
+		a.MaxReceiveMessageSize != b.MaxReceiveMessageSize ||
+		a.KeepaliveTimeMilliseconds != b.KeepaliveTimeMilliseconds ||
+		a.KeepaliveTimeoutMilliseconds != b.KeepaliveTimeoutMilliseconds ||
+		a.KeepalivePermitWithoutStream != b.KeepalivePermitWithoutStream ||
+		a.Authority != b.Authority ||
+		a.WaitForReady != b.WaitForReady ||
 		a.RootCertFile != b.RootCertFile ||
 		a.ClientCertFile != b.ClientCertFile ||
 		a.ClientKeyFile != b.ClientKeyFile {
@@ -171,7 +231,7 @@ func CompareSettings(a, b Settings) bool {
 }
 
 func CompareServerInfo(a, b ServerInfo) bool {
-	if a.Address != b.Address || a.ServerReflection != b.ServerReflection {
+	if a.Address != b.Address || a.ServerReflection != b.ServerReflection || a.Protocol != b.Protocol {
 		return false
 	}
 
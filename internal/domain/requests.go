@@ -31,6 +31,7 @@ const (
 	PrePostTypeNone           = "none"
 	PrePostTypeTriggerRequest = "triggerRequest"
 	PrePostTypeSetEnv         = "setEnv"
+	PrePostTypeSetNextRequest = "setNextRequest"
 	PrePostTypePython         = "python"
 	PrePostTypeShell          = "ssh"
 	PrePostTypeSSHTunnel      = "sshTunnel"
@@ -73,6 +74,14 @@ func (r RequestSpec) GetHTTP() *HTTPRequestSpec {
 	return nil
 }
 
+// GetPreRequest returns the pre request of the http or grpc spec.
+func (r RequestSpec) GetPreRequest() PreRequest {
+	if r.HTTP != nil {
+		return r.HTTP.GetPreRequest()
+	}
+	return r.GRPC.GetPreRequest()
+}
+
 func (r *GRPCRequestSpec) GetPreRequest() PreRequest {
 	if r != nil {
 		return r.PreRequest
@@ -178,17 +187,58 @@ type PreRequest struct {
 	SShTunnel        *SShTunnel        `yaml:"sshTunnel,omitempty"`
 	KubernetesTunnel *KubernetesTunnel `yaml:"kubernetesTunnel,omitempty"`
 	TriggerRequest   *TriggerRequest   `yaml:"triggerRequest,omitempty"`
+	// TriggerRequests are the prerequisites sent after TriggerRequest, in order.
+	TriggerRequests []TriggerRequest `yaml:"triggerRequests,omitempty"`
+}
+
+// IsZero reports whether the pre request is not set at all.
+func (p PreRequest) IsZero() bool {
+	return p.Type == "" && p.Script == "" && p.SShTunnel == nil && p.KubernetesTunnel == nil &&
+		p.TriggerRequest == nil && len(p.TriggerRequests) == 0
+}
+
+// Prerequisites returns the requests to send before the request, in order.
+func (p PreRequest) Prerequisites() []TriggerRequest {
+	if p.Type != PrePostTypeTriggerRequest {
+		return nil
+	}
+
+	out := make([]TriggerRequest, 0, len(p.TriggerRequests)+1)
+	if p.TriggerRequest != nil && p.TriggerRequest.RequestID != "" && p.TriggerRequest.RequestID != PrePostTypeNone {
+		out = append(out, *p.TriggerRequest)
+	}
+	for _, t := range p.TriggerRequests {
+		if t.RequestID != "" && t.RequestID != PrePostTypeNone {
+			out = append(out, t)
+		}
+	}
+	return out
 }
 
 type TriggerRequest struct {
 	CollectionID string `yaml:"collectionID"`
 	RequestID    string `yaml:"requestID"`
+	// Alias prefixes the variables of the response of the request, the name of the request is used if empty.
+	Alias string `yaml:"alias,omitempty"`
+	// CacheFor is how long the response of the request is reused, e.g. 10m, it is sent every time if empty.
+	CacheFor string `yaml:"cacheFor,omitempty"`
 }
 
 type PostRequest struct {
 	Type           string         `yaml:"type"`
 	Script         string         `yaml:"script"`
 	PostRequestSet PostRequestSet `yaml:"set"`
+	NextRequest    *NextRequest   `yaml:"nextRequest,omitempty"`
+}
+
+// NextRequestNone as the id of the next request ends the iteration of the collection run.
+const NextRequestNone = "none"
+
+// NextRequest sets the request a collection run continues with, instead of the one after the request.
+type NextRequest struct {
+	// StatusCode is the status code of the response the next request is set for, 0 matches any response.
+	StatusCode int    `yaml:"statusCode"`
+	RequestID  string `yaml:"requestID"`
 }
 
 const (
@@ -197,6 +247,8 @@ const (
 	PostRequestSetFromResponseCookie   = "responseCookie"
 	PostRequestSetFromResponseMetaData = "responseMetaData"
 	PostRequestSetFromResponseTrailers = "responseTrailers"
+	// PostRequestSetFromResponseErrorDetails reads the value from the JSON of the gRPC error details.
+	PostRequestSetFromResponseErrorDetails = "responseErrorDetails"
 )
 
 type PostRequestSet struct {
@@ -342,6 +394,16 @@ func ComparePreRequest(a, b PreRequest) bool {
 		return false
 	}
 
+	if len(a.TriggerRequests) != len(b.TriggerRequests) {
+		return false
+	}
+
+	for i := range a.TriggerRequests {
+		if a.TriggerRequests[i] != b.TriggerRequests[i] {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -380,7 +442,7 @@ func CompareTriggerRequest(a, b *TriggerRequest) bool {
 		return false
 	}
 
-	if a.CollectionID != b.CollectionID || a.RequestID != b.RequestID {
+	if *a != *b {
 		return false
 	}
 
@@ -412,9 +474,21 @@ func ComparePostRequest(a, b PostRequest) bool {
 		return false
 	}
 
+	if !CompareNextRequest(a.NextRequest, b.NextRequest) {
+		return false
+	}
+
 	return true
 }
 
+func CompareNextRequest(a, b *NextRequest) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
 func ComparePostRequestSet(a, b PostRequestSet) bool {
 	if a.Target != b.Target || a.From != b.From || a.FromKey != b.FromKey || a.StatusCode != b.StatusCode {
 		return false
@@ -39,6 +39,9 @@ type HTTPRequest struct {
 
 	PreRequest  PreRequest  `yaml:"preRequest"`
 	PostRequest PostRequest `yaml:"postRequest"`
+
+	// Variables are local to the request and take precedence over the variables of the other scopes.
+	Variables []KeyValue `yaml:"variables,omitempty"`
 }
 
 const (
@@ -85,9 +88,11 @@ type FormField struct {
 }
 
 type HTTPResponse struct {
-	Headers []KeyValue `yaml:"headers"`
-	Body    string     `yaml:"body"`
-	Cookies []KeyValue `yaml:"cookies"`
+	// StatusCode of the saved example, zero is served as 200 by the mock server.
+	StatusCode int        `yaml:"statusCode,omitempty"`
+	Headers    []KeyValue `yaml:"headers"`
+	Body       string     `yaml:"body"`
+	Cookies    []KeyValue `yaml:"cookies"`
 }
 
 func (r *HTTPRequest) Clone() *HTTPRequest {
@@ -189,6 +194,10 @@ func CompareHTTPRequests(a, b *HTTPRequest) bool {
 		return false
 	}
 
+	if !CompareKeyValues(a.Variables, b.Variables) {
+		return false
+	}
+
 	if !CompareFormData(a.Body.FormData, b.Body.FormData) {
 		return false
 	}
@@ -257,7 +266,7 @@ func CompareHTTPResponses(a, b HTTPResponse) bool {
 		return true
 	}
 
-	if a.Body != b.Body {
+	if a.StatusCode != b.StatusCode || a.Body != b.Body {
 		return false
 	}
 
@@ -273,7 +282,7 @@ func CompareHTTPResponses(a, b HTTPResponse) bool {
 }
 
 func IsHTTPResponseEmpty(r HTTPResponse) bool {
-	if r.Body != "" || len(r.Headers) > 0 || len(r.Cookies) > 0 {
+	if r.StatusCode != 0 || r.Body != "" || len(r.Headers) > 0 || len(r.Cookies) > 0 {
 		return false
 	}
 
@@ -301,7 +310,7 @@ func (r *Request) SetDefaultValuesForHTTP() {
 		}
 	}
 
-	if r.Spec.HTTP.Request.PreRequest == (PreRequest{}) {
+	if r.Spec.HTTP.Request.PreRequest.IsZero() {
 		r.Spec.HTTP.Request.PreRequest = PreRequest{
 			Type: "None",
 		}
@@ -388,5 +397,9 @@ type HTTPResponseDetail struct {
 	Duration   time.Duration
 	Size       int
 
+	// Body is the body as it is received, Response is its text which is pretty printed when it is JSON.
+	Body        []byte
+	ContentType string
+
 	Error error
 }
@@ -7,10 +7,16 @@ import (
 const DefaultWorkspaceName = "Default Workspace"
 
 type Workspace struct {
-	ApiVersion string   `yaml:"apiVersion"`
-	Kind       string   `yaml:"kind"`
-	MetaData   MetaData `yaml:"metadata"`
-	FilePath   string   `yaml:"-"`
+	ApiVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	MetaData   MetaData      `yaml:"metadata"`
+	Spec       WorkspaceSpec `yaml:"spec,omitempty"`
+	FilePath   string        `yaml:"-"`
+}
+
+type WorkspaceSpec struct {
+	// Variables are global to the workspace and available to all the requests.
+	Variables []KeyValue `yaml:"variables,omitempty"`
 }
 
 func NewWorkspace(name string) *Workspace {
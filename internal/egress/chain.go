@@ -0,0 +1,271 @@
+package egress
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/grpc"
+	"github.com/chapar-rest/chapar/internal/rest"
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+// maxPrerequisiteValues caps the variables taken from the response of a prerequisite, so a large JSON body
+// does not flood the scopes of the request.
+const maxPrerequisiteValues = 1000
+
+// CheckPrerequisites returns an error naming the requests of the cycle if the request, directly or through
+// its prerequisites, depends on itself.
+func (s *Service) CheckPrerequisites(id string) error {
+	const (
+		visiting = iota + 1
+		done
+	)
+
+	states := make(map[string]int)
+	var path []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch states[id] {
+		case done:
+			return nil
+		case visiting:
+			start := slices.Index(path, id)
+			cycle := append(append([]string{}, path[start:]...), id)
+			return fmt.Errorf("prerequisites form a cycle: %s", s.requestNames(cycle))
+		}
+
+		req := s.requests.GetRequest(id)
+		if req == nil {
+			// a missing request fails when it is sent
+			return nil
+		}
+
+		states[id] = visiting
+		path = append(path, id)
+		for _, t := range req.Spec.GetPreRequest().Prerequisites() {
+			if err := visit(t.RequestID); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		states[id] = done
+		return nil
+	}
+
+	return visit(id)
+}
+
+func (s *Service) requestNames(ids []string) string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if req := s.requests.GetRequest(id); req != nil {
+			names = append(names, req.MetaData.Name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return strings.Join(names, " -> ")
+}
+
+// prerequisites sends the prerequisites of the request, or takes their responses from the ones sent
+// for the same request or from the cache, and returns the values of their responses.
+func (s *Service) prerequisites(req *domain.Request, sc *sending) ([]domain.KeyValue, error) {
+	var values []domain.KeyValue
+	for _, t := range req.Spec.GetPreRequest().Prerequisites() {
+		res, err := s.prerequisite(t, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		alias := t.Alias
+		if alias == "" {
+			if r := s.requests.GetRequest(t.RequestID); r != nil {
+				alias = r.MetaData.Name
+			}
+		}
+		values = append(values, responseValues(alias, res)...)
+	}
+	return values, nil
+}
+
+func (s *Service) prerequisite(t domain.TriggerRequest, sc *sending) (any, error) {
+	if res, ok := sc.sent[t.RequestID]; ok {
+		return res, nil
+	}
+
+	var ttl time.Duration
+	if t.CacheFor != "" {
+		d, err := time.ParseDuration(t.CacheFor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache duration %q of prerequisite: %w", t.CacheFor, err)
+		}
+		ttl = d
+	}
+
+	var key string
+	if ttl > 0 {
+		key = s.cacheKey(t.RequestID, sc)
+		if res, ok := s.cache.get(key); ok {
+			sc.sent[t.RequestID] = res
+			return res, nil
+		}
+	}
+
+	res, err := s.send(t.RequestID, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.sent[t.RequestID] = res
+	if _, failed := ResponseStatus(res, nil); ttl > 0 && !failed {
+		s.cache.set(key, res, ttl)
+	}
+	return res, nil
+}
+
+// cacheKey identifies the response of a prerequisite. It holds a hash of the request, the variables of its
+// collection, the values of the environment and the iteration data, so the response is sent again once any
+// of them is edited.
+func (s *Service) cacheKey(id string, sc *sending) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	if req := s.requests.GetRequest(id); req != nil {
+		_ = enc.Encode(req.Spec)
+		if col := s.requests.GetCollection(req.CollectionID); col != nil {
+			_ = enc.Encode(col.Spec.Variables)
+		}
+	}
+	_ = enc.Encode(s.environmentValues(sc))
+	_ = enc.Encode(sc.data)
+
+	return id + "|" + hex.EncodeToString(h.Sum(nil))
+}
+
+// environmentValues returns the values of the environment the request is sent with, with the inherited ones.
+func (s *Service) environmentValues(sc *sending) []domain.KeyValue {
+	if sc.environment != nil {
+		return sc.environment.spec().Values
+	}
+	if sc.environmentID == "" {
+		return nil
+	}
+
+	env, err := s.environments.GetEffectiveEnvironment(sc.environmentID)
+	if err != nil {
+		env = s.environments.GetEnvironment(sc.environmentID)
+	}
+	if env == nil {
+		return nil
+	}
+	return env.Spec.Values
+}
+
+// ClearPrerequisiteCache drops the cached responses of the prerequisites, they are sent again the next time.
+func (s *Service) ClearPrerequisiteCache() {
+	s.cache.clear()
+}
+
+// responseCache keeps the responses of the prerequisites until they expire.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	res     any
+	expires time.Time
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]cacheEntry), now: time.Now}
+}
+
+func (c *responseCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.res, true
+}
+
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+func (c *responseCache) set(key string, res any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{res: res, expires: c.now().Add(ttl)}
+}
+
+// responseValues returns the status, body, headers and cookies of the response as variables prefixed with
+// the alias, e.g. login.status, login.headers.Content-Type and login.body.token for a JSON body.
+func responseValues(alias string, res any) []domain.KeyValue {
+	vars := make(map[string]string)
+	var body string
+
+	status, _ := ResponseStatus(res, nil)
+	vars[alias+".status"] = status
+
+	switch res := res.(type) {
+	case *rest.Response:
+		if res == nil {
+			break
+		}
+		body = string(res.Body)
+		for k, v := range res.Headers {
+			vars[alias+".headers."+k] = v
+		}
+		for _, c := range res.Cookies {
+			vars[alias+".cookies."+c.Name] = c.Value
+		}
+	case *grpc.Response:
+		if res == nil {
+			break
+		}
+		body = res.Body
+		for _, kv := range res.Metadata {
+			vars[alias+".metadata."+kv.Key] = kv.Value
+		}
+		for _, kv := range res.Trailers {
+			vars[alias+".trailers."+kv.Key] = kv.Value
+		}
+	}
+
+	vars[alias+".body"] = body
+	var data any
+	if json.Unmarshal([]byte(body), &data) == nil {
+		variables.FlattenJSON(alias+".body", data, vars, maxPrerequisiteValues)
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]domain.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, domain.KeyValue{Key: k, Value: vars[k], Enable: true})
+	}
+	return out
+}
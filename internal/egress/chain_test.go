@@ -0,0 +1,140 @@
+package egress
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/rest"
+	"github.com/chapar-rest/chapar/internal/state"
+)
+
+// newChainService returns a service with the requests, the path of each one is its name and the
+// server counts the calls of each path.
+func newChainService(t *testing.T, names ...string) (*fixture, map[string]*domain.Request, map[string]int) {
+	t.Helper()
+
+	calls := make(map[string]int)
+	f := newFixture(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"token":"secret","user":{"id":7}}`))
+		case "/orders":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}))
+
+	reqs := make(map[string]*domain.Request)
+	for _, name := range names {
+		reqs[name] = f.addRequest(name, nil)
+	}
+	return f, reqs, calls
+}
+
+func setPrerequisites(req *domain.Request, prerequisites ...domain.TriggerRequest) {
+	req.Spec.HTTP.Request.PreRequest = domain.PreRequest{
+		Type:            domain.PrePostTypeTriggerRequest,
+		TriggerRequests: prerequisites,
+	}
+}
+
+func TestPrerequisitesCycle(t *testing.T) {
+	s, reqs, calls := newChainService(t, "a", "b", "c")
+	setPrerequisites(reqs["a"], domain.TriggerRequest{RequestID: reqs["b"].MetaData.ID})
+	setPrerequisites(reqs["b"], domain.TriggerRequest{RequestID: reqs["c"].MetaData.ID})
+	setPrerequisites(reqs["c"], domain.TriggerRequest{RequestID: reqs["a"].MetaData.ID})
+
+	_, err := s.Send(reqs["a"].MetaData.ID, "")
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Fatalf("err = %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("requests of a cycle should not be sent, calls = %v", calls)
+	}
+}
+
+func TestPrerequisitesValuesAndCache(t *testing.T) {
+	s, reqs, calls := newChainService(t, "login", "profile", "orders")
+	login := domain.TriggerRequest{RequestID: reqs["login"].MetaData.ID, Alias: "auth", CacheFor: "10m"}
+
+	// login is shared by both prerequisites of orders and sent once
+	setPrerequisites(reqs["profile"], login)
+	setPrerequisites(reqs["orders"], login, domain.TriggerRequest{RequestID: reqs["profile"].MetaData.ID})
+	reqs["orders"].Spec.HTTP.Request.Headers = []domain.KeyValue{
+		{Key: "Authorization", Value: "Bearer {{auth.body.token}}", Enable: true},
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := s.Send(reqs["orders"].MetaData.ID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := res.(*rest.Response).StatusCode; status != http.StatusOK {
+			t.Fatalf("status = %d", status)
+		}
+	}
+
+	if calls["/login"] != 1 || calls["/profile"] != 2 || calls["/orders"] != 2 {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestPrerequisitesCacheInvalidation(t *testing.T) {
+	s, reqs, calls := newChainService(t, "login", "orders")
+	setPrerequisites(reqs["orders"], domain.TriggerRequest{RequestID: reqs["login"].MetaData.ID, CacheFor: "10m"})
+
+	env := domain.NewEnvironment("dev")
+	env.Spec.Values = []domain.KeyValue{{Key: "user", Value: "john", Enable: true}}
+	s.environments.AddEnvironment(env, state.SourceController)
+
+	send := func(want int) {
+		t.Helper()
+		if _, err := s.Send(reqs["orders"].MetaData.ID, env.MetaData.ID); err != nil {
+			t.Fatal(err)
+		}
+		if calls["/login"] != want {
+			t.Fatalf("login sent %d times, want %d", calls["/login"], want)
+		}
+	}
+
+	send(1)
+	send(1)
+
+	// editing the prerequisite or the environment sends it again
+	reqs["login"].Spec.HTTP.Request.Headers = []domain.KeyValue{{Key: "X-User", Value: "{{user}}", Enable: true}}
+	send(2)
+	env.Spec.Values[0].Value = "jane"
+	send(3)
+	send(3)
+
+	s.ClearPrerequisiteCache()
+	send(4)
+}
+
+func TestResponseValues(t *testing.T) {
+	values := responseValues("login", &rest.Response{
+		StatusCode: 201,
+		Headers:    map[string]string{"X-Request-Id": "42"},
+		Body:       []byte(`{"token":"secret","roles":["admin"]}`),
+	})
+
+	got := make(map[string]string)
+	for _, kv := range values {
+		got[kv.Key] = kv.Value
+	}
+	for k, want := range map[string]string{
+		"login.status":               "201",
+		"login.headers.X-Request-Id": "42",
+		"login.body.token":           "secret",
+		"login.body.roles.0":         "admin",
+	} {
+		if got[k] != want {
+			t.Errorf("%s = %q, want %q", k, got[k], want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/state"
+)
+
+// envSetter sets a value of the environment a request is sent with.
+type envSetter func(key, value string) error
+
+// RunEnvironment is a copy of an environment with its inherited values which the requests of a load test are
+// sent with. The values set by the post-request actions are kept in the copy and not persisted, so the virtual
+// users do not write the environment of the workspace and its file concurrently. It is safe for concurrent use.
+type RunEnvironment struct {
+	id string
+
+	mu  sync.RWMutex
+	env *domain.Environment
+}
+
+// NewRunEnvironment returns a copy of the environment with the id, it returns nil when the id is empty.
+func (s *Service) NewRunEnvironment(id string) (*RunEnvironment, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	env, err := s.environments.GetEffectiveEnvironment(id)
+	if errors.Is(err, state.ErrInheritanceCycle) {
+		env = s.environments.GetEnvironment(id)
+	} else if err != nil {
+		return nil, fmt.Errorf("environment with id %s not found", id)
+	}
+
+	return &RunEnvironment{id: id, env: env.Clone()}, nil
+}
+
+// spec returns a copy of the values of the environment.
+func (e *RunEnvironment) spec() *domain.EnvSpec {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	spec := e.env.Spec.Clone()
+	return &spec
+}
+
+func (e *RunEnvironment) set(key, value string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.env.SetKey(key, value)
+	return nil
+}
+
+// environmentSetter returns the function the post-request actions of the request set the values of the
+// environment with, nil when it is sent without one.
+func (s *Service) environmentSetter(req *domain.Request, sc *sending) (envSetter, error) {
+	if sc.environment != nil {
+		return sc.environment.set, nil
+	}
+	if sc.environmentID == "" {
+		return nil, nil
+	}
+
+	env := s.environments.GetEnvironment(sc.environmentID)
+	if env == nil {
+		return nil, fmt.Errorf("environment with id %s not found", sc.environmentID)
+	}
+
+	source := state.SourceRestService
+	if req.MetaData.Type != domain.RequestTypeHTTP {
+		source = state.SourceGRPCService
+	}
+	return func(key, value string) error {
+		env.SetKey(key, value)
+		return s.environments.UpdateEnvironment(env, source, false)
+	}, nil
+}
@@ -0,0 +1,61 @@
+package egress
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/state"
+)
+
+func TestSendWithEnvironmentConcurrently(t *testing.T) {
+	f := newFixture(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"fresh"}`))
+	}))
+
+	env := domain.NewEnvironment("dev")
+	env.Spec.Values = []domain.KeyValue{
+		{Key: "path", Value: "login", Enable: true},
+		{Key: "token", Value: "old", Enable: true},
+	}
+	f.environments.AddEnvironment(env, state.SourceController)
+
+	login := f.addRequest("login", nil)
+	login.Spec.HTTP.URL = f.url + "/{{path}}?token={{token}}"
+	login.Spec.HTTP.Request.PostRequest = domain.PostRequest{
+		Type: domain.PrePostTypeSetEnv,
+		PostRequestSet: domain.PostRequestSet{
+			Target: "token", StatusCode: http.StatusOK, From: domain.PostRequestSetFromResponseBody, FromKey: "$.token",
+		},
+	}
+
+	runEnv, err := f.NewRunEnvironment(env.MetaData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the virtual users of a load test send with the same copy of the environment
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := f.SendWithEnvironment(login.MetaData.ID, runEnv); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := runEnv.spec().Values[1].Value; got != "fresh" {
+		t.Errorf("token of the copy = %q, want fresh", got)
+	}
+	if got := f.environments.GetEnvironment(env.MetaData.ID).Spec.Values[1].Value; got != "old" {
+		t.Errorf("token of the environment = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,105 @@
+package egress
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// ParseIterationData parses the rows of an iteration data file, either a JSON array of objects or a CSV
+// file with the variable names in its header. The values of each row are the variables of an iteration.
+func ParseIterationData(data []byte) ([][]domain.KeyValue, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("\xef\xbb\xbf"))
+	if len(data) == 0 {
+		return nil, errors.New("iteration data is empty")
+	}
+
+	var rows [][]domain.KeyValue
+	var err error
+	if data[0] == '[' || data[0] == '{' {
+		rows, err = parseJSONIterationData(data)
+	} else {
+		rows, err = parseCSVIterationData(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("iteration data has no rows")
+	}
+	return rows, nil
+}
+
+func parseJSONIterationData(data []byte) ([][]domain.KeyValue, error) {
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("iteration data must be an array of objects, %w", err)
+	}
+
+	out := make([][]domain.KeyValue, 0, len(rows))
+	for _, row := range rows {
+		keys := make([]string, 0, len(row))
+		for k := range row {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		values := make([]domain.KeyValue, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, iterationValue(k, jsonValue(row[k])))
+		}
+		out = append(out, values)
+	}
+	return out, nil
+}
+
+// jsonValue returns the strings without their quotes, other values are kept as JSON.
+func jsonValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+func parseCSVIterationData(data []byte) ([][]domain.KeyValue, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv iteration data, %w", err)
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	out := make([][]domain.KeyValue, 0, len(records)-1)
+	for _, record := range records[1:] {
+		values := make([]domain.KeyValue, 0, len(header))
+		for i, k := range header {
+			if k != "" {
+				values = append(values, iterationValue(k, record[i]))
+			}
+		}
+		out = append(out, values)
+	}
+	return out, nil
+}
+
+func iterationValue(key, value string) domain.KeyValue {
+	return domain.KeyValue{ID: uuid.NewString(), Key: key, Value: value, Enable: true}
+}
@@ -0,0 +1,69 @@
+package egress
+
+import (
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestParseIterationData(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{
+			name: "csv",
+			data: "user, age\nalice, 30\n\"bob, jr\",4\n",
+			want: [][]string{{"user=alice", "age=30"}, {"user=bob, jr", "age=4"}},
+		},
+		{
+			name: "json",
+			data: `[{"user": "alice", "age": 30, "tags": ["a"]}, {"user": "bob", "age": null}]`,
+			want: [][]string{{"age=30", "tags=[\"a\"]", "user=alice"}, {"age=", "user=bob"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseIterationData([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("%d rows, want %d", len(rows), len(tt.want))
+			}
+			for i, row := range rows {
+				if got := pairs(row); !equal(got, tt.want[i]) {
+					t.Errorf("row %d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+
+	for _, data := range []string{"", `{"user": "alice"}`, "[]", "user\n", "a,b\n1\n"} {
+		if _, err := ParseIterationData([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func pairs(row []domain.KeyValue) []string {
+	out := make([]string, 0, len(row))
+	for _, kv := range row {
+		out = append(out, kv.Key+"="+kv.Value)
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
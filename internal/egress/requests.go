@@ -3,11 +3,14 @@ package egress
 import (
 	"fmt"
 
+	"github.com/chapar-rest/chapar/internal/codegen"
+	"github.com/chapar-rest/chapar/internal/curl"
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/grpc"
 	"github.com/chapar-rest/chapar/internal/jsonpath"
 	"github.com/chapar-rest/chapar/internal/rest"
 	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/variables"
 )
 
 type Service struct {
@@ -16,6 +19,9 @@ type Service struct {
 
 	rest *rest.Service
 	grpc *grpc.Service
+
+	// cache keeps the responses of the prerequisites which are reused for a while.
+	cache *responseCache
 }
 
 func New(requests *state.Requests, environments *state.Environments, rest *rest.Service, grpc *grpc.Service) *Service {
@@ -24,69 +30,190 @@ func New(requests *state.Requests, environments *state.Environments, rest *rest.
 		environments: environments,
 		rest:         rest,
 		grpc:         grpc,
+		cache:        newResponseCache(),
 	}
 }
 
 func (s *Service) Send(id, activeEnvironmentID string) (any, error) {
+	return s.SendWithData(id, activeEnvironmentID, nil)
+}
+
+// SendWithData sends the request with the values of an iteration data row layered above the environment,
+// its prerequisites are sent before it with them as well and the values of their responses are layered above.
+func (s *Service) SendWithData(id, activeEnvironmentID string, data []domain.KeyValue) (any, error) {
+	if err := s.CheckPrerequisites(id); err != nil {
+		return nil, err
+	}
+
+	return s.send(id, &sending{environmentID: activeEnvironmentID, data: data, sent: make(map[string]any)})
+}
+
+// SendWithEnvironment sends the request with the copy of an environment, the values its post-request
+// actions set are kept in the copy. A nil environment sends the request without one.
+func (s *Service) SendWithEnvironment(id string, env *RunEnvironment) (any, error) {
+	if err := s.CheckPrerequisites(id); err != nil {
+		return nil, err
+	}
+
+	sc := &sending{environment: env, sent: make(map[string]any)}
+	if env != nil {
+		sc.environmentID = env.id
+	}
+	return s.send(id, sc)
+}
+
+// sending are the values shared by a request and its prerequisites while they are sent.
+type sending struct {
+	environmentID string
+	// environment is the copy of the environment of a load test, it replaces the active environment when set.
+	environment *RunEnvironment
+	data        []domain.KeyValue
+	// sent holds the responses of the requests already sent so a prerequisite shared by several
+	// requests of the graph is sent once.
+	sent map[string]any
+}
+
+// send sends the request after its prerequisites.
+func (s *Service) send(id string, sc *sending) (any, error) {
 	req := s.requests.GetRequest(id)
 	if req == nil {
 		return nil, fmt.Errorf("request with id %s not found", id)
 	}
 
-	if err := s.preRequest(req, activeEnvironmentID); err != nil {
+	prerequisites, err := s.prerequisites(req, sc)
+	if err != nil {
 		return nil, err
 	}
 
+	values := variables.Values{Iteration: sc.data, Prerequisites: prerequisites}
+	if sc.environment != nil {
+		values.Environment = sc.environment.spec()
+	}
+
 	var res any
-	var err error
 	if req.MetaData.Type == domain.RequestTypeHTTP {
-		res, err = s.rest.SendRequest(req.MetaData.ID, activeEnvironmentID)
+		res, err = s.rest.SendRequestWithValues(req.MetaData.ID, sc.environmentID, values)
 	} else {
-		res, err = s.grpc.Invoke(req.MetaData.ID, activeEnvironmentID)
+		res, err = s.grpc.InvokeWithValues(req.MetaData.ID, sc.environmentID, values)
 	}
 
-	var activeEnvironment *domain.Environment
-	// Get environment if provided
-	if activeEnvironmentID != "" {
-		activeEnvironment = s.environments.GetEnvironment(activeEnvironmentID)
-		if activeEnvironment == nil {
-			return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
-		}
+	set, setErr := s.environmentSetter(req, sc)
+	if setErr != nil {
+		return nil, setErr
 	}
 
-	if err := s.postRequest(req, res, activeEnvironment); err != nil {
+	if err := s.postRequest(req, res, set); err != nil {
 		return nil, err
 	}
 
 	return res, err
 }
 
-func (s *Service) preRequest(req *domain.Request, activeEnvironmentID string) error {
-	var preReq domain.PreRequest
+// UnresolvedVariables returns the variables of the request which can not be resolved with the active environment,
+// it is meant to warn the user before sending the request.
+func (s *Service) UnresolvedVariables(id, activeEnvironmentID string) ([]variables.Warning, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return nil, fmt.Errorf("request with id %s not found", id)
+	}
+
 	if req.MetaData.Type == domain.RequestTypeHTTP {
-		preReq = req.Spec.GetHTTP().GetPreRequest()
-	} else {
-		preReq = req.Spec.GetGRPC().GetPreRequest()
+		return s.rest.UnresolvedVariables(id, activeEnvironmentID)
 	}
 
-	if preReq == (domain.PreRequest{}) || preReq.TriggerRequest == nil || preReq.TriggerRequest.RequestID == "none" {
+	return s.grpc.UnresolvedVariables(id, activeEnvironmentID)
+}
+
+// VariableScopes returns the variables known to the request and the scope each of them is defined in.
+func (s *Service) VariableScopes(id, activeEnvironmentID string) map[string]variables.Scope {
+	req := s.requests.GetRequest(id)
+	if req == nil {
 		return nil
 	}
 
-	if preReq.Type != domain.PrePostTypeTriggerRequest {
-		// TODO: implement other types
-		return nil
+	if req.MetaData.Type == domain.RequestTypeHTTP {
+		return s.rest.VariableScopes(id, activeEnvironmentID)
+	}
+
+	return s.grpc.VariableScopes(id, activeEnvironmentID)
+}
+
+// DescribeVariable returns the resolved value of the variable for the request and the scope it is defined in.
+func (s *Service) DescribeVariable(id, activeEnvironmentID, name string) (string, variables.Scope, bool) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return "", "", false
+	}
+
+	if req.MetaData.Type == domain.RequestTypeHTTP {
+		return s.rest.DescribeVariable(id, activeEnvironmentID, name)
+	}
+
+	return s.grpc.DescribeVariable(id, activeEnvironmentID, name)
+}
+
+// Command returns the request, with the variables of the active environment resolved, as a curl command
+// or as a grpcurl command for grpc requests.
+func (s *Service) Command(id, activeEnvironmentID string) (string, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return "", fmt.Errorf("request with id %s not found", id)
+	}
+
+	if req.MetaData.Type == domain.RequestTypeHTTP {
+		spec, err := s.rest.ResolveRequestSpec(id, activeEnvironmentID)
+		if err != nil {
+			return "", err
+		}
+		return curl.FromHTTPRequest(spec), nil
+	}
+
+	spec, err := s.grpc.ResolveRequestSpec(id, activeEnvironmentID)
+	if err != nil {
+		return "", err
+	}
+	if spec == nil {
+		return "", fmt.Errorf("request with id %s is not a grpc request", id)
+	}
+	return curl.FromGRPCRequest(spec), nil
+}
+
+// GenerateCode returns the request, with the variables of the active environment resolved, as a snippet
+// of the given language, grpc requests are always rendered as a Go client.
+func (s *Service) GenerateCode(id, activeEnvironmentID, language string) (string, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return "", fmt.Errorf("request with id %s not found", id)
+	}
+
+	if req.MetaData.Type == domain.RequestTypeHTTP {
+		spec, err := s.rest.ResolveRequestSpec(id, activeEnvironmentID)
+		if err != nil {
+			return "", err
+		}
+		return codegen.Generate(language, spec)
+	}
+
+	spec, err := s.grpc.ResolveRequestSpec(id, activeEnvironmentID)
+	if err != nil {
+		return "", err
+	}
+	if spec == nil {
+		return "", fmt.Errorf("request with id %s is not a grpc request", id)
 	}
 
-	_, err := s.Send(preReq.TriggerRequest.RequestID, activeEnvironmentID)
-	return err
+	md, err := s.grpc.MethodDescriptor(id, activeEnvironmentID)
+	if err != nil {
+		return "", err
+	}
+	return codegen.GenerateGRPC(spec, md), nil
 }
 
-func (s *Service) postRequest(req *domain.Request, res any, env *domain.Environment) error {
+func (s *Service) postRequest(req *domain.Request, res any, set envSetter) error {
 	if req.MetaData.Type == domain.RequestTypeHTTP {
 		postReq := req.Spec.GetHTTP().GetPostRequest()
 		if response, ok := res.(*rest.Response); ok {
-			return s.handleHTTPPostRequest(postReq, response, env)
+			return s.handleHTTPPostRequest(postReq, response, set)
 		} else {
 			return fmt.Errorf("response is not of type *rest.Response")
 		}
@@ -94,14 +221,14 @@ func (s *Service) postRequest(req *domain.Request, res any, env *domain.Environm
 
 	postReq := req.Spec.GetGRPC().GetPostRequest()
 	if response, ok := res.(*grpc.Response); ok {
-		return s.handleGRPCPostRequest(postReq, response, env)
+		return s.handleGRPCPostRequest(postReq, response, set)
 	}
 
 	return fmt.Errorf("response is not of type *grpc.Response")
 }
 
-func (s *Service) handleHTTPPostRequest(r domain.PostRequest, response *rest.Response, env *domain.Environment) error {
-	if r == (domain.PostRequest{}) || response == nil || env == nil {
+func (s *Service) handleHTTPPostRequest(r domain.PostRequest, response *rest.Response, set envSetter) error {
+	if r == (domain.PostRequest{}) || response == nil || set == nil {
 		return nil
 	}
 
@@ -117,17 +244,17 @@ func (s *Service) handleHTTPPostRequest(r domain.PostRequest, response *rest.Res
 
 	switch r.PostRequestSet.From {
 	case domain.PostRequestSetFromResponseBody:
-		return s.handlePostRequestFromBody(r, response, env)
+		return s.handlePostRequestFromBody(r, response, set)
 	case domain.PostRequestSetFromResponseHeader:
-		return s.handlePostRequestFromHeader(r, response, env)
+		return s.handlePostRequestFromHeader(r, response, set)
 	case domain.PostRequestSetFromResponseCookie:
-		return s.handlePostRequestFromCookie(r, response, env)
+		return s.handlePostRequestFromCookie(r, response, set)
 	}
 
 	return nil
 }
 
-func (s *Service) handlePostRequestFromBody(r domain.PostRequest, response *rest.Response, env *domain.Environment) error {
+func (s *Service) handlePostRequestFromBody(r domain.PostRequest, response *rest.Response, set envSetter) error {
 	// handle post request
 	if r.PostRequestSet.From != domain.PostRequestSetFromResponseBody {
 		return nil
@@ -148,25 +275,22 @@ func (s *Service) handlePostRequestFromBody(r domain.PostRequest, response *rest
 	}
 
 	if result, ok := data.(string); ok {
-		if env != nil {
-			env.SetKey(r.PostRequestSet.Target, result)
-			return s.environments.UpdateEnvironment(env, state.SourceRestService, false)
+		if set != nil {
+			return set(r.PostRequestSet.Target, result)
 		}
 	}
 
 	return nil
 }
 
-func (s *Service) handlePostRequestFromHeader(r domain.PostRequest, response *rest.Response, env *domain.Environment) error {
+func (s *Service) handlePostRequestFromHeader(r domain.PostRequest, response *rest.Response, set envSetter) error {
 	if r.PostRequestSet.From != domain.PostRequestSetFromResponseHeader {
 		return nil
 	}
 
 	if result, ok := response.Headers[r.PostRequestSet.FromKey]; ok {
-		if env != nil {
-			env.SetKey(r.PostRequestSet.Target, result)
-
-			if err := s.environments.UpdateEnvironment(env, state.SourceRestService, false); err != nil {
+		if set != nil {
+			if err := set(r.PostRequestSet.Target, result); err != nil {
 				return err
 			}
 		}
@@ -174,24 +298,23 @@ func (s *Service) handlePostRequestFromHeader(r domain.PostRequest, response *re
 	return nil
 }
 
-func (s *Service) handlePostRequestFromCookie(r domain.PostRequest, response *rest.Response, env *domain.Environment) error {
+func (s *Service) handlePostRequestFromCookie(r domain.PostRequest, response *rest.Response, set envSetter) error {
 	if r.PostRequestSet.From != domain.PostRequestSetFromResponseCookie {
 		return nil
 	}
 
 	for _, c := range response.Cookies {
 		if c.Name == r.PostRequestSet.FromKey {
-			if env != nil {
-				env.SetKey(r.PostRequestSet.Target, c.Value)
-				return s.environments.UpdateEnvironment(env, state.SourceRestService, false)
+			if set != nil {
+				return set(r.PostRequestSet.Target, c.Value)
 			}
 		}
 	}
 	return nil
 }
 
-func (s *Service) handleGRPCPostRequest(r domain.PostRequest, res *grpc.Response, env *domain.Environment) error {
-	if r == (domain.PostRequest{}) || res == nil || env == nil {
+func (s *Service) handleGRPCPostRequest(r domain.PostRequest, res *grpc.Response, set envSetter) error {
+	if r == (domain.PostRequest{}) || res == nil || set == nil {
 		return nil
 	}
 
@@ -206,17 +329,19 @@ func (s *Service) handleGRPCPostRequest(r domain.PostRequest, res *grpc.Response
 
 	switch r.PostRequestSet.From {
 	case domain.PostRequestSetFromResponseBody:
-		return s.handleGRPCPostRequestFromBody(r, res, env)
+		return s.handleGRPCPostRequestFromBody(r, res, set)
 	case domain.PostRequestSetFromResponseMetaData:
-		return s.handlePostRequestFromMetaData(r, res, env)
+		return s.handlePostRequestFromMetaData(r, res, set)
 	case domain.PostRequestSetFromResponseTrailers:
-		return s.handlePostRequestFromTrailers(r, res, env)
+		return s.handlePostRequestFromTrailers(r, res, set)
+	case domain.PostRequestSetFromResponseErrorDetails:
+		return s.handlePostRequestFromErrorDetails(r, res, set)
 	}
 
 	return nil
 }
 
-func (s *Service) handleGRPCPostRequestFromBody(r domain.PostRequest, res *grpc.Response, env *domain.Environment) error {
+func (s *Service) handleGRPCPostRequestFromBody(r domain.PostRequest, res *grpc.Response, set envSetter) error {
 	if r.PostRequestSet.From != domain.PostRequestSetFromResponseBody {
 		return nil
 	}
@@ -235,10 +360,8 @@ func (s *Service) handleGRPCPostRequestFromBody(r domain.PostRequest, res *grpc.
 	}
 
 	if result, ok := data.(string); ok {
-		if env != nil {
-			env.SetKey(r.PostRequestSet.Target, result)
-
-			if err := s.environments.UpdateEnvironment(env, state.SourceGRPCService, false); err != nil {
+		if set != nil {
+			if err := set(r.PostRequestSet.Target, result); err != nil {
 				return err
 			}
 		}
@@ -247,16 +370,15 @@ func (s *Service) handleGRPCPostRequestFromBody(r domain.PostRequest, res *grpc.
 	return nil
 }
 
-func (s *Service) handlePostRequestFromMetaData(r domain.PostRequest, res *grpc.Response, env *domain.Environment) error {
+func (s *Service) handlePostRequestFromMetaData(r domain.PostRequest, res *grpc.Response, set envSetter) error {
 	if r.PostRequestSet.From != domain.PostRequestSetFromResponseMetaData {
 		return nil
 	}
 
 	for _, item := range res.Metadata {
 		if item.Key == r.PostRequestSet.FromKey {
-			if env != nil {
-				env.SetKey(r.PostRequestSet.Target, item.Value)
-				return s.environments.UpdateEnvironment(env, state.SourceGRPCService, false)
+			if set != nil {
+				return set(r.PostRequestSet.Target, item.Value)
 			}
 		}
 	}
@@ -264,19 +386,39 @@ func (s *Service) handlePostRequestFromMetaData(r domain.PostRequest, res *grpc.
 	return nil
 }
 
-func (s *Service) handlePostRequestFromTrailers(r domain.PostRequest, res *grpc.Response, env *domain.Environment) error {
+func (s *Service) handlePostRequestFromTrailers(r domain.PostRequest, res *grpc.Response, set envSetter) error {
 	if r.PostRequestSet.From != domain.PostRequestSetFromResponseTrailers {
 		return nil
 	}
 
 	for _, item := range res.Trailers {
 		if item.Key == r.PostRequestSet.FromKey {
-			if env != nil {
-				env.SetKey(r.PostRequestSet.Target, item.Value)
-				return s.environments.UpdateEnvironment(env, state.SourceGRPCService, false)
+			if set != nil {
+				return set(r.PostRequestSet.Target, item.Value)
 			}
 		}
 	}
 
 	return nil
 }
+
+func (s *Service) handlePostRequestFromErrorDetails(r domain.PostRequest, res *grpc.Response, set envSetter) error {
+	if r.PostRequestSet.From != domain.PostRequestSetFromResponseErrorDetails {
+		return nil
+	}
+
+	if res.ErrorDetails == "" {
+		return nil
+	}
+
+	data, err := jsonpath.Get(res.ErrorDetails, r.PostRequestSet.FromKey)
+	if err != nil {
+		return err
+	}
+
+	if result, ok := data.(string); ok && set != nil {
+		return set(r.PostRequestSet.Target, result)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package egress
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/rest"
+	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+// fixture is a service sending HTTP requests to a test server, the state is kept in memory.
+type fixture struct {
+	*Service
+	requests     *state.Requests
+	environments *state.Environments
+	url          string
+}
+
+func newFixture(t *testing.T, handler http.Handler) *fixture {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	requests := state.NewRequests(nil)
+	environments := state.NewEnvironments(nil)
+	return &fixture{
+		Service:      New(requests, environments, rest.New(requests, environments, variables.NewProvider(requests, environments, nil)), nil),
+		requests:     requests,
+		environments: environments,
+		url:          srv.URL,
+	}
+}
+
+// addRequest adds a request to the path of its name, to the collection when it is not nil.
+func (f *fixture) addRequest(name string, col *domain.Collection) *domain.Request {
+	req := domain.NewHTTPRequest(name)
+	req.Spec.HTTP.URL = f.url + "/" + name
+	if col != nil {
+		req.CollectionID = col.MetaData.ID
+		col.AddRequest(req)
+	}
+	f.requests.AddRequest(req)
+	return req
+}
@@ -0,0 +1,363 @@
+package egress
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/grpc"
+	"github.com/chapar-rest/chapar/internal/rest"
+)
+
+// StatusError is the status of the requests which failed without a response.
+const StatusError = "error"
+
+// maxStepsPerRequest is how many times each request of an iteration can be sent on average when
+// RunOptions.MaxSteps is not set.
+const maxStepsPerRequest = 100
+
+type RunOptions struct {
+	EnvironmentID string
+	// Data are the rows of the iteration data, the requests are run once per row or once when there are none.
+	Data [][]domain.KeyValue
+
+	// Delay is the pause between two requests.
+	Delay time.Duration
+	// StopOnFailure ends the run at the first failed request.
+	StopOnFailure bool
+	// Retries is the number of times a request is sent again when its status is one of RetryStatuses,
+	// Backoff is the wait before the first retry and it doubles for each of the next ones.
+	Retries       int
+	RetryStatuses []string
+	Backoff       time.Duration
+
+	// MaxSteps caps the requests sent in an iteration, so next requests pointing back to a request can not
+	// loop forever. Zero is 100 times the number of requests.
+	MaxSteps int
+
+	// Skip are the ids of the requests which are not run, when Only is set only its requests are run.
+	Skip []string
+	Only []string
+
+	// OnRequest is called before each request is sent.
+	OnRequest func(iteration int, requestID string)
+}
+
+// RunResult is the result of a run of a request or of the requests of a collection.
+type RunResult struct {
+	Name       string
+	Started    time.Time
+	Duration   time.Duration
+	Iterations []*IterationResult
+	// Stopped is the reason the run ended before running all the requests, it is empty when it completed.
+	Stopped string
+}
+
+// IterationResult is the result of the requests sent with a row of the iteration data.
+type IterationResult struct {
+	// Index is the zero based index of the row.
+	Index    int
+	Data     []domain.KeyValue
+	Requests []*RequestResult
+}
+
+type RequestResult struct {
+	Iteration int
+	RequestID string
+	Name      string
+	// URL is the resolved url of an http request or the address and method of a grpc request, it is empty
+	// when the request failed before it was sent.
+	URL string
+	// Status is the http status code, the name of the grpc code or StatusError.
+	Status   string
+	Failed   bool
+	Duration time.Duration
+	// Attempts is the number of times the request was sent, it is more than one when it was retried.
+	Attempts   int
+	Assertions []Assertion
+	Error      string
+}
+
+// Assertion is a check of the response of a request, the request failed when one of them did not pass.
+type Assertion struct {
+	Name   string
+	Passed bool
+	// Message explains why the assertion did not pass.
+	Message string
+}
+
+// String describes the result in a single line.
+func (r *RequestResult) String() string {
+	out := fmt.Sprintf("#%d %s: %s (%s)", r.Iteration+1, r.Name, r.Status, r.Duration.Round(time.Millisecond))
+	if r.Attempts > 1 {
+		out += fmt.Sprintf(" after %d attempts", r.Attempts)
+	}
+	if r.Error != "" {
+		out += " " + r.Error
+	}
+	return out
+}
+
+// Failures returns the number of failed requests of the run.
+func (r *RunResult) Failures() int {
+	n := 0
+	for _, it := range r.Iterations {
+		n += it.Failures()
+	}
+	return n
+}
+
+// Failures returns the number of failed requests of the iteration.
+func (it *IterationResult) Failures() int {
+	n := 0
+	for _, req := range it.Requests {
+		if req.Failed {
+			n++
+		}
+	}
+	return n
+}
+
+// ResponseStatus returns the status of the response of Send, the http status code or the name of the grpc
+// code, and whether the request failed.
+func ResponseStatus(res any, err error) (string, bool) {
+	switch res := res.(type) {
+	case *rest.Response:
+		if res != nil && err == nil {
+			return strconv.Itoa(res.StatusCode), res.StatusCode >= 400
+		}
+	case *grpc.Response:
+		// the status is set along with the error when the call fails with a grpc code
+		if res != nil && res.Status != "" {
+			return res.Status, res.StatueCode != 0
+		}
+	}
+	return StatusError, true
+}
+
+// Run sends the request with the id, or the requests of the collection with the id in order, once per
+// row of the iteration data. A request can set the request the iteration continues with in its post
+// request. onResult is called with the result of each request as it is received.
+func (s *Service) Run(ctx context.Context, id string, opts RunOptions, onResult func(r *RequestResult)) (*RunResult, error) {
+	name, requests, err := s.runRequests(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if requests = filterRequests(requests, opts.Skip, opts.Only); len(requests) == 0 {
+		return nil, fmt.Errorf("%s has no requests to run, all of them are skipped", name)
+	}
+
+	rows := opts.Data
+	if len(rows) == 0 {
+		rows = [][]domain.KeyValue{nil}
+	}
+
+	result := &RunResult{Name: name, Started: time.Now()}
+	defer func() {
+		result.Duration = time.Since(result.Started)
+	}()
+
+	maxSteps := opts.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = maxStepsPerRequest * len(requests)
+	}
+
+	sent := false
+	for i, row := range rows {
+		iteration := &IterationResult{Index: i, Data: row}
+		result.Iterations = append(result.Iterations, iteration)
+
+		for next := 0; next < len(requests); {
+			if len(iteration.Requests) >= maxSteps {
+				result.Stopped = fmt.Sprintf("stopped after sending %d requests in iteration %d, the next requests may form a loop", maxSteps, i+1)
+				return result, nil
+			}
+
+			req := requests[next]
+			if sent && opts.Delay > 0 {
+				if err := sleep(ctx, opts.Delay); err != nil {
+					return result, err
+				}
+			}
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			sent = true
+
+			if opts.OnRequest != nil {
+				opts.OnRequest(i, req.MetaData.ID)
+			}
+
+			r, res, err := s.runRequest(ctx, req, i, row, opts)
+			if err != nil {
+				return result, err
+			}
+
+			iteration.Requests = append(iteration.Requests, r)
+			if onResult != nil {
+				onResult(r)
+			}
+
+			if r.Failed && opts.StopOnFailure {
+				result.Stopped = fmt.Sprintf("stopped at the failure of %s in iteration %d", r.Name, i+1)
+				return result, nil
+			}
+
+			next = nextRequest(requests, next, res)
+		}
+	}
+	return result, nil
+}
+
+// runRequest sends the request and sends it again while its status is one of the retry statuses.
+func (s *Service) runRequest(ctx context.Context, req *domain.Request, iteration int, row []domain.KeyValue, opts RunOptions) (*RequestResult, any, error) {
+	r := &RequestResult{
+		Iteration: iteration,
+		RequestID: req.MetaData.ID,
+		Name:      req.MetaData.Name,
+	}
+
+	var res any
+	for {
+		start := time.Now()
+		var err error
+		res, err = s.SendWithData(req.MetaData.ID, opts.EnvironmentID, row)
+		r.Duration = time.Since(start)
+		r.Attempts++
+
+		r.Status, r.Failed = ResponseStatus(res, err)
+		r.URL, r.Error = responseURL(res), ""
+		if err != nil {
+			r.Error = err.Error()
+		}
+
+		if r.Attempts > opts.Retries || !slices.Contains(opts.RetryStatuses, r.Status) {
+			break
+		}
+		if err := sleep(ctx, opts.Backoff<<(r.Attempts-1)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	r.Assertions = []Assertion{statusAssertion(r)}
+	return r, res, nil
+}
+
+// nextRequest returns the index of the request to run after the one at the index, it is the one after it
+// unless its post request sets the next request for the status of the response. The index is past the end
+// when the iteration is over.
+func nextRequest(requests []*domain.Request, index int, res any) int {
+	req := requests[index]
+
+	var post domain.PostRequest
+	if req.MetaData.Type == domain.RequestTypeHTTP {
+		post = req.Spec.GetHTTP().GetPostRequest()
+	} else {
+		post = req.Spec.GetGRPC().GetPostRequest()
+	}
+
+	next := post.NextRequest
+	if post.Type != domain.PrePostTypeSetNextRequest || next == nil {
+		return index + 1
+	}
+
+	if next.StatusCode != 0 {
+		switch res := res.(type) {
+		case *rest.Response:
+			if res == nil || res.StatusCode != next.StatusCode {
+				return index + 1
+			}
+		case *grpc.Response:
+			if res == nil || res.StatueCode != next.StatusCode {
+				return index + 1
+			}
+		default:
+			return index + 1
+		}
+	}
+
+	if next.RequestID == "" || next.RequestID == domain.NextRequestNone {
+		return len(requests)
+	}
+
+	for i, r := range requests {
+		if r.MetaData.ID == next.RequestID {
+			return i
+		}
+	}
+
+	// the next request is not part of the run, the iteration goes on in order
+	return index + 1
+}
+
+// filterRequests returns the requests which are not skipped, when only is set only its requests are kept.
+func filterRequests(requests []*domain.Request, skip, only []string) []*domain.Request {
+	out := make([]*domain.Request, 0, len(requests))
+	for _, req := range requests {
+		if slices.Contains(skip, req.MetaData.ID) || (len(only) > 0 && !slices.Contains(only, req.MetaData.ID)) {
+			continue
+		}
+		out = append(out, req)
+	}
+	return out
+}
+
+func responseURL(res any) string {
+	switch res := res.(type) {
+	case *rest.Response:
+		if res != nil {
+			return res.URL
+		}
+	case *grpc.Response:
+		if res != nil {
+			return res.URL
+		}
+	}
+	return ""
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// statusAssertion checks the request got a response with a successful status.
+func statusAssertion(r *RequestResult) Assertion {
+	a := Assertion{Name: "status is successful", Passed: !r.Failed}
+	switch {
+	case a.Passed:
+	case r.Status == StatusError:
+		a.Message = r.Error
+	default:
+		a.Message = "status is " + r.Status
+	}
+	return a
+}
+
+// runRequests returns the name of the request or collection with the id and the requests it runs.
+func (s *Service) runRequests(id string) (string, []*domain.Request, error) {
+	if req := s.requests.GetRequest(id); req != nil {
+		return req.MetaData.Name, []*domain.Request{req}, nil
+	}
+
+	col := s.requests.GetCollection(id)
+	if col == nil {
+		return "", nil, fmt.Errorf("request or collection with id %s not found", id)
+	}
+	if len(col.Spec.Requests) == 0 {
+		return "", nil, fmt.Errorf("collection %s has no requests", col.MetaData.Name)
+	}
+	return col.MetaData.Name, col.Spec.Requests, nil
+}
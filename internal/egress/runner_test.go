@@ -0,0 +1,173 @@
+package egress
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// newRunService returns a service running the requests of a collection against a server answering
+// each path with the statuses in turn, the last one is repeated.
+func newRunService(t *testing.T, statuses map[string][]int) (*Service, *domain.Collection) {
+	t.Helper()
+
+	var mu sync.Mutex
+	f := newFixture(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		codes := statuses[r.URL.Path]
+		if len(codes) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(codes[0])
+		if len(codes) > 1 {
+			statuses[r.URL.Path] = codes[1:]
+		}
+	}))
+
+	col := domain.NewCollection("Orders")
+	for _, name := range []string{"login", "create", "poll", "logout"} {
+		f.addRequest(name, col)
+	}
+	f.requests.AddCollection(col)
+	return f.Service, col
+}
+
+func names(result *RunResult) string {
+	var out []string
+	for _, it := range result.Iterations {
+		for _, r := range it.Requests {
+			out = append(out, r.Name+"="+r.Status)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func TestRunControlFlow(t *testing.T) {
+	s, col := newRunService(t, map[string][]int{
+		"/login":  {200},
+		"/create": {503, 503, 201},
+		"/poll":   {202, 202, 200},
+		"/logout": {200},
+	})
+
+	// poll until it is done, then skip logout
+	poll := col.Spec.Requests[2]
+	poll.Spec.HTTP.Request.PostRequest = domain.PostRequest{
+		Type:        domain.PrePostTypeSetNextRequest,
+		NextRequest: &domain.NextRequest{StatusCode: 202, RequestID: poll.MetaData.ID},
+	}
+
+	var started []string
+	result, err := s.Run(context.Background(), col.MetaData.ID, RunOptions{
+		Retries:       2,
+		RetryStatuses: []string{"503"},
+		Skip:          []string{col.Spec.Requests[3].MetaData.ID},
+		OnRequest: func(_ int, id string) {
+			started = append(started, s.requests.GetRequest(id).MetaData.Name)
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := names(result), "login=200 create=201 poll=202 poll=202 poll=200"; got != want {
+		t.Errorf("run = %s, want %s", got, want)
+	}
+	if attempts := result.Iterations[0].Requests[1].Attempts; attempts != 3 {
+		t.Errorf("create attempts = %d, want 3", attempts)
+	}
+	if len(started) != 5 {
+		t.Errorf("started = %v", started)
+	}
+}
+
+func TestRunStopOnFailure(t *testing.T) {
+	s, col := newRunService(t, map[string][]int{
+		"/login":  {200},
+		"/create": {500},
+		"/poll":   {200},
+		"/logout": {200},
+	})
+
+	result, err := s.Run(context.Background(), col.MetaData.ID, RunOptions{
+		StopOnFailure: true,
+		Data:          [][]domain.KeyValue{{{Key: "n", Value: "1"}}, {{Key: "n", Value: "2"}}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := names(result), "login=200 create=500"; got != want {
+		t.Errorf("run = %s, want %s", got, want)
+	}
+	if result.Stopped == "" {
+		t.Error("expected the run to be stopped")
+	}
+}
+
+func TestRunNextRequestLoop(t *testing.T) {
+	s, col := newRunService(t, map[string][]int{
+		"/login":  {200},
+		"/create": {201},
+		"/poll":   {202},
+	})
+
+	// poll goes back to create for ever
+	poll := col.Spec.Requests[2]
+	poll.Spec.HTTP.Request.PostRequest = domain.PostRequest{
+		Type:        domain.PrePostTypeSetNextRequest,
+		NextRequest: &domain.NextRequest{StatusCode: 202, RequestID: col.Spec.Requests[1].MetaData.ID},
+	}
+
+	result, err := s.Run(context.Background(), col.MetaData.ID, RunOptions{MaxSteps: 5}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := names(result), "login=200 create=201 poll=202 create=201 poll=202"; got != want {
+		t.Errorf("run = %s, want %s", got, want)
+	}
+	if result.Stopped == "" {
+		t.Error("expected the run to be stopped")
+	}
+}
+
+func TestRunOnly(t *testing.T) {
+	s, col := newRunService(t, map[string][]int{"/poll": {200}})
+
+	if _, err := s.Run(context.Background(), col.MetaData.ID, RunOptions{Only: []string{"unknown"}}, nil); err == nil {
+		t.Error("expected an error when no request is left to run")
+	}
+
+	result, err := s.Run(context.Background(), col.MetaData.ID, RunOptions{Only: []string{col.Spec.Requests[2].MetaData.ID}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(result); got != "poll=200" {
+		t.Errorf("run = %s", got)
+	}
+}
+
+func TestMoveRequest(t *testing.T) {
+	_, col := newRunService(t, nil)
+	logout := col.Spec.Requests[3].MetaData.ID
+
+	col.MoveRequest(logout, 0)
+	if col.Spec.Requests[0].MetaData.ID != logout || len(col.Spec.Order) != 4 {
+		t.Fatalf("order = %v", col.Spec.Order)
+	}
+
+	// a loaded collection is sorted in the stored order
+	col.Spec.Requests[0], col.Spec.Requests[3] = col.Spec.Requests[3], col.Spec.Requests[0]
+	col.SortRequests()
+	if col.Spec.Requests[0].MetaData.ID != logout {
+		t.Errorf("first request = %s", col.Spec.Requests[0].MetaData.Name)
+	}
+}
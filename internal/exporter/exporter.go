@@ -0,0 +1,17 @@
+package exporter
+
+import (
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// contentType returns the value of the enabled Content-Type header.
+func contentType(headers []domain.KeyValue) string {
+	for _, h := range headers {
+		if h.Enable && strings.EqualFold(h.Key, "Content-Type") {
+			return h.Value
+		}
+	}
+	return ""
+}
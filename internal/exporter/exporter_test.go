@@ -0,0 +1,168 @@
+package exporter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/importer"
+)
+
+func testCollection() *domain.Collection {
+	col := domain.NewCollection("Shop")
+	col.Spec.Variables = []domain.KeyValue{{Key: "baseUrl", Value: "https://shop.example.com", Enable: true}}
+
+	get := domain.NewHTTPRequest("Get order")
+	get.Spec.HTTP.URL = "{{baseUrl}}/orders/{id}?tag=a"
+	get.Spec.HTTP.Request = &domain.HTTPRequest{
+		Headers:     []domain.KeyValue{{Key: "X-Debug", Value: "1", Enable: true}},
+		PathParams:  []domain.KeyValue{{Key: "id", Value: "42", Enable: true}},
+		QueryParams: []domain.KeyValue{{Key: "tag", Value: "a", Enable: true}, {Key: "page", Value: "2"}},
+		Auth:        domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: "{{token}}"}},
+		PostRequest: domain.PostRequest{Type: domain.PrePostTypeSetEnv, PostRequestSet: domain.PostRequestSet{
+			Target: "orderId", StatusCode: 200, From: domain.PostRequestSetFromResponseBody, FromKey: "$.data.id",
+		}},
+	}
+	get.Spec.HTTP.Responses = []domain.HTTPResponse{{
+		Headers: []domain.KeyValue{{Key: "Content-Type", Value: "application/json"}},
+		Body:    `{"data": {"id": 42, "total": 9.5, "items": [{"name": "pen"}]}}`,
+	}}
+
+	upload := domain.NewHTTPRequest("Upload")
+	upload.Spec.HTTP.Method = domain.RequestMethodPOST
+	upload.Spec.HTTP.URL = "{{baseUrl}}/uploads"
+	upload.Spec.HTTP.Request = &domain.HTTPRequest{
+		Body: domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: []domain.FormField{
+			{Key: "title", Value: "{{$uuid}}", Type: domain.FormFieldTypeText, Enable: true},
+			{Key: "file", Type: domain.FormFieldTypeFile, Files: []string{"/tmp/a.png"}, Enable: true},
+		}}},
+		Auth: domain.Auth{Type: domain.AuthTypeAPIKey, APIKeyAuth: &domain.APIKeyAuth{Key: "X-Key", Value: "secret"}},
+	}
+
+	col.AddRequest(get)
+	col.AddRequest(upload)
+	col.AddRequest(domain.NewGRPCRequest("Ping"))
+	return col
+}
+
+func TestExportPostmanCollection(t *testing.T) {
+	data, report, err := ExportPostmanCollection(testCollection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Items) != 1 || !strings.Contains(report.Items[0].Reason, "gRPC") {
+		t.Fatalf("expected the grpc request to be reported, got %v", report.Items)
+	}
+	if !strings.Contains(string(data), `"{{$guid}}"`) {
+		t.Fatalf("expected the dynamic variables to be mapped to the postman ones:\n%s", data)
+	}
+	if !strings.Contains(string(data), `pm.environment.set(\"orderId\", pm.response.json().data.id);`) {
+		t.Fatalf("expected the post-request to become a test script:\n%s", data)
+	}
+
+	// importing the export back gives the same requests
+	result, err := importer.ParsePostmanCollection(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := result.Collections[0].Spec.Requests
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	get := requests[0].Spec.HTTP
+	if get.URL != "{{baseUrl}}/orders/{id}?tag=a" || len(get.Request.QueryParams) != 2 || get.Request.QueryParams[1].Enable {
+		t.Fatalf("unexpected url %s and query %v", get.URL, get.Request.QueryParams)
+	}
+	if len(get.Request.PathParams) != 1 || get.Request.PathParams[0].Value != "42" {
+		t.Fatalf("unexpected path params %v", get.Request.PathParams)
+	}
+	if get.Request.Auth.TokenAuth == nil || get.Request.Auth.TokenAuth.Token != "{{token}}" {
+		t.Fatalf("unexpected auth %v", get.Request.Auth)
+	}
+
+	upload := requests[1].Spec.HTTP.Request
+	if upload.Auth.APIKeyAuth == nil || upload.Auth.APIKeyAuth.Key != "X-Key" {
+		t.Fatalf("unexpected auth %v", upload.Auth)
+	}
+	fields := upload.Body.FormData.Fields
+	if len(fields) != 2 || fields[1].Type != domain.FormFieldTypeFile || fields[1].Files[0] != "/tmp/a.png" {
+		t.Fatalf("unexpected form fields %v", fields)
+	}
+
+	if len(result.Environments) != 1 || result.Environments[0].Spec.Values[0].Value != "https://shop.example.com" {
+		t.Fatalf("expected the collection variables to be exported, got %v", result.Environments)
+	}
+}
+
+func TestExportPostmanEnvironment(t *testing.T) {
+	env := domain.NewEnvironment("Dev")
+	env.Spec.Values = []domain.KeyValue{
+		{Key: "baseUrl", Value: "https://dev.example.com", Enable: true},
+		{Key: "token", Value: "hunter2", Enable: true, Secret: true},
+	}
+
+	data, err := ExportPostmanEnvironment(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Fatalf("the value of the secret is exported:\n%s", data)
+	}
+
+	var out postmanEnvironment
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "Dev" || len(out.Values) != 2 || out.Values[1].Type != "secret" || !out.Values[0].Enabled {
+		t.Fatalf("unexpected environment %+v", out)
+	}
+}
+
+func TestExportOpenAPI(t *testing.T) {
+	data, report, err := ExportOpenAPI(testCollection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Items) != 1 {
+		t.Fatalf("expected the grpc request to be reported, got %v", report.Items)
+	}
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" || len(doc.Servers) != 1 || doc.Servers[0].URL != "{baseUrl}" || doc.Servers[0].Variables["baseUrl"].Default != "https://shop.example.com" {
+		t.Fatalf("unexpected servers %+v", doc.Servers)
+	}
+
+	get := doc.Paths["/orders/{id}"]["get"]
+	if get == nil || get.OperationID != "getOrder" || len(get.Parameters) != 3 {
+		t.Fatalf("unexpected operation %+v", get)
+	}
+	if get.Security[0]["bearerAuth"] == nil || doc.Components.SecuritySchemes["apiKey"].Name != "X-Key" {
+		t.Fatalf("unexpected security %+v %+v", get.Security, doc.Components)
+	}
+
+	schema := get.Responses["default"].Content["application/json"].Schema
+	total := schema["properties"].(map[string]any)["data"].(map[string]any)["properties"].(map[string]any)["total"]
+	if total.(map[string]any)["type"] != "number" {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+
+	upload := doc.Paths["/uploads"]["post"]
+	file := upload.RequestBody.Content["multipart/form-data"].Schema["properties"].(map[string]any)["file"]
+	if file.(map[string]any)["format"] != "binary" {
+		t.Fatalf("unexpected request body %v", upload.RequestBody)
+	}
+
+	// the importer understands the document
+	result, err := importer.ParseOpenAPI(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Collections) == 0 || len(result.Collections[0].Spec.Requests) != 2 {
+		t.Fatalf("unexpected import %+v", result.Collections)
+	}
+}
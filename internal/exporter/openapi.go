@@ -0,0 +1,441 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const openAPIVersion = "3.1.0"
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Servers    []openAPIServer                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components *openAPIComponents                      `json:"components,omitempty"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL       string                           `json:"url"`
+	Variables map[string]openAPIServerVariable `json:"variables,omitempty"`
+}
+
+type openAPIServerVariable struct {
+	Default string `json:"default"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Servers     []openAPIServer            `json:"servers,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   map[string]any `json:"schema"`
+	Example  string         `json:"example,omitempty"`
+}
+
+type openAPIRequestBody struct {
+	Content map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema   map[string]any            `json:"schema,omitempty"`
+	Example  any                       `json:"example,omitempty"`
+	Examples map[string]openAPIExample `json:"examples,omitempty"`
+}
+
+type openAPIExample struct {
+	Value any `json:"value"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIComponents struct {
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// ignoredHeaders are described by other parts of the operation, the spec ignores them as header parameters.
+var ignoredHeaders = map[string]bool{"accept": true, "content-type": true, "authorization": true}
+
+var templateVariable = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+
+// openAPIExporter builds the document and keeps the state shared by the operations.
+type openAPIExporter struct {
+	col    *domain.Collection
+	doc    *openAPIDocument
+	report *convert.Report
+
+	operationIDs map[string]bool
+}
+
+// ExportOpenAPI infers an OpenAPI 3.1 document from the http requests of the collection and their saved
+// responses. The {{variables}} of the server urls become server variables defaulting to the values of the
+// collection variables.
+func ExportOpenAPI(col *domain.Collection) ([]byte, *convert.Report, error) {
+	e := &openAPIExporter{
+		col: col,
+		doc: &openAPIDocument{
+			OpenAPI: openAPIVersion,
+			Info:    openAPIInfo{Title: col.MetaData.Name, Version: "1.0.0"},
+			Paths:   make(map[string]map[string]*openAPIOperation),
+		},
+		report:       &convert.Report{},
+		operationIDs: make(map[string]bool),
+	}
+
+	for _, req := range col.Spec.Requests {
+		path := col.MetaData.Name + " / " + req.MetaData.Name
+		if req.MetaData.Type != domain.RequestTypeHTTP || req.Spec.HTTP == nil {
+			e.report.Add(path, "gRPC requests can not be exported to OpenAPI")
+			continue
+		}
+		e.addOperation(req, path)
+	}
+
+	data, err := json.MarshalIndent(e.doc, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, e.report, nil
+}
+
+func (e *openAPIExporter) addOperation(req *domain.Request, reportPath string) {
+	spec := req.Spec.HTTP
+	httpReq := spec.Request
+	if httpReq == nil {
+		httpReq = &domain.HTTPRequest{}
+	}
+
+	server, path := splitServer(spec.URL)
+	path, pathVariables := convertTemplateVariables(path)
+	method := strings.ToLower(spec.Method)
+
+	if e.doc.Paths[path] == nil {
+		e.doc.Paths[path] = make(map[string]*openAPIOperation)
+	}
+	if _, ok := e.doc.Paths[path][method]; ok {
+		e.report.Add(reportPath, "%s %s is already exported by another request", spec.Method, path)
+		return
+	}
+
+	op := &openAPIOperation{
+		OperationID: e.operationID(req.MetaData.Name),
+		Summary:     req.MetaData.Name,
+		Parameters:  e.parameters(httpReq, pathVariables),
+		RequestBody: requestBody(httpReq),
+		Responses:   responses(spec.Responses),
+		Security:    e.security(httpReq.Auth),
+	}
+
+	if server != "" {
+		s := e.server(server)
+		if len(e.doc.Servers) == 0 {
+			e.doc.Servers = append(e.doc.Servers, s)
+		} else if e.doc.Servers[0].URL != s.URL {
+			op.Servers = []openAPIServer{s}
+		}
+	}
+
+	e.doc.Paths[path][method] = op
+}
+
+// splitServer returns the scheme and host of the url, or the variable it starts with, and its path.
+func splitServer(raw string) (string, string) {
+	raw, _, _ = strings.Cut(raw, "?")
+	raw, _, _ = strings.Cut(raw, "#")
+
+	start := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		start = i + len("://")
+	} else if strings.HasPrefix(raw, "{{") {
+		start = strings.Index(raw, "}}") + len("}}")
+		if start < len("}}") {
+			start = 0
+		}
+	}
+
+	server, path := raw, "/"
+	if i := strings.Index(raw[start:], "/"); i >= 0 {
+		server, path = raw[:start+i], raw[start+i:]
+	}
+	return server, path
+}
+
+// convertTemplateVariables replaces the {{name}} variables by {name} and returns their names.
+func convertTemplateVariables(s string) (string, []string) {
+	var names []string
+	out := templateVariable.ReplaceAllStringFunc(s, func(m string) string {
+		name := templateVariable.FindStringSubmatch(m)[1]
+		names = append(names, name)
+		return "{" + name + "}"
+	})
+	return out, names
+}
+
+func (e *openAPIExporter) server(raw string) openAPIServer {
+	url, names := convertTemplateVariables(raw)
+	s := openAPIServer{URL: url}
+	for _, name := range names {
+		if s.Variables == nil {
+			s.Variables = make(map[string]openAPIServerVariable)
+		}
+		s.Variables[name] = openAPIServerVariable{Default: e.variable(name)}
+	}
+	return s
+}
+
+// variable returns the value of the collection variable.
+func (e *openAPIExporter) variable(name string) string {
+	for _, kv := range e.col.Spec.Variables {
+		if kv.Enable && kv.Key == name {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+// operationID returns the name in camel case, suffixed by a number when another operation has the same id.
+func (e *openAPIExporter) operationID(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = b.Len() > 0
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		} else if b.Len() == 0 {
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	id := b.String()
+	if id == "" {
+		id = "operation"
+	}
+	base := id
+	for i := 2; e.operationIDs[id]; i++ {
+		id = fmt.Sprintf("%s%d", base, i)
+	}
+	e.operationIDs[id] = true
+	return id
+}
+
+func (e *openAPIExporter) parameters(httpReq *domain.HTTPRequest, pathVariables []string) []openAPIParameter {
+	var out []openAPIParameter
+	seen := make(map[string]bool)
+	for _, p := range httpReq.PathParams {
+		seen[p.Key] = true
+		out = append(out, openAPIParameter{Name: p.Key, In: "path", Required: true, Schema: map[string]any{"type": "string"}, Example: p.Value})
+	}
+	for _, name := range pathVariables {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, openAPIParameter{Name: name, In: "path", Required: true, Schema: map[string]any{"type": "string"}, Example: e.variable(name)})
+		}
+	}
+
+	for _, q := range httpReq.QueryParams {
+		if q.Enable && q.Key != "" {
+			out = append(out, openAPIParameter{Name: q.Key, In: "query", Schema: map[string]any{"type": "string"}, Example: q.Value})
+		}
+	}
+
+	apiKey := ""
+	if httpReq.Auth.Type == domain.AuthTypeAPIKey && httpReq.Auth.APIKeyAuth != nil {
+		apiKey = strings.ToLower(httpReq.Auth.APIKeyAuth.Key)
+	}
+	for _, h := range httpReq.Headers {
+		name := strings.ToLower(h.Key)
+		if !h.Enable || h.Key == "" || ignoredHeaders[name] || name == apiKey {
+			continue
+		}
+		out = append(out, openAPIParameter{Name: h.Key, In: "header", Schema: map[string]any{"type": "string"}, Example: h.Value})
+	}
+	return out
+}
+
+func requestBody(httpReq *domain.HTTPRequest) *openAPIRequestBody {
+	body := httpReq.Body
+	ct := contentType(httpReq.Headers)
+	media := &openAPIMediaType{}
+
+	switch body.Type {
+	case domain.BodyTypeJSON:
+		if ct == "" {
+			ct = "application/json"
+		}
+		media.Schema, media.Example = inferExample(body.Data)
+	case domain.BodyTypeXML, domain.BodyTypeText:
+		if ct == "" {
+			ct = "text/plain"
+			if body.Type == domain.BodyTypeXML {
+				ct = "application/xml"
+			}
+		}
+		media.Schema = map[string]any{"type": "string"}
+		media.Example = body.Data
+	case domain.BodyTypeUrlencoded:
+		ct = "application/x-www-form-urlencoded"
+		properties := make(map[string]any)
+		for _, kv := range body.URLEncoded {
+			if kv.Enable && kv.Key != "" {
+				properties[kv.Key] = map[string]any{"type": "string", "example": kv.Value}
+			}
+		}
+		media.Schema = map[string]any{"type": "object", "properties": properties}
+	case domain.BodyTypeFormData:
+		ct = "multipart/form-data"
+		properties := make(map[string]any)
+		for _, f := range body.FormData.Fields {
+			if !f.Enable || f.Key == "" {
+				continue
+			}
+			if f.Type == domain.FormFieldTypeFile {
+				properties[f.Key] = map[string]any{"type": "string", "format": "binary"}
+				continue
+			}
+			properties[f.Key] = map[string]any{"type": "string", "example": f.Value}
+		}
+		media.Schema = map[string]any{"type": "object", "properties": properties}
+	case domain.BodyTypeBinary:
+		ct = "application/octet-stream"
+		media.Schema = map[string]any{"type": "string", "format": "binary"}
+	default:
+		return nil
+	}
+
+	ct, _, _ = strings.Cut(ct, ";")
+	return &openAPIRequestBody{Content: map[string]*openAPIMediaType{strings.TrimSpace(ct): media}}
+}
+
+// responses returns the saved responses as examples of the default response, grouped by their content type.
+func responses(saved []domain.HTTPResponse) map[string]openAPIResponse {
+	if len(saved) == 0 {
+		return map[string]openAPIResponse{"default": {Description: "Response"}}
+	}
+
+	content := make(map[string]*openAPIMediaType)
+	for i, res := range saved {
+		schema, example := inferExample(res.Body)
+		ct := contentType(res.Headers)
+		if ct == "" {
+			ct = "text/plain"
+			if json.Valid([]byte(res.Body)) {
+				ct = "application/json"
+			}
+		}
+		ct, _, _ = strings.Cut(ct, ";")
+		ct = strings.TrimSpace(ct)
+
+		media, ok := content[ct]
+		if !ok {
+			media = &openAPIMediaType{Schema: schema, Examples: make(map[string]openAPIExample)}
+			content[ct] = media
+		}
+		media.Examples[fmt.Sprintf("example%d", i+1)] = openAPIExample{Value: example}
+	}
+	return map[string]openAPIResponse{"default": {Description: "Saved response examples", Content: content}}
+}
+
+// security adds the scheme of the auth to the components and returns the requirement of the operation.
+func (e *openAPIExporter) security(auth domain.Auth) []map[string][]string {
+	var name string
+	var scheme openAPISecurityScheme
+	switch {
+	case auth.Type == domain.AuthTypeBasic && auth.BasicAuth != nil:
+		name, scheme = "basicAuth", openAPISecurityScheme{Type: "http", Scheme: "basic"}
+	case auth.Type == domain.AuthTypeToken && auth.TokenAuth != nil:
+		name, scheme = "bearerAuth", openAPISecurityScheme{Type: "http", Scheme: "bearer"}
+	case auth.Type == domain.AuthTypeAPIKey && auth.APIKeyAuth != nil:
+		scheme = openAPISecurityScheme{Type: "apiKey", In: "header", Name: auth.APIKeyAuth.Key}
+		name = "apiKey"
+		if e.doc.Components != nil {
+			for i := 2; ; i++ {
+				existing, ok := e.doc.Components.SecuritySchemes[name]
+				if !ok || existing == scheme {
+					break
+				}
+				name = fmt.Sprintf("apiKey%d", i)
+			}
+		}
+	default:
+		return nil
+	}
+
+	if e.doc.Components == nil {
+		e.doc.Components = &openAPIComponents{SecuritySchemes: make(map[string]openAPISecurityScheme)}
+	}
+	e.doc.Components.SecuritySchemes[name] = scheme
+	return []map[string][]string{{name: {}}}
+}
+
+// inferExample returns the schema of the JSON document and the document as the example, non JSON data is
+// described as a string.
+func inferExample(data string) (map[string]any, any) {
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return map[string]any{"type": "string"}, data
+	}
+	return inferSchema(value), value
+}
+
+func inferSchema(value any) map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		properties := make(map[string]any, len(v))
+		for key, item := range v {
+			properties[key] = inferSchema(item)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	case []any:
+		if len(v) == 0 {
+			return map[string]any{"type": "array"}
+		}
+		return map[string]any{"type": "array", "items": inferSchema(v[0])}
+	case string:
+		return map[string]any{"type": "string"}
+	case float64:
+		if v == math.Trunc(v) {
+			return map[string]any{"type": "integer"}
+		}
+		return map[string]any{"type": "number"}
+	case bool:
+		return map[string]any{"type": "boolean"}
+	default:
+		return map[string]any{"type": "null"}
+	}
+}
@@ -0,0 +1,387 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	PostmanID string `json:"_postman_id,omitempty"`
+	Name      string `json:"name"`
+	Schema    string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Request  postmanRequest    `json:"request"`
+	Response []postmanResponse `json:"response"`
+	Event    []postmanEvent    `json:"event,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string          `json:"method"`
+	Header []postmanHeader `json:"header"`
+	Body   *postmanBody    `json:"body,omitempty"`
+	URL    postmanURL      `json:"url"`
+	Auth   *postmanAuth    `json:"auth,omitempty"`
+}
+
+type postmanHeader struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type postmanBody struct {
+	Mode       string          `json:"mode"`
+	Raw        string          `json:"raw,omitempty"`
+	URLEncoded []postmanParam  `json:"urlencoded,omitempty"`
+	FormData   []postmanParam  `json:"formdata,omitempty"`
+	File       *postmanFile    `json:"file,omitempty"`
+	Options    *postmanOptions `json:"options,omitempty"`
+}
+
+type postmanFile struct {
+	Src string `json:"src"`
+}
+
+type postmanOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type postmanParam struct {
+	Key      string `json:"key"`
+	Value    string `json:"value,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Src      any    `json:"src,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Protocol string            `json:"protocol,omitempty"`
+	Host     []string          `json:"host,omitempty"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []postmanParam    `json:"query,omitempty"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanVariable struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Basic  []postmanVariable `json:"basic,omitempty"`
+	Bearer []postmanVariable `json:"bearer,omitempty"`
+	APIKey []postmanVariable `json:"apikey,omitempty"`
+}
+
+type postmanResponse struct {
+	Name                   string          `json:"name"`
+	Header                 []postmanHeader `json:"header"`
+	Cookie                 []any           `json:"cookie"`
+	Body                   string          `json:"body"`
+	PostmanPreviewLanguage string          `json:"_postman_previewlanguage,omitempty"`
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Type string   `json:"type"`
+		Exec []string `json:"exec"`
+	} `json:"script"`
+}
+
+type postmanEnvironment struct {
+	ID                   string                       `json:"id"`
+	Name                 string                       `json:"name"`
+	Values               []postmanEnvironmentVariable `json:"values"`
+	PostmanVariableScope string                       `json:"_postman_variable_scope"`
+}
+
+type postmanEnvironmentVariable struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ExportPostmanCollection converts the collection to a Postman v2.1 collection. gRPC requests and the parts
+// of the requests Postman has no equivalent for are left out and reported.
+func ExportPostmanCollection(col *domain.Collection) ([]byte, *convert.Report, error) {
+	report := &convert.Report{}
+	out := postmanCollection{
+		Info: postmanInfo{PostmanID: col.MetaData.ID, Name: col.MetaData.Name, Schema: postmanSchema},
+		Item: make([]postmanItem, 0, len(col.Spec.Requests)),
+	}
+
+	for _, kv := range col.Spec.Variables {
+		out.Variable = append(out.Variable, postmanVariable{Key: kv.Key, Value: kv.Value, Type: "string", Disabled: !kv.Enable})
+	}
+
+	for _, req := range col.Spec.Requests {
+		path := col.MetaData.Name + " / " + req.MetaData.Name
+		if req.MetaData.Type != domain.RequestTypeHTTP || req.Spec.HTTP == nil {
+			report.Add(path, "gRPC requests can not be exported to Postman")
+			continue
+		}
+		out.Item = append(out.Item, postmanRequestItem(req, path, report))
+	}
+
+	data, err := json.MarshalIndent(out, "", "\t")
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(convert.ToPostmanVariables(string(data))), report, nil
+}
+
+// ExportPostmanEnvironment converts the environment to a Postman environment, the values of the secrets are
+// left out so the exported file can be shared.
+func ExportPostmanEnvironment(env *domain.Environment) ([]byte, error) {
+	spec := env.Spec.WithoutSecrets()
+	out := postmanEnvironment{
+		ID:                   env.MetaData.ID,
+		Name:                 env.MetaData.Name,
+		Values:               make([]postmanEnvironmentVariable, 0, len(spec.Values)),
+		PostmanVariableScope: "environment",
+	}
+
+	for _, kv := range spec.Values {
+		typ := "default"
+		if kv.Secret {
+			typ = "secret"
+		}
+		out.Values = append(out.Values, postmanEnvironmentVariable{Key: kv.Key, Value: kv.Value, Type: typ, Enabled: kv.Enable})
+	}
+
+	data, err := json.MarshalIndent(out, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(convert.ToPostmanVariables(string(data))), nil
+}
+
+func postmanRequestItem(req *domain.Request, path string, report *convert.Report) postmanItem {
+	spec := req.Spec.HTTP
+	httpReq := spec.Request
+	if httpReq == nil {
+		httpReq = &domain.HTTPRequest{}
+	}
+
+	item := postmanItem{
+		Name: req.MetaData.Name,
+		Request: postmanRequest{
+			Method: spec.Method,
+			Header: make([]postmanHeader, 0, len(httpReq.Headers)),
+			URL:    postmanRequestURL(spec.URL, httpReq),
+			Auth:   postmanRequestAuth(httpReq.Auth),
+			Body:   postmanRequestBody(httpReq.Body),
+		},
+		Response: make([]postmanResponse, 0, len(spec.Responses)),
+	}
+
+	for _, h := range httpReq.Headers {
+		item.Request.Header = append(item.Request.Header, postmanHeader{Key: h.Key, Value: h.Value, Disabled: !h.Enable})
+	}
+
+	for i, res := range spec.Responses {
+		r := postmanResponse{
+			Name:   fmt.Sprintf("Example %d", i+1),
+			Header: make([]postmanHeader, 0, len(res.Headers)),
+			Cookie: make([]any, 0),
+			Body:   res.Body,
+		}
+		for _, h := range res.Headers {
+			r.Header = append(r.Header, postmanHeader{Key: h.Key, Value: h.Value})
+		}
+		if json.Valid([]byte(res.Body)) {
+			r.PostmanPreviewLanguage = "json"
+		}
+		item.Response = append(item.Response, r)
+	}
+
+	if len(httpReq.Variables) > 0 {
+		report.Add(path, "%d request variables are not exported", len(httpReq.Variables))
+	}
+	if t := httpReq.PreRequest.Type; t != "" && t != domain.PrePostTypeNone {
+		report.Add(path, "%s pre-request is not exported", t)
+	}
+	if event, ok := postmanTestEvent(httpReq.PostRequest); ok {
+		item.Event = append(item.Event, event)
+	} else if t := httpReq.PostRequest.Type; t != "" && t != domain.PrePostTypeNone {
+		report.Add(path, "%s post-request is not exported", t)
+	}
+
+	return item
+}
+
+// postmanRequestURL returns the url with the path params written as :name, the query is taken from the
+// query params so the disabled ones are kept.
+func postmanRequestURL(raw string, httpReq *domain.HTTPRequest) postmanURL {
+	base, rawQuery, _ := strings.Cut(raw, "?")
+
+	out := postmanURL{}
+	for _, p := range httpReq.PathParams {
+		base = strings.ReplaceAll(base, "{"+p.Key+"}", ":"+p.Key)
+		out.Variable = append(out.Variable, postmanVariable{Key: p.Key, Value: p.Value})
+	}
+
+	if len(httpReq.QueryParams) > 0 {
+		for _, q := range httpReq.QueryParams {
+			out.Query = append(out.Query, postmanParam{Key: q.Key, Value: q.Value, Disabled: !q.Enable})
+		}
+	} else if rawQuery != "" {
+		for _, pair := range strings.Split(rawQuery, "&") {
+			key, value, _ := strings.Cut(pair, "=")
+			out.Query = append(out.Query, postmanParam{Key: key, Value: value})
+		}
+	}
+
+	enabled := make([]string, 0, len(out.Query))
+	for _, q := range out.Query {
+		if !q.Disabled {
+			enabled = append(enabled, q.Key+"="+q.Value)
+		}
+	}
+
+	out.Raw = base
+	if len(enabled) > 0 {
+		out.Raw += "?" + strings.Join(enabled, "&")
+	}
+
+	rest := base
+	if protocol, after, ok := strings.Cut(base, "://"); ok {
+		out.Protocol = protocol
+		rest = after
+	}
+	host, path, _ := strings.Cut(rest, "/")
+	if host != "" {
+		out.Host = strings.Split(host, ".")
+	}
+	if path != "" {
+		out.Path = strings.Split(path, "/")
+	}
+	return out
+}
+
+func postmanRequestAuth(auth domain.Auth) *postmanAuth {
+	switch auth.Type {
+	case domain.AuthTypeBasic:
+		if auth.BasicAuth != nil {
+			return &postmanAuth{Type: "basic", Basic: []postmanVariable{
+				{Key: "username", Value: auth.BasicAuth.Username, Type: "string"},
+				{Key: "password", Value: auth.BasicAuth.Password, Type: "string"},
+			}}
+		}
+	case domain.AuthTypeToken:
+		if auth.TokenAuth != nil {
+			return &postmanAuth{Type: "bearer", Bearer: []postmanVariable{
+				{Key: "token", Value: auth.TokenAuth.Token, Type: "string"},
+			}}
+		}
+	case domain.AuthTypeAPIKey:
+		if auth.APIKeyAuth != nil {
+			return &postmanAuth{Type: "apikey", APIKey: []postmanVariable{
+				{Key: "key", Value: auth.APIKeyAuth.Key, Type: "string"},
+				{Key: "value", Value: auth.APIKeyAuth.Value, Type: "string"},
+				{Key: "in", Value: "header", Type: "string"},
+			}}
+		}
+	}
+	return nil
+}
+
+func postmanRequestBody(body domain.Body) *postmanBody {
+	switch body.Type {
+	case domain.BodyTypeJSON, domain.BodyTypeXML, domain.BodyTypeText:
+		out := &postmanBody{Mode: "raw", Raw: body.Data, Options: &postmanOptions{}}
+		out.Options.Raw.Language = body.Type
+		return out
+	case domain.BodyTypeUrlencoded:
+		out := &postmanBody{Mode: "urlencoded", URLEncoded: make([]postmanParam, 0, len(body.URLEncoded))}
+		for _, kv := range body.URLEncoded {
+			out.URLEncoded = append(out.URLEncoded, postmanParam{Key: kv.Key, Value: kv.Value, Type: "text", Disabled: !kv.Enable})
+		}
+		return out
+	case domain.BodyTypeFormData:
+		out := &postmanBody{Mode: "formdata", FormData: make([]postmanParam, 0, len(body.FormData.Fields))}
+		for _, f := range body.FormData.Fields {
+			p := postmanParam{Key: f.Key, Value: f.Value, Type: "text", Disabled: !f.Enable}
+			if f.Type == domain.FormFieldTypeFile {
+				p = postmanParam{Key: f.Key, Type: "file", Src: f.Files, Disabled: !f.Enable}
+				if len(f.Files) == 1 {
+					p.Src = f.Files[0]
+				}
+			}
+			out.FormData = append(out.FormData, p)
+		}
+		return out
+	case domain.BodyTypeBinary:
+		return &postmanBody{Mode: "file", File: &postmanFile{Src: body.BinaryFilePath}}
+	}
+	return nil
+}
+
+// simpleJSONPath matches the json paths which are valid javascript accessors once the leading $ is removed.
+var simpleJSONPath = regexp.MustCompile(`^\$?((\.[A-Za-z_$][\w$]*)|(\[\d+\]))+$`)
+
+// postmanTestEvent converts the post-request setting an environment value to a Postman test script.
+func postmanTestEvent(r domain.PostRequest) (postmanEvent, bool) {
+	var event postmanEvent
+	if r.Type != domain.PrePostTypeSetEnv || r.PostRequestSet.Target == "" {
+		return event, false
+	}
+
+	set := r.PostRequestSet
+	var value string
+	switch set.From {
+	case domain.PostRequestSetFromResponseBody:
+		path := set.FromKey
+		if !strings.HasPrefix(path, "$") {
+			path = "$." + path
+		}
+		if !simpleJSONPath.MatchString(path) {
+			return event, false
+		}
+		value = "pm.response.json()" + strings.TrimPrefix(path, "$")
+	case domain.PostRequestSetFromResponseHeader:
+		value = fmt.Sprintf("pm.response.headers.get(%s)", jsString(set.FromKey))
+	case domain.PostRequestSetFromResponseCookie:
+		value = fmt.Sprintf("pm.cookies.get(%s)", jsString(set.FromKey))
+	default:
+		return event, false
+	}
+
+	event.Listen = "test"
+	event.Script.Type = "text/javascript"
+	event.Script.Exec = []string{
+		fmt.Sprintf("if (pm.response.code === %d) {", set.StatusCode),
+		fmt.Sprintf("    pm.environment.set(%s, %s);", jsString(set.Target), value),
+		"}",
+	}
+	return event, true
+}
+
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
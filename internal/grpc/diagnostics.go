@@ -0,0 +1,209 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// Diagnostics is the state of the connection to the server of a gRPC request.
+type Diagnostics struct {
+	Address string
+	// State is the connectivity state of the channel after trying to connect.
+	State string
+	// TLS is nil for plain text connections.
+	TLS    *TLSDetails
+	Health []HealthStatus
+}
+
+type TLSDetails struct {
+	Version            string
+	CipherSuite        string
+	NegotiatedProtocol string
+	ServerName         string
+	Certificates       []CertificateDetails
+}
+
+type CertificateDetails struct {
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// HealthStatus is the result of grpc.health.v1.Health check for a service, empty service is the server overall health.
+type HealthStatus struct {
+	Service string
+	Status  string
+	Error   error
+}
+
+var errDiagnosticsProtocol = errors.New("diagnostics are only available for the native gRPC protocol")
+
+// Diagnose connects to the server of the request and reports the channel state, the negotiated TLS
+// parameters and the health of the server and of each service of the request.
+func (s *Service) Diagnose(id, activeEnvironmentID string) (*Diagnostics, error) {
+	spec, err := s.ResolveRequestSpec(id, activeEnvironmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec == nil {
+		return nil, errors.New("request is not a gRPC request")
+	}
+
+	if spec.ServerInfo.Protocol != "" && spec.ServerInfo.Protocol != domain.GRPCProtocolGRPC {
+		return nil, errDiagnosticsProtocol
+	}
+
+	conn, err := s.connection(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(s.outgoingContext(spec), diagnosticsTimeout(spec))
+	defer cancel()
+
+	out := &Diagnostics{
+		Address: spec.ServerInfo.Address,
+		State:   waitForReady(ctx, conn).String(),
+	}
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	var p peer.Peer
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(&p))
+	out.Health = append(out.Health, healthStatus("", resp, err))
+
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		out.TLS = tlsDetails(info.State)
+	}
+
+	for _, svc := range spec.Services {
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: svc.FullName()})
+		out.Health = append(out.Health, healthStatus(svc.FullName(), resp, err))
+	}
+
+	// the state may have changed while running the checks
+	out.State = conn.GetState().String()
+
+	return out, nil
+}
+
+// WatchHealth streams the health of the given service using grpc.health.v1.Health/Watch until the
+// context is canceled or the stream fails. onUpdate is called for every status sent by the server.
+func (s *Service) WatchHealth(ctx context.Context, id, activeEnvironmentID, service string, onUpdate func(HealthStatus)) error {
+	spec, err := s.ResolveRequestSpec(id, activeEnvironmentID)
+	if err != nil {
+		return err
+	}
+
+	if spec == nil {
+		return errors.New("request is not a gRPC request")
+	}
+
+	if spec.ServerInfo.Protocol != "" && spec.ServerInfo.Protocol != domain.GRPCProtocolGRPC {
+		return errDiagnosticsProtocol
+	}
+
+	conn, err := s.connection(spec)
+	if err != nil {
+		return err
+	}
+
+	md, _ := metadata.FromOutgoingContext(s.outgoingContext(spec))
+	stream, err := grpc_health_v1.NewHealthClient(conn).Watch(metadata.NewOutgoingContext(ctx, md), &grpc_health_v1.HealthCheckRequest{Service: service})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		onUpdate(healthStatus(service, resp, nil))
+	}
+}
+
+// outgoingContext returns a context with the enabled metadata and the auth of the request.
+func (s *Service) outgoingContext(spec *domain.GRPCRequestSpec) context.Context {
+	md := metadata.New(nil)
+	for _, item := range spec.Metadata {
+		if !item.Enable {
+			continue
+		}
+		md.Append(item.Key, item.Value)
+	}
+
+	if authHeaders := s.prepareAuth(spec); authHeaders != nil {
+		md = metadata.Join(md, *authHeaders)
+	}
+
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+func diagnosticsTimeout(spec *domain.GRPCRequestSpec) time.Duration {
+	if spec.Settings.TimeoutMilliseconds > 0 {
+		return time.Duration(spec.Settings.TimeoutMilliseconds) * time.Millisecond
+	}
+	return 5 * time.Second
+}
+
+// waitForReady triggers the connection and waits until it is ready, failed or the context is done.
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) connectivity.State {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready || state == connectivity.TransientFailure || state == connectivity.Shutdown {
+			return state
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return conn.GetState()
+		}
+	}
+}
+
+func healthStatus(service string, resp *grpc_health_v1.HealthCheckResponse, err error) HealthStatus {
+	if err != nil {
+		return HealthStatus{Service: service, Status: "UNKNOWN", Error: err}
+	}
+	return HealthStatus{Service: service, Status: resp.GetStatus().String()}
+}
+
+func tlsDetails(state tls.ConnectionState) *TLSDetails {
+	out := &TLSDetails{
+		Version:            tls.VersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		ServerName:         state.ServerName,
+	}
+
+	for _, cert := range state.PeerCertificates {
+		out.Certificates = append(out.Certificates, CertificateDetails{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+		})
+	}
+
+	return out
+}
@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+// healthServer reports the statuses it is given, services it does not know are not found.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	statuses map[string]grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+func (h *healthServer) Check(_ context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	st, ok := h.statuses[req.GetService()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: st}, nil
+}
+
+func (h *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: h.statuses[req.GetService()]}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+// newHealthService starts a server with the health service and returns a service holding a grpc request to it,
+// the services of the request are parsed from the test proto the way they are loaded by the app.
+func newHealthService(t *testing.T) (*Service, *domain.Request) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, &healthServer{statuses: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+		"":                 grpc_health_v1.HealthCheckResponse_SERVING,
+		"test.UserService": grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	req := domain.NewGRPCRequest("health")
+	req.Spec.GRPC.ServerInfo.Address = lis.Addr().String()
+	req.Spec.GRPC.Settings = domain.Settings{Insecure: true, TimeoutMilliseconds: 2000}
+
+	files := new(protoregistry.Files)
+	if err := files.RegisterFile(testFile(t)); err != nil {
+		t.Fatal(err)
+	}
+	services, err := (&Service{}).parseRegistryFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Spec.GRPC.Services = services
+
+	requests := state.NewRequests(nil)
+	environments := state.NewEnvironments(nil)
+	requests.AddRequest(req)
+
+	s := NewService(requests, environments, nil, variables.NewProvider(requests, environments, nil))
+	t.Cleanup(s.CloseConnections)
+	return s, req
+}
+
+func Test_connection(t *testing.T) {
+	s := NewService(nil, nil, nil, nil)
+	defer s.CloseConnections()
+
+	spec := &domain.GRPCRequestSpec{
+		ServerInfo: domain.ServerInfo{Address: "127.0.0.1:1"},
+		Settings:   domain.Settings{Insecure: true},
+	}
+
+	first, err := s.connection(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn, _ := s.connection(spec); conn != first {
+		t.Fatal("connection() dialed again for the same address and settings")
+	}
+
+	changed := *spec
+	changed.Settings.Authority = "example.com"
+	other, err := s.connection(&changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Fatal("connection() reused the connection of different settings")
+	}
+
+	_ = first.Close()
+	redialed, err := s.connection(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redialed == first || redialed.GetState() == connectivity.Shutdown {
+		t.Fatal("connection() returned a closed connection")
+	}
+	if got := len(s.connections.Keys()); got != 2 {
+		t.Fatalf("pool has %d connections, want 2", got)
+	}
+
+	web := *spec
+	web.ServerInfo.Protocol = domain.GRPCProtocolConnect
+	client, err := s.webClient(&web)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := s.webClient(&web); again != client {
+		t.Fatal("webClient() created another client for the same address and settings")
+	}
+
+	s.CloseConnections()
+	if got := len(s.connections.Keys()) + len(s.httpClients.Keys()); got != 0 {
+		t.Fatalf("pool has %d connections after closing, want 0", got)
+	}
+	if other.GetState() != connectivity.Shutdown {
+		t.Fatal("CloseConnections() did not close the pooled connections")
+	}
+}
+
+func Test_Diagnose(t *testing.T) {
+	s, req := newHealthService(t)
+
+	out, err := s.Diagnose(req.MetaData.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Address != req.Spec.GRPC.ServerInfo.Address || out.State != connectivity.Ready.String() || out.TLS != nil {
+		t.Fatalf("Diagnose() = %+v", out)
+	}
+
+	// the services are checked with their fully-qualified names, the server does not know AddressService
+	want := map[string]string{"": "SERVING", "test.UserService": "NOT_SERVING", "test.AddressService": "UNKNOWN"}
+	if len(out.Health) != len(want) {
+		t.Fatalf("Diagnose() health = %+v", out.Health)
+	}
+	for i, h := range out.Health {
+		if status, ok := want[h.Service]; !ok || h.Status != status || (h.Error != nil) != (status == "UNKNOWN") {
+			t.Errorf("health[%d] = %+v", i, h)
+		}
+	}
+
+	req.Spec.GRPC.ServerInfo.Protocol = domain.GRPCProtocolGRPCWeb
+	if _, err := s.Diagnose(req.MetaData.ID, ""); err != errDiagnosticsProtocol {
+		t.Fatalf("Diagnose() error = %v, want %v", err, errDiagnosticsProtocol)
+	}
+}
+
+func Test_WatchHealth(t *testing.T) {
+	s, req := newHealthService(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var updates []HealthStatus
+	err := s.WatchHealth(ctx, req.MetaData.ID, "", "test.UserService", func(h HealthStatus) {
+		updates = append(updates, h)
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(updates) != 1 || updates[0].Status != "NOT_SERVING" {
+		t.Fatalf("WatchHealth() updates = %+v", updates)
+	}
+}
+
+func Test_tlsDetails(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+
+	cert := srv.Certificate()
+	out := tlsDetails(tls.ConnectionState{
+		Version:            tls.VersionTLS13,
+		CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+		NegotiatedProtocol: "h2",
+		ServerName:         "example.com",
+		PeerCertificates:   []*x509.Certificate{cert},
+	})
+
+	if out.Version != "TLS 1.3" || out.CipherSuite != "TLS_AES_128_GCM_SHA256" || out.NegotiatedProtocol != "h2" || out.ServerName != "example.com" {
+		t.Fatalf("tlsDetails() = %+v", out)
+	}
+	if len(out.Certificates) != 1 || out.Certificates[0].Subject != cert.Subject.String() || !out.Certificates[0].NotAfter.Equal(cert.NotAfter) {
+		t.Fatalf("tlsDetails() certificates = %+v", out.Certificates)
+	}
+}
@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	// register the well known error detail types such as BadRequest and ErrorInfo
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// typeResolver resolves message types from the global registry first and then from the descriptors
+// loaded for the request, so custom error detail types defined by the server can be rendered too.
+type typeResolver struct {
+	dynamic *dynamicpb.Types
+}
+
+func newTypeResolver(files *protoregistry.Files) *typeResolver {
+	r := &typeResolver{}
+	if files != nil {
+		r.dynamic = dynamicpb.NewTypes(files)
+	}
+	return r
+}
+
+func (r *typeResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(name)
+	if err == nil || r.dynamic == nil {
+		return mt, err
+	}
+	return r.dynamic.FindMessageByName(name)
+}
+
+func (r *typeResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(url)
+	if err == nil || r.dynamic == nil {
+		return mt, err
+	}
+	return r.dynamic.FindMessageByURL(url)
+}
+
+func (r *typeResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	xt, err := protoregistry.GlobalTypes.FindExtensionByName(field)
+	if err == nil || r.dynamic == nil {
+		return xt, err
+	}
+	return r.dynamic.FindExtensionByName(field)
+}
+
+func (r *typeResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	xt, err := protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+	if err == nil || r.dynamic == nil {
+		return xt, err
+	}
+	return r.dynamic.FindExtensionByNumber(message, field)
+}
+
+// errorDetails renders the status of err with its details as JSON. It returns an empty string when
+// the error carries no details.
+func (s *Service) errorDetails(id string, err error) string {
+	st, ok := status.FromError(err)
+	if !ok || len(st.Proto().GetDetails()) == 0 {
+		return ""
+	}
+
+	files, _ := s.protoFilesRegistry.Get(id)
+	return errorDetailsJSON(st, newTypeResolver(files))
+}
+
+// errorDetailsJSON renders the code, message and details of the status as indented JSON. Details whose
+// type can not be resolved are rendered with their type url and base64 encoded value.
+func errorDetailsJSON(st *status.Status, resolver *typeResolver) string {
+	details := make([]json.RawMessage, 0, len(st.Proto().GetDetails()))
+	for _, detail := range st.Proto().GetDetails() {
+		details = append(details, detailJSON(detail, resolver))
+	}
+
+	out := struct {
+		Code    string            `json:"code"`
+		Message string            `json:"message"`
+		Details []json.RawMessage `json:"details"`
+	}{
+		Code:    st.Code().String(),
+		Message: st.Message(),
+		Details: details,
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+func detailJSON(detail *anypb.Any, resolver *typeResolver) json.RawMessage {
+	data, err := (protojson.MarshalOptions{Resolver: resolver}).Marshal(detail)
+	if err == nil {
+		return data
+	}
+
+	raw, _ := json.Marshal(map[string]string{
+		"@type": detail.GetTypeUrl(),
+		"value": base64.StdEncoding.EncodeToString(detail.GetValue()),
+	})
+	return raw
+}
+
+// statusWithDetails decodes the grpc-status-details-bin value sent by gRPC-Web servers.
+func statusWithDetails(code codes.Code, msg, detailsBin string) error {
+	data, err := decodeBinaryHeader(detailsBin)
+	if err != nil {
+		return status.Error(code, msg)
+	}
+
+	var st spb.Status
+	if err := proto.Unmarshal(data, &st); err != nil {
+		return status.Error(code, msg)
+	}
+
+	// the code and message of the header take precedence as they are what the server reported
+	st.Code = int32(code)
+	st.Message = msg
+	return status.FromProto(&st).Err()
+}
+
+// decodeBinaryHeader decodes a -bin header which may be padded or not.
+func decodeBinaryHeader(v string) ([]byte, error) {
+	if strings.HasSuffix(v, "=") {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return base64.RawStdEncoding.DecodeString(v)
+}
+
+type connectErrorDetail struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// connectStatus builds the status of a Connect error including its details.
+func connectStatus(code codes.Code, msg string, details []connectErrorDetail) error {
+	st := &spb.Status{Code: int32(code), Message: msg}
+	for _, d := range details {
+		value, err := decodeBinaryHeader(d.Value)
+		if err != nil {
+			continue
+		}
+		st.Details = append(st.Details, &anypb.Any{TypeUrl: "type.googleapis.com/" + d.Type, Value: value})
+	}
+
+	return status.FromProto(st).Err()
+}
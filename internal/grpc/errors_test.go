@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func Test_errorDetails(t *testing.T) {
+	badRequest := &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "is required"}},
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid user").WithDetails(badRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bin, err := proto.Marshal(st.Proto())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := proto.Marshal(badRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connectBody, _ := json.Marshal(map[string]any{
+		"code":    "invalid_argument",
+		"message": "invalid user",
+		"details": []map[string]string{{"type": "google.rpc.BadRequest", "value": base64.RawStdEncoding.EncodeToString(value)}},
+	})
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "native", err: st.Err()},
+		{
+			name: "grpc-web trailers",
+			err: statusFromMetadata(metadata.Pairs(
+				"grpc-status", "3",
+				"grpc-message", "invalid user",
+				"grpc-status-details-bin", base64.RawStdEncoding.EncodeToString(bin),
+			)),
+		},
+		{name: "connect", err: connectError(connectBody, 400, "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := status.FromError(tt.err)
+			out := errorDetailsJSON(got, newTypeResolver(nil))
+
+			var decoded struct {
+				Code    string
+				Message string
+				Details []map[string]any
+			}
+			if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+				t.Fatalf("invalid details json %q: %v", out, err)
+			}
+
+			if decoded.Code != "InvalidArgument" || decoded.Message != "invalid user" || len(decoded.Details) != 1 {
+				t.Fatalf("unexpected details %s", out)
+			}
+
+			if decoded.Details[0]["@type"] != "type.googleapis.com/google.rpc.BadRequest" || decoded.Details[0]["fieldViolations"] == nil {
+				t.Errorf("detail is not decoded %s", out)
+			}
+		})
+	}
+
+	t.Run("unknown type", func(t *testing.T) {
+		unknown := status.New(codes.Internal, "failed").Proto()
+		unknown.Details = []*anypb.Any{{TypeUrl: "type.googleapis.com/acme.Unknown", Value: []byte{1, 2}}}
+
+		var decoded struct {
+			Details []map[string]string
+		}
+		if err := json.Unmarshal([]byte(errorDetailsJSON(status.FromProto(unknown), newTypeResolver(nil))), &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(decoded.Details) != 1 || decoded.Details[0]["@type"] != "type.googleapis.com/acme.Unknown" || decoded.Details[0]["value"] != "AQI=" {
+			t.Errorf("unexpected fallback %v", decoded.Details)
+		}
+	})
+}
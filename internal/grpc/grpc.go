@@ -8,15 +8,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	// register the gzip compressor
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -39,17 +45,28 @@ type Service struct {
 	requests     *state.Requests
 	environments *state.Environments
 	protoFiles   *state.ProtoFiles
+	variables    *variables.Provider
 
 	protoFilesRegistry *safemap.Map[*protoregistry.Files]
+
+	// connections pools the client connections by address and connection settings.
+	connections *safemap.Map[*grpc.ClientConn]
+	// httpClients pools the clients of the gRPC-Web and Connect calls the same way.
+	httpClients *safemap.Map[*http.Client]
+	connMux     sync.Mutex
 }
 
 type Response struct {
+	// URL is the address of the server followed by the method, with the variables resolved.
+	URL        string
 	Body       string
 	Metadata   []domain.KeyValue
 	Trailers   []domain.KeyValue
 	TimePassed time.Duration
 	Size       int
 	Error      error
+	// ErrorDetails is the JSON of the status details sent with the error, empty when there are none.
+	ErrorDetails string
 
 	StatueCode int
 	Status     string
@@ -60,12 +77,15 @@ var (
 	semver  = "0.1.0-beta1"
 )
 
-func NewService(requests *state.Requests, envs *state.Environments, protoFiles *state.ProtoFiles) *Service {
+func NewService(requests *state.Requests, envs *state.Environments, protoFiles *state.ProtoFiles, variables *variables.Provider) *Service {
 	return &Service{
 		requests:           requests,
 		environments:       envs,
 		protoFiles:         protoFiles,
+		variables:          variables,
 		protoFilesRegistry: safemap.New[*protoregistry.Files](),
+		connections:        safemap.New[*grpc.ClientConn](),
+		httpClients:        safemap.New[*http.Client](),
 	}
 }
 
@@ -75,46 +95,238 @@ func (s *Service) Dial(req *domain.GRPCRequestSpec) (*grpc.ClientConn, error) {
 	}
 
 	if !req.Settings.Insecure {
-		var tlsCfg tls.Config
-		tlsCfg.InsecureSkipVerify = req.Settings.Insecure
+		tlsCfg, err := tlsConfig(req.Settings)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
 
-		if req.Settings.ClientCertFile != "" {
-			certFile, err := os.ReadFile(req.Settings.ClientCertFile)
-			if err != nil {
-				return nil, err
-			}
+	if req.Settings.Authority != "" {
+		opts = append(opts, grpc.WithAuthority(req.Settings.Authority))
+	}
 
-			keyFile, err := os.ReadFile(req.Settings.ClientCertFile)
-			if err != nil {
-				return nil, err
-			}
+	if params, ok := keepaliveParams(req.Settings); ok {
+		opts = append(opts, grpc.WithKeepaliveParams(params))
+	}
 
-			cert, err := tls.X509KeyPair(certFile, keyFile)
-			if err != nil {
-				return nil, err
-			}
-			tlsCfg.Certificates = []tls.Certificate{cert}
+	var callOpts []grpc.CallOption
+	if req.Settings.MaxSendMessageSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(req.Settings.MaxSendMessageSize))
+	}
+
+	if req.Settings.MaxReceiveMessageSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(req.Settings.MaxReceiveMessageSize))
+	}
+
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	return grpc.NewClient(req.ServerInfo.Address, opts...)
+}
+
+// keepaliveParams returns the client keepalive parameters, ok is false when keepalive is disabled.
+func keepaliveParams(settings domain.Settings) (keepalive.ClientParameters, bool) {
+	if settings.KeepaliveTimeMilliseconds <= 0 {
+		return keepalive.ClientParameters{}, false
+	}
+
+	return keepalive.ClientParameters{
+		Time:                time.Duration(settings.KeepaliveTimeMilliseconds) * time.Millisecond,
+		Timeout:             time.Duration(settings.KeepaliveTimeoutMilliseconds) * time.Millisecond,
+		PermitWithoutStream: settings.KeepalivePermitWithoutStream,
+	}, true
+}
+
+// connection returns a pooled connection for the request, a new one is dialed if there is no usable connection
+// with the same address and settings.
+func (s *Service) connection(req *domain.GRPCRequestSpec) (*grpc.ClientConn, error) {
+	key := connectionKey(req)
+
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+
+	if conn, ok := s.connections.Get(key); ok {
+		if conn.GetState() != connectivity.Shutdown {
+			return conn, nil
+		}
+		s.connections.Delete(key)
+	}
+
+	conn, err := s.Dial(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.connections.Set(key, conn)
+	return conn, nil
+}
+
+func connectionKey(req *domain.GRPCRequestSpec) string {
+	return fmt.Sprintf("%s|%+v", req.ServerInfo.Address, req.Settings)
+}
+
+// webClient returns the pooled HTTP client of the gRPC-Web and Connect calls with the same address and
+// connection settings, it creates one if there is none.
+func (s *Service) webClient(req *domain.GRPCRequestSpec) (*http.Client, error) {
+	key := connectionKey(req)
+
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+
+	if client, ok := s.httpClients.Get(key); ok {
+		return client, nil
+	}
+
+	client, err := httpClient(req.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	s.httpClients.Set(key, client)
+	return client, nil
+}
+
+// CloseConnections closes all the pooled connections.
+func (s *Service) CloseConnections() {
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+
+	for _, key := range s.connections.Keys() {
+		if conn, ok := s.connections.Get(key); ok {
+			_ = conn.Close()
+		}
+		s.connections.Delete(key)
+	}
+
+	for _, key := range s.httpClients.Keys() {
+		if client, ok := s.httpClients.Get(key); ok {
+			client.CloseIdleConnections()
+		}
+		s.httpClients.Delete(key)
+	}
+}
+
+// ResolveRequestSpec returns a copy of the request spec with the variables and the active environment applied.
+func (s *Service) ResolveRequestSpec(id, activeEnvironmentID string) (*domain.GRPCRequestSpec, error) {
+	return s.resolveRequestSpec(id, activeEnvironmentID, variables.Values{})
+}
+
+func (s *Service) resolveRequestSpec(id, activeEnvironmentID string, values variables.Values) (*domain.GRPCRequestSpec, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return nil, ErrRequestNotFound
+	}
+
+	r := req.Clone()
+	if r.Spec.GRPC == nil {
+		return nil, nil
+	}
+
+	s.applyVariables(r, activeEnvironmentID, values)
+	return r.Spec.GRPC, nil
+}
+
+// MethodDescriptor returns the descriptor of the method selected in the request.
+func (s *Service) MethodDescriptor(id, activeEnvironmentID string) (protoreflect.MethodDescriptor, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return nil, ErrRequestNotFound
+	}
+
+	if req.Spec.GRPC == nil || req.Spec.GRPC.LasSelectedMethod == "" {
+		return nil, errors.New("no method selected")
+	}
+
+	return s.getMethodDesc(id, activeEnvironmentID, req.Spec.GRPC.LasSelectedMethod)
+}
+
+// UnresolvedVariables returns the variables used in the request which can not be resolved with the active environment.
+func (s *Service) UnresolvedVariables(id, activeEnvironmentID string) ([]variables.Warning, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return nil, ErrRequestNotFound
+	}
+
+	r := req.Clone()
+	if r.Spec.GRPC == nil {
+		return nil, nil
+	}
+
+	return s.applyVariables(r, activeEnvironmentID, variables.Values{}), nil
+}
+
+// VariableScopes returns the variables known to the request and the scope each of them is defined in.
+func (s *Service) VariableScopes(id, activeEnvironmentID string) map[string]variables.Scope {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return nil
+	}
+
+	_, sources := s.variables.Scopes(req.Clone(), activeEnvironmentID).Variables()
+	return sources
+}
+
+// DescribeVariable returns the resolved value of the variable for the request and the scope it is defined in.
+func (s *Service) DescribeVariable(id, activeEnvironmentID, name string) (string, variables.Scope, bool) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return "", "", false
+	}
+
+	return s.variables.Scopes(req.Clone(), activeEnvironmentID).Describe(name)
+}
+
+// applyVariables renders the variables of the scopes of the request in its grpc spec, req should be a clone.
+// values are the ones of the iteration data row and of the prerequisites, if any.
+func (s *Service) applyVariables(req *domain.Request, activeEnvironmentID string, values variables.Values) []variables.Warning {
+	scopes := s.variables.Scopes(req, activeEnvironmentID)
+	scopes.SetValues(values)
+	engine, warnings := scopes.Resolve()
+	return append(warnings, variables.ApplyToGRPCRequest(engine, req.Spec.GRPC)...)
+}
+
+func tlsConfig(settings domain.Settings) (*tls.Config, error) {
+	var tlsCfg tls.Config
+	tlsCfg.InsecureSkipVerify = settings.Insecure
+	tlsCfg.ServerName = settings.NameOverride
+
+	if settings.ClientCertFile != "" {
+		certFile, err := os.ReadFile(settings.ClientCertFile)
+		if err != nil {
+			return nil, err
 		}
 
-		var err error
-		tlsCfg.RootCAs, err = x509.SystemCertPool()
+		keyFile, err := os.ReadFile(settings.ClientKeyFile)
 		if err != nil {
-			tlsCfg.RootCAs = x509.NewCertPool()
+			return nil, err
 		}
-		if req.Settings.RootCertFile != "" {
-			rootFile, err := os.ReadFile(req.Settings.RootCertFile)
-			if err != nil {
-				return nil, err
-			}
 
-			tlsCfg.RootCAs.AppendCertsFromPEM(rootFile)
+		cert, err := tls.X509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
 		}
-		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tlsCfg)))
-	} else {
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		tlsCfg.Certificates = []tls.Certificate{cert}
 	}
 
-	return grpc.NewClient(req.ServerInfo.Address, opts...)
+	var err error
+	tlsCfg.RootCAs, err = x509.SystemCertPool()
+	if err != nil {
+		tlsCfg.RootCAs = x509.NewCertPool()
+	}
+	if settings.RootCertFile != "" {
+		rootFile, err := os.ReadFile(settings.RootCertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsCfg.RootCAs.AppendCertsFromPEM(rootFile)
+	}
+
+	return &tlsCfg, nil
 }
 
 func (s *Service) GetRequestStruct(id, environmentID string) (string, error) {
@@ -142,6 +354,11 @@ func (s *Service) GetRequestStruct(id, environmentID string) (string, error) {
 	return string(jsonBytes), nil
 }
 
+// ExampleJSON returns an example of the message with a placeholder value for each field.
+func ExampleJSON(md protoreflect.MessageDescriptor) ([]byte, error) {
+	return json.Marshal(generateExampleJSON(md))
+}
+
 func generateExampleJSON(messageDescriptor protoreflect.MessageDescriptor) map[string]interface{} {
 	out := make(map[string]interface{})
 
@@ -160,7 +377,8 @@ func generateExampleJSON(messageDescriptor protoreflect.MessageDescriptor) map[s
 			protoreflect.Sfixed64Kind:
 			out = 123
 		case protoreflect.BytesKind:
-			out = "bytes"
+			// base64 of "bytes", so the example is a valid JSON encoding of the message
+			out = "Ynl0ZXM="
 		case protoreflect.EnumKind:
 			enum := field.Enum()
 			out = string(enum.Values().Get(0).Name())
@@ -220,24 +438,15 @@ func generateExampleJSON(messageDescriptor protoreflect.MessageDescriptor) map[s
 }
 
 func (s *Service) Invoke(id, activeEnvironmentID string) (*Response, error) {
-	req := s.requests.GetRequest(id)
-	if req == nil {
-		return nil, ErrRequestNotFound
-	}
-
-	spec := req.Clone().Spec.GRPC
-	if spec == nil {
-		return nil, nil
-	}
-
-	var activeEnvironment = s.getActiveEnvironment(activeEnvironmentID)
-
-	vars := variables.GetVariables()
-	variables.ApplyToGRPCRequest(vars, spec)
+	return s.InvokeWithValues(id, activeEnvironmentID, variables.Values{})
+}
 
-	if activeEnvironment != nil {
-		variables.ApplyToEnv(vars, &activeEnvironment.Spec)
-		activeEnvironment.ApplyToGRPCRequest(spec)
+// InvokeWithValues invokes the method with the values of an iteration data row and of the responses of its
+// prerequisites layered above the environment.
+func (s *Service) InvokeWithValues(id, activeEnvironmentID string, values variables.Values) (*Response, error) {
+	spec, err := s.resolveRequestSpec(id, activeEnvironmentID, values)
+	if err != nil || spec == nil {
+		return nil, err
 	}
 
 	method := spec.LasSelectedMethod
@@ -247,9 +456,14 @@ func (s *Service) Invoke(id, activeEnvironmentID string) (*Response, error) {
 
 	rawJSON := []byte(spec.Body)
 
-	conn, err := s.Dial(spec)
-	if err != nil {
-		return nil, err
+	useHTTP := spec.ServerInfo.Protocol == domain.GRPCProtocolGRPCWeb || spec.ServerInfo.Protocol == domain.GRPCProtocolConnect
+
+	var conn *grpc.ClientConn
+	if !useHTTP {
+		conn, err = s.connection(spec)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// get the method descriptor
@@ -260,21 +474,11 @@ func (s *Service) Invoke(id, activeEnvironmentID string) (*Response, error) {
 
 	// create the message
 	request := dynamicpb.NewMessage(md.Input())
-	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rawJSON, request); err != nil {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: !spec.Settings.StrictMode}).Unmarshal(rawJSON, request); err != nil {
 		return nil, err
 	}
 
-	ctx := metadata.NewOutgoingContext(context.Background(), metadata.New(nil))
-	for _, item := range spec.Metadata {
-		if !item.Enable {
-			continue
-		}
-		ctx = metadata.AppendToOutgoingContext(ctx, item.Key, item.Value)
-	}
-
-	if authHeaders := s.prepareAuth(spec); authHeaders != nil {
-		ctx = metadata.NewOutgoingContext(ctx, *authHeaders)
-	}
+	ctx := s.outgoingContext(spec)
 
 	var respHeaders, respTrailers metadata.MD
 
@@ -289,6 +493,11 @@ func (s *Service) Invoke(id, activeEnvironmentID string) (*Response, error) {
 	callOpts := []grpc.CallOption{
 		grpc.Header(&respHeaders),
 		grpc.Trailer(&respTrailers),
+		grpc.WaitForReady(spec.Settings.WaitForReady),
+	}
+
+	if spec.Settings.Compression != "" && spec.Settings.Compression != domain.CompressionNone {
+		callOpts = append(callOpts, grpc.UseCompressor(spec.Settings.Compression))
 	}
 
 	var (
@@ -297,14 +506,22 @@ func (s *Service) Invoke(id, activeEnvironmentID string) (*Response, error) {
 	)
 
 	start := time.Now()
-	if md.IsStreamingServer() {
+	switch {
+	case useHTTP:
+		var result *httpResult
+		result, respErr = s.invokeHTTP(ctx, spec, method, request, md, timeOut)
+		if result != nil {
+			respStr, respHeaders, respTrailers = result.body, result.headers, result.trailers
+		}
+	case md.IsStreamingServer():
 		respStr, respErr = s.invokeServerStream(ctx, conn, method, request, md, callOpts...)
-	} else {
+	default:
 		respStr, respErr = s.invokeUnary(ctx, conn, method, request, md, callOpts...)
 	}
 	elapsed := time.Since(start)
 
 	out := &Response{
+		URL:        spec.ServerInfo.Address + method,
 		TimePassed: elapsed,
 		Metadata:   domain.MetadataToKeyValue(respHeaders),
 		Trailers:   domain.MetadataToKeyValue(respTrailers),
@@ -316,6 +533,7 @@ func (s *Service) Invoke(id, activeEnvironmentID string) (*Response, error) {
 	}
 
 	if respErr != nil {
+		out.ErrorDetails = s.errorDetails(id, respErr)
 		return out, respErr
 	}
 
@@ -421,6 +639,52 @@ func (s *Service) prepareAuth(req *domain.GRPCRequestSpec) *metadata.MD {
 	return nil
 }
 
+// ValidateRequestBody validates the body against the input message of the selected method.
+// It only uses the already loaded proto registry so it is cheap enough to run on every change,
+// if the services are not loaded yet it returns no issues.
+func (s *Service) ValidateRequestBody(id, method, body string) []BodyIssue {
+	md := s.cachedInputDesc(id, method)
+	if md == nil {
+		return nil
+	}
+
+	return ValidateBody(md, body)
+}
+
+// CompleteRequestBody returns the completions for the caret position in the body of the given request.
+func (s *Service) CompleteRequestBody(id, method, body string, offset int) (string, []Completion) {
+	md := s.cachedInputDesc(id, method)
+	if md == nil {
+		return "", nil
+	}
+
+	return CompleteBody(md, body, offset)
+}
+
+func (s *Service) cachedInputDesc(id, method string) protoreflect.MessageDescriptor {
+	if method == "" {
+		return nil
+	}
+
+	registryFiles, exist := s.protoFilesRegistry.Get(id)
+	if !exist {
+		return nil
+	}
+
+	name := strings.Replace(method[1:], "/", ".", 1)
+	desc, err := registryFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil
+	}
+
+	methodDesc, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil
+	}
+
+	return methodDesc.Input()
+}
+
 func (s *Service) getMethodDesc(id, envID, fullName string) (protoreflect.MethodDescriptor, error) {
 	registryFiles, exist := s.protoFilesRegistry.Get(id)
 	if !exist {
@@ -447,29 +711,36 @@ func (s *Service) getMethodDesc(id, envID, fullName string) (protoreflect.Method
 	return methodDesc, nil
 }
 
-func (s *Service) GetServices(id, activeEnvironmentID string) ([]domain.GRPCService, error) {
-	req := s.requests.GetRequest(id)
-	if req == nil {
-		return nil, ErrRequestNotFound
+// Registry returns the proto files of the request, loading them when they are not cached yet.
+func (s *Service) Registry(id, activeEnvironmentID string) (*protoregistry.Files, error) {
+	if registryFiles, exist := s.protoFilesRegistry.Get(id); exist {
+		return registryFiles, nil
 	}
 
-	req = req.Clone()
-
-	var activeEnvironment = s.getActiveEnvironment(activeEnvironmentID)
-	vars := variables.GetVariables()
-	variables.ApplyToGRPCRequest(vars, req.Spec.GRPC)
-
-	if activeEnvironment != nil {
-		variables.ApplyToEnv(vars, &activeEnvironment.Spec)
-		activeEnvironment.ApplyToGRPCRequest(req.Spec.GRPC)
+	if _, err := s.GetServices(id, activeEnvironmentID); err != nil {
+		return nil, err
 	}
 
-	conn, err := s.Dial(req.Spec.GRPC)
+	registryFiles, _ := s.protoFilesRegistry.Get(id)
+	return registryFiles, nil
+}
+
+func (s *Service) GetServices(id, activeEnvironmentID string) ([]domain.GRPCService, error) {
+	spec, err := s.ResolveRequestSpec(id, activeEnvironmentID)
 	if err != nil {
 		return nil, err
 	}
 
-	if req.Spec.GRPC.ServerInfo.ServerReflection {
+	if spec == nil {
+		return nil, errors.New("request is not a gRPC request")
+	}
+
+	if spec.ServerInfo.ServerReflection {
+		conn, err := s.connection(spec)
+		if err != nil {
+			return nil, err
+		}
+
 		protoRegistryFiles, err := ProtoFilesFromReflectionAPI(context.Background(), conn)
 		if err != nil {
 			return nil, err
@@ -478,13 +749,13 @@ func (s *Service) GetServices(id, activeEnvironmentID string) ([]domain.GRPCServ
 		s.protoFilesRegistry.Set(id, protoRegistryFiles)
 
 		return s.parseRegistryFiles(protoRegistryFiles)
-	} else if len(req.Spec.GRPC.ServerInfo.ProtoFiles) > 0 {
+	} else if len(spec.ServerInfo.ProtoFiles) > 0 {
 		protoFiles, err := s.protoFiles.LoadProtoFilesFromDisk()
 		if err != nil {
 			return nil, err
 		}
 
-		protoRegistryFiles, err := ProtoFilesFromDisk(GetImportPaths(protoFiles, req.Spec.GRPC.ServerInfo.ProtoFiles))
+		protoRegistryFiles, err := ProtoFilesFromDisk(GetImportPaths(protoFiles, spec.ServerInfo.ProtoFiles))
 		if err != nil {
 			return nil, err
 		}
@@ -496,19 +767,6 @@ func (s *Service) GetServices(id, activeEnvironmentID string) ([]domain.GRPCServ
 	return nil, fmt.Errorf("no server reflection or proto files found")
 }
 
-func (s *Service) getActiveEnvironment(id string) *domain.Environment {
-	if id == "" {
-		return nil
-	}
-
-	activeEnvironment := s.environments.GetEnvironment(id)
-	if activeEnvironment == nil {
-		return nil
-	}
-
-	return activeEnvironment
-}
-
 func GetImportPaths(protoFiles []*domain.ProtoFile, files []string) ([]string, []string) {
 	importPaths := make([]string, 0, len(protoFiles)+len(files))
 	fileNames := make([]string, 0, len(protoFiles)+len(files))
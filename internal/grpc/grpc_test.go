@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func Test_tlsConfig(t *testing.T) {
+	cfg, err := tlsConfig(domain.Settings{NameOverride: "api.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.ServerName != "api.example.com" || cfg.InsecureSkipVerify || cfg.RootCAs == nil {
+		t.Fatalf("tlsConfig() = ServerName %q, InsecureSkipVerify %v", cfg.ServerName, cfg.InsecureSkipVerify)
+	}
+
+	if _, err := tlsConfig(domain.Settings{RootCertFile: "missing.pem"}); err == nil {
+		t.Fatal("tlsConfig() should fail for a missing root certificate")
+	}
+}
+
+func Test_keepaliveParams(t *testing.T) {
+	if _, ok := keepaliveParams(domain.Settings{KeepaliveTimeoutMilliseconds: 1000}); ok {
+		t.Fatal("keepaliveParams() enabled keepalive without a time")
+	}
+
+	params, ok := keepaliveParams(domain.Settings{
+		KeepaliveTimeMilliseconds:    30000,
+		KeepaliveTimeoutMilliseconds: 5000,
+		KeepalivePermitWithoutStream: true,
+	})
+	if !ok || params.Time != 30*time.Second || params.Timeout != 5*time.Second || !params.PermitWithoutStream {
+		t.Fatalf("keepaliveParams() = %+v, %v", params, ok)
+	}
+}
+
+func Test_Dial(t *testing.T) {
+	get := testFile(t).Services().ByName("UserService").Methods().ByName("Get")
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the server echoes the user and sends back the authority it was called with
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		msg := dynamicpb.NewMessage(get.Input())
+		if err := stream.RecvMsg(msg); err != nil {
+			return err
+		}
+
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if err := stream.SetHeader(metadata.Pairs("x-authority", strings.Join(md.Get(":authority"), ","))); err != nil {
+			return err
+		}
+		return stream.SendMsg(msg)
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	invoke := func(t *testing.T, settings domain.Settings, body string) (metadata.MD, error) {
+		t.Helper()
+
+		settings.Insecure = true
+		conn, err := (&Service{}).Dial(&domain.GRPCRequestSpec{ServerInfo: domain.ServerInfo{Address: lis.Addr().String()}, Settings: settings})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		req := dynamicpb.NewMessage(get.Input())
+		if err := protojson.Unmarshal([]byte(body), req); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var header metadata.MD
+		err = conn.Invoke(ctx, "/test.UserService/Get", req, dynamicpb.NewMessage(get.Output()), grpc.Header(&header))
+		return header, err
+	}
+
+	t.Run("authority", func(t *testing.T) {
+		header, err := invoke(t, domain.Settings{Authority: "api.example.com"}, `{"name":"john"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := header.Get("x-authority"); len(got) != 1 || got[0] != "api.example.com" {
+			t.Fatalf("authority = %v", got)
+		}
+	})
+
+	t.Run("keepalive", func(t *testing.T) {
+		if _, err := invoke(t, domain.Settings{KeepaliveTimeMilliseconds: 10000, KeepaliveTimeoutMilliseconds: 1000}, `{}`); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("max send message size", func(t *testing.T) {
+		_, err := invoke(t, domain.Settings{MaxSendMessageSize: 8}, `{"name":"a longer name than eight bytes"}`)
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("error = %v; want ResourceExhausted", err)
+		}
+	})
+
+	t.Run("max receive message size", func(t *testing.T) {
+		_, err := invoke(t, domain.Settings{MaxReceiveMessageSize: 8}, `{"name":"a longer name than eight bytes"}`)
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("error = %v; want ResourceExhausted", err)
+		}
+	})
+}
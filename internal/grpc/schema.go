@@ -0,0 +1,843 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// BodyIssue is a problem found while validating a request body against its message descriptor.
+// Start and End are rune offsets in the body so the editor can highlight the offending token.
+type BodyIssue struct {
+	Path    string
+	Message string
+	Start   int
+	End     int
+}
+
+func (b BodyIssue) String() string {
+	if b.Path == "" {
+		return b.Message
+	}
+	return fmt.Sprintf("%s: %s", b.Path, b.Message)
+}
+
+// Completion is a suggestion for the position of the caret in the request body.
+type Completion struct {
+	// Text is inserted in place of the prefix returned by CompleteBody.
+	Text   string
+	Detail string
+}
+
+type jsonKind int
+
+const (
+	jsonObject jsonKind = iota
+	jsonArray
+	jsonString
+	jsonNumber
+	jsonBool
+	jsonNull
+	// jsonTemplate is an unquoted {{...}} variable, it can be any value once the body is rendered.
+	jsonTemplate
+)
+
+type jsonNode struct {
+	kind  jsonKind
+	start int
+	end   int
+
+	// raw holds the unquoted string, the number literal or true/false.
+	raw string
+
+	keys   []*jsonNode
+	values []*jsonNode
+}
+
+type jsonParser struct {
+	src []rune
+	pos int
+}
+
+type jsonSyntaxError struct {
+	msg string
+	pos int
+}
+
+func (e *jsonSyntaxError) Error() string {
+	return e.msg
+}
+
+func parseJSONWithPositions(body string) (*jsonNode, error) {
+	p := &jsonParser{src: []rune(body)}
+	p.skipSpace()
+	node, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, &jsonSyntaxError{msg: "unexpected data after top-level value", pos: p.pos}
+	}
+
+	return node, nil
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jsonParser) parseValue() (*jsonNode, error) {
+	if p.pos >= len(p.src) {
+		return nil, &jsonSyntaxError{msg: "unexpected end of input", pos: p.pos}
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '{' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '{':
+		return p.parseTemplate()
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseArray()
+	case c == '"':
+		return p.parseString()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	default:
+		return p.parseLiteral()
+	}
+}
+
+func (p *jsonParser) parseObject() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonObject, start: p.pos}
+	p.pos++
+	p.skipSpace()
+
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		p.pos++
+		node.end = p.pos
+		return node, nil
+	}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '"' {
+			return nil, &jsonSyntaxError{msg: "expected object key", pos: p.pos}
+		}
+
+		key, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+			return nil, &jsonSyntaxError{msg: "expected ':' after object key", pos: p.pos}
+		}
+		p.pos++
+		p.skipSpace()
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		node.keys = append(node.keys, key)
+		node.values = append(node.values, value)
+
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, &jsonSyntaxError{msg: "unexpected end of input, expected '}'", pos: p.pos}
+		}
+
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			node.end = p.pos
+			return node, nil
+		default:
+			return nil, &jsonSyntaxError{msg: "expected ',' or '}'", pos: p.pos}
+		}
+	}
+}
+
+func (p *jsonParser) parseArray() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonArray, start: p.pos}
+	p.pos++
+	p.skipSpace()
+
+	if p.pos < len(p.src) && p.src[p.pos] == ']' {
+		p.pos++
+		node.end = p.pos
+		return node, nil
+	}
+
+	for {
+		p.skipSpace()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.values = append(node.values, value)
+
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, &jsonSyntaxError{msg: "unexpected end of input, expected ']'", pos: p.pos}
+		}
+
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			node.end = p.pos
+			return node, nil
+		default:
+			return nil, &jsonSyntaxError{msg: "expected ',' or ']'", pos: p.pos}
+		}
+	}
+}
+
+func (p *jsonParser) parseString() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonString, start: p.pos}
+	p.pos++
+
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			node.end = p.pos
+			node.raw = sb.String()
+			return node, nil
+		case '\\':
+			if p.pos+1 >= len(p.src) {
+				return nil, &jsonSyntaxError{msg: "unterminated escape sequence", pos: p.pos}
+			}
+			p.pos++
+			switch e := p.src[p.pos]; e {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			case 'b':
+				sb.WriteRune('\b')
+			case 'f':
+				sb.WriteRune('\f')
+			case 'u':
+				if p.pos+4 >= len(p.src) {
+					return nil, &jsonSyntaxError{msg: "invalid unicode escape", pos: p.pos}
+				}
+				v, err := strconv.ParseUint(string(p.src[p.pos+1:p.pos+5]), 16, 32)
+				if err != nil {
+					return nil, &jsonSyntaxError{msg: "invalid unicode escape", pos: p.pos}
+				}
+				sb.WriteRune(rune(v))
+				p.pos += 4
+			default:
+				sb.WriteRune(e)
+			}
+			p.pos++
+		case '\n':
+			return nil, &jsonSyntaxError{msg: "unterminated string", pos: node.start}
+		default:
+			sb.WriteRune(c)
+			p.pos++
+		}
+	}
+
+	return nil, &jsonSyntaxError{msg: "unterminated string", pos: node.start}
+}
+
+func (p *jsonParser) parseNumber() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonNumber, start: p.pos}
+	for p.pos < len(p.src) && strings.ContainsRune("+-0123456789.eE", p.src[p.pos]) {
+		p.pos++
+	}
+	node.end = p.pos
+	node.raw = string(p.src[node.start:node.end])
+
+	if _, err := strconv.ParseFloat(node.raw, 64); err != nil {
+		return nil, &jsonSyntaxError{msg: fmt.Sprintf("invalid number %q", node.raw), pos: node.start}
+	}
+
+	return node, nil
+}
+
+func (p *jsonParser) parseTemplate() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonTemplate, start: p.pos}
+	for p.pos += 2; p.pos+1 < len(p.src); p.pos++ {
+		if p.src[p.pos] == '}' && p.src[p.pos+1] == '}' {
+			p.pos += 2
+			node.end = p.pos
+			node.raw = string(p.src[node.start:node.end])
+			return node, nil
+		}
+	}
+
+	return nil, &jsonSyntaxError{msg: "unterminated template", pos: node.start}
+}
+
+func (p *jsonParser) parseLiteral() (*jsonNode, error) {
+	for _, lit := range []string{"true", "false", "null"} {
+		end := p.pos + len(lit)
+		if end <= len(p.src) && string(p.src[p.pos:end]) == lit {
+			node := &jsonNode{kind: jsonBool, start: p.pos, end: end, raw: lit}
+			if lit == "null" {
+				node.kind = jsonNull
+			}
+			p.pos = end
+			return node, nil
+		}
+	}
+
+	return nil, &jsonSyntaxError{msg: fmt.Sprintf("unexpected character %q", p.src[p.pos]), pos: p.pos}
+}
+
+// ValidateBody checks the given JSON body against the message descriptor and returns all the
+// unknown fields, type mismatches and syntax errors it can find.
+func ValidateBody(md protoreflect.MessageDescriptor, body string) []BodyIssue {
+	if md == nil || strings.TrimSpace(body) == "" {
+		return nil
+	}
+
+	root, err := parseJSONWithPositions(body)
+	if err != nil {
+		pos := 0
+		if se, ok := err.(*jsonSyntaxError); ok {
+			pos = se.pos
+		}
+		return []BodyIssue{{Message: err.Error(), Start: pos, End: pos + 1}}
+	}
+
+	var issues []BodyIssue
+	validateMessage(md, root, "", &issues)
+	return issues
+}
+
+func validateMessage(md protoreflect.MessageDescriptor, node *jsonNode, path string, issues *[]BodyIssue) {
+	if node.kind == jsonNull || node.kind == jsonTemplate {
+		return
+	}
+
+	if isWellKnownType(md) {
+		validateWellKnownType(md, node, path, issues)
+		return
+	}
+
+	if node.kind != jsonObject {
+		addIssue(issues, node, path, fmt.Sprintf("expected object for %s, got %s", md.FullName(), kindName(node.kind)))
+		return
+	}
+
+	seen := make(map[protoreflect.FieldNumber]bool)
+	for i, key := range node.keys {
+		if isTemplate(key) {
+			continue
+		}
+
+		fd := findField(md, key.raw)
+		if fd == nil {
+			addIssue(issues, key, path, fmt.Sprintf("unknown field %q in %s", key.raw, md.FullName()))
+			continue
+		}
+
+		if seen[fd.Number()] {
+			addIssue(issues, key, path, fmt.Sprintf("duplicate field %q", key.raw))
+		}
+		seen[fd.Number()] = true
+
+		validateField(fd, node.values[i], joinPath(path, key.raw), issues)
+	}
+}
+
+func validateField(fd protoreflect.FieldDescriptor, node *jsonNode, path string, issues *[]BodyIssue) {
+	if node.kind == jsonNull || node.kind == jsonTemplate {
+		return
+	}
+
+	switch {
+	case fd.IsMap():
+		if node.kind != jsonObject {
+			addIssue(issues, node, path, fmt.Sprintf("expected object for map field, got %s", kindName(node.kind)))
+			return
+		}
+		for i, key := range node.keys {
+			validateMapKey(fd.MapKey(), key, joinPath(path, key.raw), issues)
+			validateSingular(fd.MapValue(), node.values[i], joinPath(path, key.raw), issues)
+		}
+	case fd.IsList():
+		if node.kind != jsonArray {
+			addIssue(issues, node, path, fmt.Sprintf("expected array for repeated field, got %s", kindName(node.kind)))
+			return
+		}
+		for i, item := range node.values {
+			validateSingular(fd, item, fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+	default:
+		validateSingular(fd, node, path, issues)
+	}
+}
+
+func validateMapKey(fd protoreflect.FieldDescriptor, key *jsonNode, path string, issues *[]BodyIssue) {
+	if isTemplate(key) {
+		return
+	}
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if key.raw != "true" && key.raw != "false" {
+			addIssue(issues, key, path, fmt.Sprintf("invalid bool map key %q", key.raw))
+		}
+	case protoreflect.StringKind:
+	default:
+		if _, err := strconv.ParseInt(key.raw, 10, 64); err != nil {
+			if _, err := strconv.ParseUint(key.raw, 10, 64); err != nil {
+				addIssue(issues, key, path, fmt.Sprintf("invalid integer map key %q", key.raw))
+			}
+		}
+	}
+}
+
+// nolint:gocyclo
+func validateSingular(fd protoreflect.FieldDescriptor, node *jsonNode, path string, issues *[]BodyIssue) {
+	if node.kind == jsonNull || node.kind == jsonTemplate {
+		return
+	}
+	// a string holding a variable can render to any scalar, e.g. "{{userId}}" for an int64 field
+	if isTemplate(node) && fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+		return
+	}
+
+	mismatch := func(expected string) {
+		addIssue(issues, node, path, fmt.Sprintf("expected %s for field of type %s, got %s", expected, fd.Kind(), kindName(node.kind)))
+	}
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		validateMessage(fd.Message(), node, path, issues)
+	case protoreflect.StringKind:
+		if node.kind != jsonString {
+			mismatch("string")
+		}
+	case protoreflect.BoolKind:
+		if node.kind != jsonBool {
+			mismatch("bool")
+		}
+	case protoreflect.BytesKind:
+		if node.kind != jsonString {
+			mismatch("base64 string")
+			return
+		}
+		if !isBase64(node.raw) {
+			addIssue(issues, node, path, "invalid base64 value for bytes field")
+		}
+	case protoreflect.EnumKind:
+		validateEnum(fd.Enum(), node, path, issues)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		switch node.kind {
+		case jsonNumber:
+		case jsonString:
+			if node.raw != "NaN" && node.raw != "Infinity" && node.raw != "-Infinity" {
+				if _, err := strconv.ParseFloat(node.raw, 64); err != nil {
+					mismatch("number")
+				}
+			}
+		default:
+			mismatch("number")
+		}
+	default:
+		validateInteger(fd, node, path, issues)
+	}
+}
+
+func validateInteger(fd protoreflect.FieldDescriptor, node *jsonNode, path string, issues *[]BodyIssue) {
+	if node.kind != jsonNumber && node.kind != jsonString {
+		addIssue(issues, node, path, fmt.Sprintf("expected integer for field of type %s, got %s", fd.Kind(), kindName(node.kind)))
+		return
+	}
+
+	f, err := strconv.ParseFloat(node.raw, 64)
+	if err != nil || f != math.Trunc(f) {
+		addIssue(issues, node, path, fmt.Sprintf("invalid integer value %q for field of type %s", node.raw, fd.Kind()))
+		return
+	}
+
+	var lo, hi float64
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		lo, hi = math.MinInt32, math.MaxInt32
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		lo, hi = 0, math.MaxUint32
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		lo, hi = 0, math.MaxUint64
+	default:
+		lo, hi = math.MinInt64, math.MaxInt64
+	}
+
+	if f < lo || f > hi {
+		addIssue(issues, node, path, fmt.Sprintf("value %s out of range for field of type %s", node.raw, fd.Kind()))
+	}
+}
+
+func validateEnum(ed protoreflect.EnumDescriptor, node *jsonNode, path string, issues *[]BodyIssue) {
+	switch node.kind {
+	case jsonString:
+		if ed.Values().ByName(protoreflect.Name(node.raw)) == nil {
+			addIssue(issues, node, path, fmt.Sprintf("unknown enum value %q for %s", node.raw, ed.FullName()))
+		}
+	case jsonNumber:
+		n, err := strconv.ParseInt(node.raw, 10, 32)
+		if err != nil {
+			addIssue(issues, node, path, fmt.Sprintf("invalid enum number %s", node.raw))
+			return
+		}
+		if ed.Values().ByNumber(protoreflect.EnumNumber(n)) == nil && ed.IsClosed() {
+			addIssue(issues, node, path, fmt.Sprintf("unknown enum number %d for %s", n, ed.FullName()))
+		}
+	default:
+		addIssue(issues, node, path, fmt.Sprintf("expected enum name or number, got %s", kindName(node.kind)))
+	}
+}
+
+func isWellKnownType(md protoreflect.MessageDescriptor) bool {
+	return md.FullName().Parent() == "google.protobuf"
+}
+
+func validateWellKnownType(md protoreflect.MessageDescriptor, node *jsonNode, path string, issues *[]BodyIssue) {
+	expect := func(kinds ...jsonKind) {
+		for _, k := range kinds {
+			if node.kind == k {
+				return
+			}
+		}
+		names := make([]string, 0, len(kinds))
+		for _, k := range kinds {
+			names = append(names, kindName(k))
+		}
+		addIssue(issues, node, path, fmt.Sprintf("expected %s for %s, got %s", strings.Join(names, " or "), md.FullName(), kindName(node.kind)))
+	}
+
+	if isTemplate(node) {
+		switch md.Name() {
+		case "Struct", "Empty", "Any", "ListValue":
+		default:
+			return
+		}
+	}
+
+	switch md.Name() {
+	case "Timestamp", "Duration", "FieldMask":
+		expect(jsonString)
+	case "Struct", "Empty", "Any":
+		expect(jsonObject)
+	case "ListValue":
+		expect(jsonArray)
+	case "StringValue", "BytesValue":
+		expect(jsonString)
+	case "BoolValue":
+		expect(jsonBool)
+	case "Int32Value", "UInt32Value", "Int64Value", "UInt64Value", "FloatValue", "DoubleValue":
+		expect(jsonNumber, jsonString)
+	}
+}
+
+func findField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByJSONName(name); fd != nil {
+		return fd
+	}
+	return fields.ByTextName(name)
+}
+
+func isBase64(s string) bool {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if _, err := enc.DecodeString(s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isTemplate reports whether the node is an unquoted variable or a string holding one, which are only
+// known once the body is rendered.
+func isTemplate(node *jsonNode) bool {
+	return node.kind == jsonTemplate || (node.kind == jsonString && strings.Contains(node.raw, "{{") && strings.Contains(node.raw, "}}"))
+}
+
+func addIssue(issues *[]BodyIssue, node *jsonNode, path, msg string) {
+	*issues = append(*issues, BodyIssue{Path: path, Message: msg, Start: node.start, End: node.end})
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func kindName(k jsonKind) string {
+	switch k {
+	case jsonObject:
+		return "object"
+	case jsonArray:
+		return "array"
+	case jsonString:
+		return "string"
+	case jsonNumber:
+		return "number"
+	case jsonBool:
+		return "bool"
+	case jsonTemplate:
+		return "template"
+	default:
+		return "null"
+	}
+}
+
+type completionFrame struct {
+	isObject bool
+	// key is the object key that opened this frame, empty for array elements and the root
+	key string
+}
+
+// CompleteBody returns the suggestions for the caret at offset (a rune offset) in body.
+// Inside an object key it suggests the field names of the message at that position, in a
+// value position it suggests enum values and booleans. The returned prefix is the part of
+// the word under the caret which the suggestion should replace.
+// nolint:gocyclo
+func CompleteBody(md protoreflect.MessageDescriptor, body string, offset int) (string, []Completion) {
+	if md == nil {
+		return "", nil
+	}
+
+	src := []rune(body)
+	if offset > len(src) {
+		offset = len(src)
+	}
+
+	var (
+		stack      []completionFrame
+		inString   bool
+		strStart   int
+		lastString string
+		expectKey  bool
+		afterColon bool
+		pendingKey string
+	)
+
+	for i := 0; i < offset; i++ {
+		c := src[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+				lastString = string(src[strStart+1 : i])
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+			strStart = i
+		case '{':
+			stack = append(stack, completionFrame{isObject: true, key: pendingKey})
+			expectKey, afterColon, pendingKey = true, false, ""
+		case '[':
+			stack = append(stack, completionFrame{key: pendingKey})
+			expectKey, afterColon, pendingKey = false, true, ""
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			expectKey, afterColon, pendingKey = false, false, ""
+		case ':':
+			pendingKey = lastString
+			expectKey, afterColon = false, true
+		case ',':
+			if len(stack) > 0 && stack[len(stack)-1].isObject {
+				expectKey, afterColon = true, false
+			} else {
+				afterColon = true
+			}
+			pendingKey = ""
+		}
+	}
+
+	// resolve the message of the innermost object and, for value positions, the field
+	current := md
+	mapFrame := -1
+	var listField protoreflect.FieldDescriptor
+	for idx, frame := range stack {
+		if idx == 0 {
+			continue
+		}
+
+		if frame.key == "" {
+			// array element, keep the message of the repeated field
+			continue
+		}
+
+		fd := findField(current, frame.key)
+		if fd == nil {
+			return "", nil
+		}
+
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() != protoreflect.MessageKind {
+				return "", nil
+			}
+			// the next frame is keyed by the map key and holds the value message
+			current = fd.MapValue().Message()
+			mapFrame = idx
+			if idx+1 < len(stack) {
+				stack[idx+1].key = ""
+			}
+		case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+			current = fd.Message()
+			listField = nil
+		default:
+			listField = fd
+		}
+	}
+
+	// keys of a map literal are free form, there is nothing to suggest
+	if len(stack) == 0 || mapFrame == len(stack)-1 {
+		return "", nil
+	}
+
+	top := stack[len(stack)-1]
+	prefix := ""
+	if inString {
+		prefix = string(src[strStart+1 : offset])
+	} else {
+		start := offset
+		for start > 0 && isWordRune(src[start-1]) {
+			start--
+		}
+		prefix = string(src[start:offset])
+	}
+
+	if top.isObject && expectKey {
+		return prefix, fieldCompletions(current, prefix, inString)
+	}
+
+	if !afterColon {
+		return "", nil
+	}
+
+	var fd protoreflect.FieldDescriptor
+	if top.isObject {
+		fd = findField(current, pendingKey)
+	} else {
+		fd = listField
+	}
+
+	if fd == nil {
+		return "", nil
+	}
+
+	return prefix, valueCompletions(fd, prefix, inString)
+}
+
+func fieldCompletions(md protoreflect.MessageDescriptor, prefix string, inString bool) []Completion {
+	fields := md.Fields()
+	out := make([]Completion, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := fd.JSONName()
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			continue
+		}
+
+		text := name
+		if !inString {
+			text = strconv.Quote(name)
+		}
+		out = append(out, Completion{Text: text, Detail: fieldTypeName(fd)})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Text < out[j].Text
+	})
+
+	return out
+}
+
+func valueCompletions(fd protoreflect.FieldDescriptor, prefix string, inString bool) []Completion {
+	var out []Completion
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			name := string(values.Get(i).Name())
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			text := name
+			if !inString {
+				text = strconv.Quote(name)
+			}
+			out = append(out, Completion{Text: text, Detail: string(fd.Enum().FullName())})
+		}
+	case protoreflect.BoolKind:
+		if inString {
+			return nil
+		}
+		for _, v := range []string{"true", "false"} {
+			if strings.HasPrefix(v, prefix) {
+				out = append(out, Completion{Text: v, Detail: "bool"})
+			}
+		}
+	}
+
+	return out
+}
+
+func fieldTypeName(fd protoreflect.FieldDescriptor) string {
+	var name string
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		name = string(fd.Message().FullName())
+	case protoreflect.EnumKind:
+		name = string(fd.Enum().FullName())
+	default:
+		name = fd.Kind().String()
+	}
+
+	switch {
+	case fd.IsMap():
+		return fmt.Sprintf("map<%s, %s>", fd.MapKey().Kind(), fieldTypeName(fd.MapValue()))
+	case fd.IsList():
+		return "repeated " + name
+	default:
+		return name
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r >= utf8.RuneSelf
+}
@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const testProto = `
+syntax = "proto3";
+package test;
+
+enum Kind {
+  KIND_UNSPECIFIED = 0;
+  KIND_USER = 1;
+  KIND_ADMIN = 2;
+}
+
+message Address {
+  string city = 1;
+  int32 zip_code = 2;
+}
+
+message User {
+  string name = 1;
+  int32 age = 2;
+  bool active = 3;
+  Kind kind = 4;
+  repeated string tags = 5;
+  map<string, Address> addresses = 6;
+  bytes avatar = 7;
+  int64 balance = 8;
+  Address home = 9;
+}
+
+service UserService {
+  rpc Get(User) returns (User);
+  rpc List(User) returns (stream User);
+}
+
+service AddressService {
+  rpc Get(Address) returns (Address);
+}
+`
+
+func testMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	return testFile(t).Messages().ByName("User")
+}
+
+func testFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"test.proto": testProto}),
+	}
+
+	files, err := parser.ParseFiles("test.proto")
+	if err != nil {
+		t.Fatalf("failed to parse test proto: %v", err)
+	}
+
+	return files[0].UnwrapFile()
+}
+
+func Test_ValidateBody(t *testing.T) {
+	md := testMessage(t)
+
+	t.Run("valid body", func(t *testing.T) {
+		body := `{
+  "name": "john",
+  "age": 30,
+  "active": true,
+  "kind": "KIND_ADMIN",
+  "tags": ["a", "b"],
+  "addresses": {"home": {"city": "Berlin", "zipCode": 10115}},
+  "avatar": "aGVsbG8=",
+  "balance": "9007199254740993",
+  "home": {"city": "Paris", "zip_code": 75001}
+}`
+		if issues := ValidateBody(md, body); len(issues) != 0 {
+			t.Fatalf("ValidateBody() = %v; want no issues", issues)
+		}
+	})
+
+	t.Run("templates", func(t *testing.T) {
+		body := `{
+  "name": "{{name}}",
+  "age": {{count}},
+  "active": "{{isActive}}",
+  "kind": "{{kind}}",
+  "tags": [{{tag}}, "b"],
+  "addresses": {"{{key}}": {{address}}},
+  "balance": "{{userId}}",
+  "home": {{home}}
+}`
+		if issues := ValidateBody(md, body); len(issues) != 0 {
+			t.Fatalf("ValidateBody() = %v; want no issues", issues)
+		}
+
+		if issues := ValidateBody(md, `{{body}}`); len(issues) != 0 {
+			t.Fatalf("ValidateBody() = %v; want no issues", issues)
+		}
+	})
+
+	tests := []struct {
+		name string
+		body string
+		path string
+		msg  string
+	}{
+		{name: "unknown field", body: `{"nmae": "john"}`, path: "", msg: `unknown field "nmae"`},
+		{name: "wrong type", body: `{"age": "thirty"}`, path: "age", msg: "invalid integer value"},
+		{name: "out of range", body: `{"age": 3000000000}`, path: "age", msg: "out of range"},
+		{name: "bool as string", body: `{"active": "yes"}`, path: "active", msg: "expected bool"},
+		{name: "unknown enum", body: `{"kind": "KIND_ROOT"}`, path: "kind", msg: "unknown enum value"},
+		{name: "repeated not array", body: `{"tags": "a"}`, path: "tags", msg: "expected array"},
+		{name: "nested unknown field", body: `{"home": {"street": "x"}}`, path: "home", msg: `unknown field "street"`},
+		{name: "map value", body: `{"addresses": {"a": {"zipCode": true}}}`, path: "addresses.a.zipCode", msg: "expected integer"},
+		{name: "syntax error", body: `{"name": }`, path: "", msg: "unexpected character"},
+		{name: "unterminated template", body: `{"age": {{count}`, path: "", msg: "unterminated template"},
+		{name: "template next to error", body: `{"age": {{count}}, "active": "yes"}`, path: "active", msg: "expected bool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := ValidateBody(md, tt.body)
+			if len(issues) != 1 {
+				t.Fatalf("ValidateBody(%q) = %v; want one issue", tt.body, issues)
+			}
+
+			if issues[0].Path != tt.path || !strings.Contains(issues[0].Message, tt.msg) {
+				t.Fatalf("ValidateBody(%q) = %v; want path %q with message containing %q", tt.body, issues[0], tt.path, tt.msg)
+			}
+
+			if issues[0].Start >= issues[0].End || issues[0].End > len([]rune(tt.body)) {
+				t.Fatalf("ValidateBody(%q) returned invalid range %d-%d", tt.body, issues[0].Start, issues[0].End)
+			}
+		})
+	}
+}
+
+func Test_CompleteBody(t *testing.T) {
+	md := testMessage(t)
+
+	texts := func(items []Completion) []string {
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			out = append(out, item.Text)
+		}
+		return out
+	}
+
+	t.Run("field names", func(t *testing.T) {
+		body := `{"a`
+		prefix, items := CompleteBody(md, body, len(body))
+		if prefix != "a" {
+			t.Fatalf("CompleteBody() prefix = %q; want %q", prefix, "a")
+		}
+
+		if got := strings.Join(texts(items), ","); got != "active,addresses,age,avatar" {
+			t.Fatalf("CompleteBody() = %s; want active,addresses,age,avatar", got)
+		}
+	})
+
+	t.Run("nested field names", func(t *testing.T) {
+		body := `{"name": "x", "home": {`
+		_, items := CompleteBody(md, body, len(body))
+		if got := strings.Join(texts(items), ","); got != `"city","zipCode"` {
+			t.Fatalf("CompleteBody() = %s; want \"city\",\"zipCode\"", got)
+		}
+	})
+
+	t.Run("enum values", func(t *testing.T) {
+		body := `{"kind": "KIND_A`
+		prefix, items := CompleteBody(md, body, len(body))
+		if prefix != "KIND_A" || strings.Join(texts(items), ",") != "KIND_ADMIN" {
+			t.Fatalf("CompleteBody() = %q, %v; want KIND_ADMIN", prefix, texts(items))
+		}
+	})
+
+	t.Run("bool values", func(t *testing.T) {
+		body := `{"active": t`
+		_, items := CompleteBody(md, body, len(body))
+		if strings.Join(texts(items), ",") != "true" {
+			t.Fatalf("CompleteBody() = %v; want true", texts(items))
+		}
+	})
+}
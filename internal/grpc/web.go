@@ -0,0 +1,653 @@
+package grpc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const (
+	grpcWebContentType       = "application/grpc-web+proto"
+	connectUnaryContentType  = "application/json"
+	connectStreamContentType = "application/connect+json"
+
+	// compressedFlag marks a frame whose message is compressed with the encoding named in the headers.
+	compressedFlag = 0x01
+	// grpcWebTrailerFlag marks the gRPC-Web frame that carries the trailers.
+	grpcWebTrailerFlag = 0x80
+	// connectEndStreamFlag marks the Connect envelope that ends the stream.
+	connectEndStreamFlag = 0x02
+
+	// maxFrameSize protects against reading a broken length prefix into memory.
+	maxFrameSize = 64 << 20
+)
+
+// webCall is a gRPC-Web or Connect call, the compression and message size limits of the settings apply
+// to it as they do to native gRPC calls.
+type webCall struct {
+	client   *http.Client
+	endpoint string
+	outgoing metadata.MD
+	settings domain.Settings
+	timeout  time.Duration
+}
+
+// httpResult is the outcome of a gRPC-Web or Connect call.
+type httpResult struct {
+	body     string
+	headers  metadata.MD
+	trailers metadata.MD
+}
+
+// invokeHTTP invokes the method using gRPC-Web or Connect depending on the protocol of the request.
+// Both protocols run over plain HTTP, with TLS the transport negotiates HTTP/2 and falls back to HTTP/1.1.
+func (s *Service) invokeHTTP(ctx context.Context, spec *domain.GRPCRequestSpec, method string, req proto.Message, md protoreflect.MethodDescriptor, timeout time.Duration) (*httpResult, error) {
+	if md.IsStreamingClient() {
+		return nil, fmt.Errorf("client streaming is not supported over %s", spec.ServerInfo.Protocol)
+	}
+
+	client, err := s.webClient(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := methodURL(spec.ServerInfo.Address, method, spec.Settings.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	outgoing, _ := metadata.FromOutgoingContext(ctx)
+	call := &webCall{client: client, endpoint: endpoint, outgoing: outgoing, settings: spec.Settings, timeout: timeout}
+
+	switch spec.ServerInfo.Protocol {
+	case domain.GRPCProtocolGRPCWeb:
+		return call.invokeGRPCWeb(ctx, req, md)
+	case domain.GRPCProtocolConnect:
+		if md.IsStreamingServer() {
+			return call.invokeConnectStream(ctx, req, md)
+		}
+		return call.invokeConnectUnary(ctx, req, md)
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", spec.ServerInfo.Protocol)
+	}
+}
+
+func httpClient(settings domain.Settings) (*http.Client, error) {
+	transport := &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		ForceAttemptHTTP2: true,
+	}
+
+	if !settings.Insecure {
+		tlsCfg, err := tlsConfig(settings)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsCfg
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// methodURL builds the url of the method from the server address, the address may or may not have a scheme.
+func methodURL(address, method string, plainText bool) (string, error) {
+	if !strings.Contains(address, "://") {
+		scheme := "https"
+		if plainText {
+			scheme = "http"
+		}
+		address = scheme + "://" + address
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid server address: %w", err)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + method
+	return u.String(), nil
+}
+
+func (c *webCall) newHTTPRequest(ctx context.Context, contentType string, body []byte) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.settings.Authority != "" {
+		httpReq.Host = c.settings.Authority
+	}
+
+	for k, values := range c.outgoing {
+		for _, v := range values {
+			httpReq.Header.Add(k, v)
+		}
+	}
+
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("User-Agent", fmt.Sprintf("%s/%s", appName, semver))
+	return httpReq, nil
+}
+
+func (c *webCall) invokeGRPCWeb(ctx context.Context, req proto.Message, md protoreflect.MethodDescriptor) (*httpResult, error) {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	frame, err := c.encodeMessage(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := c.newHTTPRequest(ctx, grpcWebContentType, frame)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", grpcWebContentType)
+	httpReq.Header.Set("X-Grpc-Web", "1")
+	httpReq.Header.Set("Grpc-Timeout", fmt.Sprintf("%dm", c.timeout.Milliseconds()))
+	httpReq.Header.Set("Grpc-Accept-Encoding", domain.CompressionGzip)
+	if c.compressed() {
+		httpReq.Header.Set("Grpc-Encoding", c.settings.Compression)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	out := &httpResult{headers: headerToMetadata(resp.Header), trailers: metadata.MD{}}
+
+	// trailers only response, the status is in the headers
+	if resp.Header.Get("Grpc-Status") != "" {
+		return out, statusFromMetadata(out.headers)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return out, status.Error(httpStatusToCode(resp.StatusCode), resp.Status)
+	}
+
+	var messages []string
+	reader := bufio.NewReader(resp.Body)
+	for {
+		flag, data, err := c.readMessage(reader, resp.Header.Get("Grpc-Encoding"))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return out, err
+		}
+
+		if flag&grpcWebTrailerFlag != 0 {
+			out.trailers, err = parseWebTrailers(data)
+			if err != nil {
+				return out, status.Error(codes.Internal, err.Error())
+			}
+			continue
+		}
+
+		msg := dynamicpb.NewMessage(md.Output())
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return out, status.Error(codes.Internal, err.Error())
+		}
+
+		respJSON, err := (protojson.MarshalOptions{Indent: "  "}).Marshal(msg)
+		if err != nil {
+			return out, err
+		}
+		messages = append(messages, string(respJSON))
+	}
+
+	out.body = formatMessages(messages, md.IsStreamingServer())
+	if len(out.trailers) == 0 {
+		return out, status.Error(codes.Internal, "server closed the stream without sending trailers")
+	}
+
+	return out, statusFromMetadata(out.trailers)
+}
+
+func (c *webCall) invokeConnectUnary(ctx context.Context, req proto.Message, md protoreflect.MethodDescriptor) (*httpResult, error) {
+	payload, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkSendSize(payload); err != nil {
+		return nil, err
+	}
+
+	if c.compressed() {
+		if payload, err = compress(c.settings.Compression, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	httpReq, err := c.newHTTPRequest(ctx, connectUnaryContentType, payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Connect-Protocol-Version", "1")
+	httpReq.Header.Set("Connect-Timeout-Ms", strconv.FormatInt(c.timeout.Milliseconds(), 10))
+	// setting it disables the transparent decompression of the transport, the body is decompressed below
+	httpReq.Header.Set("Accept-Encoding", domain.CompressionGzip)
+	if c.compressed() {
+		httpReq.Header.Set("Content-Encoding", c.settings.Compression)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	out := &httpResult{}
+	out.headers, out.trailers = splitConnectHeaders(resp.Header)
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(c.receiveLimit())+1))
+	if err != nil {
+		return out, status.Error(codes.Internal, err.Error())
+	}
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		if data, err = c.decompress(encoding, data); err != nil {
+			return out, err
+		}
+	} else if len(data) > c.receiveLimit() {
+		return out, c.receiveSizeError(len(data))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return out, connectError(data, resp.StatusCode, resp.Status)
+	}
+
+	msg := dynamicpb.NewMessage(md.Output())
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, msg); err != nil {
+		return out, status.Error(codes.Internal, err.Error())
+	}
+
+	respJSON, err := (protojson.MarshalOptions{Indent: "  "}).Marshal(msg)
+	if err != nil {
+		return out, err
+	}
+	out.body = string(respJSON)
+
+	return out, nil
+}
+
+func (c *webCall) invokeConnectStream(ctx context.Context, req proto.Message, md protoreflect.MethodDescriptor) (*httpResult, error) {
+	payload, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	frame, err := c.encodeMessage(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := c.newHTTPRequest(ctx, connectStreamContentType, frame)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Connect-Protocol-Version", "1")
+	httpReq.Header.Set("Connect-Timeout-Ms", strconv.FormatInt(c.timeout.Milliseconds(), 10))
+	httpReq.Header.Set("Connect-Accept-Encoding", domain.CompressionGzip)
+	if c.compressed() {
+		httpReq.Header.Set("Connect-Content-Encoding", c.settings.Compression)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	out := &httpResult{headers: headerToMetadata(resp.Header), trailers: metadata.MD{}}
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxFrameSize))
+		return out, connectError(data, resp.StatusCode, resp.Status)
+	}
+
+	var messages []string
+	reader := bufio.NewReader(resp.Body)
+	for {
+		flag, data, err := c.readMessage(reader, resp.Header.Get("Connect-Content-Encoding"))
+		if err == io.EOF {
+			return out, status.Error(codes.Internal, "server closed the stream without end of stream message")
+		}
+		if err != nil {
+			return out, err
+		}
+
+		if flag&connectEndStreamFlag != 0 {
+			out.body = formatMessages(messages, true)
+			return out, parseConnectEndStream(data, out)
+		}
+
+		msg := dynamicpb.NewMessage(md.Output())
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, msg); err != nil {
+			return out, status.Error(codes.Internal, err.Error())
+		}
+
+		respJSON, err := (protojson.MarshalOptions{Indent: "  "}).Marshal(msg)
+		if err != nil {
+			return out, err
+		}
+		messages = append(messages, string(respJSON))
+	}
+}
+
+func (c *webCall) compressed() bool {
+	return c.settings.Compression != "" && c.settings.Compression != domain.CompressionNone
+}
+
+// receiveLimit is the largest message accepted from the server, maxFrameSize unless a smaller one is set.
+func (c *webCall) receiveLimit() int {
+	if c.settings.MaxReceiveMessageSize > 0 && c.settings.MaxReceiveMessageSize < maxFrameSize {
+		return c.settings.MaxReceiveMessageSize
+	}
+	return maxFrameSize
+}
+
+func (c *webCall) receiveSizeError(size int) error {
+	return status.Errorf(codes.ResourceExhausted, "received message larger than max (%d vs. %d)", size, c.receiveLimit())
+}
+
+func (c *webCall) checkSendSize(payload []byte) error {
+	if c.settings.MaxSendMessageSize > 0 && len(payload) > c.settings.MaxSendMessageSize {
+		return status.Errorf(codes.ResourceExhausted, "trying to send message larger than max (%d vs. %d)", len(payload), c.settings.MaxSendMessageSize)
+	}
+	return nil
+}
+
+// encodeMessage frames the message, compressed when the settings ask for it.
+func (c *webCall) encodeMessage(payload []byte) ([]byte, error) {
+	if err := c.checkSendSize(payload); err != nil {
+		return nil, err
+	}
+
+	if !c.compressed() {
+		return encodeFrame(0, payload), nil
+	}
+
+	data, err := compress(c.settings.Compression, payload)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFrame(compressedFlag, data), nil
+}
+
+// readMessage reads the next frame and decompresses it with the encoding the server named in its headers.
+// The errors other than io.EOF are gRPC status errors.
+func (c *webCall) readMessage(r io.Reader, encoding string) (byte, []byte, error) {
+	flag, data, err := readFrame(r, c.receiveLimit())
+	if err == io.EOF {
+		return 0, nil, err
+	}
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return 0, nil, err
+		}
+		return 0, nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if flag&compressedFlag == 0 {
+		return flag, data, nil
+	}
+
+	if encoding == "" || encoding == "identity" {
+		return 0, nil, status.Error(codes.Internal, "compressed message without a message encoding")
+	}
+
+	data, err = c.decompress(encoding, data)
+	return flag, data, err
+}
+
+func (c *webCall) decompress(encoding string, data []byte) ([]byte, error) {
+	if encoding != domain.CompressionGzip {
+		return nil, status.Errorf(codes.Unimplemented, "unsupported message encoding %q", encoding)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("invalid compressed message: %v", err))
+	}
+	defer zr.Close()
+
+	limit := c.receiveLimit()
+	out, err := io.ReadAll(io.LimitReader(zr, int64(limit)+1))
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("invalid compressed message: %v", err))
+	}
+	if len(out) > limit {
+		return nil, c.receiveSizeError(len(out))
+	}
+	return out, nil
+}
+
+func compress(encoding string, data []byte) ([]byte, error) {
+	if encoding != domain.CompressionGzip {
+		return nil, fmt.Errorf("unsupported compression %q", encoding)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeFrame(flag byte, payload []byte) []byte {
+	frame := make([]byte, 5+len(payload))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	return frame
+}
+
+// readFrame reads a length prefixed frame, frames larger than limit are rejected with a ResourceExhausted status.
+func readFrame(r io.Reader, limit int) (byte, []byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, errors.New("truncated frame header")
+		}
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if uint64(size) > uint64(limit) {
+		return 0, nil, status.Errorf(codes.ResourceExhausted, "received message larger than max (%d vs. %d)", size, limit)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, errors.New("truncated frame")
+	}
+
+	return prefix[0], data, nil
+}
+
+// parseWebTrailers parses the trailer frame of gRPC-Web which is an HTTP/1 style header block.
+func parseWebTrailers(data []byte) (metadata.MD, error) {
+	reader := textproto.NewReader(bufio.NewReader(io.MultiReader(bytes.NewReader(data), strings.NewReader("\r\n"))))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid trailers: %w", err)
+	}
+
+	return headerToMetadata(http.Header(header)), nil
+}
+
+func parseConnectEndStream(data []byte, out *httpResult) error {
+	var end struct {
+		Error    *connectErrorBody   `json:"error"`
+		Metadata map[string][]string `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(data, &end); err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("invalid end of stream message: %v", err))
+	}
+
+	for k, values := range end.Metadata {
+		out.trailers.Append(k, values...)
+	}
+
+	if end.Error != nil {
+		return connectStatus(connectCodeToGRPC(end.Error.Code), end.Error.Message, end.Error.Details)
+	}
+
+	return nil
+}
+
+type connectErrorBody struct {
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Details []connectErrorDetail `json:"details"`
+}
+
+func connectError(data []byte, httpStatus int, fallback string) error {
+	var body connectErrorBody
+	if err := json.Unmarshal(data, &body); err != nil || body.Code == "" {
+		return status.Error(httpStatusToCode(httpStatus), fallback)
+	}
+
+	return connectStatus(connectCodeToGRPC(body.Code), body.Message, body.Details)
+}
+
+// splitConnectHeaders splits the response headers of a Connect unary call, trailers are sent as headers prefixed with trailer-.
+func splitConnectHeaders(header http.Header) (metadata.MD, metadata.MD) {
+	headers, trailers := metadata.MD{}, metadata.MD{}
+	for k, values := range header {
+		key := strings.ToLower(k)
+		if strings.HasPrefix(key, "trailer-") {
+			trailers.Append(strings.TrimPrefix(key, "trailer-"), values...)
+			continue
+		}
+		headers.Append(key, values...)
+	}
+
+	return headers, trailers
+}
+
+func headerToMetadata(header http.Header) metadata.MD {
+	out := metadata.MD{}
+	for k, values := range header {
+		out.Append(strings.ToLower(k), values...)
+	}
+	return out
+}
+
+func statusFromMetadata(md metadata.MD) error {
+	values := md.Get("grpc-status")
+	if len(values) == 0 {
+		return status.Error(codes.Internal, "missing grpc-status")
+	}
+
+	code, err := strconv.Atoi(values[0])
+	if err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("invalid grpc-status %q", values[0]))
+	}
+
+	if codes.Code(code) == codes.OK {
+		return nil
+	}
+
+	var msg string
+	if messages := md.Get("grpc-message"); len(messages) > 0 {
+		msg, err = url.PathUnescape(messages[0])
+		if err != nil {
+			msg = messages[0]
+		}
+	}
+
+	if details := md.Get("grpc-status-details-bin"); len(details) > 0 {
+		return statusWithDetails(codes.Code(code), msg, details[0])
+	}
+
+	return status.Error(codes.Code(code), msg)
+}
+
+var connectCodes = map[string]codes.Code{
+	"canceled":            codes.Canceled,
+	"unknown":             codes.Unknown,
+	"invalid_argument":    codes.InvalidArgument,
+	"deadline_exceeded":   codes.DeadlineExceeded,
+	"not_found":           codes.NotFound,
+	"already_exists":      codes.AlreadyExists,
+	"permission_denied":   codes.PermissionDenied,
+	"resource_exhausted":  codes.ResourceExhausted,
+	"failed_precondition": codes.FailedPrecondition,
+	"aborted":             codes.Aborted,
+	"out_of_range":        codes.OutOfRange,
+	"unimplemented":       codes.Unimplemented,
+	"internal":            codes.Internal,
+	"unavailable":         codes.Unavailable,
+	"data_loss":           codes.DataLoss,
+	"unauthenticated":     codes.Unauthenticated,
+}
+
+func connectCodeToGRPC(code string) codes.Code {
+	if c, ok := connectCodes[code]; ok {
+		return c
+	}
+	return codes.Unknown
+}
+
+// httpStatusToCode maps the HTTP status to a gRPC code as described in the gRPC spec.
+func httpStatusToCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+func formatMessages(messages []string, stream bool) string {
+	if !stream {
+		return strings.Join(messages, "\n")
+	}
+
+	var out string
+	for i, m := range messages {
+		out += fmt.Sprintf("Message %d:\n%s\n\n", i, m)
+	}
+	return out
+}
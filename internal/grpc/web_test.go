@@ -0,0 +1,266 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func Test_invokeHTTP(t *testing.T) {
+	s := NewService(nil, nil, nil, nil)
+	defer s.CloseConnections()
+
+	service := testFile(t).Services().ByName("UserService")
+	get := service.Methods().ByName("Get")
+	list := service.Methods().ByName("List")
+
+	newUser := func(t *testing.T, body string) *dynamicpb.Message {
+		t.Helper()
+		msg := dynamicpb.NewMessage(get.Input())
+		if err := protojson.Unmarshal([]byte(body), msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	t.Run("grpc-web unary", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/test.UserService/Get" || r.Header.Get("Content-Type") != grpcWebContentType {
+				t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+			}
+
+			if r.Header.Get("X-Token") != "secret" {
+				t.Errorf("metadata was not sent as header")
+			}
+
+			_, data, err := readFrame(bufio.NewReader(r.Body), maxFrameSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w.Header().Set("Content-Type", grpcWebContentType)
+			_, _ = w.Write(encodeFrame(0, data))
+			_, _ = w.Write(encodeFrame(grpcWebTrailerFlag, []byte("grpc-status: 0\r\nx-trace: abc\r\n")))
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolGRPCWeb},
+			Settings:   domain.Settings{Insecure: true},
+		}
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-token", "secret")
+		res, err := s.invokeHTTP(ctx, spec, "/test.UserService/Get", newUser(t, `{"name":"john"}`), get, time.Second)
+		if err != nil {
+			t.Fatalf("invokeHTTP() error = %v", err)
+		}
+
+		if !jsonHasField(res.body, "name", "john") {
+			t.Fatalf("invokeHTTP() body = %s", res.body)
+		}
+
+		if got := res.trailers.Get("x-trace"); len(got) != 1 || got[0] != "abc" {
+			t.Fatalf("invokeHTTP() trailers = %v", res.trailers)
+		}
+	})
+
+	t.Run("grpc-web error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Grpc-Status", "5")
+			w.Header().Set("Grpc-Message", "user%20not%20found")
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolGRPCWeb},
+			Settings:   domain.Settings{Insecure: true},
+		}
+
+		_, err := s.invokeHTTP(context.Background(), spec, "/test.UserService/Get", newUser(t, `{}`), get, time.Second)
+		if st := status.Convert(err); st.Code() != codes.NotFound || st.Message() != "user not found" {
+			t.Fatalf("invokeHTTP() error = %v; want NotFound user not found", err)
+		}
+	})
+
+	t.Run("connect unary", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Connect-Protocol-Version") != "1" {
+				t.Errorf("missing Connect-Protocol-Version header")
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", connectUnaryContentType)
+			w.Header().Set("Trailer-X-Trace", "abc")
+			_, _ = w.Write(body)
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolConnect},
+			Settings:   domain.Settings{Insecure: true},
+		}
+
+		res, err := s.invokeHTTP(context.Background(), spec, "/test.UserService/Get", newUser(t, `{"age":3}`), get, time.Second)
+		if err != nil {
+			t.Fatalf("invokeHTTP() error = %v", err)
+		}
+
+		if !jsonHasField(res.body, "age", float64(3)) || len(res.trailers.Get("x-trace")) != 1 {
+			t.Fatalf("invokeHTTP() = %s, trailers %v", res.body, res.trailers)
+		}
+	})
+
+	t.Run("connect unary error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"invalid_argument","message":"age must be positive"}`))
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolConnect},
+			Settings:   domain.Settings{Insecure: true},
+		}
+
+		_, err := s.invokeHTTP(context.Background(), spec, "/test.UserService/Get", newUser(t, `{}`), get, time.Second)
+		if st := status.Convert(err); st.Code() != codes.InvalidArgument || st.Message() != "age must be positive" {
+			t.Fatalf("invokeHTTP() error = %v; want InvalidArgument", err)
+		}
+	})
+
+	t.Run("connect server stream", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", connectStreamContentType)
+			for _, name := range []string{"a", "b"} {
+				_, _ = w.Write(encodeFrame(0, []byte(`{"name":"`+name+`"}`)))
+			}
+			_, _ = w.Write(encodeFrame(connectEndStreamFlag, []byte(`{"metadata":{"x-count":["2"]}}`)))
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolConnect},
+			Settings:   domain.Settings{Insecure: true},
+		}
+
+		res, err := s.invokeHTTP(context.Background(), spec, "/test.UserService/List", newUser(t, `{}`), list, time.Second)
+		if err != nil {
+			t.Fatalf("invokeHTTP() error = %v", err)
+		}
+
+		if !strings.Contains(res.body, "Message 1:") || len(res.trailers.Get("x-count")) != 1 {
+			t.Fatalf("invokeHTTP() = %s, trailers %v", res.body, res.trailers)
+		}
+	})
+
+	t.Run("grpc-web compression and authority", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Host != "api.example.com" || r.Header.Get("Grpc-Encoding") != domain.CompressionGzip {
+				t.Errorf("unexpected request host %q encoding %q", r.Host, r.Header.Get("Grpc-Encoding"))
+			}
+
+			flag, data, err := readFrame(bufio.NewReader(r.Body), maxFrameSize)
+			if err != nil || flag != compressedFlag {
+				t.Errorf("readFrame() = %d, %v; want a compressed frame", flag, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", grpcWebContentType)
+			w.Header().Set("Grpc-Encoding", domain.CompressionGzip)
+			// the request message is echoed as it is, compressed
+			_, _ = w.Write(encodeFrame(compressedFlag, data))
+			_, _ = w.Write(encodeFrame(grpcWebTrailerFlag, []byte("grpc-status: 0\r\n")))
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolGRPCWeb},
+			Settings:   domain.Settings{Insecure: true, Compression: domain.CompressionGzip, Authority: "api.example.com"},
+		}
+
+		res, err := s.invokeHTTP(context.Background(), spec, "/test.UserService/Get", newUser(t, `{"name":"john"}`), get, time.Second)
+		if err != nil {
+			t.Fatalf("invokeHTTP() error = %v", err)
+		}
+		if !jsonHasField(res.body, "name", "john") {
+			t.Fatalf("invokeHTTP() body = %s", res.body)
+		}
+	})
+
+	t.Run("connect unary compression", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Content-Encoding") != domain.CompressionGzip {
+				t.Errorf("Content-Encoding = %q", r.Header.Get("Content-Encoding"))
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", connectUnaryContentType)
+			w.Header().Set("Content-Encoding", domain.CompressionGzip)
+			_, _ = w.Write(body)
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolConnect},
+			Settings:   domain.Settings{Insecure: true, Compression: domain.CompressionGzip},
+		}
+
+		res, err := s.invokeHTTP(context.Background(), spec, "/test.UserService/Get", newUser(t, `{"age":3}`), get, time.Second)
+		if err != nil {
+			t.Fatalf("invokeHTTP() error = %v", err)
+		}
+		if !jsonHasField(res.body, "age", float64(3)) {
+			t.Fatalf("invokeHTTP() body = %s", res.body)
+		}
+	})
+
+	t.Run("message size limits", func(t *testing.T) {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", grpcWebContentType)
+			_, _ = w.Write(encodeFrame(0, make([]byte, 64)))
+			_, _ = w.Write(encodeFrame(grpcWebTrailerFlag, []byte("grpc-status: 0\r\n")))
+		}))
+		defer srv.Close()
+
+		spec := &domain.GRPCRequestSpec{
+			ServerInfo: domain.ServerInfo{Address: srv.URL, Protocol: domain.GRPCProtocolGRPCWeb},
+			Settings:   domain.Settings{Insecure: true, MaxSendMessageSize: 4},
+		}
+
+		_, err := s.invokeHTTP(context.Background(), spec, "/test.UserService/Get", newUser(t, `{"name":"john"}`), get, time.Second)
+		if status.Code(err) != codes.ResourceExhausted || calls != 0 {
+			t.Fatalf("invokeHTTP() error = %v, calls = %d; want ResourceExhausted before sending", err, calls)
+		}
+
+		spec.Settings = domain.Settings{Insecure: true, MaxReceiveMessageSize: 32}
+		_, err = s.invokeHTTP(context.Background(), spec, "/test.UserService/Get", newUser(t, `{}`), get, time.Second)
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("invokeHTTP() error = %v; want ResourceExhausted", err)
+		}
+	})
+}
+
+func jsonHasField(body, key string, want any) bool {
+	var out map[string]any
+	if err := json.Unmarshal([]byte(body), &out); err != nil {
+		return false
+	}
+	return out[key] == want
+}
@@ -0,0 +1,103 @@
+// Package headless loads the active workspace without the ui, so the command line tools send the
+// requests through the same pipeline as the app.
+package headless
+
+import (
+	"fmt"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/grpc"
+	"github.com/chapar-rest/chapar/internal/repository"
+	"github.com/chapar-rest/chapar/internal/rest"
+	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+// Workspace is the loaded state of the active workspace.
+type Workspace struct {
+	Requests     *state.Requests
+	Environments *state.Environments
+	Egress       *egress.Service
+}
+
+// Load loads the collections, requests, environments and proto files of the active workspace.
+func Load() (*Workspace, error) {
+	repo, err := repository.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+
+	workspacesState := state.NewWorkspaces(repo)
+	if _, err := workspacesState.LoadWorkspacesFromDisk(); err != nil {
+		return nil, err
+	}
+	if ws := workspacesState.GetWorkspace(repo.ActiveWorkspace.MetaData.ID); ws != nil {
+		workspacesState.SetActiveWorkspace(ws)
+	}
+
+	environmentsState := state.NewEnvironments(repo)
+	if _, err := environmentsState.LoadEnvironmentsFromDisk(); err != nil {
+		return nil, err
+	}
+
+	requestsState := state.NewRequests(repo)
+	if _, err := requestsState.LoadCollectionsFromDisk(); err != nil {
+		return nil, err
+	}
+	if _, err := requestsState.LoadRequestsFromDisk(); err != nil {
+		return nil, err
+	}
+
+	protoFilesState := state.NewProtoFiles(repo)
+	if _, err := protoFilesState.LoadProtoFilesFromDisk(); err != nil {
+		return nil, err
+	}
+
+	provider := variables.NewProvider(requestsState, environmentsState, workspacesState)
+	grpcService := grpc.NewService(requestsState, environmentsState, protoFilesState, provider)
+	restService := rest.New(requestsState, environmentsState, provider)
+
+	return &Workspace{
+		Requests:     requestsState,
+		Environments: environmentsState,
+		Egress:       egress.New(requestsState, environmentsState, restService, grpcService),
+	}, nil
+}
+
+// Collection returns the collection with the name or id.
+func (w *Workspace) Collection(name string) (*domain.Collection, error) {
+	for _, col := range w.Requests.GetCollections() {
+		if col.MetaData.Name == name || col.MetaData.ID == name {
+			return col, nil
+		}
+	}
+	return nil, fmt.Errorf("collection %s not found", name)
+}
+
+// Request returns the request with the name or id, a request of a collection can be named as
+// "collection/request".
+func (w *Workspace) Request(name string) (*domain.Request, error) {
+	for _, req := range w.Requests.GetRequests() {
+		if req.MetaData.Name == name || req.MetaData.ID == name {
+			return req, nil
+		}
+		if req.CollectionName != "" && req.CollectionName+"/"+req.MetaData.Name == name {
+			return req, nil
+		}
+	}
+	return nil, fmt.Errorf("request %s not found", name)
+}
+
+// EnvironmentID returns the id of the environment with the name or id, empty when the name is empty.
+func (w *Workspace) EnvironmentID(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	for _, env := range w.Environments.GetEnvironments() {
+		if env.MetaData.Name == name || env.MetaData.ID == name {
+			return env.MetaData.ID, nil
+		}
+	}
+	return "", fmt.Errorf("environment %s not found", name)
+}
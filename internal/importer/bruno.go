@@ -0,0 +1,503 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// brunoMethods are the blocks of a .bru file which hold the method and the url of the request.
+var brunoMethods = map[string]string{
+	"get":     domain.RequestMethodGET,
+	"post":    domain.RequestMethodPOST,
+	"put":     domain.RequestMethodPUT,
+	"delete":  domain.RequestMethodDELETE,
+	"patch":   domain.RequestMethodPATCH,
+	"head":    domain.RequestMethodHEAD,
+	"options": domain.RequestMethodOPTIONS,
+	"connect": domain.RequestMethodCONNECT,
+	"trace":   domain.RequestMethodTRACE,
+}
+
+// bruBlock is a block of a .bru file such as headers { ... }, its lines are kept without the indentation.
+type bruBlock struct {
+	name  string
+	lines []string
+}
+
+type bruPair struct {
+	key      string
+	value    string
+	disabled bool
+}
+
+// pairs returns the "key: value" lines of the block, the disabled ones start with ~.
+func (b *bruBlock) pairs() []bruPair {
+	if b == nil {
+		return nil
+	}
+
+	out := make([]bruPair, 0, len(b.lines))
+	for _, line := range b.lines {
+		line = strings.TrimSpace(line)
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || key == "" {
+			continue
+		}
+
+		p := bruPair{key: strings.TrimSpace(key), value: strings.TrimSpace(value)}
+		if strings.HasPrefix(p.key, "~") {
+			p.key = strings.TrimPrefix(p.key, "~")
+			p.disabled = true
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func (b *bruBlock) value(key string) string {
+	for _, p := range b.pairs() {
+		if p.key == key {
+			return p.value
+		}
+	}
+	return ""
+}
+
+func (b *bruBlock) text() string {
+	if b == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(b.lines, "\n"))
+}
+
+type bruFile []*bruBlock
+
+func (f bruFile) block(name string) *bruBlock {
+	for _, b := range f {
+		if b.name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// auth returns the auth:mode block, named after the mode.
+func (f bruFile) auth(mode string) *bruBlock {
+	out := &bruBlock{name: mode}
+	if b := f.block("auth:" + mode); b != nil {
+		out.lines = b.lines
+	}
+	return out
+}
+
+// parseBru splits a .bru file into its blocks, a block starts with "name {" or "name [" and ends with a
+// closing bracket at the start of a line.
+func parseBru(data string) (bruFile, error) {
+	var out bruFile
+	var current *bruBlock
+	closing := ""
+
+	for i, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if current != nil {
+			if line == closing {
+				out = append(out, current)
+				current = nil
+				continue
+			}
+			current.lines = append(current.lines, strings.TrimPrefix(line, "  "))
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case strings.HasSuffix(trimmed, "{"):
+			current = &bruBlock{name: strings.TrimSpace(strings.TrimSuffix(trimmed, "{"))}
+			closing = "}"
+		case strings.HasSuffix(trimmed, "["):
+			current = &bruBlock{name: strings.TrimSpace(strings.TrimSuffix(trimmed, "["))}
+			closing = "]"
+		default:
+			return nil, fmt.Errorf("unexpected line %d: %s", i+1, trimmed)
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("block %s is not closed", current.name)
+	}
+	return out, nil
+}
+
+// brunoConverter converts the files of a collection and records what it can not convert.
+type brunoConverter struct {
+	fsys   fs.FS
+	report *convert.Report
+}
+
+// brunoDefaults are the headers and the auth a collection or a folder gives to its requests.
+type brunoDefaults struct {
+	headers []domain.KeyValue
+	auth    *bruBlock
+	file    bruFile
+}
+
+// ParseBrunoCollection converts the Bruno collection at the root of fsys without saving it, its folders
+// become collections as they do for Postman and the files of its environments directory become environments.
+func ParseBrunoCollection(fsys fs.FS) (*Result, error) {
+	data, err := fs.ReadFile(fsys, "bruno.json")
+	if err != nil {
+		return nil, fmt.Errorf("bruno.json not found, select the directory of a Bruno collection: %w", err)
+	}
+
+	var config struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing bruno.json: %w", err)
+	}
+
+	name := config.Name
+	if name == "" {
+		name = "Bruno"
+	}
+
+	c := &brunoConverter{fsys: fsys, report: &convert.Report{}}
+	root := &folder{name: name}
+	defaults, err := c.defaults("collection.bru", brunoDefaults{}, name)
+	if err != nil {
+		return nil, err
+	}
+	root.variables = brunoVariables(defaults.file.block("vars:pre-request"))
+
+	if err := c.walk(root, ".", defaults, name); err != nil {
+		return nil, err
+	}
+
+	envs, err := c.environments()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Collections: root.collections(), Environments: envs, Report: c.report}, nil
+}
+
+// defaults reads the collection.bru or folder.bru file, its headers are added to the inherited ones and its auth
+// replaces the inherited auth unless it is inherit.
+func (c *brunoConverter) defaults(file string, inherited brunoDefaults, itemPath string) (brunoDefaults, error) {
+	data, err := fs.ReadFile(c.fsys, file)
+	if err != nil {
+		return brunoDefaults{headers: inherited.headers, auth: inherited.auth}, nil
+	}
+
+	f, err := parseBru(string(data))
+	if err != nil {
+		return brunoDefaults{}, fmt.Errorf("error parsing %s: %w", file, err)
+	}
+	c.checkScripts(f, itemPath)
+
+	out := brunoDefaults{headers: append(append([]domain.KeyValue(nil), inherited.headers...), brunoKeyValues(f.block("headers"))...), auth: inherited.auth, file: f}
+	if mode := f.block("auth").value("mode"); mode != "" && mode != "inherit" {
+		out.auth = f.auth(mode)
+	}
+	return out, nil
+}
+
+func (c *brunoConverter) walk(f *folder, dir string, defaults brunoDefaults, itemPath string) error {
+	entries, err := fs.ReadDir(c.fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	type request struct {
+		seq int
+		req *domain.Request
+	}
+	var requests []request
+
+	for _, e := range entries {
+		p := path.Join(dir, e.Name())
+		if e.IsDir() {
+			if (dir == "." && e.Name() == "environments") || strings.HasPrefix(e.Name(), ".") || e.Name() == "node_modules" {
+				continue
+			}
+
+			sub := &folder{name: e.Name()}
+			subPath := itemPath + " / " + e.Name()
+			subDefaults, err := c.defaults(path.Join(p, "folder.bru"), defaults, subPath)
+			if err != nil {
+				return err
+			}
+			if n := subDefaults.file.block("meta").value("name"); n != "" {
+				sub.name = n
+			}
+			sub.variables = brunoVariables(subDefaults.file.block("vars:pre-request"))
+
+			if err := c.walk(sub, p, subDefaults, subPath); err != nil {
+				return err
+			}
+			f.folders = append(f.folders, sub)
+			continue
+		}
+
+		if !strings.HasSuffix(e.Name(), ".bru") || e.Name() == "collection.bru" || e.Name() == "folder.bru" {
+			continue
+		}
+
+		data, err := fs.ReadFile(c.fsys, p)
+		if err != nil {
+			return err
+		}
+
+		file, err := parseBru(string(data))
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", p, err)
+		}
+
+		meta := file.block("meta")
+		name := meta.value("name")
+		if name == "" {
+			name = strings.TrimSuffix(e.Name(), ".bru")
+		}
+
+		reqPath := itemPath + " / " + name
+		if t := meta.value("type"); t != "" && t != "http" && t != "graphql" {
+			c.report.Add(reqPath, "%s requests are not supported", t)
+			continue
+		}
+
+		req := c.request(file, defaults, reqPath)
+		if req == nil {
+			continue
+		}
+		req.MetaData.Name = name
+
+		seq, _ := strconv.Atoi(meta.value("seq"))
+		requests = append(requests, request{seq: seq, req: req})
+	}
+
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].seq < requests[j].seq
+	})
+	for _, r := range requests {
+		f.requests = append(f.requests, r.req)
+	}
+	return nil
+}
+
+func (c *brunoConverter) request(file bruFile, defaults brunoDefaults, reqPath string) *domain.Request {
+	var method string
+	var block *bruBlock
+	for _, b := range file {
+		if m, ok := brunoMethods[b.name]; ok {
+			method, block = m, b
+			break
+		}
+	}
+	if block == nil {
+		c.report.Add(reqPath, "file has no request")
+		return nil
+	}
+
+	c.checkScripts(file, reqPath)
+
+	httpReq := &domain.HTTPRequest{
+		Headers:     append(append([]domain.KeyValue(nil), defaults.headers...), brunoKeyValues(file.block("headers"))...),
+		PathParams:  make([]domain.KeyValue, 0),
+		QueryParams: make([]domain.KeyValue, 0),
+		Body:        domain.Body{Type: domain.BodyTypeNone},
+		Variables:   brunoVariables(file.block("vars:pre-request")),
+	}
+
+	base, query := splitURL(block.value("url"))
+	if params := file.block("params:query"); params != nil {
+		query = brunoKeyValues(params)
+	}
+	base, names := convertPathVariables(base)
+
+	values := make(map[string]string)
+	for _, p := range file.block("params:path").pairs() {
+		values[p.key] = p.value
+	}
+	for _, name := range names {
+		httpReq.PathParams = append(httpReq.PathParams, domain.KeyValue{ID: uuid.NewString(), Key: name, Value: values[name], Enable: true})
+	}
+
+	auth := defaults.auth
+	if mode := block.value("auth"); mode != "" && mode != "inherit" {
+		auth = file.auth(mode)
+	}
+	httpReq.QueryParams = append(query, c.setAuth(httpReq, auth, reqPath)...)
+	c.setBody(httpReq, file, block.value("body"), reqPath)
+
+	return &domain.Request{
+		ApiVersion: domain.ApiVersion,
+		Kind:       domain.KindRequest,
+		MetaData: domain.RequestMeta{
+			ID:   uuid.NewString(),
+			Type: domain.RequestTypeHTTP,
+		},
+		Spec: domain.RequestSpec{
+			HTTP: &domain.HTTPRequestSpec{
+				Method:  method,
+				URL:     joinQuery(base, httpReq.QueryParams),
+				Request: httpReq,
+			},
+		},
+	}
+}
+
+func (c *brunoConverter) setBody(httpReq *domain.HTTPRequest, file bruFile, mode, reqPath string) {
+	switch mode {
+	case "", "none":
+	case "json":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: file.block("body:json").text()}
+	case "xml":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeXML, Data: file.block("body:xml").text()}
+	case "text":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeText, Data: file.block("body:text").text()}
+	case "formUrlEncoded":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: brunoKeyValues(file.block("body:form-urlencoded"))}
+	case "multipartForm":
+		fields := make([]domain.FormField, 0)
+		for _, p := range file.block("body:multipart-form").pairs() {
+			field := domain.FormField{ID: uuid.NewString(), Type: domain.FormFieldTypeText, Key: p.key, Value: p.value, Enable: !p.disabled}
+			if strings.HasPrefix(p.value, "@file(") && strings.HasSuffix(p.value, ")") {
+				field.Type = domain.FormFieldTypeFile
+				field.Value = ""
+				field.Files = strings.Split(strings.TrimSuffix(strings.TrimPrefix(p.value, "@file("), ")"), "|")
+			}
+			fields = append(fields, field)
+		}
+		httpReq.Body = domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: fields}}
+	case "graphql":
+		// graphql requests are sent as json
+		body := map[string]any{"query": file.block("body:graphql").text()}
+		var vars any
+		if err := json.Unmarshal([]byte(file.block("body:graphql:vars").text()), &vars); err == nil {
+			body["variables"] = vars
+		}
+		data, _ := json.MarshalIndent(body, "", "  ")
+		httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: string(data)}
+	default:
+		c.report.Add(reqPath, "body mode %s is not supported", mode)
+	}
+}
+
+// setAuth sets the auth of the request and returns the query params the auth is sent with.
+func (c *brunoConverter) setAuth(httpReq *domain.HTTPRequest, auth *bruBlock, reqPath string) []domain.KeyValue {
+	httpReq.Auth = domain.Auth{Type: domain.AuthTypeNone}
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.name {
+	case "none":
+	case "basic":
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: auth.value("username"), Password: auth.value("password")}}
+	case "bearer":
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: auth.value("token")}}
+	case "apikey":
+		if auth.value("placement") == "queryparams" {
+			return []domain.KeyValue{{ID: uuid.NewString(), Key: auth.value("key"), Value: auth.value("value"), Enable: true}}
+		}
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeAPIKey, APIKeyAuth: &domain.APIKeyAuth{Key: auth.value("key"), Value: auth.value("value")}}
+	default:
+		c.report.Add(reqPath, "%s auth is not supported", auth.name)
+	}
+	return nil
+}
+
+func (c *brunoConverter) checkScripts(file bruFile, itemPath string) {
+	for _, b := range file {
+		switch {
+		case strings.HasPrefix(b.name, "script:"), b.name == "tests":
+			if b.text() != "" {
+				c.report.Add(itemPath, "%s is not imported", strings.ReplaceAll(b.name, ":", " "))
+			}
+		case b.name == "vars:post-response", b.name == "assert":
+			if len(b.pairs()) > 0 {
+				c.report.Add(itemPath, "%s is not imported", strings.ReplaceAll(b.name, ":", " "))
+			}
+		}
+	}
+}
+
+// environments reads the environments directory, the secrets are listed without their values which Bruno keeps outside of the files.
+func (c *brunoConverter) environments() ([]*domain.Environment, error) {
+	entries, err := fs.ReadDir(c.fsys, "environments")
+	if err != nil {
+		return nil, nil
+	}
+
+	var out []*domain.Environment
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".bru") {
+			continue
+		}
+
+		data, err := fs.ReadFile(c.fsys, path.Join("environments", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := parseBru(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing environment %s: %w", e.Name(), err)
+		}
+
+		env := domain.NewEnvironment(strings.TrimSuffix(e.Name(), ".bru"))
+		env.Spec.Values = brunoKeyValues(file.block("vars"))
+		if secrets := file.block("vars:secret"); secrets != nil {
+			for _, line := range secrets.lines {
+				key := strings.TrimSuffix(strings.TrimSpace(line), ",")
+				disabled := strings.HasPrefix(key, "~")
+				if key = strings.TrimPrefix(key, "~"); key == "" {
+					continue
+				}
+				env.Spec.Values = append(env.Spec.Values, domain.KeyValue{ID: uuid.NewString(), Key: key, Enable: !disabled, Secret: true})
+			}
+		}
+		out = append(out, env)
+	}
+	return out, nil
+}
+
+func brunoKeyValues(b *bruBlock) []domain.KeyValue {
+	pairs := b.pairs()
+	out := make([]domain.KeyValue, 0, len(pairs))
+	for _, p := range pairs {
+		out = append(out, domain.KeyValue{ID: uuid.NewString(), Key: p.key, Value: p.value, Enable: !p.disabled})
+	}
+	return out
+}
+
+func brunoVariables(b *bruBlock) []domain.KeyValue {
+	if b == nil {
+		return nil
+	}
+	return brunoKeyValues(b)
+}
+
+// ImportBrunoCollection saves the collections and environments of the Bruno collection in dir to the active workspace.
+func ImportBrunoCollection(dir string) (*Result, error) {
+	result, err := ParseBrunoCollection(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveResult(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
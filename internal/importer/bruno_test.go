@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestParseBrunoCollection(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bruno.json": {Data: []byte(`{"version": "1", "name": "Store", "type": "collection"}`)},
+		"collection.bru": {Data: []byte(`headers {
+  X-Client: chapar
+}
+
+auth {
+  mode: bearer
+}
+
+auth:bearer {
+  token: {{token}}
+}
+`)},
+		"Health.bru": {Data: []byte(`meta {
+  name: Health
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/health
+  body: none
+  auth: none
+}
+`)},
+		"Users/Get user.bru": {Data: []byte(`meta {
+  name: Get user
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/:id?expand=true
+  body: none
+  auth: inherit
+}
+
+params:query {
+  expand: true
+  ~debug: 1
+}
+
+params:path {
+  id: 7
+}
+
+tests {
+  test("ok", function() {});
+}
+`)},
+		"Users/Create user.bru": {Data: []byte(`meta {
+  name: Create user
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: inherit
+}
+
+body:json {
+  {
+    "name": "ada"
+  }
+}
+`)},
+		"environments/Local.bru": {Data: []byte(`vars {
+  baseUrl: http://localhost:3000
+}
+vars:secret [
+  token
+]
+`)},
+	}
+
+	result, err := ParseBrunoCollection(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Collections) != 2 || result.Collections[1].MetaData.Name != "Store - Users" {
+		t.Fatalf("unexpected collections %+v", result.Collections)
+	}
+
+	health := result.Collections[0].Spec.Requests[0].Spec.HTTP.Request
+	if health.Auth.Type != domain.AuthTypeNone || len(health.Headers) != 1 {
+		t.Errorf("unexpected health request %+v", health)
+	}
+
+	users := result.Collections[1].Spec.Requests
+	if users[0].MetaData.Name != "Get user" || users[1].MetaData.Name != "Create user" {
+		t.Fatalf("expected the requests ordered by seq, got %s, %s", users[0].MetaData.Name, users[1].MetaData.Name)
+	}
+
+	get := users[0].Spec.HTTP
+	if get.URL != "{{baseUrl}}/users/{id}?expand=true" || get.Request.PathParams[0].Value != "7" || get.Request.QueryParams[1].Enable {
+		t.Errorf("unexpected get request %s %+v", get.URL, get.Request)
+	}
+	if get.Request.Auth.Type != domain.AuthTypeToken || get.Request.Auth.TokenAuth.Token != "{{token}}" {
+		t.Errorf("expected the collection auth, got %+v", get.Request.Auth)
+	}
+
+	create := users[1].Spec.HTTP.Request
+	if create.Body.Type != domain.BodyTypeJSON || create.Body.Data != "{\n  \"name\": \"ada\"\n}" {
+		t.Errorf("unexpected body %q", create.Body.Data)
+	}
+
+	if len(result.Environments) != 1 || len(result.Environments[0].Spec.Values) != 2 || !result.Environments[0].Spec.Values[1].Secret {
+		t.Errorf("unexpected environments %+v", result.Environments)
+	}
+
+	if len(result.Report.Items) != 1 || result.Report.Items[0].Path != "Store / Users / Get user" {
+		t.Errorf("unexpected report %+v", result.Report.Items)
+	}
+}
@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// browserHeaders are the headers the browser adds by itself, they are not imported from the captured requests.
+var browserHeaders = map[string]bool{
+	"host":                      true,
+	"connection":                true,
+	"keep-alive":                true,
+	"content-length":            true,
+	"accept-encoding":           true,
+	"te":                        true,
+	"upgrade-insecure-requests": true,
+	"dnt":                       true,
+	"priority":                  true,
+	"purpose":                   true,
+	"pragma":                    true,
+	"cache-control":             true,
+}
+
+// HAR is an HTTP archive, such as the ones the network panel of the browsers saves.
+type HAR struct {
+	Log struct {
+		Entries []HAREntry `json:"entries"`
+	} `json:"log"`
+}
+
+type HAREntry struct {
+	Request struct {
+		Method   string      `json:"method"`
+		URL      string      `json:"url"`
+		Headers  []harRecord `json:"headers"`
+		PostData *struct {
+			MimeType string      `json:"mimeType"`
+			Text     string      `json:"text"`
+			Params   []harRecord `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harRecord struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	FileName string `json:"fileName"`
+}
+
+// String describes the entry to let the user pick the entries to import.
+func (e HAREntry) String() string {
+	return fmt.Sprintf("%s %s (%d)", e.Request.Method, e.Request.URL, e.Response.Status)
+}
+
+// IsHAR reports whether the data is an HTTP archive.
+func IsHAR(data []byte) bool {
+	var raw struct {
+		Log *struct {
+			Entries json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	return json.Unmarshal(data, &raw) == nil && raw.Log != nil && raw.Log.Entries != nil
+}
+
+func ParseHAR(data []byte) (*HAR, error) {
+	var har HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("error parsing HAR: %w", err)
+	}
+	return &har, nil
+}
+
+// Convert converts the entries at the keep indexes, or all of them when keep is nil, to a collection per host.
+func (h *HAR) Convert(keep []int) *Result {
+	indexes := keep
+	if indexes == nil {
+		for i := range h.Log.Entries {
+			indexes = append(indexes, i)
+		}
+	}
+
+	out := &Result{Report: &convert.Report{}}
+	collections := make(map[string]*domain.Collection)
+	for _, i := range indexes {
+		if i < 0 || i >= len(h.Log.Entries) {
+			continue
+		}
+
+		entry := h.Log.Entries[i]
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || u.Host == "" {
+			out.Report.Add(entry.String(), "url is not valid")
+			continue
+		}
+
+		col, ok := collections[u.Host]
+		if !ok {
+			col = domain.NewCollection(u.Host)
+			collections[u.Host] = col
+			out.Collections = append(out.Collections, col)
+		}
+
+		req := harRequest(entry, out.Report)
+		req.MetaData.Name = entry.Request.Method + " " + u.Path
+		if u.Path == "" {
+			req.MetaData.Name += "/"
+		}
+		req.CollectionID = col.MetaData.ID
+		req.CollectionName = col.MetaData.Name
+		col.AddRequest(req)
+	}
+	return out
+}
+
+func harRequest(entry HAREntry, report *convert.Report) *domain.Request {
+	httpReq := &domain.HTTPRequest{
+		Headers:     make([]domain.KeyValue, 0, len(entry.Request.Headers)),
+		PathParams:  make([]domain.KeyValue, 0),
+		QueryParams: make([]domain.KeyValue, 0),
+		Body:        domain.Body{Type: domain.BodyTypeNone},
+		Auth:        domain.Auth{Type: domain.AuthTypeNone},
+	}
+
+	for _, h := range entry.Request.Headers {
+		name := strings.ToLower(h.Name)
+		if browserHeaders[name] || strings.HasPrefix(name, ":") || strings.HasPrefix(name, "sec-") {
+			continue
+		}
+		httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: h.Name, Value: h.Value, Enable: true})
+	}
+
+	base, query := splitURL(entry.Request.URL)
+	httpReq.QueryParams = query
+
+	if data := entry.Request.PostData; data != nil {
+		mimeType, _, _ := strings.Cut(data.MimeType, ";")
+		switch {
+		case mimeType == "application/x-www-form-urlencoded":
+			_, values := splitURL("?" + data.Text)
+			if len(data.Params) > 0 {
+				values = values[:0]
+				for _, p := range data.Params {
+					values = append(values, domain.KeyValue{ID: uuid.NewString(), Key: p.Name, Value: p.Value, Enable: true})
+				}
+			}
+			httpReq.Body = domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: values}
+		case mimeType == "multipart/form-data":
+			fields := make([]domain.FormField, 0, len(data.Params))
+			for _, p := range data.Params {
+				field := domain.FormField{ID: uuid.NewString(), Type: domain.FormFieldTypeText, Key: p.Name, Value: p.Value, Enable: true}
+				if p.FileName != "" {
+					field.Type = domain.FormFieldTypeFile
+					field.Value = ""
+					report.Add(entry.String(), "file %s of field %s must be selected again", p.FileName, p.Name)
+				}
+				fields = append(fields, field)
+			}
+			httpReq.Body = domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: fields}}
+		case isJSONMediaType(mimeType):
+			httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: data.Text}
+		case strings.Contains(mimeType, "xml"):
+			httpReq.Body = domain.Body{Type: domain.BodyTypeXML, Data: data.Text}
+		case data.Text != "":
+			httpReq.Body = domain.Body{Type: domain.BodyTypeText, Data: data.Text}
+		}
+	}
+
+	// the multipart boundary of the browser does not match the one of the new body
+	if httpReq.Body.Type == domain.BodyTypeFormData {
+		for i, h := range httpReq.Headers {
+			if strings.EqualFold(h.Key, "Content-Type") {
+				httpReq.Headers = append(httpReq.Headers[:i], httpReq.Headers[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return &domain.Request{
+		ApiVersion: domain.ApiVersion,
+		Kind:       domain.KindRequest,
+		MetaData: domain.RequestMeta{
+			ID:   uuid.NewString(),
+			Type: domain.RequestTypeHTTP,
+		},
+		Spec: domain.RequestSpec{
+			HTTP: &domain.HTTPRequestSpec{
+				Method:  strings.ToUpper(entry.Request.Method),
+				URL:     joinQuery(base, httpReq.QueryParams),
+				Request: httpReq,
+			},
+		},
+	}
+}
+
+// ImportHAR saves the entries at the keep indexes of the archive, or all of them when keep is nil, to the active workspace.
+func ImportHAR(data []byte, keep []int) (*Result, error) {
+	har, err := ParseHAR(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := har.Convert(keep)
+	if err := saveResult(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ImportHARFromFile(filePath string, keep []int) (*Result, error) {
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return ImportHAR(fileContent, keep)
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const harData = `{
+  "log": {
+    "version": "1.2",
+    "entries": [
+      {
+        "request": {
+          "method": "POST",
+          "url": "https://api.example.com/v1/login?next=home",
+          "headers": [
+            {"name": ":authority", "value": "api.example.com"},
+            {"name": "Content-Type", "value": "application/json"},
+            {"name": "sec-ch-ua", "value": "\"Chromium\""},
+            {"name": "Accept-Encoding", "value": "gzip"},
+            {"name": "Authorization", "value": "Bearer abc"}
+          ],
+          "postData": {"mimeType": "application/json", "text": "{\"user\": \"ada\"}"}
+        },
+        "response": {"status": 200}
+      },
+      {
+        "request": {"method": "GET", "url": "https://cdn.example.com/app.js", "headers": []},
+        "response": {"status": 200}
+      }
+    ]
+  }
+}`
+
+func TestHARConvert(t *testing.T) {
+	if !IsHAR([]byte(harData)) {
+		t.Fatal("expected the data to be detected as a HAR")
+	}
+
+	har, err := ParseHAR([]byte(harData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if all := har.Convert(nil); len(all.Collections) != 2 {
+		t.Errorf("expected a collection per host, got %d", len(all.Collections))
+	}
+
+	result := har.Convert([]int{0})
+	if len(result.Collections) != 1 || result.Collections[0].MetaData.Name != "api.example.com" {
+		t.Fatalf("unexpected collections %+v", result.Collections)
+	}
+
+	req := result.Collections[0].Spec.Requests[0]
+	if req.MetaData.Name != "POST /v1/login" || req.Spec.HTTP.URL != "https://api.example.com/v1/login?next=home" {
+		t.Errorf("unexpected request %s %s", req.MetaData.Name, req.Spec.HTTP.URL)
+	}
+
+	headers := req.Spec.HTTP.Request.Headers
+	if len(headers) != 2 || headers[0].Key != "Content-Type" || headers[1].Key != "Authorization" {
+		t.Errorf("expected the browser headers to be stripped, got %+v", headers)
+	}
+	if req.Spec.HTTP.Request.Body.Type != domain.BodyTypeJSON {
+		t.Errorf("unexpected body %+v", req.Spec.HTTP.Request.Body)
+	}
+}
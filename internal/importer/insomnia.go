@@ -0,0 +1,382 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+var (
+	// insomniaVariable matches {{ _.name }} and {{ name }}.
+	insomniaVariable = regexp.MustCompile(`\{\{\s*(?:_\.)?([\w.-]+)\s*\}\}`)
+	// insomniaTag matches the template tags such as {% uuid 'v4' %}.
+	insomniaTag = regexp.MustCompile(`\{%\s*(\w+)([^%]*)%\}`)
+)
+
+// insomniaExport is an Insomnia v4 export, the resources reference their parent by id.
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+type insomniaResource struct {
+	ID          string  `json:"_id"`
+	Type        string  `json:"_type"`
+	ParentID    string  `json:"parentId"`
+	Name        string  `json:"name"`
+	MetaSortKey float64 `json:"metaSortKey"`
+
+	// requests
+	Method         string          `json:"method"`
+	URL            string          `json:"url"`
+	Body           insomniaBody    `json:"body"`
+	Parameters     []insomniaParam `json:"parameters"`
+	Headers        []insomniaParam `json:"headers"`
+	Authentication map[string]any  `json:"authentication"`
+
+	// environments, folders keep theirs in environment
+	Data        map[string]any `json:"data"`
+	Environment map[string]any `json:"environment"`
+}
+
+type insomniaBody struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text"`
+	FileName string          `json:"fileName"`
+	Params   []insomniaParam `json:"params"`
+}
+
+type insomniaParam struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`
+	FileName string `json:"fileName"`
+}
+
+// IsInsomnia reports whether the data, JSON or YAML, is an Insomnia v4 export.
+func IsInsomnia(data []byte) bool {
+	doc, err := decodeInsomnia(data)
+	return err == nil && doc.Type == "export" && doc.ExportFormat == 4
+}
+
+func decodeInsomnia(data []byte) (*insomniaExport, error) {
+	normalized, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc insomniaExport
+	if err := json.Unmarshal(normalized, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing insomnia export: %w", err)
+	}
+	return &doc, nil
+}
+
+// insomniaConverter converts the resources of an export and records what it can not convert.
+type insomniaConverter struct {
+	children map[string][]*insomniaResource
+	report   *convert.Report
+}
+
+// ParseInsomnia converts an Insomnia v4 export without saving it. Each workspace becomes collections, as
+// folders do for Postman, its base environment becomes an environment and the sub environments extend it.
+func ParseInsomnia(data []byte) (*Result, error) {
+	doc, err := decodeInsomnia(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Type != "export" || doc.ExportFormat != 4 {
+		return nil, fmt.Errorf("unsupported insomnia export format %d, export the data as Insomnia v4", doc.ExportFormat)
+	}
+
+	c := &insomniaConverter{children: make(map[string][]*insomniaResource), report: &convert.Report{}}
+	var workspaces []*insomniaResource
+	for i := range doc.Resources {
+		r := &doc.Resources[i]
+		if r.Type == "workspace" {
+			workspaces = append(workspaces, r)
+			continue
+		}
+		c.children[r.ParentID] = append(c.children[r.ParentID], r)
+	}
+
+	for _, list := range c.children {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].MetaSortKey < list[j].MetaSortKey
+		})
+	}
+
+	out := &Result{Report: c.report}
+	for _, ws := range workspaces {
+		name := ws.Name
+		if name == "" {
+			name = "Insomnia"
+		}
+
+		root := &folder{name: name}
+		c.addChildren(root, ws.ID, name)
+		out.Collections = append(out.Collections, root.collections()...)
+		out.Environments = append(out.Environments, c.environments(ws.ID, name)...)
+	}
+
+	return out, nil
+}
+
+func (c *insomniaConverter) addChildren(f *folder, parentID, path string) {
+	for _, r := range c.children[parentID] {
+		itemPath := path + " / " + r.Name
+		switch r.Type {
+		case "request_group":
+			sub := &folder{name: r.Name, variables: c.keyValues(flattenValues(r.Environment), itemPath)}
+			c.addChildren(sub, r.ID, itemPath)
+			f.folders = append(f.folders, sub)
+		case "request":
+			f.requests = append(f.requests, c.request(r, itemPath))
+		case "grpc_request", "websocket_request":
+			c.report.Add(itemPath, "%s is not supported", strings.ReplaceAll(r.Type, "_", " "))
+		}
+	}
+}
+
+// environments returns the base environment of the workspace and its sub environments which extend it.
+func (c *insomniaConverter) environments(workspaceID, name string) []*domain.Environment {
+	var out []*domain.Environment
+	for _, base := range c.children[workspaceID] {
+		if base.Type != "environment" {
+			continue
+		}
+
+		baseName := base.Name
+		if baseName == "" || baseName == "Base Environment" {
+			baseName = name
+		}
+
+		env := domain.NewEnvironment(baseName)
+		env.Spec.Values = c.keyValues(flattenValues(base.Data), baseName)
+		out = append(out, env)
+
+		for _, sub := range c.children[base.ID] {
+			if sub.Type != "environment" {
+				continue
+			}
+
+			child := domain.NewEnvironment(sub.Name)
+			child.Spec.Extends = env.MetaData.ID
+			child.Spec.Values = c.keyValues(flattenValues(sub.Data), sub.Name)
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+func (c *insomniaConverter) request(r *insomniaResource, path string) *domain.Request {
+	method := strings.ToUpper(r.Method)
+	if method == "" {
+		method = domain.RequestMethodGET
+	}
+
+	httpReq := &domain.HTTPRequest{
+		Headers:     make([]domain.KeyValue, 0, len(r.Headers)),
+		PathParams:  make([]domain.KeyValue, 0),
+		QueryParams: make([]domain.KeyValue, 0),
+		Body:        domain.Body{Type: domain.BodyTypeNone},
+	}
+
+	for _, h := range r.Headers {
+		httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: c.text(h.Name, path), Value: c.text(h.Value, path), Enable: !h.Disabled})
+	}
+
+	base, query := splitURL(c.text(r.URL, path))
+	for _, p := range r.Parameters {
+		query = append(query, domain.KeyValue{ID: uuid.NewString(), Key: c.text(p.Name, path), Value: c.text(p.Value, path), Enable: !p.Disabled})
+	}
+	httpReq.QueryParams = append(query, c.setAuth(httpReq, r.Authentication, path)...)
+	c.setBody(httpReq, r.Body, path)
+
+	return &domain.Request{
+		ApiVersion: domain.ApiVersion,
+		Kind:       domain.KindRequest,
+		MetaData: domain.RequestMeta{
+			ID:   uuid.NewString(),
+			Name: r.Name,
+			Type: domain.RequestTypeHTTP,
+		},
+		Spec: domain.RequestSpec{
+			HTTP: &domain.HTTPRequestSpec{
+				Method:  method,
+				URL:     joinQuery(base, httpReq.QueryParams),
+				Request: httpReq,
+			},
+		},
+	}
+}
+
+func (c *insomniaConverter) setBody(httpReq *domain.HTTPRequest, b insomniaBody, path string) {
+	switch {
+	case b.MimeType == "" && b.Text == "" && len(b.Params) == 0:
+	case b.MimeType == "application/x-www-form-urlencoded":
+		values := make([]domain.KeyValue, 0, len(b.Params))
+		for _, p := range b.Params {
+			values = append(values, domain.KeyValue{ID: uuid.NewString(), Key: c.text(p.Name, path), Value: c.text(p.Value, path), Enable: !p.Disabled})
+		}
+		httpReq.Body = domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: values}
+	case b.MimeType == "multipart/form-data":
+		fields := make([]domain.FormField, 0, len(b.Params))
+		for _, p := range b.Params {
+			field := domain.FormField{ID: uuid.NewString(), Type: domain.FormFieldTypeText, Key: c.text(p.Name, path), Value: c.text(p.Value, path), Enable: !p.Disabled}
+			if p.Type == "file" {
+				field.Type = domain.FormFieldTypeFile
+				if p.FileName != "" {
+					field.Files = []string{p.FileName}
+				}
+			}
+			fields = append(fields, field)
+		}
+		httpReq.Body = domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: fields}}
+	case b.MimeType == "application/octet-stream":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeBinary, BinaryFilePath: b.FileName}
+	case b.MimeType == "application/graphql":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: c.text(b.Text, path)}
+	case isJSONMediaType(b.MimeType):
+		httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: c.text(b.Text, path)}
+	case strings.Contains(b.MimeType, "xml"):
+		httpReq.Body = domain.Body{Type: domain.BodyTypeXML, Data: c.text(b.Text, path)}
+	default:
+		httpReq.Body = domain.Body{Type: domain.BodyTypeText, Data: c.text(b.Text, path)}
+	}
+}
+
+// setAuth sets the auth of the request and returns the query params the auth is sent with.
+func (c *insomniaConverter) setAuth(httpReq *domain.HTTPRequest, auth map[string]any, path string) []domain.KeyValue {
+	httpReq.Auth = domain.Auth{Type: domain.AuthTypeNone}
+	if len(auth) == 0 {
+		return nil
+	}
+
+	attr := func(name string) string {
+		return c.text(stringValue(auth[name]), path)
+	}
+
+	if disabled, _ := auth["disabled"].(bool); disabled {
+		return nil
+	}
+
+	switch t := attr("type"); t {
+	case "", "none":
+	case "basic":
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: attr("username"), Password: attr("password")}}
+	case "bearer", "oauth2":
+		token := attr("token")
+		if t == "oauth2" {
+			token = attr("accessToken")
+			if token == "" {
+				c.report.Add(path, "oauth2 auth has no access token, set the token after fetching one")
+			}
+		}
+
+		if prefix := attr("prefix"); prefix != "" && !strings.EqualFold(prefix, "bearer") {
+			httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: "Authorization", Value: prefix + " " + token, Enable: true})
+			return nil
+		}
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: token}}
+	case "apikey":
+		switch attr("addTo") {
+		case "queryParams":
+			return []domain.KeyValue{{ID: uuid.NewString(), Key: attr("key"), Value: attr("value"), Enable: true}}
+		case "cookie":
+			httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: "Cookie", Value: attr("key") + "=" + attr("value"), Enable: true})
+		default:
+			httpReq.Auth = domain.Auth{Type: domain.AuthTypeAPIKey, APIKeyAuth: &domain.APIKeyAuth{Key: attr("key"), Value: attr("value")}}
+		}
+	default:
+		c.report.Add(path, "%s auth is not supported", t)
+	}
+	return nil
+}
+
+// text converts the variables and the template tags of Insomnia to the ones of Chapar.
+func (c *insomniaConverter) text(s, path string) string {
+	s = insomniaVariable.ReplaceAllString(s, "{{$1}}")
+	return insomniaTag.ReplaceAllStringFunc(s, func(tag string) string {
+		m := insomniaTag.FindStringSubmatch(tag)
+		switch args := strings.Trim(strings.TrimSpace(m[2]), `'"`); m[1] {
+		case "uuid":
+			return "{{$uuid}}"
+		case "now", "timestamp":
+			if args == "millis" {
+				return `{{$now "unixMilli"}}`
+			}
+			if args == "unix" || m[1] == "timestamp" {
+				return "{{$timestamp}}"
+			}
+			return "{{$now}}"
+		}
+		c.report.Add(path, "template tag %s is not supported", m[1])
+		return tag
+	})
+}
+
+func (c *insomniaConverter) keyValues(values map[string]string, path string) []domain.KeyValue {
+	out := make([]domain.KeyValue, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		out = append(out, domain.KeyValue{ID: uuid.NewString(), Key: key, Value: c.text(values[key], path), Enable: true})
+	}
+	return out
+}
+
+// flattenValues flattens the nested objects of an environment, {"a": {"b": 1}} becomes a.b = 1 as Insomnia refers to it as _.a.b.
+func flattenValues(data map[string]any) map[string]string {
+	out := make(map[string]string)
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			out[prefix] = stringValue(v)
+			return
+		}
+		for k, val := range obj {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			walk(k, val)
+		}
+	}
+
+	for k, v := range data {
+		walk(k, v)
+	}
+	return out
+}
+
+// ImportInsomnia saves the collections and environments of an Insomnia v4 export to the active workspace.
+func ImportInsomnia(data []byte) (*Result, error) {
+	result, err := ParseInsomnia(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveResult(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ImportInsomniaFromFile(filePath string) (*Result, error) {
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return ImportInsomnia(fileContent)
+}
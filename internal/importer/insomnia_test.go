@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const insomniaExportData = `{
+  "_type": "export",
+  "__export_format": 4,
+  "resources": [
+    {"_id": "wrk_1", "_type": "workspace", "name": "Billing"},
+    {"_id": "env_base", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment", "data": {"baseUrl": "http://localhost", "auth": {"token": "t"}}},
+    {"_id": "env_prod", "_type": "environment", "parentId": "env_base", "name": "Production", "data": {"baseUrl": "https://billing.example.com"}},
+    {"_id": "fld_1", "_type": "request_group", "parentId": "wrk_1", "name": "Invoices", "environment": {"limit": 10}},
+    {
+      "_id": "req_2", "_type": "request", "parentId": "fld_1", "name": "Create invoice", "method": "POST", "metaSortKey": 2,
+      "url": "{{ _.baseUrl }}/invoices",
+      "headers": [{"name": "X-Request-ID", "value": "{% uuid 'v4' %}"}],
+      "body": {"mimeType": "application/json", "text": "{\"total\": 1}"},
+      "authentication": {"type": "bearer", "token": "{{ _.auth.token }}"}
+    },
+    {
+      "_id": "req_1", "_type": "request", "parentId": "fld_1", "name": "List invoices", "method": "GET", "metaSortKey": 1,
+      "url": "{{ _.baseUrl }}/invoices",
+      "parameters": [{"name": "limit", "value": "{{ _.limit }}"}, {"name": "page", "value": "1", "disabled": true}],
+      "authentication": {"type": "hawk"}
+    }
+  ]
+}`
+
+func TestParseInsomnia(t *testing.T) {
+	if !IsInsomnia([]byte(insomniaExportData)) {
+		t.Fatal("expected the data to be detected as an insomnia export")
+	}
+
+	result, err := ParseInsomnia([]byte(insomniaExportData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Collections) != 1 || result.Collections[0].MetaData.Name != "Billing - Invoices" {
+		t.Fatalf("unexpected collections %+v", result.Collections)
+	}
+
+	col := result.Collections[0]
+	if len(col.Spec.Variables) != 1 || col.Spec.Variables[0].Key != "limit" {
+		t.Errorf("expected the folder environment as collection variables, got %+v", col.Spec.Variables)
+	}
+
+	list, create := col.Spec.Requests[0].Spec.HTTP, col.Spec.Requests[1].Spec.HTTP
+	if list.URL != "{{baseUrl}}/invoices?limit={{limit}}" || len(list.Request.QueryParams) != 2 {
+		t.Errorf("unexpected list request %s %+v", list.URL, list.Request.QueryParams)
+	}
+	if create.Request.Headers[0].Value != "{{$uuid}}" || create.Request.Body.Type != domain.BodyTypeJSON {
+		t.Errorf("unexpected create request %+v", create.Request)
+	}
+	if create.Request.Auth.Type != domain.AuthTypeToken || create.Request.Auth.TokenAuth.Token != "{{auth.token}}" {
+		t.Errorf("unexpected auth %+v", create.Request.Auth)
+	}
+
+	if len(result.Environments) != 2 {
+		t.Fatalf("expected the base and the sub environments, got %d", len(result.Environments))
+	}
+	base, prod := result.Environments[0], result.Environments[1]
+	if base.MetaData.Name != "Billing" || prod.Spec.Extends != base.MetaData.ID || base.Spec.Values[0].Key != "auth.token" {
+		t.Errorf("unexpected environments %+v %+v", base, prod)
+	}
+
+	if len(result.Report.Items) != 1 || result.Report.Items[0].Reason != "hawk auth is not supported" {
+		t.Errorf("unexpected report %+v", result.Report.Items)
+	}
+}
@@ -0,0 +1,871 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v2"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const (
+	// baseURLVariable is the environment variable the urls of the imported requests start with.
+	baseURLVariable = "baseUrl"
+
+	// maxSchemaDepth limits the generation of examples for recursive schemas.
+	maxSchemaDepth = 8
+)
+
+var ErrNotOpenAPI = errors.New("document is not an OpenAPI 3 or Swagger 2 document")
+
+// openAPIMethods are the operations of a path item in the order they are imported.
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Swagger string `json:"swagger"`
+	Info    struct {
+		Title string `json:"title"`
+	} `json:"info"`
+	Tags []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+	Paths    map[string]map[string]json.RawMessage `json:"paths"`
+	Security []map[string][]string                 `json:"security"`
+
+	// OpenAPI 3
+	Servers    []openAPIServer `json:"servers"`
+	Components struct {
+		Schemas         map[string]*openAPISchema         `json:"schemas"`
+		Parameters      map[string]*openAPIParameter      `json:"parameters"`
+		RequestBodies   map[string]*openAPIRequestBody    `json:"requestBodies"`
+		SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+	} `json:"components"`
+
+	// Swagger 2
+	Host                string                            `json:"host"`
+	BasePath            string                            `json:"basePath"`
+	Schemes             []string                          `json:"schemes"`
+	Consumes            []string                          `json:"consumes"`
+	Definitions         map[string]*openAPISchema         `json:"definitions"`
+	Parameters          map[string]*openAPIParameter      `json:"parameters"`
+	SecurityDefinitions map[string]*openAPISecurityScheme `json:"securityDefinitions"`
+}
+
+type openAPIServer struct {
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Variables   map[string]struct {
+		Default string `json:"default"`
+	} `json:"variables"`
+}
+
+type openAPIOperation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary"`
+	Tags        []string               `json:"tags"`
+	Parameters  []*openAPIParameter    `json:"parameters"`
+	RequestBody *openAPIRequestBody    `json:"requestBody"`
+	Security    *[]map[string][]string `json:"security"`
+	Consumes    []string               `json:"consumes"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `json:"$ref"`
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Example  any            `json:"example"`
+	Schema   *openAPISchema `json:"schema"`
+
+	// Swagger 2 keeps the schema of non body parameters in the parameter itself.
+	Type    string `json:"type"`
+	Format  string `json:"format"`
+	Default any    `json:"default"`
+	Enum    []any  `json:"enum"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string                       `json:"$ref"`
+	Content map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema   *openAPISchema `json:"schema"`
+	Example  any            `json:"example"`
+	Examples map[string]struct {
+		Value any `json:"value"`
+	} `json:"examples"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       any                       `json:"type"`
+	Format     string                    `json:"format"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Items      *openAPISchema            `json:"items"`
+	Example    any                       `json:"example"`
+	Default    any                       `json:"default"`
+	Enum       []any                     `json:"enum"`
+	AllOf      []*openAPISchema          `json:"allOf"`
+	OneOf      []*openAPISchema          `json:"oneOf"`
+	AnyOf      []*openAPISchema          `json:"anyOf"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+	In     string `json:"in"`
+	Name   string `json:"name"`
+}
+
+// IsOpenAPI reports whether the data, JSON or YAML, is an OpenAPI 3 or Swagger 2 document.
+func IsOpenAPI(data []byte) bool {
+	doc, err := decodeOpenAPI(data)
+	return err == nil && (doc.OpenAPI != "" || doc.Swagger != "")
+}
+
+// ParseOpenAPI converts an OpenAPI 3 or Swagger 2 document, JSON or YAML, to a collection per tag
+// and an environment per server without saving them.
+func ParseOpenAPI(data []byte) (*Result, error) {
+	doc, err := decodeOpenAPI(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") && !strings.HasPrefix(doc.Swagger, "2.") {
+		return nil, ErrNotOpenAPI
+	}
+
+	title := doc.Info.Title
+	if title == "" {
+		title = "OpenAPI"
+	}
+
+	out := &Result{Environments: doc.environments(title), Report: &convert.Report{}}
+
+	groups := make(map[string][]*domain.Request)
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := doc.Paths[path]
+
+		var shared []*openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("failed to parse parameters of %s, %w", path, err)
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+
+			var op openAPIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("failed to parse %s %s, %w", strings.ToUpper(method), path, err)
+			}
+
+			tag := ""
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+			}
+			groups[tag] = append(groups[tag], doc.request(path, method, &op, shared))
+		}
+	}
+
+	for _, tag := range doc.tagOrder(groups) {
+		name := title
+		if tag != "" && (len(groups) > 1 || len(doc.Tags) > 0) {
+			name = fmt.Sprintf("%s - %s", title, tag)
+		}
+
+		col := domain.NewCollection(name)
+		for _, req := range groups[tag] {
+			req.CollectionID = col.MetaData.ID
+			req.CollectionName = col.MetaData.Name
+			col.AddRequest(req)
+		}
+		out.Collections = append(out.Collections, col)
+	}
+
+	return out, nil
+}
+
+// ImportOpenAPI saves the collections and environments of an OpenAPI 3 or Swagger 2 document to the active workspace.
+func ImportOpenAPI(data []byte) (*Result, error) {
+	result, err := ParseOpenAPI(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveResult(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ImportOpenAPIFromFile(filePath string) (*Result, error) {
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return ImportOpenAPI(fileContent)
+}
+
+// decodeOpenAPI reads the document as YAML, which covers JSON too.
+func decodeOpenAPI(data []byte) (*openAPIDocument, error) {
+	normalized, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(normalized, &doc); err != nil {
+		return nil, ErrNotOpenAPI
+	}
+	return &doc, nil
+}
+
+// yamlToJSON converts a YAML document, or a JSON one as JSON is valid YAML, to JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing document: %w", err)
+	}
+
+	// yaml decodes maps with interface keys which json can not encode
+	out, err := json.Marshal(normalizeYAML(raw))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing document: %w", err)
+	}
+	return out, nil
+}
+
+func normalizeYAML(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[fmt.Sprint(k)] = normalizeYAML(val)
+		}
+		return out
+	case []any:
+		for i := range v {
+			v[i] = normalizeYAML(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// tagOrder returns the tags in the order the document declares them, undeclared tags are sorted after them.
+func (d *openAPIDocument) tagOrder(groups map[string][]*domain.Request) []string {
+	out := make([]string, 0, len(groups))
+	seen := make(map[string]bool)
+	for _, t := range d.Tags {
+		if _, ok := groups[t.Name]; ok && !seen[t.Name] {
+			out = append(out, t.Name)
+			seen[t.Name] = true
+		}
+	}
+
+	rest := make([]string, 0)
+	for tag := range groups {
+		if !seen[tag] {
+			rest = append(rest, tag)
+		}
+	}
+	sort.Strings(rest)
+	return append(out, rest...)
+}
+
+// environments returns an environment per server with its url as the base url of the requests.
+func (d *openAPIDocument) environments(title string) []*domain.Environment {
+	type server struct{ name, url string }
+	var servers []server
+
+	if d.Swagger != "" {
+		if d.Host != "" {
+			scheme := "https"
+			if len(d.Schemes) > 0 {
+				scheme = d.Schemes[0]
+			}
+			servers = append(servers, server{url: scheme + "://" + d.Host + d.BasePath})
+		} else {
+			servers = append(servers, server{url: d.BasePath})
+		}
+	}
+
+	for _, s := range d.Servers {
+		u := s.URL
+		for name, v := range s.Variables {
+			u = strings.ReplaceAll(u, "{"+name+"}", v.Default)
+		}
+		servers = append(servers, server{name: s.Description, url: u})
+	}
+
+	if len(servers) == 0 {
+		servers = append(servers, server{})
+	}
+
+	secrets := d.secretVariables()
+	out := make([]*domain.Environment, 0, len(servers))
+	for i, s := range servers {
+		name := title
+		if len(servers) > 1 {
+			name = s.name
+			if name == "" {
+				name = fmt.Sprintf("%s %d", title, i+1)
+			}
+		}
+
+		env := domain.NewEnvironment(name)
+		env.Spec.Values = append(env.Spec.Values, domain.KeyValue{
+			ID:     uuid.NewString(),
+			Key:    baseURLVariable,
+			Value:  strings.TrimSuffix(s.url, "/"),
+			Enable: true,
+		})
+
+		for _, key := range secrets {
+			env.Spec.Values = append(env.Spec.Values, domain.KeyValue{
+				ID:     uuid.NewString(),
+				Key:    key,
+				Enable: true,
+				Secret: true,
+			})
+		}
+		out = append(out, env)
+	}
+
+	return out
+}
+
+func (d *openAPIDocument) securitySchemes() map[string]*openAPISecurityScheme {
+	if d.Swagger != "" {
+		return d.SecurityDefinitions
+	}
+	return d.Components.SecuritySchemes
+}
+
+// secretVariables returns the variables used by the authentication of the requests.
+func (d *openAPIDocument) secretVariables() []string {
+	seen := make(map[string]bool)
+	for _, s := range d.securitySchemes() {
+		switch auth := securitySchemeAuth(s); auth.Type {
+		case domain.AuthTypeBasic:
+			seen["username"] = true
+			seen["password"] = true
+		case domain.AuthTypeToken:
+			seen["token"] = true
+		default:
+			if s.Type == "apiKey" {
+				seen["apiKey"] = true
+			}
+		}
+	}
+
+	out := make([]string, 0, len(seen))
+	for key := range seen {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (d *openAPIDocument) request(path, method string, op *openAPIOperation, shared []*openAPIParameter) *domain.Request {
+	name := op.Summary
+	if name == "" {
+		name = op.OperationID
+	}
+	if name == "" {
+		name = strings.ToUpper(method) + " " + path
+	}
+
+	httpReq := &domain.HTTPRequest{
+		Headers:     make([]domain.KeyValue, 0),
+		PathParams:  make([]domain.KeyValue, 0),
+		QueryParams: make([]domain.KeyValue, 0),
+		Body:        domain.Body{Type: domain.BodyTypeNone},
+	}
+
+	req := &domain.Request{
+		ApiVersion: domain.ApiVersion,
+		Kind:       domain.KindRequest,
+		MetaData: domain.RequestMeta{
+			ID:   uuid.NewString(),
+			Name: name,
+			Type: domain.RequestTypeHTTP,
+		},
+		Spec: domain.RequestSpec{
+			HTTP: &domain.HTTPRequestSpec{
+				Method:  strings.ToUpper(method),
+				Request: httpReq,
+			},
+		},
+	}
+
+	// the query is written as is, escaping would break the variables
+	var query []string
+	for _, p := range d.parameters(shared, op.Parameters) {
+		value := d.parameterValue(p)
+		kv := domain.KeyValue{ID: uuid.NewString(), Key: p.Name, Value: value, Enable: p.Required || p.In == "path"}
+
+		switch p.In {
+		case "path":
+			httpReq.PathParams = append(httpReq.PathParams, kv)
+		case "query":
+			httpReq.QueryParams = append(httpReq.QueryParams, kv)
+			if kv.Enable {
+				query = append(query, kv.Key+"="+kv.Value)
+			}
+		case "header":
+			httpReq.Headers = append(httpReq.Headers, kv)
+		case "body":
+			d.setJSONBody(httpReq, d.example(p.Schema, 0))
+		}
+	}
+
+	if d.Swagger != "" {
+		d.setSwaggerFormBody(httpReq, op, d.parameters(shared, op.Parameters))
+	} else if op.RequestBody != nil {
+		d.setRequestBody(httpReq, d.resolveRequestBody(op.RequestBody))
+	}
+
+	security := d.Security
+	if op.Security != nil {
+		security = *op.Security
+	}
+	query = append(query, d.setAuth(httpReq, security)...)
+
+	req.Spec.HTTP.URL = "{{" + baseURLVariable + "}}" + path
+	if len(query) > 0 {
+		req.Spec.HTTP.URL += "?" + strings.Join(query, "&")
+	}
+
+	return req
+}
+
+// parameters merges the parameters of the path item with the ones of the operation, the operation ones win.
+func (d *openAPIDocument) parameters(shared, own []*openAPIParameter) []*openAPIParameter {
+	out := make([]*openAPIParameter, 0, len(shared)+len(own))
+	index := make(map[string]int)
+	for _, p := range append(append([]*openAPIParameter{}, shared...), own...) {
+		p = d.resolveParameter(p)
+		if p == nil || p.Name == "" {
+			continue
+		}
+
+		key := p.In + "/" + p.Name
+		if i, ok := index[key]; ok {
+			out[i] = p
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, p)
+	}
+	return out
+}
+
+func (d *openAPIDocument) resolveParameter(p *openAPIParameter) *openAPIParameter {
+	if p == nil || p.Ref == "" {
+		return p
+	}
+
+	name := refName(p.Ref)
+	if d.Swagger != "" {
+		return d.Parameters[name]
+	}
+	return d.Components.Parameters[name]
+}
+
+func (d *openAPIDocument) resolveRequestBody(b *openAPIRequestBody) *openAPIRequestBody {
+	if b == nil || b.Ref == "" {
+		return b
+	}
+	return d.Components.RequestBodies[refName(b.Ref)]
+}
+
+func (d *openAPIDocument) resolveSchema(s *openAPISchema) *openAPISchema {
+	if s == nil || s.Ref == "" {
+		return s
+	}
+
+	name := refName(s.Ref)
+	if d.Swagger != "" {
+		return d.Definitions[name]
+	}
+	return d.Components.Schemas[name]
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func (d *openAPIDocument) parameterValue(p *openAPIParameter) string {
+	for _, v := range []any{p.Example, p.Default} {
+		if v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	if len(p.Enum) > 0 {
+		return fmt.Sprint(p.Enum[0])
+	}
+
+	schema := p.Schema
+	if schema == nil && p.Type != "" {
+		schema = &openAPISchema{Type: p.Type, Format: p.Format}
+	}
+	if v := d.example(schema, 0); v != nil {
+		if _, ok := v.(map[string]any); !ok {
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+func (d *openAPIDocument) setRequestBody(httpReq *domain.HTTPRequest, body *openAPIRequestBody) {
+	if body == nil || len(body.Content) == 0 {
+		return
+	}
+
+	types := make([]string, 0, len(body.Content))
+	for t := range body.Content {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return mediaTypeRank(types[i]) < mediaTypeRank(types[j])
+	})
+
+	contentType := types[0]
+	media := body.Content[contentType]
+	if media == nil {
+		return
+	}
+
+	example := media.Example
+	if example == nil && len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		example = media.Examples[names[0]].Value
+	}
+	if example == nil {
+		example = d.example(media.Schema, 0)
+	}
+
+	switch {
+	case isJSONMediaType(contentType):
+		d.setJSONBody(httpReq, example)
+	case contentType == "application/x-www-form-urlencoded":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: formValues(example)}
+	case contentType == "multipart/form-data":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeFormData, FormData: d.formData(media.Schema, example)}
+	case strings.Contains(contentType, "xml"):
+		httpReq.Body = domain.Body{Type: domain.BodyTypeXML, Data: stringExample(example)}
+	default:
+		httpReq.Body = domain.Body{Type: domain.BodyTypeText, Data: stringExample(example)}
+	}
+
+	if contentType != "multipart/form-data" {
+		httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: "Content-Type", Value: contentType, Enable: true})
+	}
+}
+
+// setSwaggerFormBody sets the body of the Swagger 2 operations which send formData parameters.
+func (d *openAPIDocument) setSwaggerFormBody(httpReq *domain.HTTPRequest, op *openAPIOperation, params []*openAPIParameter) {
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = d.Consumes
+	}
+	multipart := false
+	for _, c := range consumes {
+		if c == "multipart/form-data" {
+			multipart = true
+		}
+	}
+
+	var urlencoded []domain.KeyValue
+	var fields []domain.FormField
+	for _, p := range params {
+		if p.In != "formData" {
+			continue
+		}
+
+		if multipart || p.Type == "file" {
+			fieldType := domain.FormFieldTypeText
+			if p.Type == "file" {
+				fieldType = domain.FormFieldTypeFile
+			}
+			fields = append(fields, domain.FormField{ID: uuid.NewString(), Type: fieldType, Key: p.Name, Value: d.parameterValue(p), Enable: true})
+			continue
+		}
+		urlencoded = append(urlencoded, domain.KeyValue{ID: uuid.NewString(), Key: p.Name, Value: d.parameterValue(p), Enable: true})
+	}
+
+	switch {
+	case len(fields) > 0:
+		httpReq.Body = domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: append(fields, formFieldsFromKeyValues(urlencoded)...)}}
+	case len(urlencoded) > 0:
+		httpReq.Body = domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: urlencoded}
+		httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: "Content-Type", Value: "application/x-www-form-urlencoded", Enable: true})
+	}
+}
+
+func formFieldsFromKeyValues(values []domain.KeyValue) []domain.FormField {
+	out := make([]domain.FormField, 0, len(values))
+	for _, v := range values {
+		out = append(out, domain.FormField{ID: v.ID, Type: domain.FormFieldTypeText, Key: v.Key, Value: v.Value, Enable: v.Enable})
+	}
+	return out
+}
+
+func (d *openAPIDocument) setJSONBody(httpReq *domain.HTTPRequest, example any) {
+	data := ""
+	if example != nil {
+		if b, err := json.MarshalIndent(example, "", "  "); err == nil {
+			data = string(b)
+		}
+	}
+
+	httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: data}
+	if d.Swagger != "" {
+		httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: "Content-Type", Value: "application/json", Enable: true})
+	}
+}
+
+func (d *openAPIDocument) formData(schema *openAPISchema, example any) domain.FormData {
+	schema = d.resolveSchema(schema)
+	values, _ := example.(map[string]any)
+
+	out := domain.FormData{Fields: make([]domain.FormField, 0)}
+	if schema == nil {
+		return out
+	}
+
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := d.resolveSchema(schema.Properties[name])
+		field := domain.FormField{ID: uuid.NewString(), Type: domain.FormFieldTypeText, Key: name, Enable: true}
+		if prop != nil && prop.Format == "binary" {
+			field.Type = domain.FormFieldTypeFile
+		} else if v, ok := values[name]; ok && v != nil {
+			field.Value = fmt.Sprint(v)
+		}
+		out.Fields = append(out.Fields, field)
+	}
+	return out
+}
+
+func formValues(example any) []domain.KeyValue {
+	values, _ := example.(map[string]any)
+	out := make([]domain.KeyValue, 0, len(values))
+	for _, name := range sortedKeys(values) {
+		out = append(out, domain.KeyValue{ID: uuid.NewString(), Key: name, Value: fmt.Sprint(values[name]), Enable: true})
+	}
+	return out
+}
+
+func stringExample(example any) string {
+	switch v := example.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+func isJSONMediaType(t string) bool {
+	return t == "application/json" || strings.HasSuffix(t, "+json")
+}
+
+// mediaTypeRank orders the media types of a body by preference.
+func mediaTypeRank(t string) int {
+	switch {
+	case t == "application/json":
+		return 0
+	case isJSONMediaType(t):
+		return 1
+	case t == "application/x-www-form-urlencoded":
+		return 2
+	case t == "multipart/form-data":
+		return 3
+	case strings.Contains(t, "xml"):
+		return 4
+	default:
+		return 5
+	}
+}
+
+// setAuth maps the first security requirement to the auth of the request, api keys which are not sent
+// in a header are added to the cookies or the query, the returned query should be added to the url.
+func (d *openAPIDocument) setAuth(httpReq *domain.HTTPRequest, security []map[string][]string) []string {
+	httpReq.Auth = domain.Auth{Type: domain.AuthTypeNone}
+	if len(security) == 0 {
+		return nil
+	}
+
+	schemes := d.securitySchemes()
+	for _, name := range sortedKeys(security[0]) {
+		scheme, ok := schemes[name]
+		if !ok || scheme == nil {
+			continue
+		}
+
+		if scheme.Type == "apiKey" && scheme.In == "query" {
+			httpReq.QueryParams = append(httpReq.QueryParams, domain.KeyValue{ID: uuid.NewString(), Key: scheme.Name, Value: "{{apiKey}}", Enable: true})
+			return []string{scheme.Name + "={{apiKey}}"}
+		}
+
+		if scheme.Type == "apiKey" && scheme.In == "cookie" {
+			httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: "Cookie", Value: scheme.Name + "={{apiKey}}", Enable: true})
+			return nil
+		}
+
+		if auth := securitySchemeAuth(scheme); auth.Type != domain.AuthTypeNone {
+			httpReq.Auth = auth
+			return nil
+		}
+	}
+	return nil
+}
+
+// securitySchemeAuth returns the auth of the scheme, the credentials are environment variables.
+func securitySchemeAuth(s *openAPISecurityScheme) domain.Auth {
+	switch {
+	case s == nil:
+		return domain.Auth{Type: domain.AuthTypeNone}
+	case s.Type == "basic", s.Type == "http" && strings.EqualFold(s.Scheme, "basic"):
+		return domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: "{{username}}", Password: "{{password}}"}}
+	case s.Type == "http" && strings.EqualFold(s.Scheme, "bearer"), s.Type == "oauth2", s.Type == "openIdConnect":
+		return domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: "{{token}}"}}
+	case s.Type == "apiKey" && s.In == "header":
+		return domain.Auth{Type: domain.AuthTypeAPIKey, APIKeyAuth: &domain.APIKeyAuth{Key: s.Name, Value: "{{apiKey}}"}}
+	default:
+		return domain.Auth{Type: domain.AuthTypeNone}
+	}
+}
+
+// example returns the example of the schema or one generated from its type.
+func (d *openAPIDocument) example(schema *openAPISchema, depth int) any {
+	schema = d.resolveSchema(schema)
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		out := make(map[string]any)
+		for _, s := range schema.AllOf {
+			if m, ok := d.example(s, depth+1).(map[string]any); ok {
+				for k, v := range m {
+					out[k] = v
+				}
+			}
+		}
+		return out
+	case len(schema.OneOf) > 0:
+		return d.example(schema.OneOf[0], depth+1)
+	case len(schema.AnyOf) > 0:
+		return d.example(schema.AnyOf[0], depth+1)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		out := make(map[string]any, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			out[name] = d.example(prop, depth+1)
+		}
+		return out
+	case "array":
+		if item := d.example(schema.Items, depth+1); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		return stringFormatExample(schema.Format)
+	default:
+		return nil
+	}
+}
+
+// schemaType returns the type of the schema, the first non null one when it is a list.
+func schemaType(s *openAPISchema) string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if str, ok := v.(string); ok && str != "null" {
+				return str
+			}
+		}
+	}
+
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+	if s.Items != nil {
+		return "array"
+	}
+	return ""
+}
+
+func stringFormatExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
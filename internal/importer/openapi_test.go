@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const petStoreV3 = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://{region}.example.com/v1
+    description: Production
+    variables:
+      region:
+        default: eu
+  - url: http://localhost:8080/v1
+    description: Local
+tags:
+  - name: pets
+security:
+  - bearer: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      tags: [pets]
+      summary: Get a pet
+      parameters:
+        - name: verbose
+          in: query
+          required: true
+          schema:
+            type: boolean
+        - $ref: '#/components/parameters/TraceID'
+  /pets:
+    post:
+      tags: [pets]
+      operationId: createPet
+      security:
+        - apiKey: []
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+  /health:
+    get:
+      summary: Health
+      security: []
+components:
+  parameters:
+    TraceID:
+      name: X-Trace-ID
+      in: header
+      example: abc
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          example: rex
+        tags:
+          type: array
+          items:
+            type: string
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+`
+
+const petStoreV2 = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy"},
+  "host": "api.example.com",
+  "basePath": "/v2",
+  "schemes": ["http"],
+  "securityDefinitions": {"basic": {"type": "basic"}},
+  "security": [{"basic": []}],
+  "paths": {
+    "/upload": {
+      "post": {
+        "summary": "Upload",
+        "consumes": ["multipart/form-data"],
+        "parameters": [
+          {"name": "file", "in": "formData", "type": "file"},
+          {"name": "note", "in": "formData", "type": "string", "default": "hi"}
+        ]
+      }
+    }
+  }
+}`
+
+func TestParseOpenAPI_V3(t *testing.T) {
+	result, err := ParseOpenAPI([]byte(petStoreV3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Environments) != 2 {
+		t.Fatalf("expected an environment per server, got %d", len(result.Environments))
+	}
+	if env := result.Environments[0]; env.MetaData.Name != "Production" || env.Spec.Values[0].Value != "https://eu.example.com/v1" {
+		t.Errorf("unexpected environment %s = %+v", env.MetaData.Name, env.Spec.Values)
+	}
+
+	names := make([]string, 0, len(result.Collections))
+	for _, col := range result.Collections {
+		names = append(names, col.MetaData.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Pet Store - pets", "Pet Store"}) {
+		t.Fatalf("unexpected collections %v", names)
+	}
+
+	pets := result.Collections[0].Spec.Requests
+	if len(pets) != 2 {
+		t.Fatalf("expected 2 pets requests, got %d", len(pets))
+	}
+
+	create, get := pets[0].Spec.HTTP, pets[1].Spec.HTTP
+	if create.Method != domain.RequestMethodPOST || create.URL != "{{baseUrl}}/pets" {
+		t.Errorf("unexpected create request %s %s", create.Method, create.URL)
+	}
+	if create.Request.Body.Type != domain.BodyTypeJSON || create.Request.Body.Data != "{\n  \"name\": \"rex\",\n  \"tags\": [\n    \"string\"\n  ]\n}" {
+		t.Errorf("unexpected create body %+v", create.Request.Body)
+	}
+	if auth := create.Request.Auth; auth.Type != domain.AuthTypeAPIKey || auth.APIKeyAuth.Key != "X-API-Key" {
+		t.Errorf("unexpected create auth %+v", auth)
+	}
+
+	if get.URL != "{{baseUrl}}/pets/{petId}?verbose=false" {
+		t.Errorf("unexpected get url %s", get.URL)
+	}
+	if len(get.Request.PathParams) != 1 || get.Request.PathParams[0].Key != "petId" {
+		t.Errorf("unexpected path params %+v", get.Request.PathParams)
+	}
+	if len(get.Request.Headers) != 1 || get.Request.Headers[0].Key != "X-Trace-ID" || get.Request.Headers[0].Value != "abc" {
+		t.Errorf("unexpected headers %+v", get.Request.Headers)
+	}
+	if get.Request.Auth.Type != domain.AuthTypeToken || get.Request.Auth.TokenAuth.Token != "{{token}}" {
+		t.Errorf("unexpected get auth %+v", get.Request.Auth)
+	}
+
+	if health := result.Collections[1].Spec.Requests[0].Spec.HTTP; health.Request.Auth.Type != domain.AuthTypeNone {
+		t.Errorf("expected the empty security to disable auth, got %+v", health.Request.Auth)
+	}
+}
+
+func TestParseOpenAPI_V2(t *testing.T) {
+	result, err := ParseOpenAPI([]byte(petStoreV2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if url := result.Environments[0].Spec.Values[0].Value; url != "http://api.example.com/v2" {
+		t.Errorf("unexpected base url %s", url)
+	}
+
+	upload := result.Collections[0].Spec.Requests[0].Spec.HTTP.Request
+	if upload.Body.Type != domain.BodyTypeFormData || len(upload.Body.FormData.Fields) != 2 {
+		t.Fatalf("unexpected body %+v", upload.Body)
+	}
+	if f := upload.Body.FormData.Fields[0]; f.Type != domain.FormFieldTypeFile || f.Key != "file" {
+		t.Errorf("unexpected file field %+v", f)
+	}
+	if upload.Auth.Type != domain.AuthTypeBasic {
+		t.Errorf("unexpected auth %+v", upload.Auth)
+	}
+}
+
+func TestParseOpenAPI_NotOpenAPI(t *testing.T) {
+	if _, err := ParseOpenAPI([]byte(`{"info": {"name": "postman"}, "item": []}`)); err != ErrNotOpenAPI {
+		t.Errorf("expected ErrNotOpenAPI, got %v", err)
+	}
+}
@@ -0,0 +1,673 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/repository"
+)
+
+// PostmanCollection represents the structure of a Postman v2.0 or v2.1 exported JSON
+type PostmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []RequestItem     `json:"item"`
+	Auth     *PostmanAuth      `json:"auth"`
+	Variable []PostmanVariable `json:"variable"`
+	Event    []PostmanEvent    `json:"event"`
+}
+
+type RequestItem struct {
+	Name string `json:"name"`
+	// if request is a folder, it will have an item array
+	Item     []RequestItem     `json:"item,omitempty"`
+	Request  *PostmanRequest   `json:"request,omitempty"`
+	Response []json.RawMessage `json:"response,omitempty"`
+
+	// folders have their own auth, variables and scripts
+	Auth     *PostmanAuth      `json:"auth,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+	Event    []PostmanEvent    `json:"event,omitempty"`
+}
+
+func (i RequestItem) isFolder() bool {
+	return i.Request == nil && i.Item != nil
+}
+
+type PostmanRequest struct {
+	Method string         `json:"method"`
+	Header postmanHeaders `json:"header"`
+	Body   *PostmanBody   `json:"body"`
+	URL    PostmanURL     `json:"url"`
+	Auth   *PostmanAuth   `json:"auth"`
+}
+
+// UnmarshalJSON accepts the short form of a request which is only its url.
+func (r *PostmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		r.Method = domain.RequestMethodGET
+		r.URL.Raw = raw
+		return nil
+	}
+
+	type request PostmanRequest
+	return json.Unmarshal(data, (*request)(r))
+}
+
+type PostmanHeader struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+type postmanHeaders []PostmanHeader
+
+// UnmarshalJSON accepts the headers as a list or as a block of "Key: Value" lines.
+func (h *postmanHeaders) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		for _, line := range strings.Split(raw, "\n") {
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				*h = append(*h, PostmanHeader{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+			}
+		}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]PostmanHeader)(h))
+}
+
+type PostmanBody struct {
+	Mode       string         `json:"mode"`
+	Raw        string         `json:"raw"`
+	URLEncoded []PostmanParam `json:"urlencoded"`
+	FormData   []PostmanParam `json:"formdata"`
+	File       *struct {
+		Src string `json:"src"`
+	} `json:"file"`
+	GraphQL *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+type PostmanParam struct {
+	Key      string         `json:"key"`
+	Value    string         `json:"value"`
+	Type     string         `json:"type"`
+	Src      postmanStrings `json:"src"`
+	Disabled bool           `json:"disabled"`
+}
+
+type PostmanURL struct {
+	Raw      string              `json:"raw"`
+	Protocol string              `json:"protocol"`
+	Host     postmanStrings      `json:"host"`
+	Port     string              `json:"port"`
+	Path     postmanStrings      `json:"path"`
+	Query    []PostmanQueryParam `json:"query"`
+	Variable []PostmanVariable   `json:"variable"`
+}
+
+// UnmarshalJSON accepts the url as a string too.
+func (u *PostmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+
+	type url PostmanURL
+	return json.Unmarshal(data, (*url)(u))
+}
+
+type PostmanQueryParam struct {
+	Key      *string `json:"key"`
+	Value    *string `json:"value"`
+	Disabled bool    `json:"disabled"`
+}
+
+type PostmanVariable struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+type PostmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec postmanStrings `json:"exec"`
+	} `json:"script"`
+	Disabled bool `json:"disabled"`
+}
+
+// PostmanAuth keeps the attributes of the auth type, Postman v2.1 lists them as key values while v2.0 uses an object.
+type PostmanAuth struct {
+	Type       string
+	Attributes map[string]string
+}
+
+func (a *PostmanAuth) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw["type"], &a.Type); err != nil {
+		return err
+	}
+
+	a.Attributes = make(map[string]string)
+	attrs, ok := raw[a.Type]
+	if !ok {
+		return nil
+	}
+
+	var list []struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}
+	if err := json.Unmarshal(attrs, &list); err == nil {
+		for _, attr := range list {
+			a.Attributes[attr.Key] = stringValue(attr.Value)
+		}
+		return nil
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(attrs, &obj); err != nil {
+		return err
+	}
+	for k, v := range obj {
+		a.Attributes[k] = stringValue(v)
+	}
+	return nil
+}
+
+// postmanStrings accepts a string, a list of strings or of {"value": ""} objects.
+type postmanStrings []string
+
+func (s *postmanStrings) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch v := raw.(type) {
+	case string:
+		*s = postmanStrings{v}
+	case []any:
+		for _, item := range v {
+			if obj, ok := item.(map[string]any); ok {
+				item = obj["value"]
+			}
+			*s = append(*s, stringValue(item))
+		}
+	}
+	return nil
+}
+
+func stringValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+// postmanConverter converts the items of a collection and records what it can not convert.
+type postmanConverter struct {
+	report *convert.Report
+}
+
+// ParsePostmanCollection converts a Postman collection without saving it. The top level folders become
+// collections of their own, deeper folders are flattened into the names of their requests, and the
+// collection variables become an environment.
+func ParsePostmanCollection(data []byte) (*Result, error) {
+	var collection PostmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "/v2.") {
+		return nil, fmt.Errorf("unsupported postman collection schema %s, export the collection as v2.1", collection.Info.Schema)
+	}
+
+	name := collection.Info.Name
+	if name == "" {
+		name = "Postman"
+	}
+
+	c := &postmanConverter{report: &convert.Report{}}
+	out := &Result{Report: c.report}
+
+	c.checkAuth(name, collection.Auth)
+	c.checkEvents(name, collection.Event)
+
+	root := domain.NewCollection(name)
+	var folders []*domain.Collection
+	for _, item := range collection.Item {
+		if !item.isFolder() {
+			c.addItem(root, item, "", collection.Auth, name)
+			continue
+		}
+
+		folder := domain.NewCollection(name + " - " + item.Name)
+		path := name + " / " + item.Name
+		c.checkAuth(path, item.Auth)
+		c.checkEvents(path, item.Event)
+		c.checkVariables(path, item.Variable)
+		for _, sub := range item.Item {
+			c.addItem(folder, sub, "", inheritAuth(item.Auth, collection.Auth), path)
+		}
+		folders = append(folders, folder)
+	}
+
+	if len(root.Spec.Requests) > 0 || len(folders) == 0 {
+		out.Collections = append(out.Collections, root)
+	}
+	out.Collections = append(out.Collections, folders...)
+
+	if len(collection.Variable) > 0 {
+		env := domain.NewEnvironment(name)
+		env.Spec.Values = postmanKeyValues(collection.Variable)
+		out.Environments = append(out.Environments, env)
+	}
+
+	return out, nil
+}
+
+// addItem adds the request, or the requests of the folder, to the collection. prefix is the names of the parent folders.
+func (c *postmanConverter) addItem(col *domain.Collection, item RequestItem, prefix string, auth *PostmanAuth, path string) {
+	path = path + " / " + item.Name
+	c.checkEvents(path, item.Event)
+
+	if item.isFolder() {
+		c.checkAuth(path, item.Auth)
+		c.checkVariables(path, item.Variable)
+		for _, sub := range item.Item {
+			c.addItem(col, sub, prefix+item.Name+" - ", inheritAuth(item.Auth, auth), path)
+		}
+		return
+	}
+
+	if item.Request == nil {
+		c.report.Add(path, "item has neither a request nor items")
+		return
+	}
+
+	c.checkAuth(path, item.Request.Auth)
+	if len(item.Response) > 0 {
+		c.report.Add(path, "%d saved responses are not imported", len(item.Response))
+	}
+
+	req := c.request(item.Request, inheritAuth(item.Request.Auth, auth), path)
+	req.MetaData.Name = prefix + item.Name
+	req.CollectionID = col.MetaData.ID
+	req.CollectionName = col.MetaData.Name
+	col.AddRequest(req)
+}
+
+// inheritAuth returns the auth of the item, or the auth of its parent when the item has none.
+func inheritAuth(own, parent *PostmanAuth) *PostmanAuth {
+	if own == nil || own.Type == "inherit" {
+		return parent
+	}
+	return own
+}
+
+func (c *postmanConverter) request(r *PostmanRequest, auth *PostmanAuth, path string) *domain.Request {
+	method := strings.ToUpper(r.Method)
+	if method == "" {
+		method = domain.RequestMethodGET
+	}
+
+	httpReq := &domain.HTTPRequest{
+		Headers:     make([]domain.KeyValue, 0, len(r.Header)),
+		PathParams:  make([]domain.KeyValue, 0),
+		QueryParams: make([]domain.KeyValue, 0),
+		Body:        domain.Body{Type: domain.BodyTypeNone},
+	}
+
+	for _, h := range r.Header {
+		httpReq.Headers = append(httpReq.Headers, domain.KeyValue{ID: uuid.NewString(), Key: h.Key, Value: h.Value, Enable: !h.Disabled})
+	}
+
+	base, query := splitPostmanURL(r.URL)
+	base, names := convertPathVariables(base)
+
+	values := make(map[string]string)
+	for _, v := range r.URL.Variable {
+		values[v.Key] = stringValue(v.Value)
+	}
+	for _, name := range names {
+		httpReq.PathParams = append(httpReq.PathParams, domain.KeyValue{ID: uuid.NewString(), Key: name, Value: values[name], Enable: true})
+	}
+
+	httpReq.QueryParams = append(query, c.setAuth(httpReq, auth)...)
+	c.setBody(httpReq, r.Body, path)
+
+	return &domain.Request{
+		ApiVersion: domain.ApiVersion,
+		Kind:       domain.KindRequest,
+		MetaData: domain.RequestMeta{
+			ID:   uuid.NewString(),
+			Type: domain.RequestTypeHTTP,
+		},
+		Spec: domain.RequestSpec{
+			HTTP: &domain.HTTPRequestSpec{
+				Method:  method,
+				URL:     joinQuery(base, httpReq.QueryParams),
+				Request: httpReq,
+			},
+		},
+	}
+}
+
+// splitPostmanURL returns the url without its query and the query params, disabled params included.
+func splitPostmanURL(u PostmanURL) (string, []domain.KeyValue) {
+	raw := u.Raw
+	if raw == "" {
+		if u.Protocol != "" {
+			raw = u.Protocol + "://"
+		}
+		raw += strings.Join(u.Host, ".")
+		if u.Port != "" {
+			raw += ":" + u.Port
+		}
+		if len(u.Path) > 0 {
+			raw += "/" + strings.Join(u.Path, "/")
+		}
+	}
+
+	if len(u.Query) == 0 {
+		return splitURL(raw)
+	}
+
+	base, _, _ := strings.Cut(raw, "?")
+	base, _, _ = strings.Cut(base, "#")
+
+	query := make([]domain.KeyValue, 0, len(u.Query))
+	for _, q := range u.Query {
+		kv := domain.KeyValue{ID: uuid.NewString(), Enable: !q.Disabled}
+		if q.Key != nil {
+			kv.Key = *q.Key
+		}
+		if q.Value != nil {
+			kv.Value = *q.Value
+		}
+		query = append(query, kv)
+	}
+	return base, query
+}
+
+// splitURL returns the url without its query and fragment, and the params of the query.
+func splitURL(raw string) (string, []domain.KeyValue) {
+	base, rawQuery, _ := strings.Cut(raw, "?")
+	base, _, _ = strings.Cut(base, "#")
+	rawQuery, _, _ = strings.Cut(rawQuery, "#")
+
+	query := make([]domain.KeyValue, 0)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		query = append(query, domain.KeyValue{ID: uuid.NewString(), Key: key, Value: value, Enable: true})
+	}
+	return base, query
+}
+
+// joinQuery appends the enabled query params to the url, they are written as is as escaping would break the variables.
+func joinQuery(base string, query []domain.KeyValue) string {
+	enabled := make([]string, 0, len(query))
+	for _, q := range query {
+		if q.Enable {
+			enabled = append(enabled, q.Key+"="+q.Value)
+		}
+	}
+	if len(enabled) == 0 {
+		return base
+	}
+	return base + "?" + strings.Join(enabled, "&")
+}
+
+// convertPathVariables replaces the :name segments of the path by {name} and returns the names.
+func convertPathVariables(u string) (string, []string) {
+	start := 0
+	if i := strings.Index(u, "://"); i >= 0 {
+		start = i + len("://")
+	}
+
+	slash := strings.Index(u[start:], "/")
+	if slash < 0 {
+		return u, nil
+	}
+	start += slash
+
+	var names []string
+	segments := strings.Split(u[start:], "/")
+	for i, s := range segments {
+		if len(s) > 1 && s[0] == ':' {
+			names = append(names, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return u[:start] + strings.Join(segments, "/"), names
+}
+
+func (c *postmanConverter) setBody(httpReq *domain.HTTPRequest, b *PostmanBody, path string) {
+	if b == nil || b.Disabled {
+		return
+	}
+
+	switch b.Mode {
+	case "":
+	case "raw":
+		httpReq.Body = domain.Body{Type: rawBodyType(b.Options.Raw.Language, b.Raw), Data: b.Raw}
+	case "urlencoded":
+		values := make([]domain.KeyValue, 0, len(b.URLEncoded))
+		for _, p := range b.URLEncoded {
+			values = append(values, domain.KeyValue{ID: uuid.NewString(), Key: p.Key, Value: p.Value, Enable: !p.Disabled})
+		}
+		httpReq.Body = domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: values}
+	case "formdata":
+		fields := make([]domain.FormField, 0, len(b.FormData))
+		for _, p := range b.FormData {
+			field := domain.FormField{ID: uuid.NewString(), Type: domain.FormFieldTypeText, Key: p.Key, Value: p.Value, Enable: !p.Disabled}
+			if p.Type == "file" {
+				field.Type = domain.FormFieldTypeFile
+				field.Files = []string(p.Src)
+				if len(p.Src) == 0 {
+					c.report.Add(path, "form field %s has no file selected", p.Key)
+				}
+			}
+			fields = append(fields, field)
+		}
+		httpReq.Body = domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: fields}}
+	case "file":
+		httpReq.Body = domain.Body{Type: domain.BodyTypeBinary}
+		if b.File != nil {
+			httpReq.Body.BinaryFilePath = b.File.Src
+		}
+		if httpReq.Body.BinaryFilePath == "" {
+			c.report.Add(path, "binary body has no file selected")
+		}
+	case "graphql":
+		// graphql requests are sent as json
+		body := map[string]any{"query": ""}
+		if b.GraphQL != nil {
+			body["query"] = b.GraphQL.Query
+			var vars any
+			if err := json.Unmarshal([]byte(b.GraphQL.Variables), &vars); err == nil {
+				body["variables"] = vars
+			}
+		}
+		data, _ := json.MarshalIndent(body, "", "  ")
+		httpReq.Body = domain.Body{Type: domain.BodyTypeJSON, Data: string(data)}
+	default:
+		c.report.Add(path, "body mode %s is not supported", b.Mode)
+	}
+}
+
+func rawBodyType(language, data string) string {
+	switch language {
+	case "json":
+		return domain.BodyTypeJSON
+	case "xml":
+		return domain.BodyTypeXML
+	case "":
+		if json.Valid([]byte(data)) {
+			return domain.BodyTypeJSON
+		}
+	}
+	return domain.BodyTypeText
+}
+
+// setAuth sets the auth of the request and returns the query params the auth is sent with.
+func (c *postmanConverter) setAuth(httpReq *domain.HTTPRequest, a *PostmanAuth) []domain.KeyValue {
+	httpReq.Auth = domain.Auth{Type: domain.AuthTypeNone}
+	if a == nil {
+		return nil
+	}
+
+	attrs := a.Attributes
+	switch a.Type {
+	case "basic":
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeBasic, BasicAuth: &domain.BasicAuth{Username: attrs["username"], Password: attrs["password"]}}
+	case "bearer":
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: attrs["token"]}}
+	case "oauth2":
+		if attrs["addTokenTo"] == "queryParams" {
+			return []domain.KeyValue{{ID: uuid.NewString(), Key: "access_token", Value: attrs["accessToken"], Enable: true}}
+		}
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeToken, TokenAuth: &domain.TokenAuth{Token: attrs["accessToken"]}}
+	case "apikey":
+		if attrs["in"] == "query" {
+			return []domain.KeyValue{{ID: uuid.NewString(), Key: attrs["key"], Value: attrs["value"], Enable: true}}
+		}
+		httpReq.Auth = domain.Auth{Type: domain.AuthTypeAPIKey, APIKeyAuth: &domain.APIKeyAuth{Key: attrs["key"], Value: attrs["value"]}}
+	}
+	return nil
+}
+
+// checkAuth reports the auth types which setAuth can not convert, at the level they are set.
+func (c *postmanConverter) checkAuth(path string, a *PostmanAuth) {
+	if a == nil {
+		return
+	}
+
+	switch a.Type {
+	case "noauth", "inherit", "basic", "bearer", "apikey":
+	case "oauth2":
+		if a.Attributes["accessToken"] == "" {
+			c.report.Add(path, "oauth2 auth has no access token, set the token after fetching one")
+		}
+	default:
+		c.report.Add(path, "%s auth is not supported", a.Type)
+	}
+}
+
+func (c *postmanConverter) checkEvents(path string, events []PostmanEvent) {
+	for _, e := range events {
+		if e.Disabled || strings.TrimSpace(strings.Join(e.Script.Exec, "")) == "" {
+			continue
+		}
+
+		if e.Listen == "prerequest" {
+			c.report.Add(path, "pre-request script is not imported")
+		} else {
+			c.report.Add(path, "%s script is not imported", e.Listen)
+		}
+	}
+}
+
+func (c *postmanConverter) checkVariables(path string, vars []PostmanVariable) {
+	if len(vars) > 0 {
+		c.report.Add(path, "%d folder variables are not imported", len(vars))
+	}
+}
+
+func postmanKeyValues(vars []PostmanVariable) []domain.KeyValue {
+	out := make([]domain.KeyValue, 0, len(vars))
+	for _, v := range vars {
+		out = append(out, domain.KeyValue{
+			ID:     uuid.NewString(),
+			Key:    v.Key,
+			Value:  stringValue(v.Value),
+			Enable: !v.Disabled,
+			Secret: v.Type == "secret",
+		})
+	}
+	return out
+}
+
+// ImportPostmanCollection saves the collections and the environment of a Postman collection to the active workspace.
+func ImportPostmanCollection(data []byte) (*Result, error) {
+	result, err := ParsePostmanCollection(data)
+	if err != nil {
+		return nil, err
+	}
+
+	filesystem, err := repository.NewFilesystem()
+	if err != nil {
+		return nil, fmt.Errorf("error creating filesystem: %w", err)
+	}
+
+	for _, col := range result.Collections {
+		if err := saveCollection(filesystem, col); err != nil {
+			return nil, err
+		}
+
+		for _, req := range col.Spec.Requests {
+			// Replace variables in the request file
+			if err := findAndReplaceVariables(req.FilePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, env := range result.Environments {
+		if err := saveEnvironment(filesystem, env, result.Report); err != nil {
+			return nil, err
+		}
+
+		if err := findAndReplaceVariables(env.FilePath); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func ImportPostmanCollectionFromFile(filePath string) (*Result, error) {
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return ImportPostmanCollection(fileContent)
+}
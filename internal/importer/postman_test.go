@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const postmanCollection = `{
+  "info": {
+    "name": "Shop",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [
+    {"key": "baseUrl", "value": "https://shop.example.com"},
+    {"key": "token", "value": "", "type": "secret"}
+  ],
+  "item": [
+    {"name": "Ping", "request": "{{baseUrl}}/ping"},
+    {
+      "name": "Orders",
+      "event": [{"listen": "prerequest", "script": {"exec": ["console.log(1)"]}}],
+      "item": [
+        {
+          "name": "Get order",
+          "request": {
+            "method": "GET",
+            "header": [
+              {"key": "Accept", "value": "application/json"},
+              {"key": "X-Debug", "value": "1", "disabled": true}
+            ],
+            "url": {
+              "raw": "{{baseUrl}}/orders/:id?tag=a&tag=b",
+              "host": ["{{baseUrl}}"],
+              "path": ["orders", ":id"],
+              "query": [
+                {"key": "tag", "value": "a"},
+                {"key": "tag", "value": "b"},
+                {"key": "page", "value": "2", "disabled": true}
+              ],
+              "variable": [{"key": "id", "value": "42"}]
+            }
+          },
+          "response": [{}]
+        },
+        {
+          "name": "Items",
+          "item": [
+            {
+              "name": "Upload",
+              "request": {
+                "method": "POST",
+                "auth": {"type": "apikey", "apikey": [
+                  {"key": "key", "value": "X-Key"},
+                  {"key": "value", "value": "secret"},
+                  {"key": "in", "value": "header"}
+                ]},
+                "body": {
+                  "mode": "formdata",
+                  "formdata": [
+                    {"key": "file", "type": "file", "src": "/tmp/a.png"},
+                    {"key": "note", "value": "hi", "type": "text"}
+                  ]
+                },
+                "url": "{{baseUrl}}/items"
+              }
+            },
+            {
+              "name": "Sign",
+              "request": {
+                "method": "POST",
+                "auth": {"type": "digest", "digest": []},
+                "body": {"mode": "urlencoded", "urlencoded": [{"key": "a", "value": "1"}]},
+                "url": "{{baseUrl}}/sign"
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestParsePostmanCollection(t *testing.T) {
+	result, err := ParsePostmanCollection([]byte(postmanCollection))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Collections) != 2 {
+		t.Fatalf("expected the root and the folder collections, got %d", len(result.Collections))
+	}
+
+	root, orders := result.Collections[0], result.Collections[1]
+	if root.MetaData.Name != "Shop" || orders.MetaData.Name != "Shop - Orders" {
+		t.Errorf("unexpected collections %s, %s", root.MetaData.Name, orders.MetaData.Name)
+	}
+
+	ping := root.Spec.Requests[0].Spec.HTTP
+	if ping.Method != domain.RequestMethodGET || ping.URL != "{{baseUrl}}/ping" || ping.Request.Auth.Type != domain.AuthTypeToken {
+		t.Errorf("unexpected ping request %s %s %+v", ping.Method, ping.URL, ping.Request.Auth)
+	}
+
+	names := make([]string, 0)
+	for _, req := range orders.Spec.Requests {
+		names = append(names, req.MetaData.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Get order", "Items - Upload", "Items - Sign"}) {
+		t.Fatalf("unexpected requests %v", names)
+	}
+
+	get := orders.Spec.Requests[0].Spec.HTTP
+	if get.URL != "{{baseUrl}}/orders/{id}?tag=a&tag=b" {
+		t.Errorf("unexpected url %s", get.URL)
+	}
+	if len(get.Request.QueryParams) != 3 || get.Request.QueryParams[2].Enable {
+		t.Errorf("unexpected query params %+v", get.Request.QueryParams)
+	}
+	if len(get.Request.PathParams) != 1 || get.Request.PathParams[0].Value != "42" {
+		t.Errorf("unexpected path params %+v", get.Request.PathParams)
+	}
+	if len(get.Request.Headers) != 2 || get.Request.Headers[1].Enable {
+		t.Errorf("unexpected headers %+v", get.Request.Headers)
+	}
+
+	upload := orders.Spec.Requests[1].Spec.HTTP.Request
+	if upload.Auth.Type != domain.AuthTypeAPIKey || upload.Auth.APIKeyAuth.Key != "X-Key" {
+		t.Errorf("unexpected upload auth %+v", upload.Auth)
+	}
+	if upload.Body.Type != domain.BodyTypeFormData || upload.Body.FormData.Fields[0].Files[0] != "/tmp/a.png" {
+		t.Errorf("unexpected upload body %+v", upload.Body)
+	}
+
+	sign := orders.Spec.Requests[2].Spec.HTTP.Request
+	if sign.Body.Type != domain.BodyTypeUrlencoded || sign.Auth.Type != domain.AuthTypeNone {
+		t.Errorf("unexpected sign request %+v", sign)
+	}
+
+	if len(result.Environments) != 1 || !result.Environments[0].Spec.Values[1].Secret {
+		t.Errorf("expected an environment with the secret token, got %+v", result.Environments)
+	}
+
+	reasons := make([]string, 0)
+	for _, item := range result.Report.Items {
+		reasons = append(reasons, item.Path+": "+item.Reason)
+	}
+	expected := []string{
+		"Shop / Orders: pre-request script is not imported",
+		"Shop / Orders / Get order: 1 saved responses are not imported",
+		"Shop / Orders / Items / Sign: digest auth is not supported",
+	}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("unexpected report %v", reasons)
+	}
+}
+
+func TestConvertPathVariables(t *testing.T) {
+	u, names := convertPathVariables("http://localhost:8080/users/:id/posts/:postId")
+	if u != "http://localhost:8080/users/{id}/posts/{postId}" || !reflect.DeepEqual(names, []string{"id", "postId"}) {
+		t.Errorf("unexpected %s %v", u, names)
+	}
+}
@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/repository"
+)
+
+// Result is what an import converted, along with the report of what it could not.
+type Result struct {
+	Collections  []*domain.Collection
+	Environments []*domain.Environment
+	Report       *convert.Report
+}
+
+// fileName replaces the path separators of a name so it can be used as a file or directory name.
+func fileName(name string) string {
+	return strings.NewReplacer("/", "-", "\\", "-").Replace(name)
+}
+
+// newName returns the name unless the file path had to be renamed as the file already exists.
+func newName(name string, fp *repository.FilePath) string {
+	if fp.NewName == fileName(name) {
+		return name
+	}
+	return fp.NewName
+}
+
+// saveCollection saves the collection and its requests, each request is saved to its own file.
+func saveCollection(filesystem *repository.Filesystem, col *domain.Collection) error {
+	fp, err := filesystem.GetNewCollectionDir(fileName(col.MetaData.Name))
+	if err != nil {
+		return fmt.Errorf("error getting new collection directory: %w", err)
+	}
+	col.FilePath = fp.Path
+	col.MetaData.Name = newName(col.MetaData.Name, fp)
+
+	// requests are not part of the collection file
+	requests := col.Spec.Requests
+	col.Spec.Requests = make([]*domain.Request, 0)
+	if err := filesystem.UpdateCollection(col); err != nil {
+		return fmt.Errorf("error saving collection: %w", err)
+	}
+	col.Spec.Requests = requests
+
+	for _, req := range requests {
+		fp, err := filesystem.GetCollectionRequestNewFilePath(col, fileName(req.MetaData.Name))
+		if err != nil {
+			return fmt.Errorf("error getting new request file path: %w", err)
+		}
+
+		req.FilePath = fp.Path
+		req.MetaData.Name = newName(req.MetaData.Name, fp)
+		req.CollectionID = col.MetaData.ID
+		req.CollectionName = col.MetaData.Name
+		req.SetDefaultValues()
+
+		if err := filesystem.UpdateRequest(req); err != nil {
+			return fmt.Errorf("error saving request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// saveEnvironment saves the environment, the values of its secrets are dropped and reported while the vault is locked.
+func saveEnvironment(filesystem *repository.Filesystem, env *domain.Environment, report *convert.Report) error {
+	fp, err := filesystem.GetNewEnvironmentFilePath(fileName(env.MetaData.Name))
+	if err != nil {
+		return fmt.Errorf("error getting new environment file path: %w", err)
+	}
+
+	env.FilePath = fp.Path
+	env.MetaData.Name = newName(env.MetaData.Name, fp)
+
+	if filesystem.IsVaultLocked() {
+		for i, kv := range env.Spec.Values {
+			if kv.Secret && kv.Value != "" {
+				env.Spec.Values[i].Value = ""
+				report.Add(env.MetaData.Name, "value of secret %s is not imported as the vault is locked", kv.Key)
+			}
+		}
+	}
+
+	if err := filesystem.UpdateEnvironment(env); err != nil {
+		return fmt.Errorf("error saving environment: %w", err)
+	}
+	return nil
+}
+
+// saveResult saves the collections and the environments of the result.
+func saveResult(result *Result) error {
+	filesystem, err := repository.NewFilesystem()
+	if err != nil {
+		return fmt.Errorf("error creating filesystem: %w", err)
+	}
+
+	for _, col := range result.Collections {
+		if err := saveCollection(filesystem, col); err != nil {
+			return err
+		}
+	}
+
+	for _, env := range result.Environments {
+		if err := saveEnvironment(filesystem, env, result.Report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folder is a node of the tree of requests an importer builds before the folders are mapped to collections.
+type folder struct {
+	name      string
+	requests  []*domain.Request
+	folders   []*folder
+	variables []domain.KeyValue
+}
+
+// collections maps the tree to collections as collections can not be nested. The requests of the root stay
+// in a collection named after it, the top level folders become collections of their own and the deeper
+// folders are flattened into the names of their requests.
+func (f *folder) collections() []*domain.Collection {
+	root := domain.NewCollection(f.name)
+	root.Spec.Variables = f.variables
+	for _, req := range f.requests {
+		root.AddRequest(req)
+	}
+
+	out := make([]*domain.Collection, 0, len(f.folders)+1)
+	for _, sub := range f.folders {
+		col := domain.NewCollection(f.name + " - " + sub.name)
+		col.Spec.Variables = mergeVariables(sub.variables, f.variables)
+		for _, req := range sub.requests {
+			col.AddRequest(req)
+		}
+		for _, nested := range sub.folders {
+			nested.flatten(col, nested.name+" - ", nil)
+		}
+		out = append(out, col)
+	}
+
+	if len(root.Spec.Requests) > 0 || len(out) == 0 {
+		out = append([]*domain.Collection{root}, out...)
+	}
+
+	for _, col := range out {
+		for _, req := range col.Spec.Requests {
+			req.CollectionID = col.MetaData.ID
+			req.CollectionName = col.MetaData.Name
+		}
+	}
+	return out
+}
+
+// flatten adds the requests of the folder and of its sub folders to the collection, the names of the requests
+// are prefixed with the names of the folders and the variables of the folders are added to the ones of the requests.
+func (f *folder) flatten(col *domain.Collection, prefix string, inherited []domain.KeyValue) {
+	variables := mergeVariables(f.variables, inherited)
+	for _, req := range f.requests {
+		req.MetaData.Name = prefix + req.MetaData.Name
+		if req.Spec.HTTP != nil && len(variables) > 0 {
+			req.Spec.HTTP.Request.Variables = mergeVariables(req.Spec.HTTP.Request.Variables, variables)
+		}
+		col.AddRequest(req)
+	}
+
+	for _, sub := range f.folders {
+		sub.flatten(col, prefix+sub.name+" - ", variables)
+	}
+}
+
+// mergeVariables returns the variables followed by the inherited ones which they do not override.
+func mergeVariables(own, inherited []domain.KeyValue) []domain.KeyValue {
+	out := append([]domain.KeyValue(nil), own...)
+	seen := make(map[string]bool, len(own))
+	for _, kv := range own {
+		seen[kv.Key] = true
+	}
+	for _, kv := range inherited {
+		if !seen[kv.Key] {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
@@ -0,0 +1,211 @@
+// Package loadtest sends requests concurrently through the egress pipeline and measures their
+// throughput, latencies and errors.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/egress"
+)
+
+const progressInterval = 500 * time.Millisecond
+
+// SendFunc sends the request with the id and returns its response, it is called by the users concurrently.
+// egress.Service.SendWithEnvironment bound to a copy of an environment satisfies it, so each send resolves
+// the variables again and the users do not write the environment of the workspace.
+type SendFunc func(requestID string) (any, error)
+
+// Target is a request sent in each iteration.
+type Target struct {
+	ID   string
+	Name string
+}
+
+type Options struct {
+	// VirtualUsers is the number of users sending the iterations concurrently.
+	VirtualUsers int
+	// RampUp is the time over which the users are started evenly.
+	RampUp time.Duration
+	// Duration stops the run once passed, zero runs until the iterations are done.
+	Duration time.Duration
+	// Iterations is the number of iterations over all users, when both it and the duration are zero
+	// each user runs a single iteration.
+	Iterations int
+	// RateLimit is the maximum requests per second over all users, zero is unlimited.
+	RateLimit float64
+}
+
+func (o Options) Validate() error {
+	if o.VirtualUsers < 1 {
+		return errors.New("at least one virtual user is required")
+	}
+	if o.RampUp < 0 || o.Duration < 0 || o.Iterations < 0 || o.RateLimit < 0 {
+		return errors.New("ramp-up, duration, iterations and rate limit can not be negative")
+	}
+	return nil
+}
+
+// Runner runs the iterations of a load test, an iteration sends each target in order.
+type Runner struct {
+	send    SendFunc
+	targets []Target
+	opts    Options
+
+	stats      *stats
+	onProgress func(r *Report)
+}
+
+func New(send SendFunc, targets []Target, opts Options) *Runner {
+	return &Runner{
+		send:    send,
+		targets: targets,
+		opts:    opts,
+		stats:   newStats(targets, opts),
+	}
+}
+
+// SetOnProgress sets the function called with the report of the run so far while it is running.
+func (r *Runner) SetOnProgress(f func(r *Report)) {
+	r.onProgress = f
+}
+
+// Snapshot returns the report of the run so far.
+func (r *Runner) Snapshot() *Report {
+	return r.stats.report()
+}
+
+// Run sends the iterations until they are done, the duration passes or the context is canceled.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	if err := r.opts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(r.targets) == 0 {
+		return nil, errors.New("there are no requests to send")
+	}
+
+	if r.opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.Duration)
+		defer cancel()
+	}
+
+	iterations := int64(r.opts.Iterations)
+	if iterations == 0 && r.opts.Duration == 0 {
+		iterations = int64(r.opts.VirtualUsers)
+	}
+
+	var limit *limiter
+	if r.opts.RateLimit > 0 {
+		limit = newLimiter(r.opts.RateLimit)
+	}
+
+	r.stats.start()
+	done := make(chan struct{})
+	go r.reportProgress(done)
+
+	var started atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.VirtualUsers; i++ {
+		wg.Add(1)
+		go func(delay time.Duration) {
+			defer wg.Done()
+			if !sleep(ctx, delay) {
+				return
+			}
+
+			for ctx.Err() == nil {
+				if iterations > 0 && started.Add(1) > iterations {
+					return
+				}
+				r.iterate(ctx, limit)
+			}
+		}(r.opts.RampUp * time.Duration(i) / time.Duration(r.opts.VirtualUsers))
+	}
+	wg.Wait()
+
+	r.stats.finish()
+	close(done)
+	return r.stats.report(), nil
+}
+
+func (r *Runner) reportProgress(done chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if r.onProgress != nil {
+				r.onProgress(r.stats.report())
+			}
+		}
+	}
+}
+
+func (r *Runner) iterate(ctx context.Context, limit *limiter) {
+	for i, t := range r.targets {
+		if limit != nil && !limit.wait(ctx) {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		res, err := r.send(t.ID)
+		status, failed := egress.ResponseStatus(res, err)
+		r.stats.add(i, time.Since(start), status, failed)
+	}
+}
+
+// limiter spaces the requests of all users evenly to keep their rate under the limit.
+type limiter struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newLimiter(rate float64) *limiter {
+	return &limiter{interval: time.Duration(float64(time.Second) / rate), now: time.Now}
+}
+
+// reserve returns the time the next request can be sent at, the time the users were idle is not saved up.
+func (l *limiter) reserve() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(l.interval)
+	return at
+}
+
+func (l *limiter) wait(ctx context.Context) bool {
+	return sleep(ctx, l.reserve().Sub(l.now()))
+}
+
+// sleep waits for the duration, it returns false when the context is canceled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,139 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/grpc"
+	"github.com/chapar-rest/chapar/internal/rest"
+)
+
+func TestRunIterations(t *testing.T) {
+	var sent atomic.Int64
+	send := func(id string) (any, error) {
+		n := sent.Add(1)
+		switch {
+		case id == "grpc":
+			return &grpc.Response{StatueCode: 14, Status: "Unavailable"}, errors.New("unavailable")
+		case n%4 == 0:
+			return nil, errors.New("connection refused")
+		default:
+			return &rest.Response{StatusCode: http.StatusOK}, nil
+		}
+	}
+
+	targets := []Target{{ID: "http", Name: "Get users"}, {ID: "grpc", Name: "Say hello"}}
+	r := New(send, targets, Options{VirtualUsers: 4, Iterations: 10})
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Count != 20 || sent.Load() != 20 {
+		t.Fatalf("count = %d, sent = %d, want 20", report.Count, sent.Load())
+	}
+	if report.Statuses["Unavailable"] != 10 || report.Statuses["200"]+report.Statuses[egress.StatusError] != 10 {
+		t.Errorf("statuses = %v", report.Statuses)
+	}
+	if report.Failures != 10+report.Statuses[egress.StatusError] {
+		t.Errorf("failures = %d", report.Failures)
+	}
+	if len(report.Requests) != 2 || report.Requests[1].Name != "Say hello" || report.Requests[1].ErrorRate != 1 {
+		t.Errorf("requests = %+v", report.Requests)
+	}
+	if !report.Finished || report.Latency.P99 < report.Latency.P50 || report.Latency.Max < report.Latency.P99 {
+		t.Errorf("report = %+v", report)
+	}
+
+	if _, err := report.JSON(); err != nil {
+		t.Error(err)
+	}
+	if _, err := report.HTML(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunDurationAndRateLimit(t *testing.T) {
+	send := func(string) (any, error) {
+		return &rest.Response{StatusCode: http.StatusOK}, nil
+	}
+
+	r := New(send, []Target{{ID: "http"}}, Options{VirtualUsers: 5, Duration: 300 * time.Millisecond, RateLimit: 50})
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 50 requests per second for 300ms, the first one is sent right away. A slow machine sends fewer
+	// but the limiter never lets more through.
+	if report.Count < 1 || report.Count > 16 {
+		t.Errorf("count = %d, want at most 16", report.Count)
+	}
+	if report.Elapsed < 0.3 {
+		t.Errorf("elapsed = %f, want at least the duration", report.Elapsed)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	start := time.Now()
+	now := start
+	l := newLimiter(50)
+	l.now = func() time.Time { return now }
+
+	// the requests are spaced by 20ms
+	for i := 0; i < 3; i++ {
+		if got, want := l.reserve(), start.Add(time.Duration(i)*20*time.Millisecond); !got.Equal(want) {
+			t.Errorf("reserve() #%d = %s, want %s", i, got.Sub(start), want.Sub(start))
+		}
+	}
+
+	// the time the users were idle is not saved up for a burst
+	now = start.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if got, want := l.reserve(), now.Add(time.Duration(i)*20*time.Millisecond); !got.Equal(want) {
+			t.Errorf("reserve() after idle #%d = %s, want %s", i, got.Sub(start), want.Sub(start))
+		}
+	}
+}
+
+func TestStatsSamples(t *testing.T) {
+	s := newStats([]Target{{ID: "http", Name: "Get users"}}, Options{VirtualUsers: 1})
+	s.start()
+
+	n := 3 * maxSamples
+	for i := 1; i <= n; i++ {
+		s.add(0, time.Duration(i)*time.Millisecond, "200", false)
+	}
+
+	if got := len(s.all.samples); got != maxSamples {
+		t.Fatalf("samples = %d, want %d", got, maxSamples)
+	}
+
+	report := s.report()
+	if report.Count != n || report.Latency.Min != 1 || report.Latency.Max != float64(n) || report.Latency.Mean != float64(n+1)/2 {
+		t.Errorf("report = %+v", report.Summary)
+	}
+
+	// the percentiles of the sample are close to the ones of all the latencies
+	if p50 := report.Latency.P50; p50 < 0.4*float64(n) || p50 > 0.6*float64(n) {
+		t.Errorf("p50 = %.1f, want about %d", p50, n/2)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	for p, want := range map[float64]time.Duration{50: 50 * time.Millisecond, 90: 90 * time.Millisecond, 99: 99 * time.Millisecond} {
+		if got := percentile(latencies, p); got != want {
+			t.Errorf("p%v = %s, want %s", p, got, want)
+		}
+	}
+}
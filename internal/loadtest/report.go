@@ -0,0 +1,320 @@
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report is the summary of a load test, the latencies are in milliseconds.
+type Report struct {
+	Started time.Time `json:"started"`
+	// Elapsed is the time the run took so far, in seconds.
+	Elapsed  float64 `json:"elapsed"`
+	Finished bool    `json:"finished"`
+
+	VirtualUsers int     `json:"virtualUsers"`
+	RampUp       string  `json:"rampUp,omitempty"`
+	Duration     string  `json:"duration,omitempty"`
+	Iterations   int     `json:"iterations,omitempty"`
+	RateLimit    float64 `json:"rateLimit,omitempty"`
+
+	Summary
+	Requests []*Summary `json:"requests"`
+}
+
+// Summary is the statistics of the sent requests, either all of them or the ones of a single target.
+type Summary struct {
+	Name string `json:"name,omitempty"`
+
+	Count int `json:"count"`
+	// Failures are the requests which failed without a response, with an http status code of 400 or more
+	// or with a grpc code other than OK.
+	Failures  int     `json:"failures"`
+	ErrorRate float64 `json:"errorRate"`
+	// Throughput is the number of requests per second.
+	Throughput float64 `json:"throughput"`
+	Latency    Latency `json:"latency"`
+	// Statuses counts the requests by http status code, grpc code or error.
+	Statuses map[string]int `json:"statuses"`
+}
+
+type Latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// StatusKeys returns the statuses sorted to show them in a stable order.
+func (s *Summary) StatusKeys() []string {
+	keys := make([]string, 0, len(s.Statuses))
+	for k := range s.Statuses {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String is a short human readable summary of the report.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d requests in %.1fs, %.1f req/s, %.1f%% errors\n", r.Count, r.Elapsed, r.Throughput, r.ErrorRate*100)
+	fmt.Fprintf(&sb, "latency ms: min %.1f, mean %.1f, p50 %.1f, p90 %.1f, p99 %.1f, max %.1f\n",
+		r.Latency.Min, r.Latency.Mean, r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max)
+
+	statuses := make([]string, 0, len(r.Statuses))
+	for _, k := range r.StatusKeys() {
+		statuses = append(statuses, fmt.Sprintf("%s: %d", k, r.Statuses[k]))
+	}
+	fmt.Fprintf(&sb, "statuses: %s", strings.Join(statuses, ", "))
+
+	if len(r.Requests) > 1 {
+		for _, req := range r.Requests {
+			fmt.Fprintf(&sb, "\n%s: %d requests, %.1f%% errors, p50 %.1fms, p90 %.1fms, p99 %.1fms",
+				req.Name, req.Count, req.ErrorRate*100, req.Latency.P50, req.Latency.P90, req.Latency.P99)
+		}
+	}
+	return sb.String()
+}
+
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.2f%%", v*100) },
+	"ms":      func(v float64) string { return fmt.Sprintf("%.1f", v) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Load test report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+</style>
+</head>
+<body>
+<h1>Load test report</h1>
+<p>Started {{.Started.Format "2006-01-02 15:04:05"}}, ran for {{printf "%.1f" .Elapsed}}s with {{.VirtualUsers}} virtual users
+{{- if .RampUp}}, {{.RampUp}} ramp-up{{end}}
+{{- if .Duration}}, {{.Duration}} duration{{end}}
+{{- if .Iterations}}, {{.Iterations}} iterations{{end}}
+{{- if .RateLimit}}, limited to {{.RateLimit}} req/s{{end}}.</p>
+<table>
+<tr><th>Request</th><th>Count</th><th>Failures</th><th>Error rate</th><th>Req/s</th><th>Min</th><th>Mean</th><th>p50</th><th>p90</th><th>p99</th><th>Max</th></tr>
+{{- define "row"}}<td>{{.Count}}</td><td>{{.Failures}}</td><td>{{percent .ErrorRate}}</td><td>{{printf "%.1f" .Throughput}}</td><td>{{ms .Latency.Min}}</td><td>{{ms .Latency.Mean}}</td><td>{{ms .Latency.P50}}</td><td>{{ms .Latency.P90}}</td><td>{{ms .Latency.P99}}</td><td>{{ms .Latency.Max}}</td>{{end}}
+<tr><th>All</th>{{template "row" .Summary}}</tr>
+{{- range .Requests}}
+<tr><td>{{.Name}}</td>{{template "row" .}}</tr>
+{{- end}}
+</table>
+<p>Latencies are in milliseconds.</p>
+<h2>Statuses</h2>
+<table>
+<tr><th>Request</th><th>Status</th><th>Count</th></tr>
+{{- range .Requests}}{{$s := .}}
+{{- range .StatusKeys}}
+<tr><td>{{$s.Name}}</td><td>{{.}}</td><td>{{index $s.Statuses .}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+func (r *Report) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maxSamples bounds the latencies kept per target to compute the percentiles, a long run keeps a uniform
+// sample of them so its memory and the cost of a report do not grow with the number of requests.
+const maxSamples = 10000
+
+// stats collects the results of the requests.
+type stats struct {
+	mu sync.Mutex
+
+	targets []Target
+	opts    Options
+
+	started  time.Time
+	finished time.Time
+
+	all     *targetStats
+	results []*targetStats
+}
+
+type targetStats struct {
+	count    int
+	failures int
+	statuses map[string]int
+
+	total    time.Duration
+	min, max time.Duration
+	// samples are all the latencies until there are maxSamples of them, then a uniform sample.
+	samples []time.Duration
+}
+
+func newTargetStats() *targetStats {
+	return &targetStats{statuses: make(map[string]int)}
+}
+
+func newStats(targets []Target, opts Options) *stats {
+	s := &stats{targets: targets, opts: opts, all: newTargetStats()}
+	for range targets {
+		s.results = append(s.results, newTargetStats())
+	}
+	return s
+}
+
+func (s *stats) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = time.Now()
+}
+
+func (s *stats) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = time.Now()
+}
+
+func (s *stats) add(target int, latency time.Duration, status string, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range []*targetStats{s.all, s.results[target]} {
+		t.add(latency, status, failed)
+	}
+}
+
+func (t *targetStats) add(latency time.Duration, status string, failed bool) {
+	t.count++
+	t.statuses[status]++
+	if failed {
+		t.failures++
+	}
+
+	t.total += latency
+	if t.count == 1 || latency < t.min {
+		t.min = latency
+	}
+	if latency > t.max {
+		t.max = latency
+	}
+
+	// reservoir sampling, the latency replaces a sample with the probability of maxSamples/count
+	if len(t.samples) < maxSamples {
+		t.samples = append(t.samples, latency)
+	} else if i := rand.IntN(t.count); i < maxSamples {
+		t.samples[i] = latency
+	}
+}
+
+// snapshot returns a copy of the statistics which is not changed by the requests added later.
+func (t *targetStats) snapshot() *targetStats {
+	out := *t
+	out.statuses = maps.Clone(t.statuses)
+	out.samples = slices.Clone(t.samples)
+	return &out
+}
+
+// report returns the report of the requests added so far, the statistics are copied under the lock and the
+// percentiles are computed after releasing it so the users are not held up.
+func (s *stats) report() *Report {
+	s.mu.Lock()
+	r := &Report{
+		Started:      s.started,
+		Finished:     !s.finished.IsZero(),
+		VirtualUsers: s.opts.VirtualUsers,
+		Iterations:   s.opts.Iterations,
+		RateLimit:    s.opts.RateLimit,
+	}
+	if s.opts.RampUp > 0 {
+		r.RampUp = s.opts.RampUp.String()
+	}
+	if s.opts.Duration > 0 {
+		r.Duration = s.opts.Duration.String()
+	}
+
+	switch {
+	case r.Finished:
+		r.Elapsed = s.finished.Sub(s.started).Seconds()
+	case !s.started.IsZero():
+		r.Elapsed = time.Since(s.started).Seconds()
+	}
+
+	all := s.all.snapshot()
+	results := make([]*targetStats, 0, len(s.results))
+	for _, t := range s.results {
+		results = append(results, t.snapshot())
+	}
+	s.mu.Unlock()
+
+	r.Summary = all.summary("", r.Elapsed)
+	r.Requests = make([]*Summary, 0, len(results))
+	for i, t := range results {
+		sum := t.summary(s.targets[i].Name, r.Elapsed)
+		r.Requests = append(r.Requests, &sum)
+	}
+	return r
+}
+
+// summary returns the summary of a snapshot of the statistics, it sorts the samples in place.
+func (t *targetStats) summary(name string, elapsed float64) Summary {
+	sum := Summary{
+		Name:     name,
+		Count:    t.count,
+		Failures: t.failures,
+		Statuses: t.statuses,
+	}
+	if sum.Count == 0 {
+		return sum
+	}
+
+	sum.ErrorRate = float64(sum.Failures) / float64(sum.Count)
+	if elapsed > 0 {
+		sum.Throughput = float64(sum.Count) / elapsed
+	}
+
+	sorted := t.samples
+	slices.Sort(sorted)
+	sum.Latency = Latency{
+		Min:  ms(t.min),
+		Mean: ms(t.total / time.Duration(t.count)),
+		P50:  ms(percentile(sorted, 50)),
+		P90:  ms(percentile(sorted, 90)),
+		P99:  ms(percentile(sorted, 99)),
+		Max:  ms(t.max),
+	}
+	return sum
+}
+
+// percentile returns the nearest rank percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,252 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	v1reflectiongrpc "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	chapargrpc "github.com/chapar-rest/chapar/internal/grpc"
+)
+
+// GRPCServer answers the methods of the services of a registry with example messages, it also serves
+// the reflection api so clients can discover them.
+type GRPCServer struct {
+	files    *protoregistry.Files
+	examples map[string]string
+	opts     Options
+	log      *Log
+
+	mu   sync.Mutex
+	srv  *grpc.Server
+	addr string
+}
+
+// NewGRPCServer returns a server for the services of the files. The examples are the JSON responses keyed
+// by the full name of the methods, such as /helloworld.Greeter/SayHello, an example is generated from the
+// output message of the methods without one.
+func NewGRPCServer(files *protoregistry.Files, examples map[string]string, opts Options) *GRPCServer {
+	if examples == nil {
+		examples = make(map[string]string)
+	}
+	return &GRPCServer{files: files, examples: examples, opts: opts, log: NewLog()}
+}
+
+// MergeFiles returns a registry with the files of all the registries, the first file of a path wins.
+func MergeFiles(registries ...*protoregistry.Files) *protoregistry.Files {
+	out := new(protoregistry.Files)
+	for _, r := range registries {
+		if r == nil {
+			continue
+		}
+		r.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+			if _, err := out.FindFileByPath(fd.Path()); err != nil {
+				// conflicting files are skipped, their services are served by the first registry
+				_ = out.RegisterFile(fd)
+			}
+			return true
+		})
+	}
+	return out
+}
+
+// GetServiceInfo lists the services of the files for the reflection api.
+func (s *GRPCServer) GetServiceInfo() map[string]grpc.ServiceInfo {
+	out := make(map[string]grpc.ServiceInfo)
+	s.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			sd := services.Get(i)
+			info := grpc.ServiceInfo{Metadata: fd.Path()}
+			methods := sd.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+				info.Methods = append(info.Methods, grpc.MethodInfo{
+					Name:           string(md.Name()),
+					IsClientStream: md.IsStreamingClient(),
+					IsServerStream: md.IsStreamingServer(),
+				})
+			}
+			out[string(sd.FullName())] = info
+		}
+		return true
+	})
+	return out
+}
+
+// Start listens on the address and serves the calls in the background.
+func (s *GRPCServer) Start(addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.srv != nil {
+		return errors.New("mock server is already running")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.srv = grpc.NewServer(grpc.UnknownServiceHandler(s.handle))
+	v1reflectiongrpc.RegisterServerReflectionServer(s.srv, reflection.NewServerV1(reflection.ServerOptions{
+		Services:           s,
+		DescriptorResolver: s.files,
+	}))
+
+	s.addr = ln.Addr().String()
+	go func(srv *grpc.Server) {
+		_ = srv.Serve(ln)
+	}(s.srv)
+	return nil
+}
+
+// Addr returns the address the server listens on, empty when it is not running.
+func (s *GRPCServer) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+func (s *GRPCServer) Stop() error {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv, s.addr = nil, ""
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		srv.Stop()
+	}
+	return nil
+}
+
+func (s *GRPCServer) Log() *Log {
+	return s.log
+}
+
+func (s *GRPCServer) handle(_ any, stream grpc.ServerStream) (err error) {
+	start := time.Now()
+	fullName, _ := grpc.MethodFromServerStream(stream)
+	entry := LogEntry{Time: start, Method: "GRPC", Path: fullName}
+	defer func() {
+		entry.Duration = time.Since(start)
+		st := status.Convert(err)
+		entry.Status = int(st.Code())
+		if err != nil && entry.Error == "" {
+			entry.Error = st.Message()
+		}
+		s.log.Add(entry)
+	}()
+
+	md, err := s.method(fullName)
+	if err != nil {
+		return err
+	}
+	entry.Route = string(md.FullName())
+
+	response, err := s.response(fullName, md.Output())
+	if err != nil {
+		return err
+	}
+
+	// read the requests as the client sends them, the unary and server streaming calls send only one
+	for {
+		if err := stream.RecvMsg(dynamicpb.NewMessage(md.Input())); err != nil {
+			if errors.Is(err, io.EOF) && md.IsStreamingClient() {
+				break
+			}
+			if errors.Is(err, io.EOF) {
+				return status.Error(codes.InvalidArgument, "no request message")
+			}
+			return err
+		}
+
+		if !md.IsStreamingClient() {
+			break
+		}
+
+		// bidirectional calls are answered message by message
+		if md.IsStreamingServer() {
+			if err := s.send(stream, response); err != nil {
+				return err
+			}
+		}
+	}
+
+	if md.IsStreamingClient() && md.IsStreamingServer() {
+		return nil
+	}
+	return s.send(stream, response)
+}
+
+// method returns the descriptor of a method name such as /helloworld.Greeter/SayHello.
+func (s *GRPCServer) method(fullName string) (protoreflect.MethodDescriptor, error) {
+	name := strings.Replace(strings.TrimPrefix(fullName, "/"), "/", ".", 1)
+	desc, err := s.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, status.Errorf(codes.Unimplemented, "method %s is not mocked", fullName)
+	}
+
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "%s is not a method", fullName)
+	}
+	return md, nil
+}
+
+// response returns the example of the method, or one generated from its output message.
+func (s *GRPCServer) response(fullName string, output protoreflect.MessageDescriptor) (*dynamicpb.Message, error) {
+	example, ok := s.examples[fullName]
+	if !ok {
+		data, err := chapargrpc.ExampleJSON(output)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate the example: %v", err)
+		}
+		example = string(data)
+	}
+
+	msg := dynamicpb.NewMessage(output)
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(example), msg); err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid example of %s: %v", fullName, err)
+	}
+	return msg, nil
+}
+
+// send sends the response after the latency of the options, or an injected error.
+func (s *GRPCServer) send(stream grpc.ServerStream, msg *dynamicpb.Message) error {
+	s.opts.delay(stream.Context())
+
+	if s.opts.injectError() {
+		return status.Error(codes.Internal, "injected error")
+	}
+
+	if err := stream.SendMsg(msg); err != nil {
+		return fmt.Errorf("failed to send the response: %w", err)
+	}
+	return nil
+}
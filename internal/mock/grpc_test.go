@@ -0,0 +1,121 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func testFiles(t *testing.T) *protoregistry.Files {
+	t.Helper()
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+	}
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("greeter.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("HelloRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			}},
+			{Name: proto.String("HelloReply"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("message", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("data", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+				field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+			}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("SayHello"), InputType: proto.String(".test.HelloRequest"), OutputType: proto.String(".test.HelloReply")},
+				{Name: proto.String("Generated"), InputType: proto.String(".test.HelloRequest"), OutputType: proto.String(".test.HelloReply")},
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := new(protoregistry.Files)
+	if err := files.RegisterFile(fd); err != nil {
+		t.Fatal(err)
+	}
+	// the same file in two registries, as with two requests of a collection using it
+	return MergeFiles(files, files)
+}
+
+func TestGRPCServer(t *testing.T) {
+	files := testFiles(t)
+	srv := NewGRPCServer(files, map[string]string{
+		"/test.Greeter/SayHello": `{"message": "hello", "unknown": true}`,
+	}, Options{})
+	if err := srv.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(srv.Addr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	desc, err := files.FindDescriptorByName("test.Greeter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := desc.(protoreflect.ServiceDescriptor).Methods()
+
+	invoke := func(method string) (*dynamicpb.Message, error) {
+		md := methods.ByName(protoreflect.Name(method))
+		out := dynamicpb.NewMessage(md.Output())
+		err := conn.Invoke(context.Background(), "/test.Greeter/"+method, dynamicpb.NewMessage(md.Input()), out)
+		return out, err
+	}
+
+	out, err := invoke("SayHello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Get(out.Descriptor().Fields().ByName("message")).String(); got != "hello" {
+		t.Errorf("message = %q, want hello", got)
+	}
+
+	out, err = invoke("Generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Get(out.Descriptor().Fields().ByName("count")).Int(); got != 123 {
+		t.Errorf("count = %d, want the generated 123", got)
+	}
+
+	err = conn.Invoke(context.Background(), "/test.Greeter/Missing", &descriptorpb.FileDescriptorProto{}, &descriptorpb.FileDescriptorProto{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("code = %v, want Unimplemented", status.Code(err))
+	}
+
+	if _, ok := srv.GetServiceInfo()["test.Greeter"]; !ok {
+		t.Error("service info does not list test.Greeter")
+	}
+	if n := len(srv.Log().Entries()); n != 3 {
+		t.Errorf("log has %d entries, want 3", n)
+	}
+}
@@ -0,0 +1,369 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+// skippedHeaders are not copied from the saved examples as the server sets them for the served body.
+var skippedHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+}
+
+// segment is a part of the path of a route, either a literal or a parameter matching any value.
+type segment struct {
+	literal string
+	param   string
+}
+
+type route struct {
+	name     string
+	method   string
+	segments []segment
+	examples []domain.HTTPResponse
+}
+
+// literals is the number of literal segments, routes with more of them are matched first.
+func (r *route) literals() int {
+	n := 0
+	for _, s := range r.segments {
+		if s.param == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// match returns the values of the parameters when the path matches the route.
+func (r *route) match(parts []string) (map[string]string, bool) {
+	if len(parts) != len(r.segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, s := range r.segments {
+		if s.param != "" {
+			params[s.param] = parts[i]
+			continue
+		}
+		if s.literal != parts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// Server is an http server answering the http requests of a collection with their saved examples.
+type Server struct {
+	routes []*route
+	opts   Options
+	log    *Log
+
+	mu   sync.Mutex
+	srv  *http.Server
+	addr string
+}
+
+func NewServer(col *domain.Collection, opts Options) *Server {
+	s := &Server{opts: opts, log: NewLog()}
+	for _, req := range col.Spec.Requests {
+		if req.MetaData.Type != domain.RequestTypeHTTP || req.Spec.HTTP == nil {
+			continue
+		}
+
+		s.routes = append(s.routes, &route{
+			name:     req.MetaData.Name,
+			method:   strings.ToUpper(req.Spec.HTTP.Method),
+			segments: parseSegments(routePath(req.Spec.HTTP.URL)),
+			examples: req.Spec.HTTP.Responses,
+		})
+	}
+
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		return s.routes[i].literals() > s.routes[j].literals()
+	})
+	return s
+}
+
+// routePath returns the path of the url, which may start with a variable such as {{baseUrl}} instead of a host.
+func routePath(raw string) string {
+	raw, _, _ = strings.Cut(raw, "?")
+	raw, _, _ = strings.Cut(raw, "#")
+
+	switch {
+	case strings.Contains(raw, "://"):
+		raw = raw[strings.Index(raw, "://")+len("://"):]
+	case strings.HasPrefix(raw, "{{"):
+		if i := strings.Index(raw, "}}"); i >= 0 {
+			return raw[i+len("}}"):]
+		}
+	case strings.HasPrefix(raw, "/"):
+		return raw
+	}
+
+	if i := strings.Index(raw, "/"); i >= 0 {
+		return raw[i:]
+	}
+	return "/"
+}
+
+// parseSegments splits the path, {name}, {{name}} and :name segments become parameters.
+func parseSegments(path string) []segment {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	parts := strings.Split(path, "/")
+	out := make([]segment, 0, len(parts))
+	for _, p := range parts {
+		switch {
+		case strings.HasPrefix(p, "{{") && strings.HasSuffix(p, "}}"):
+			out = append(out, segment{param: strings.TrimSpace(p[2 : len(p)-2])})
+		case strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") && len(p) > 2:
+			out = append(out, segment{param: p[1 : len(p)-1]})
+		case strings.HasPrefix(p, ":") && len(p) > 1:
+			out = append(out, segment{param: p[1:]})
+		default:
+			out = append(out, segment{literal: p})
+		}
+	}
+	return out
+}
+
+// Start listens on the address, such as localhost:8090, and serves the requests in the background.
+func (s *Server) Start(addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.srv != nil {
+		return errors.New("mock server is already running")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.srv = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	s.addr = ln.Addr().String()
+	go func(srv *http.Server) {
+		_ = srv.Serve(ln)
+	}(s.srv)
+	return nil
+}
+
+// Addr returns the address the server listens on, empty when it is not running.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+func (s *Server) Stop() error {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv, s.addr = nil, ""
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
+
+func (s *Server) Log() *Log {
+	return s.log
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	entry := LogEntry{Time: start, Method: r.Method, Path: r.URL.RequestURI()}
+	defer func() {
+		entry.Duration = time.Since(start)
+		s.log.Add(entry)
+	}()
+
+	// let the browsers call the mock from any origin
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	rt, params, allowed := s.find(r)
+	if rt == nil {
+		if r.Method == http.MethodOptions && len(allowed) > 0 {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowed, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", "*")
+			entry.Status = http.StatusNoContent
+			w.WriteHeader(entry.Status)
+			return
+		}
+
+		entry.Status = http.StatusNotFound
+		entry.Error = "no request matches"
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			entry.Status = http.StatusMethodNotAllowed
+			entry.Error = "method is not allowed"
+		}
+		writeError(w, entry.Status, fmt.Sprintf("%s %s: %s", r.Method, r.URL.Path, entry.Error))
+		return
+	}
+	entry.Route = rt.name
+
+	s.opts.delay(r.Context())
+
+	if s.opts.injectError() {
+		entry.Status = s.opts.ErrorStatus
+		if entry.Status == 0 {
+			entry.Status = http.StatusInternalServerError
+		}
+		entry.Error = "injected error"
+		writeError(w, entry.Status, entry.Error)
+		return
+	}
+
+	example, err := selectExample(rt.examples, r.Header.Get(ExampleHeader))
+	if err != nil {
+		entry.Status = http.StatusBadRequest
+		entry.Error = err.Error()
+		writeError(w, entry.Status, entry.Error)
+		return
+	}
+
+	entry.Status = s.write(w, r, example, params)
+}
+
+// find returns the route matching the request, or the methods of the routes matching its path.
+func (s *Server) find(r *http.Request) (*route, map[string]string, []string) {
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	if len(parts) == 1 && parts[0] == "" {
+		parts = nil
+	}
+	for i, p := range parts {
+		if unescaped, err := url.PathUnescape(p); err == nil {
+			parts[i] = unescaped
+		}
+	}
+
+	var allowed []string
+	for _, rt := range s.routes {
+		params, ok := rt.match(parts)
+		if !ok {
+			continue
+		}
+		if rt.method == r.Method {
+			return rt, params, nil
+		}
+		allowed = append(allowed, rt.method)
+	}
+	return nil, nil, allowed
+}
+
+// selectExample returns the example picked by the value of the example header, the first one by default.
+func selectExample(examples []domain.HTTPResponse, header string) (domain.HTTPResponse, error) {
+	if header == "" {
+		if len(examples) == 0 {
+			return domain.HTTPResponse{}, nil
+		}
+		return examples[0], nil
+	}
+
+	i, err := strconv.Atoi(header)
+	if err != nil || i < 1 || i > len(examples) {
+		return domain.HTTPResponse{}, fmt.Errorf("%s must be between 1 and %d", ExampleHeader, len(examples))
+	}
+	return examples[i-1], nil
+}
+
+// write writes the example and returns its status.
+func (s *Server) write(w http.ResponseWriter, r *http.Request, example domain.HTTPResponse, params map[string]string) int {
+	render := func(v string) string { return v }
+	if s.opts.Template {
+		body, _ := io.ReadAll(r.Body)
+		// the values of the request are sent by any client, they are inserted as they are and the
+		// functions reading the system of the host are not available
+		engine := variables.NewEngine(requestVariables(r, params, body))
+		engine.SetLiteral(true)
+		engine.DisableFunctions("env", "file")
+		render = func(v string) string {
+			out, _ := engine.Render(v)
+			return out
+		}
+	}
+
+	for _, h := range example.Headers {
+		if !skippedHeaders[http.CanonicalHeaderKey(h.Key)] {
+			w.Header().Add(h.Key, render(h.Value))
+		}
+	}
+	for _, c := range example.Cookies {
+		http.SetCookie(w, &http.Cookie{Name: c.Key, Value: render(c.Value)})
+	}
+
+	body := render(example.Body)
+	if w.Header().Get("Content-Type") == "" && body != "" && json.Valid([]byte(body)) {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	status := example.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, body)
+	return status
+}
+
+// maxRequestValues caps the variables taken from the JSON body of a request.
+const maxRequestValues = 1000
+
+// requestVariables returns the data of the request the examples can use, such as {{request.params.id}},
+// {{request.query.page}}, {{request.headers.X-Request-Id}} or {{request.json.user.name}}.
+func requestVariables(r *http.Request, params map[string]string, body []byte) map[string]string {
+	vars := map[string]string{
+		"request.method": r.Method,
+		"request.path":   r.URL.Path,
+		"request.url":    r.URL.String(),
+		"request.body":   string(body),
+	}
+	for k, v := range params {
+		vars["request.params."+k] = v
+	}
+	for k, v := range r.URL.Query() {
+		vars["request.query."+k] = v[0]
+	}
+	for k, v := range r.Header {
+		vars["request.headers."+k] = v[0]
+	}
+
+	var data any
+	if json.Unmarshal(body, &data) == nil {
+		variables.FlattenJSON("request.json", data, vars, maxRequestValues)
+	}
+	return vars
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
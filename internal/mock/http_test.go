@@ -0,0 +1,158 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func testServer(opts Options) *httptest.Server {
+	col := domain.NewCollection("Shop")
+
+	get := domain.NewHTTPRequest("Get order")
+	get.Spec.HTTP.URL = "{{baseUrl}}/orders/{id}?tag=a"
+	get.Spec.HTTP.Responses = []domain.HTTPResponse{
+		{
+			Headers: []domain.KeyValue{{Key: "X-Order", Value: "{{request.params.id}}"}, {Key: "Content-Length", Value: "1"}},
+			Body:    `{"id": "{{request.params.id}}", "tag": "{{request.query.tag}}"}`,
+		},
+		{StatusCode: http.StatusNotFound, Body: `{"error": "not found"}`},
+	}
+
+	latest := domain.NewHTTPRequest("Latest order")
+	latest.Spec.HTTP.URL = "https://shop.example.com/orders/latest"
+	latest.Spec.HTTP.Responses = []domain.HTTPResponse{{Body: "latest"}}
+
+	create := domain.NewHTTPRequest("Create order")
+	create.Spec.HTTP.Method = domain.RequestMethodPOST
+	create.Spec.HTTP.URL = "/orders"
+	create.Spec.HTTP.Responses = []domain.HTTPResponse{{StatusCode: http.StatusCreated, Body: "{{request.json.items.0.name}}"}}
+
+	col.Spec.Requests = []*domain.Request{get, latest, create}
+	return httptest.NewServer(NewServer(col, opts))
+}
+
+func call(t *testing.T, method, url, body string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(data)
+}
+
+func TestServer(t *testing.T) {
+	srv := testServer(Options{Template: true})
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+		status  int
+		want    string
+	}{
+		{name: "path params", method: http.MethodGet, path: "/orders/7?tag=x", status: http.StatusOK, want: `{"id": "7", "tag": "x"}`},
+		{name: "literal first", method: http.MethodGet, path: "/orders/latest", status: http.StatusOK, want: "latest"},
+		{name: "example header", method: http.MethodGet, path: "/orders/7", headers: map[string]string{ExampleHeader: "2"}, status: http.StatusNotFound, want: `{"error": "not found"}`},
+		{name: "invalid example", method: http.MethodGet, path: "/orders/7", headers: map[string]string{ExampleHeader: "3"}, status: http.StatusBadRequest},
+		{name: "json body", method: http.MethodPost, path: "/orders", body: `{"items": [{"name": "pen"}]}`, status: http.StatusCreated, want: "pen"},
+		{name: "method not allowed", method: http.MethodDelete, path: "/orders", status: http.StatusMethodNotAllowed},
+		{name: "not found", method: http.MethodGet, path: "/customers", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, body := call(t, tt.method, srv.URL+tt.path, tt.body, tt.headers)
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+			if tt.want != "" && body != tt.want {
+				t.Errorf("body = %s, want %s", body, tt.want)
+			}
+		})
+	}
+
+	res, _ := call(t, http.MethodGet, srv.URL+"/orders/7", "", nil)
+	if got := res.Header.Get("X-Order"); got != "7" {
+		t.Errorf("X-Order = %q, want 7", got)
+	}
+	if got := res.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	res, _ = call(t, http.MethodDelete, srv.URL+"/orders", "", nil)
+	if got := res.Header.Get("Allow"); got != http.MethodPost {
+		t.Errorf("Allow = %q, want POST", got)
+	}
+}
+
+func TestServerRequestValuesLiteral(t *testing.T) {
+	srv := testServer(Options{Template: true})
+	defer srv.Close()
+
+	_, body := call(t, http.MethodGet, srv.URL+"/orders/7?tag="+url.QueryEscape(`{{$env "HOME"}}`), "", nil)
+	if want := `{"id": "7", "tag": "{{$env "HOME"}}"}`; body != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+
+	_, body = call(t, http.MethodPost, srv.URL+"/orders", `{"items": [{"name": "{{$file \"/etc/hostname\"}}"}]}`, nil)
+	if want := `{{$file "/etc/hostname"}}`; body != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
+
+func TestServerErrorRate(t *testing.T) {
+	srv := testServer(Options{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable})
+	defer srv.Close()
+
+	res, _ := call(t, http.MethodGet, srv.URL+"/orders/latest", "", nil)
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestServerTemplateDisabled(t *testing.T) {
+	srv := testServer(Options{})
+	defer srv.Close()
+
+	_, body := call(t, http.MethodGet, srv.URL+"/orders/7", "", nil)
+	if !strings.Contains(body, "{{request.params.id}}") {
+		t.Errorf("body = %s, want the raw example", body)
+	}
+}
+
+func TestLog(t *testing.T) {
+	l := NewLog()
+	l.limit = 2
+	for _, p := range []string{"/a", "/b", "/c"} {
+		l.Add(LogEntry{Path: p})
+	}
+
+	entries := l.Entries()
+	if len(entries) != 2 || entries[0].Path != "/b" || entries[1].Path != "/c" {
+		t.Errorf("entries = %+v, want /b and /c", entries)
+	}
+}
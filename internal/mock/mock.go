@@ -0,0 +1,130 @@
+// Package mock serves the requests of a collection, answering them with their saved examples, so clients
+// can be built before the real services exist.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// ExampleHeader selects the example to answer with by its 1-based index, the first one is used otherwise.
+	ExampleHeader = "X-Mock-Example"
+
+	defaultLogLimit = 500
+)
+
+// Options alter the responses of the mock servers.
+type Options struct {
+	// Latency is added to every response, along with a random duration up to Jitter.
+	Latency time.Duration
+	Jitter  time.Duration
+
+	// ErrorRate is the share of the requests, between 0 and 1, answered with an error instead of an example.
+	ErrorRate float64
+	// ErrorStatus is the status of the injected http errors, 500 when zero.
+	ErrorStatus int
+
+	// Template renders the {{request.*}} variables and the functions such as {{$uuid}} in the bodies and
+	// the headers of the examples.
+	Template bool
+}
+
+// delay waits for the latency of the options, it returns early when the context is done.
+func (o Options) delay(ctx context.Context) {
+	d := o.Latency
+	if o.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(o.Jitter)))
+	}
+	if d <= 0 {
+		return
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// injectError reports whether the request should fail.
+func (o Options) injectError() bool {
+	return o.ErrorRate > 0 && rand.Float64() < o.ErrorRate
+}
+
+// Runner is implemented by the http and the grpc mock servers.
+type Runner interface {
+	Start(addr string) error
+	Stop() error
+	Addr() string
+	Log() *Log
+}
+
+// LogEntry is a request received by a mock server.
+type LogEntry struct {
+	Time time.Time
+	// Method is the http method, or GRPC for the grpc calls.
+	Method string
+	Path   string
+	// Status is the http status, or the grpc status code.
+	Status   int
+	Duration time.Duration
+	// Route is the name of the request which answered, empty when none matched.
+	Route string
+	// Error describes why the request failed, such as an injected error.
+	Error string
+}
+
+// String returns the entry as a log line.
+func (e LogEntry) String() string {
+	line := fmt.Sprintf("%s %s %s %d %s", e.Time.Format("15:04:05"), e.Method, e.Path, e.Status, e.Duration.Round(time.Microsecond))
+	if e.Error != "" {
+		line += " " + e.Error
+	}
+	return line
+}
+
+// Log keeps the last entries of a mock server.
+type Log struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	limit   int
+
+	onEntry func(entry LogEntry)
+}
+
+func NewLog() *Log {
+	return &Log{limit: defaultLogLimit}
+}
+
+// SetOnEntry sets the function called, outside the lock, with each new entry.
+func (l *Log) SetOnEntry(f func(entry LogEntry)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onEntry = f
+}
+
+func (l *Log) Add(entry LogEntry) {
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > l.limit {
+		l.entries = l.entries[len(l.entries)-l.limit:]
+	}
+	onEntry := l.onEntry
+	l.mu.Unlock()
+
+	if onEntry != nil {
+		onEntry(entry)
+	}
+}
+
+// Entries returns a copy of the entries, the oldest first.
+func (l *Log) Entries() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LogEntry(nil), l.entries...)
+}
@@ -0,0 +1,392 @@
+// Package proxy is a forward http proxy recording the requests passing through it, https requests are
+// intercepted with certificates signed by a local certificate authority.
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxRecordedBody is the size up to which the bodies are recorded, larger bodies are still forwarded.
+	maxRecordedBody = 10 << 20
+
+	defaultExchangesLimit = 1000
+)
+
+// hopHeaders are the headers of a single connection, they are not forwarded.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Exchange is a request recorded by the proxy along with its response.
+type Exchange struct {
+	ID   string
+	Time time.Time
+
+	Method        string
+	URL           string
+	RequestHeader http.Header
+	RequestBody   []byte
+
+	Status         int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	// Truncated reports whether the response body was larger than what is recorded.
+	Truncated bool
+
+	Duration time.Duration
+	// Error is why the request could not be forwarded.
+	Error string
+}
+
+// String describes the exchange to let the user pick the ones to save.
+func (e *Exchange) String() string {
+	if e.Error != "" {
+		return fmt.Sprintf("%s %s (%s)", e.Method, e.URL, e.Error)
+	}
+	return fmt.Sprintf("%s %s (%d, %s)", e.Method, e.URL, e.Status, e.Duration.Round(time.Millisecond))
+}
+
+// Proxy is a forward http proxy keeping the last exchanges passing through it.
+type Proxy struct {
+	ca        *CA
+	transport http.RoundTripper
+
+	mu         sync.Mutex
+	exchanges  []*Exchange
+	limit      int
+	onExchange func(ex *Exchange)
+
+	srv  *http.Server
+	addr string
+}
+
+// New returns a proxy intercepting the https requests with certificates of the authority, they are
+// tunneled without being recorded when the authority is nil.
+func New(ca *CA) *Proxy {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	return &Proxy{ca: ca, transport: transport, limit: defaultExchangesLimit}
+}
+
+// SetOnExchange sets the function called with each recorded exchange.
+func (p *Proxy) SetOnExchange(f func(ex *Exchange)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onExchange = f
+}
+
+// Exchanges returns the recorded exchanges, the oldest first.
+func (p *Proxy) Exchanges() []*Exchange {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Exchange(nil), p.exchanges...)
+}
+
+func (p *Proxy) Exchange(id string) *Exchange {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ex := range p.exchanges {
+		if ex.ID == id {
+			return ex
+		}
+	}
+	return nil
+}
+
+func (p *Proxy) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.exchanges = nil
+}
+
+func (p *Proxy) record(ex *Exchange) {
+	p.mu.Lock()
+	p.exchanges = append(p.exchanges, ex)
+	if len(p.exchanges) > p.limit {
+		p.exchanges = p.exchanges[len(p.exchanges)-p.limit:]
+	}
+	onExchange := p.onExchange
+	p.mu.Unlock()
+
+	if onExchange != nil {
+		onExchange(ex)
+	}
+}
+
+// Start listens on the address, such as localhost:8888, and proxies the requests in the background.
+func (p *Proxy) Start(addr string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.srv != nil {
+		return errors.New("proxy is already running")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	p.srv = &http.Server{Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	p.addr = ln.Addr().String()
+	go func(srv *http.Server) {
+		_ = srv.Serve(ln)
+	}(p.srv)
+	return nil
+}
+
+// Addr returns the address the proxy listens on, empty when it is not running.
+func (p *Proxy) Addr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.addr
+}
+
+func (p *Proxy) Stop() error {
+	p.mu.Lock()
+	srv := p.srv
+	p.srv, p.addr = nil, ""
+	p.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		// the hijacked connections are not tracked by the server, they end with their clients
+		return srv.Close()
+	}
+	return nil
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.connect(w, r)
+		return
+	}
+
+	if !r.URL.IsAbs() {
+		http.Error(w, "this is a proxy, requests must use absolute urls", http.StatusBadRequest)
+		return
+	}
+	p.forward(w, r)
+}
+
+// connect intercepts the tls connection of the client with a certificate for the requested host.
+func (p *Proxy) connect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection can not be hijacked", http.StatusInternalServerError)
+		return
+	}
+
+	var upstream net.Conn
+	if p.ca == nil {
+		var err error
+		if upstream, err = net.DialTimeout("tcp", r.Host, 10*time.Second); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		conn.Close()
+		return
+	}
+
+	if upstream != nil {
+		tunnel(conn, upstream)
+		return
+	}
+
+	host := r.Host
+	tlsConn := tls.Server(conn, &tls.Config{
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return p.ca.Certificate(hello.ServerName)
+			}
+			return p.ca.Certificate(host)
+		},
+	})
+
+	// serve the requests of the client on the intercepted connection, they are forwarded to the host
+	srv := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.URL.Scheme = "https"
+			r.URL.Host = r.Host
+			if r.URL.Host == "" {
+				r.URL.Host = host
+			}
+			p.forward(w, r)
+		}),
+	}
+	_ = srv.Serve(&connListener{conn: tlsConn})
+}
+
+func tunnel(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	cp := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go cp(a, b)
+	go cp(b, a)
+	<-done
+	a.Close()
+	b.Close()
+}
+
+// forward sends the request to its host, the exchange is recorded once the response is written.
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	ex := &Exchange{
+		ID:            uuid.NewString(),
+		Time:          time.Now(),
+		Method:        r.Method,
+		URL:           r.URL.String(),
+		RequestHeader: r.Header.Clone(),
+	}
+	removeHopHeaders(ex.RequestHeader)
+	defer func() {
+		ex.Duration = time.Since(ex.Time)
+		p.record(ex)
+	}()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ex.Error = err.Error()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ex.RequestBody = body
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.Header = ex.RequestHeader.Clone()
+	// let the transport negotiate the compression, so the recorded response bodies are decoded
+	out.Header.Del("Accept-Encoding")
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		out.Body = http.NoBody
+	}
+
+	res, err := p.transport.RoundTrip(out)
+	if err != nil {
+		ex.Error = err.Error()
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+
+	ex.Status = res.StatusCode
+	ex.ResponseHeader = res.Header.Clone()
+	removeHopHeaders(ex.ResponseHeader)
+
+	for k, v := range ex.ResponseHeader {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(res.StatusCode)
+
+	recorded := &limitedBuffer{limit: maxRecordedBody}
+	_, err = io.Copy(&flushWriter{w: w}, io.TeeReader(res.Body, recorded))
+	ex.ResponseBody, ex.Truncated = recorded.buf.Bytes(), recorded.truncated
+	if err != nil {
+		ex.Error = err.Error()
+	}
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, k := range h.Values("Connection") {
+		for _, name := range strings.Split(k, ",") {
+			h.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+// limitedBuffer keeps the first bytes written to it, it never fails so the copy goes on past the limit.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// flushWriter flushes each write, so the streamed responses such as server sent events reach the client.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
+// connListener accepts a single connection, the server serving it returns once it is accepted while
+// the connection is served until the client closes it.
+type connListener struct {
+	conn net.Conn
+	once sync.Once
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.once.Do(func() {
+		conn = l.conn
+	})
+	if conn == nil {
+		return nil, io.EOF
+	}
+	return conn, nil
+}
+
+func (l *connListener) Close() error {
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func upstreamHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = io.WriteString(w, `{"path": "`+r.URL.Path+`", "body": `+string(body)+`}`)
+}
+
+func send(t *testing.T, client *http.Client, target string) string {
+	t.Helper()
+
+	res, err := client.Post(target+"/orders?page=2", "application/json", strings.NewReader(`{"item": "pen"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusCreated)
+	}
+	return string(body)
+}
+
+func checkExchange(t *testing.T, p *Proxy, scheme string) {
+	t.Helper()
+
+	// the exchange is recorded once the response is written, which can be after the client read it
+	exchanges := p.Exchanges()
+	for i := 0; i < 100 && len(exchanges) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		exchanges = p.Exchanges()
+	}
+	if len(exchanges) != 1 {
+		t.Fatalf("recorded %d exchanges, want 1", len(exchanges))
+	}
+
+	ex := exchanges[0]
+	if !strings.HasPrefix(ex.URL, scheme+"://") || !strings.HasSuffix(ex.URL, "/orders?page=2") {
+		t.Errorf("url = %s", ex.URL)
+	}
+	if ex.Status != http.StatusCreated || string(ex.RequestBody) != `{"item": "pen"}` {
+		t.Errorf("exchange = %+v", ex)
+	}
+	if p.Exchange(ex.ID) != ex {
+		t.Error("exchange is not found by id")
+	}
+}
+
+func TestProxyHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(upstreamHandler))
+	defer upstream.Close()
+
+	p := New(nil)
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	proxyURL, _ := url.Parse(srv.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	if got := send(t, client, upstream.URL); got != `{"path": "/orders", "body": {"item": "pen"}}` {
+		t.Errorf("body = %s", got)
+	}
+	checkExchange(t, p, "http")
+}
+
+func TestProxyHTTPS(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(upstreamHandler))
+	defer upstream.Close()
+
+	ca, err := LoadOrCreateCA(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(ca)
+	// trust the certificate of the test server
+	p.transport = upstream.Client().Transport
+	if err := p.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca.CertPEM())
+	proxyURL, _ := url.Parse("http://" + p.Addr())
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}}
+
+	send(t, client, upstream.URL)
+	checkExchange(t, p, "https")
+}
+
+func TestLoadOrCreateCA(t *testing.T) {
+	dir := t.TempDir()
+	created, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(created.CertPEM()) != string(loaded.CertPEM()) {
+		t.Error("the saved certificate authority is not loaded")
+	}
+}
+
+func TestToRequest(t *testing.T) {
+	ex := &Exchange{
+		Method: http.MethodPost,
+		URL:    "https://shop.example.com/orders?page=2&tag=a",
+		RequestHeader: http.Header{
+			"Content-Type":    {"application/json"},
+			"Authorization":   {"Bearer token"},
+			"Accept-Encoding": {"gzip"},
+		},
+		RequestBody:    []byte(`{"item": "pen"}`),
+		Status:         http.StatusCreated,
+		ResponseHeader: http.Header{"Content-Type": {"application/json"}},
+		ResponseBody:   []byte(`{"id": 1}`),
+	}
+
+	req := ToRequest(ex)
+	if req.MetaData.Name != "POST /orders" {
+		t.Errorf("name = %s", req.MetaData.Name)
+	}
+
+	spec := req.Spec.HTTP
+	if spec.Method != domain.RequestMethodPOST || spec.URL != ex.URL {
+		t.Errorf("method = %s, url = %s", spec.Method, spec.URL)
+	}
+	if body := spec.Request.Body; body.Type != domain.BodyTypeJSON || body.Data != `{"item": "pen"}` {
+		t.Errorf("body = %+v", body)
+	}
+	if n := len(spec.Request.QueryParams); n != 2 {
+		t.Errorf("%d query params, want 2", n)
+	}
+	if n := len(spec.Request.Headers); n != 2 {
+		t.Errorf("%d headers, want 2 without Accept-Encoding", n)
+	}
+	if len(spec.Responses) != 1 || spec.Responses[0].StatusCode != http.StatusCreated || spec.Responses[0].Body != `{"id": 1}` {
+		t.Errorf("responses = %+v", spec.Responses)
+	}
+}
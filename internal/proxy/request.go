@@ -0,0 +1,142 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// skippedHeaders are set by the clients for each request, they are not saved with the recorded requests.
+var skippedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Accept-Encoding":   true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+}
+
+// ToRequest converts the exchange to an http request, its response is saved as the example of the request.
+func ToRequest(ex *Exchange) *domain.Request {
+	name := ex.Method + " " + ex.URL
+	if u, err := url.Parse(ex.URL); err == nil {
+		name = ex.Method + " " + u.Path
+		if u.Path == "" {
+			name += "/"
+		}
+	}
+
+	req := domain.NewHTTPRequest(name)
+	spec := req.Spec.HTTP
+	spec.Method = strings.ToUpper(ex.Method)
+	spec.URL = ex.URL
+
+	httpReq := &domain.HTTPRequest{
+		Headers:     keyValues(ex.RequestHeader, true),
+		PathParams:  make([]domain.KeyValue, 0),
+		QueryParams: make([]domain.KeyValue, 0),
+		Body:        requestBody(ex),
+		Auth:        domain.Auth{Type: domain.AuthTypeNone},
+	}
+	if _, rawQuery, ok := strings.Cut(ex.URL, "?"); ok {
+		httpReq.QueryParams = append(httpReq.QueryParams, domain.ParseQueryParams(rawQuery)...)
+	}
+
+	// the multipart boundary of the recorded body does not match the one of the new body
+	if httpReq.Body.Type == domain.BodyTypeFormData {
+		for i, h := range httpReq.Headers {
+			if strings.EqualFold(h.Key, "Content-Type") {
+				httpReq.Headers = append(httpReq.Headers[:i], httpReq.Headers[i+1:]...)
+				break
+			}
+		}
+	}
+	spec.Request = httpReq
+
+	if ex.Error == "" && !ex.Truncated {
+		spec.Responses = []domain.HTTPResponse{{
+			StatusCode: ex.Status,
+			Headers:    keyValues(ex.ResponseHeader, false),
+			Body:       string(ex.ResponseBody),
+		}}
+	}
+	return req
+}
+
+func keyValues(h http.Header, skip bool) []domain.KeyValue {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		if !skip || !skippedHeaders[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	out := make([]domain.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range h[k] {
+			out = append(out, domain.KeyValue{ID: uuid.NewString(), Key: k, Value: v, Enable: true})
+		}
+	}
+	return out
+}
+
+func requestBody(ex *Exchange) domain.Body {
+	if len(ex.RequestBody) == 0 {
+		return domain.Body{Type: domain.BodyTypeNone}
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(ex.RequestHeader.Get("Content-Type"))
+	data := string(ex.RequestBody)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return domain.Body{Type: domain.BodyTypeUrlencoded, URLEncoded: domain.ParseQueryParams(data)}
+	case mediaType == "multipart/form-data":
+		if fields, ok := formFields(ex.RequestBody, params["boundary"]); ok {
+			return domain.Body{Type: domain.BodyTypeFormData, FormData: domain.FormData{Fields: fields}}
+		}
+		return domain.Body{Type: domain.BodyTypeText, Data: data}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || json.Valid(ex.RequestBody):
+		return domain.Body{Type: domain.BodyTypeJSON, Data: data}
+	case strings.Contains(mediaType, "xml"):
+		return domain.Body{Type: domain.BodyTypeXML, Data: data}
+	default:
+		return domain.Body{Type: domain.BodyTypeText, Data: data}
+	}
+}
+
+// formFields returns the fields of the multipart body, the files have to be selected again as only
+// their content was recorded.
+func formFields(body []byte, boundary string) ([]domain.FormField, bool) {
+	if boundary == "" {
+		return nil, false
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	fields := make([]domain.FormField, 0)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+
+		field := domain.FormField{ID: uuid.NewString(), Type: domain.FormFieldTypeText, Key: part.FormName(), Enable: true}
+		if part.FileName() != "" {
+			field.Type = domain.FormFieldTypeFile
+		} else {
+			var value bytes.Buffer
+			_, _ = value.ReadFrom(part)
+			field.Value = value.String()
+		}
+		fields = append(fields, field)
+	}
+	return fields, len(fields) > 0
+}
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/egress"
+)
+
+// HTML writes a self-contained HTML page of the run, its styles are inlined so it can be shared as a single file.
+type HTML struct{}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms":        func(d time.Duration) string { return fmt.Sprintf("%.1f ms", milliseconds(d)) },
+	"iteration": iterationName,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} run report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+.url { font-family: monospace; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<p>Started {{.Started.Format "2006-01-02 15:04:05"}}, took {{ms .Duration}},
+{{- with .Failures}} <span class="failed">{{.}} failed requests</span>{{else}} <span class="passed">all requests passed</span>{{end}}.</p>
+{{- with .Stopped}}
+<p class="failed">Run {{.}}.</p>
+{{- end}}
+{{- range .Iterations}}
+<h2>{{iteration .}}</h2>
+<table>
+<tr><th>Request</th><th>URL</th><th>Status</th><th>Time</th><th>Assertions</th></tr>
+{{- range .Requests}}
+<tr>
+<td>{{.Name}}</td>
+<td class="url">{{.URL}}</td>
+<td class="{{if .Failed}}failed{{else}}passed{{end}}">{{.Status}}</td>
+<td>{{ms .Duration}}</td>
+<td>
+{{- range .Assertions}}
+<div class="{{if .Passed}}passed{{else}}failed{{end}}">{{if .Passed}}&#10003;{{else}}&#10007;{{end}} {{.Name}}{{with .Message}}: {{.}}{{end}}</div>
+{{- end}}
+</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+func (HTML) Extension() string {
+	return ".html"
+}
+
+func (HTML) Report(w io.Writer, result *egress.RunResult) error {
+	return htmlReport.Execute(w, result)
+}
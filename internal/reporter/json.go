@@ -0,0 +1,105 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/egress"
+)
+
+// JSON writes every request of the run with its iteration, resolved url, timing, assertions and error.
+type JSON struct{}
+
+type jsonReport struct {
+	Name       string          `json:"name"`
+	Started    time.Time       `json:"started"`
+	Duration   float64         `json:"durationMs"`
+	Requests   int             `json:"requests"`
+	Failures   int             `json:"failures"`
+	Stopped    string          `json:"stopped,omitempty"`
+	Iterations []jsonIteration `json:"iterations"`
+}
+
+type jsonIteration struct {
+	Index    int               `json:"index"`
+	Data     map[string]string `json:"data,omitempty"`
+	Failures int               `json:"failures"`
+	Requests []jsonRequest     `json:"requests"`
+}
+
+type jsonRequest struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	URL        string          `json:"url,omitempty"`
+	Status     string          `json:"status"`
+	Passed     bool            `json:"passed"`
+	Duration   float64         `json:"durationMs"`
+	Attempts   int             `json:"attempts"`
+	Assertions []jsonAssertion `json:"assertions"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type jsonAssertion struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+func (JSON) Extension() string {
+	return ".json"
+}
+
+func (JSON) Report(w io.Writer, result *egress.RunResult) error {
+	out := jsonReport{
+		Name:       result.Name,
+		Started:    result.Started,
+		Duration:   milliseconds(result.Duration),
+		Failures:   result.Failures(),
+		Stopped:    result.Stopped,
+		Iterations: make([]jsonIteration, 0, len(result.Iterations)),
+	}
+
+	for _, it := range result.Iterations {
+		iteration := jsonIteration{
+			Index:    it.Index,
+			Failures: it.Failures(),
+			Requests: make([]jsonRequest, 0, len(it.Requests)),
+		}
+		if len(it.Data) > 0 {
+			iteration.Data = make(map[string]string, len(it.Data))
+			for _, kv := range it.Data {
+				iteration.Data[kv.Key] = kv.Value
+			}
+		}
+
+		for _, r := range it.Requests {
+			req := jsonRequest{
+				ID:         r.RequestID,
+				Name:       r.Name,
+				URL:        r.URL,
+				Status:     r.Status,
+				Passed:     !r.Failed,
+				Duration:   milliseconds(r.Duration),
+				Attempts:   r.Attempts,
+				Assertions: make([]jsonAssertion, 0, len(r.Assertions)),
+				Error:      r.Error,
+			}
+			for _, a := range r.Assertions {
+				req.Assertions = append(req.Assertions, jsonAssertion{Name: a.Name, Passed: a.Passed, Message: a.Message})
+			}
+			iteration.Requests = append(iteration.Requests, req)
+		}
+
+		out.Requests += len(it.Requests)
+		out.Iterations = append(out.Iterations, iteration)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,116 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/egress"
+)
+
+// JUnit writes a JUnit XML report, each iteration is a test suite and each request a test case.
+type JUnit struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (JUnit) Extension() string {
+	return ".xml"
+}
+
+func (JUnit) Report(w io.Writer, result *egress.RunResult) error {
+	out := junitTestSuites{
+		Name:     result.Name,
+		Failures: result.Failures(),
+		Time:     seconds(result.Duration),
+	}
+
+	for _, it := range result.Iterations {
+		suite := junitTestSuite{
+			Name:      iterationName(it),
+			Tests:     len(it.Requests),
+			Failures:  it.Failures(),
+			Timestamp: result.Started.Format("2006-01-02T15:04:05"),
+		}
+
+		var elapsed time.Duration
+		for _, r := range it.Requests {
+			elapsed += r.Duration
+			tc := junitTestCase{
+				Name:      r.Name,
+				ClassName: result.Name,
+				Time:      seconds(r.Duration),
+				SystemOut: r.URL,
+			}
+			if r.Failed {
+				tc.Failure = failure(r)
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Time = seconds(elapsed)
+
+		out.Tests += suite.Tests
+		out.Suites = append(out.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func failure(r *egress.RequestResult) *junitFailure {
+	var messages []string
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			messages = append(messages, a.Name+": "+a.Message)
+		}
+	}
+
+	f := &junitFailure{Message: "status " + r.Status, Type: "assertion", Text: strings.Join(messages, "\n")}
+	if r.Status == egress.StatusError {
+		f.Message, f.Type = r.Error, "error"
+	}
+	return f
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,67 @@
+// Package reporter writes the results of the runs of requests and collections in the formats of the
+// continuous integration tools and for people to read.
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/egress"
+)
+
+// Reporter writes the result of a run in its format.
+type Reporter interface {
+	// Extension is the extension of the files of the reports, such as ".xml".
+	Extension() string
+	Report(w io.Writer, result *egress.RunResult) error
+}
+
+var reporters = map[string]Reporter{
+	"junit": JUnit{},
+	"json":  JSON{},
+	"html":  HTML{},
+}
+
+// Register adds a reporter, it replaces the reporter registered with the same name.
+func Register(name string, r Reporter) {
+	reporters[name] = r
+}
+
+// Get returns the reporter registered with the name.
+func Get(name string) (Reporter, error) {
+	r, ok := reporters[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown reporter %s, the reporters are %s", name, strings.Join(Names(), ", "))
+	}
+	return r, nil
+}
+
+// Names returns the names of the registered reporters.
+func Names() []string {
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dataString formats the variables of an iteration as name=value pairs.
+func dataString(it *egress.IterationResult) string {
+	values := make([]string, 0, len(it.Data))
+	for _, kv := range it.Data {
+		values = append(values, kv.Key+"="+kv.Value)
+	}
+	return strings.Join(values, ", ")
+}
+
+// iterationName names the iteration after its index and its variables.
+func iterationName(it *egress.IterationResult) string {
+	name := fmt.Sprintf("Iteration %d", it.Index+1)
+	if data := dataString(it); data != "" {
+		name += " (" + data + ")"
+	}
+	return name
+}
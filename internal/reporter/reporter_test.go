@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+)
+
+func sampleResult() *egress.RunResult {
+	passed := &egress.RequestResult{
+		Name:       "Get user",
+		URL:        "https://api.example.com/users/alice",
+		Status:     "200",
+		Duration:   120 * time.Millisecond,
+		Assertions: []egress.Assertion{{Name: "status is successful", Passed: true}},
+	}
+	failed := &egress.RequestResult{
+		Iteration:  1,
+		Name:       "Get user",
+		URL:        "https://api.example.com/users/<bob>",
+		Status:     "404",
+		Failed:     true,
+		Duration:   80 * time.Millisecond,
+		Assertions: []egress.Assertion{{Name: "status is successful", Message: "status is 404"}},
+	}
+
+	return &egress.RunResult{
+		Name:     "Users",
+		Started:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Duration: 200 * time.Millisecond,
+		Iterations: []*egress.IterationResult{
+			{Index: 0, Data: []domain.KeyValue{{Key: "user", Value: "alice"}}, Requests: []*egress.RequestResult{passed}},
+			{Index: 1, Data: []domain.KeyValue{{Key: "user", Value: "bob"}}, Requests: []*egress.RequestResult{failed}},
+		},
+	}
+}
+
+func report(t *testing.T, name string) string {
+	t.Helper()
+
+	r, err := Get(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Report(&buf, sampleResult()); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestJUnit(t *testing.T) {
+	var suites junitTestSuites
+	if err := xml.Unmarshal([]byte(report(t, "junit")), &suites); err != nil {
+		t.Fatal(err)
+	}
+
+	if suites.Tests != 2 || suites.Failures != 1 || len(suites.Suites) != 2 {
+		t.Fatalf("suites = %+v", suites)
+	}
+	if name := suites.Suites[1].Name; name != "Iteration 2 (user=bob)" {
+		t.Errorf("suite name = %s", name)
+	}
+	if f := suites.Suites[1].Cases[0].Failure; f == nil || f.Message != "status 404" {
+		t.Errorf("failure = %+v", f)
+	}
+	if suites.Suites[0].Cases[0].Time != "0.120" {
+		t.Errorf("time = %s", suites.Suites[0].Cases[0].Time)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var out jsonReport
+	if err := json.Unmarshal([]byte(report(t, "json")), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Requests != 2 || out.Failures != 1 || len(out.Iterations) != 2 {
+		t.Fatalf("report = %+v", out)
+	}
+	req := out.Iterations[1].Requests[0]
+	if req.Passed || req.URL != "https://api.example.com/users/<bob>" || req.Duration != 80 || len(req.Assertions) != 1 {
+		t.Errorf("request = %+v", req)
+	}
+	if out.Iterations[0].Data["user"] != "alice" {
+		t.Errorf("data = %v", out.Iterations[0].Data)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	out := report(t, "html")
+	for _, want := range []string{"<h1>Users</h1>", "1 failed requests", "Iteration 2 (user=bob)", "/users/&lt;bob&gt;", "status is 404"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+}
+
+func TestGetUnknown(t *testing.T) {
+	if _, err := Get("pdf"); err == nil {
+		t.Error("expected an error for an unknown reporter")
+	}
+}
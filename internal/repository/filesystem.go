@@ -7,10 +7,12 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 
 	"gopkg.in/yaml.v2"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/vault"
 )
 
 const (
@@ -21,12 +23,20 @@ const (
 	collectionsDir  = "collections"
 	requestsDir     = "requests"
 	preferencesDir  = "preferences"
+
+	// vaultFile is kept in the config directory, outside the workspaces, so it is not shared with them.
+	vaultFile = "secrets.vault"
+	// VaultPassphraseEnv unlocks the vault on start when it is set.
+	VaultPassphraseEnv = "CHAPAR_VAULT_PASSPHRASE"
 )
 
 var _ Repository = &Filesystem{}
 
 type Filesystem struct {
 	ActiveWorkspace *domain.Workspace
+
+	// vault holds the secret values of the environments, nil while the vault is locked.
+	vault atomic.Pointer[vault.Vault]
 }
 
 func NewFilesystem() (*Filesystem, error) {
@@ -60,9 +70,88 @@ func NewFilesystem() (*Filesystem, error) {
 		fs.ActiveWorkspace = ws
 	}
 
+	if passphrase := os.Getenv(VaultPassphraseEnv); passphrase != "" {
+		// a wrong passphrase keeps the vault locked so it can be unlocked from the app
+		_ = fs.UnlockVault(passphrase)
+	}
+
 	return fs, nil
 }
 
+// UnlockVault opens the vault of the secret values with the passphrase, the vault is created on first use.
+func (f *Filesystem) UnlockVault(passphrase string) error {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	v, err := vault.Open(filepath.Join(dir, vaultFile), passphrase)
+	if err != nil {
+		return err
+	}
+
+	f.vault.Store(v)
+	return nil
+}
+
+func (f *Filesystem) IsVaultLocked() bool {
+	return f.vault.Load() == nil
+}
+
+func secretKey(envID, valueID string) string {
+	return envID + "/" + valueID
+}
+
+// fillSecrets sets the values of the secrets of the environment from the vault, they stay empty while the vault is locked.
+func (f *Filesystem) fillSecrets(env *domain.Environment) {
+	v := f.vault.Load()
+	if v == nil {
+		return
+	}
+
+	for i, kv := range env.Spec.Values {
+		if !kv.Secret {
+			continue
+		}
+
+		if value, ok := v.Get(secretKey(env.MetaData.ID, kv.ID)); ok {
+			env.Spec.Values[i].Value = value
+		}
+	}
+}
+
+// storeSecrets writes the secrets of the environment to the vault and removes the ones which are no longer secret.
+func (f *Filesystem) storeSecrets(env *domain.Environment) error {
+	v := f.vault.Load()
+	if v == nil {
+		for _, kv := range env.Spec.Values {
+			if kv.Secret && kv.Value != "" {
+				return fmt.Errorf("failed to save secret %s, %w", kv.Key, vault.ErrLocked)
+			}
+		}
+		return nil
+	}
+
+	keep := make(map[string]bool)
+	for _, kv := range env.Spec.Values {
+		if !kv.Secret {
+			continue
+		}
+
+		key := secretKey(env.MetaData.ID, kv.ID)
+		keep[key] = true
+		v.Set(key, kv.Value)
+	}
+
+	for _, key := range v.Keys(env.MetaData.ID + "/") {
+		if !keep[key] {
+			v.Delete(key)
+		}
+	}
+
+	return v.Save()
+}
+
 func (f *Filesystem) GetProtoFilesDir() (string, error) {
 	dir, err := CreateConfigDir()
 	if err != nil {
@@ -360,6 +449,11 @@ func (f *Filesystem) LoadCollections() ([]*domain.Collection, error) {
 }
 
 func (f *Filesystem) loadCollection(collectionPath string) (*domain.Collection, error) {
+	return LoadCollection(collectionPath)
+}
+
+// LoadCollection reads the collection and its requests from the directory of the collection.
+func LoadCollection(collectionPath string) (*domain.Collection, error) {
 	// Read the collection metadata
 	collectionMetadataPath := filepath.Join(collectionPath, "_collection.yaml")
 	collectionMetadata, err := os.ReadFile(collectionMetadataPath)
@@ -399,6 +493,8 @@ func (f *Filesystem) loadCollection(collectionPath string) (*domain.Collection,
 		req.CollectionName = collection.MetaData.Name
 		collection.Spec.Requests = append(collection.Spec.Requests, req)
 	}
+
+	collection.SortRequests()
 	return collection, nil
 }
 
@@ -501,6 +597,7 @@ func (f *Filesystem) LoadEnvironments() ([]*domain.Environment, error) {
 			return nil, err
 		}
 		env.FilePath = filePath
+		f.fillSecrets(env)
 		out = append(out, env)
 	}
 
@@ -514,6 +611,7 @@ func (f *Filesystem) GetEnvironment(filepath string) (*domain.Environment, error
 	}
 
 	env.FilePath = filepath
+	f.fillSecrets(env)
 	return env, nil
 }
 
@@ -532,7 +630,14 @@ func (f *Filesystem) GetEnvironmentDir() (string, error) {
 }
 
 func (f *Filesystem) UpdateEnvironment(env *domain.Environment) error {
-	if err := SaveToYaml(env.FilePath, env); err != nil {
+	if err := f.storeSecrets(env); err != nil {
+		return err
+	}
+
+	// the secrets are kept in the vault so the yaml can be shared
+	stored := *env
+	stored.Spec = env.Spec.WithoutSecrets()
+	if err := SaveToYaml(env.FilePath, &stored); err != nil {
 		return err
 	}
 
@@ -558,7 +663,18 @@ func (f *Filesystem) GetNewEnvironmentFilePath(name string) (*FilePath, error) {
 }
 
 func (f *Filesystem) DeleteEnvironment(env *domain.Environment) error {
-	return os.Remove(env.FilePath)
+	if err := os.Remove(env.FilePath); err != nil {
+		return err
+	}
+
+	if v := f.vault.Load(); v != nil {
+		for _, key := range v.Keys(env.MetaData.ID + "/") {
+			v.Delete(key)
+		}
+		return v.Save()
+	}
+
+	return nil
 }
 
 func (f *Filesystem) ReadPreferencesData() (*domain.Preferences, error) {
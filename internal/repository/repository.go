@@ -24,6 +24,9 @@ type Repository interface {
 	DeleteEnvironment(env *domain.Environment) error
 	GetNewEnvironmentFilePath(name string) (*FilePath, error)
 
+	UnlockVault(passphrase string) error
+	IsVaultLocked() bool
+
 	ReadPreferencesData() (*domain.Preferences, error)
 	UpdatePreferences(pref *domain.Preferences) error
 
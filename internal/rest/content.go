@@ -0,0 +1,401 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"  // register the gif decoder
+	_ "image/jpeg" // register the jpeg decoder
+	_ "image/png"  // register the png decoder
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	_ "golang.org/x/image/webp" // register the webp decoder
+	"golang.org/x/net/html"
+)
+
+// Kinds of the content of a response body, each one has its own viewer.
+const (
+	ContentJSON   = "json"
+	ContentXML    = "xml"
+	ContentHTML   = "html"
+	ContentImage  = "image"
+	ContentPDF    = "pdf"
+	ContentText   = "text"
+	ContentBinary = "binary"
+)
+
+const (
+	// MaxImagePixels is the largest image, in pixels, which is decoded for the preview.
+	MaxImagePixels = 40_000_000
+	// svgSize is the size the longest side of an svg image is rasterized to.
+	svgSize = 1024
+)
+
+// DetectContent returns the kind of the body from its content type header, or from its bytes when the
+// header is missing or too generic.
+func DetectContent(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" || mediaType == "application/octet-stream" || mediaType == "text/plain" {
+		if len(body) > 0 && IsJSON(string(body)) {
+			return ContentJSON
+		}
+		if mediaType != "text/plain" {
+			mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+		}
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if IsJSON(string(body)) {
+			return ContentJSON
+		}
+		return ContentText
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return ContentHTML
+	case mediaType == "image/svg+xml", mediaType == "image/png", mediaType == "image/jpeg",
+		mediaType == "image/gif", mediaType == "image/webp":
+		return ContentImage
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return ContentXML
+	case mediaType == "application/pdf":
+		return ContentPDF
+	case strings.HasPrefix(mediaType, "text/"), mediaType == "application/javascript",
+		mediaType == "application/x-www-form-urlencoded", mediaType == "application/yaml":
+		return ContentText
+	}
+
+	if utf8.Valid(body) && !bytes.ContainsRune(body, 0) {
+		return ContentText
+	}
+	return ContentBinary
+}
+
+// XMLNode is a node of an xml document. Start is the start tag of an element, or the whole comment,
+// processing instruction or directive. An element holding only text has it in Text and no children.
+type XMLNode struct {
+	Start    string
+	Text     string
+	End      string
+	Children []*XMLNode
+}
+
+// ParseXML returns the top level nodes of the document, the prefixes of the names are kept as they are.
+func ParseXML(data []byte) ([]*XMLNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	root := &XMLNode{}
+	stack := []*XMLNode{root}
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &XMLNode{Start: startTag(t), End: "</" + xmlName(t.Name) + ">"}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected end element %s", xmlName(t.Name))
+			}
+			if len(parent.Children) == 1 && parent.Children[0].Start == "" {
+				parent.Text, parent.Children = parent.Children[0].Text, nil
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if text := strings.TrimSpace(string(t)); text != "" {
+				parent.Children = append(parent.Children, &XMLNode{Text: escapeXML(text)})
+			}
+		case xml.Comment:
+			parent.Children = append(parent.Children, &XMLNode{Start: "<!--" + string(t) + "-->"})
+		case xml.ProcInst:
+			parent.Children = append(parent.Children, &XMLNode{Start: "<?" + t.Target + " " + string(t.Inst) + "?>"})
+		case xml.Directive:
+			parent.Children = append(parent.Children, &XMLNode{Start: "<!" + string(t) + ">"})
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, errors.New("unexpected end of the document")
+	}
+	if len(root.Children) == 0 {
+		return nil, errors.New("document is empty")
+	}
+	return root.Children, nil
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+func startTag(e xml.StartElement) string {
+	var b strings.Builder
+	b.WriteString("<" + xmlName(e.Name))
+	for _, a := range e.Attr {
+		b.WriteString(" " + xmlName(a.Name) + `="` + escapeXML(a.Value) + `"`)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// Line returns the node on a single line, the children of an element are left out.
+func (n *XMLNode) Line() string {
+	if n.Start == "" {
+		return n.Text
+	}
+	if n.End == "" {
+		return n.Start
+	}
+	if len(n.Children) == 0 {
+		if n.Text == "" {
+			return strings.TrimSuffix(n.Start, ">") + "/>"
+		}
+		return n.Start + n.Text + n.End
+	}
+	return n.Start
+}
+
+// PrettyXML indents the xml document, with the same indentation as PrettyJSON.
+func PrettyXML(data []byte) (string, error) {
+	nodes, err := ParseXML(data)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	var write func(nodes []*XMLNode, depth int)
+	write = func(nodes []*XMLNode, depth int) {
+		indent := strings.Repeat("    ", depth)
+		for _, n := range nodes {
+			b.WriteString(indent + n.Line() + "\n")
+			if len(n.Children) > 0 {
+				write(n.Children, depth+1)
+				b.WriteString(indent + n.End + "\n")
+			}
+		}
+	}
+	write(nodes, 0)
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// blockElements start a new line in the text of an html document.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true,
+	"title": true, "tr": true, "ul": true,
+}
+
+// HTMLText returns the text of the html document as it reads in a browser, without the markup, scripts
+// and styles. Blocks are on their own lines, list items are bulleted and images are replaced by their alt text.
+func HTMLText(data []byte) string {
+	var (
+		b       strings.Builder
+		skip    int
+		pre     int
+		newline = true
+		// space is set when the last text ended with a space, so it is kept before the next one
+		space bool
+	)
+
+	breakLine := func() {
+		if !newline {
+			b.WriteString("\n")
+			newline = true
+		}
+		space = false
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		tok := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok.Data {
+			case "script", "style", "noscript", "template":
+				if tt == html.StartTagToken {
+					skip++
+				}
+				continue
+			case "pre":
+				pre++
+			case "img":
+				for _, a := range tok.Attr {
+					if a.Key == "alt" && a.Val != "" {
+						b.WriteString("[" + a.Val + "]")
+						newline = false
+					}
+				}
+			case "td", "th":
+				if !newline {
+					b.WriteString("\t")
+				}
+			}
+			if blockElements[tok.Data] {
+				breakLine()
+			}
+			if tok.Data == "li" {
+				b.WriteString("• ")
+				newline = false
+			}
+		case html.EndTagToken:
+			switch tok.Data {
+			case "script", "style", "noscript", "template":
+				skip = max(skip-1, 0)
+			case "pre":
+				pre = max(pre-1, 0)
+			}
+			if blockElements[tok.Data] {
+				breakLine()
+			}
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			text := tok.Data
+			if pre == 0 {
+				lead := strings.TrimLeft(text, " \t\r\n") != text
+				trail := strings.TrimRight(text, " \t\r\n") != text
+				if text = strings.Join(strings.Fields(text), " "); text == "" {
+					space = space || lead
+					continue
+				}
+				if (space || lead) && !newline {
+					b.WriteString(" ")
+				}
+				space = trail
+			}
+			b.WriteString(text)
+			newline = strings.HasSuffix(text, "\n")
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimRight(line, " \t"); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// HexDump returns the offsets, bytes and printable characters of the first limit bytes of the data.
+func HexDump(data []byte, limit int) string {
+	if limit > 0 && len(data) > limit {
+		data = data[:limit]
+	}
+	return strings.TrimSuffix(hex.Dump(data), "\n")
+}
+
+// DecodeImage decodes a png, jpeg, gif or webp image, or rasterizes an svg one. Images larger than
+// MaxImagePixels are not decoded.
+func DecodeImage(data []byte) (image.Image, error) {
+	if isSVG(data) {
+		return rasterizeSVG(data)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, fmt.Errorf("image is too large to preview, %dx%d pixels", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+func isSVG(data []byte) bool {
+	head := data[:min(len(data), 1024)]
+	return bytes.Contains(head, []byte("<svg"))
+}
+
+func rasterizeSVG(data []byte) (image.Image, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	w, h := icon.ViewBox.W, icon.ViewBox.H
+	if w <= 0 || h <= 0 {
+		w, h = svgSize, svgSize
+	}
+	scale := svgSize / math.Max(w, h)
+	width, height := int(math.Ceil(w*scale)), int(math.Ceil(h*scale))
+
+	icon.SetTarget(0, 0, float64(width), float64(height))
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+	scanner := rasterx.NewScannerGV(width, height, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(width, height, scanner), 1)
+	return img, nil
+}
+
+// FileExtension returns the extension a body of the content type is saved with.
+func FileExtension(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	case "text/plain":
+		if len(body) > 0 && IsJSON(string(body)) {
+			return ".json"
+		}
+		return ".txt"
+	}
+
+	switch DetectContent(mediaType, body) {
+	case ContentJSON:
+		return ".json"
+	case ContentXML:
+		return ".xml"
+	case ContentHTML:
+		return ".html"
+	}
+
+	if ext, err := mime.ExtensionsByType(mediaType); err == nil && len(ext) > 0 {
+		return ext[0]
+	}
+	return ".bin"
+}
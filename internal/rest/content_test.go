@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestDetectContent(t *testing.T) {
+	var pngBody bytes.Buffer
+	_ = png.Encode(&pngBody, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        string
+	}{
+		{"json", "application/json; charset=utf-8", []byte(`{"a":1}`), ContentJSON},
+		{"invalid json", "application/json", []byte(`{"a":`), ContentText},
+		{"problem json", "application/problem+json", []byte(`{}`), ContentJSON},
+		{"xml", "application/xml", []byte(`<a/>`), ContentXML},
+		{"atom", "application/atom+xml", []byte(`<feed/>`), ContentXML},
+		{"html", "text/html; charset=utf-8", []byte(`<p>hi</p>`), ContentHTML},
+		{"svg", "image/svg+xml", []byte(`<svg/>`), ContentImage},
+		{"sniffed png", "", pngBody.Bytes(), ContentImage},
+		{"octet stream json", "application/octet-stream", []byte(`[1, 2]`), ContentJSON},
+		{"pdf", "application/pdf", []byte("%PDF-1.7"), ContentPDF},
+		{"text", "text/csv", []byte("a,b"), ContentText},
+		{"binary", "application/octet-stream", []byte{0x00, 0xff, 0x10}, ContentBinary},
+	}
+
+	for _, tt := range tests {
+		if got := DetectContent(tt.contentType, tt.body); got != tt.want {
+			t.Errorf("%s: DetectContent() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrettyXML(t *testing.T) {
+	got, err := PrettyXML([]byte(`<?xml version="1.0"?><soap:Envelope xmlns:soap="urn:x"><soap:Body><!-- c --><user id="1"><name>A &amp; B</name><tags/></user></soap:Body></soap:Envelope>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="urn:x">
+    <soap:Body>
+        <!-- c -->
+        <user id="1">
+            <name>A &amp; B</name>
+            <tags/>
+        </user>
+    </soap:Body>
+</soap:Envelope>`
+	if got != want {
+		t.Errorf("PrettyXML() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := PrettyXML([]byte(`<a><b></a>`)); err == nil {
+		t.Error("expected an error for a malformed document")
+	}
+}
+
+func TestHTMLText(t *testing.T) {
+	got := HTMLText([]byte(`<html><head><title>Home</title><style>p{color:red}</style></head>
+<body><h1>Welcome</h1><p>Hello   <b>world</b> &amp; friends</p><script>alert(1)</script>
+<ul><li>one</li><li>two</li></ul><img alt="logo"></body></html>`))
+
+	want := "Home\nWelcome\nHello world & friends\n• one\n• two\n[logo]"
+	if got != want {
+		t.Errorf("HTMLText() = %q, want %q", got, want)
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	got := HexDump([]byte("chapar rest client"), 8)
+	if !strings.HasPrefix(got, "00000000  63 68 61 70 61 72 20 72") || strings.Contains(got, "client") {
+		t.Errorf("HexDump() = %q", got)
+	}
+}
+
+func TestDecodeImage(t *testing.T) {
+	img, err := DecodeImage([]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 20 10"><rect width="20" height="10" fill="#ff0000"/></svg>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != svgSize || b.Dy() != svgSize/2 {
+		t.Fatalf("bounds = %v", b)
+	}
+	if r, _, _, a := img.At(svgSize/2, svgSize/4).RGBA(); r != 0xffff || a != 0xffff {
+		t.Errorf("center = %v", color.RGBAModel.Convert(img.At(svgSize/2, svgSize/4)))
+	}
+
+	if _, err := DecodeImage([]byte("not an image")); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFileExtension(t *testing.T) {
+	for contentType, want := range map[string]string{
+		"image/jpeg":                      ".jpg",
+		"application/json; charset=utf-8": ".json",
+		"application/pdf":                 ".pdf",
+		"":                                ".bin",
+	} {
+		if got := FileExtension(contentType, []byte{0x00, 0x01}); got != want {
+			t.Errorf("FileExtension(%q) = %s, want %s", contentType, got, want)
+		}
+	}
+}
@@ -19,6 +19,8 @@ import (
 )
 
 type Response struct {
+	// URL is the url the request was sent to, with the variables resolved.
+	URL        string
 	StatusCode int
 	Headers    map[string]string
 	Cookies    []*http.Cookie
@@ -33,16 +35,24 @@ type Response struct {
 type Service struct {
 	requests     *state.Requests
 	environments *state.Environments
+	variables    *variables.Provider
 }
 
-func New(requests *state.Requests, environments *state.Environments) *Service {
+func New(requests *state.Requests, environments *state.Environments, variables *variables.Provider) *Service {
 	return &Service{
 		requests:     requests,
 		environments: environments,
+		variables:    variables,
 	}
 }
 
 func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*Response, error) {
+	return s.SendRequestWithValues(requestID, activeEnvironmentID, variables.Values{})
+}
+
+// SendRequestWithValues sends the request with the values of an iteration data row and of the responses of
+// its prerequisites layered above the environment.
+func (s *Service) SendRequestWithValues(requestID, activeEnvironmentID string, values variables.Values) (*Response, error) {
 	req := s.requests.GetRequest(requestID)
 	if req == nil {
 		return nil, fmt.Errorf("request with id %s not found", requestID)
@@ -51,16 +61,14 @@ func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*Response,
 	// clone the request to make sure we do not modify the original request
 	r := req.Clone()
 
-	var activeEnvironment *domain.Environment
 	// Get environment if provided
-	if activeEnvironmentID != "" {
-		activeEnvironment = s.environments.GetEnvironment(activeEnvironmentID)
-		if activeEnvironment == nil {
-			return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
-		}
+	if activeEnvironmentID != "" && s.environments.GetEnvironment(activeEnvironmentID) == nil {
+		return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
 	}
 
-	response, err := s.sendRequest(r.Spec.HTTP, activeEnvironment)
+	scopes := s.variables.Scopes(r, activeEnvironmentID)
+	scopes.SetValues(values)
+	response, err := s.sendRequest(r.Spec.HTTP, scopes)
 	if err != nil {
 		return nil, err
 	}
@@ -68,18 +76,68 @@ func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*Response,
 	return response, nil
 }
 
-func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment) (*Response, error) {
+// UnresolvedVariables returns the variables used in the request which can not be resolved with the active environment.
+func (s *Service) UnresolvedVariables(requestID, activeEnvironmentID string) ([]variables.Warning, error) {
+	req := s.requests.GetRequest(requestID)
+	if req == nil {
+		return nil, fmt.Errorf("request with id %s not found", requestID)
+	}
+
+	if activeEnvironmentID != "" && s.environments.GetEnvironment(activeEnvironmentID) == nil {
+		return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
+	}
+
+	r := req.Clone()
+	return applyVariables(r.Spec.HTTP, s.variables.Scopes(r, activeEnvironmentID)), nil
+}
+
+// ResolveRequestSpec returns a copy of the request spec with the variables and the active environment applied.
+func (s *Service) ResolveRequestSpec(requestID, activeEnvironmentID string) (*domain.HTTPRequestSpec, error) {
+	req := s.requests.GetRequest(requestID)
+	if req == nil {
+		return nil, fmt.Errorf("request with id %s not found", requestID)
+	}
+
+	if activeEnvironmentID != "" && s.environments.GetEnvironment(activeEnvironmentID) == nil {
+		return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
+	}
+
+	r := req.Clone()
+	if r.Spec.HTTP == nil {
+		return nil, fmt.Errorf("request with id %s is not an http request", requestID)
+	}
+
+	applyVariables(r.Spec.HTTP, s.variables.Scopes(r, activeEnvironmentID))
+	return r.Spec.HTTP, nil
+}
+
+// VariableScopes returns the variables known to the request and the scope each of them is defined in.
+func (s *Service) VariableScopes(requestID, activeEnvironmentID string) map[string]variables.Scope {
+	req := s.requests.GetRequest(requestID)
+	if req == nil {
+		return nil
+	}
+
+	_, sources := s.variables.Scopes(req.Clone(), activeEnvironmentID).Variables()
+	return sources
+}
+
+// DescribeVariable returns the resolved value of the variable for the request and the scope it is defined in.
+func (s *Service) DescribeVariable(requestID, activeEnvironmentID, name string) (string, variables.Scope, bool) {
+	req := s.requests.GetRequest(requestID)
+	if req == nil {
+		return "", "", false
+	}
+
+	return s.variables.Scopes(req.Clone(), activeEnvironmentID).Describe(name)
+}
+
+func (s *Service) sendRequest(req *domain.HTTPRequestSpec, scopes variables.Scopes) (*Response, error) {
 	// prepare request
 	// - apply environment
 	// - apply variables
 	// - apply authentication (if any) is not already applied to the headers
-
-	if e == nil {
-		applyVariables(req, nil)
-	} else {
-		env := e.Clone()
-		applyVariables(req, &env.Spec)
-	}
+	applyVariables(req, scopes)
 
 	httpReq, err := http.NewRequest(req.Method, req.URL, nil)
 	if err != nil {
@@ -164,6 +222,7 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 
 	// handle response
 	response := &Response{
+		URL:        httpReq.URL.String(),
 		StatusCode: res.StatusCode,
 		Headers:    map[string]string{},
 		Cookies:    res.Cookies(),
@@ -282,93 +341,11 @@ func (s *Service) applyBody(req *domain.HTTPRequestSpec, httpReq *http.Request)
 	return nil
 }
 
-// TODO refactor
-// nolint:gocyclo
-func applyVariables(req *domain.HTTPRequestSpec, env *domain.EnvSpec) {
-	// apply internal variables to environment
-	// apply environment to request
-	vars := variables.GetVariables()
-
-	// apply environment variables if any
-	if env != nil {
-		variables.ApplyToEnv(vars, env)
-	}
-
-	// apply variables to request
-	for k, v := range vars {
-		for i, kv := range req.Request.Headers {
-			// if value contain the variable in double curly braces then replace it
-			if strings.Contains(kv.Value, "{{"+k+"}}") {
-				req.Request.Headers[i].Value = strings.ReplaceAll(kv.Value, "{{"+k+"}}", v)
-			}
-		}
-
-		for i, kv := range req.Request.PathParams {
-			// if value contain the variable in double curly braces then replace it
-			if strings.Contains(kv.Value, "{{"+k+"}}") {
-				req.Request.PathParams[i].Value = strings.ReplaceAll(kv.Value, "{{"+k+"}}", v)
-			}
-		}
-
-		for i, kv := range req.Request.QueryParams {
-			// if value contain the variable in double curly braces then replace it
-			if strings.Contains(kv.Value, "{{"+k+"}}") {
-				req.Request.QueryParams[i].Value = strings.ReplaceAll(kv.Value, "{{"+k+"}}", v)
-			}
-		}
-
-		if strings.Contains(req.URL, "{{"+k+"}}") {
-			req.URL = strings.ReplaceAll(req.URL, "{{"+k+"}}", v)
-		}
-
-		if strings.Contains(req.Request.Body.Data, "{{"+k+"}}") {
-			req.Request.Body.Data = strings.ReplaceAll(req.Request.Body.Data, "{{"+k+"}}", v)
-		}
-
-		for i, field := range req.Request.Body.FormData.Fields {
-			if field.Type == domain.FormFieldTypeFile {
-				continue
-			}
-			// if value contain the variable in double curly braces then replace it
-			if strings.Contains(field.Value, "{{"+k+"}}") {
-				req.Request.Body.FormData.Fields[i].Value = strings.ReplaceAll(field.Value, "{{"+k+"}}", v)
-			}
-		}
-
-		for i, kv := range req.Request.Body.URLEncoded {
-			// if value contain the variable in double curly braces then replace it
-			if strings.Contains(kv.Value, "{{"+k+"}}") {
-				req.Request.Body.URLEncoded[i].Value = strings.ReplaceAll(kv.Value, "{{"+k+"}}", v)
-			}
-		}
-
-		if req.Request.Auth != (domain.Auth{}) && req.Request.Auth.TokenAuth != nil {
-			if strings.Contains(req.Request.Auth.TokenAuth.Token, "{{"+k+"}}") {
-				req.Request.Auth.TokenAuth.Token = strings.ReplaceAll(req.Request.Auth.TokenAuth.Token, "{{"+k+"}}", v)
-			}
-		}
-
-		if req.Request.Auth != (domain.Auth{}) && req.Request.Auth.BasicAuth != nil {
-			if strings.Contains(req.Request.Auth.BasicAuth.Username, "{{"+k+"}}") {
-				req.Request.Auth.BasicAuth.Username = strings.ReplaceAll(req.Request.Auth.BasicAuth.Username, "{{"+k+"}}", v)
-			}
-
-			if strings.Contains(req.Request.Auth.BasicAuth.Password, "{{"+k+"}}") {
-				req.Request.Auth.BasicAuth.Password = strings.ReplaceAll(req.Request.Auth.BasicAuth.Password, "{{"+k+"}}", v)
-			}
-		}
-
-		if req.Request.Auth != (domain.Auth{}) && req.Request.Auth.APIKeyAuth != nil {
-			if strings.Contains(req.Request.Auth.APIKeyAuth.Key, "{{"+k+"}}") {
-				req.Request.Auth.APIKeyAuth.Key = strings.ReplaceAll(req.Request.Auth.APIKeyAuth.Key, "{{"+k+"}}", v)
-			}
-
-			if strings.Contains(req.Request.Auth.APIKeyAuth.Value, "{{"+k+"}}") {
-				req.Request.Auth.APIKeyAuth.Value = strings.ReplaceAll(req.Request.Auth.APIKeyAuth.Value, "{{"+k+"}}", v)
-			}
-		}
-
-	}
+// applyVariables resolves the scopes and renders the variables of the request, it returns the
+// variables which could not be resolved.
+func applyVariables(req *domain.HTTPRequestSpec, scopes variables.Scopes) []variables.Warning {
+	engine, warnings := scopes.Resolve()
+	return append(warnings, variables.ApplyToHTTPRequest(engine, req)...)
 }
 
 func IsJSON(s string) bool {
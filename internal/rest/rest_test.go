@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/variables"
 )
 
 func Test_applyVariables(t *testing.T) {
@@ -23,7 +24,7 @@ func Test_applyVariables(t *testing.T) {
 		Request: &domain.HTTPRequest{},
 	}
 
-	applyVariables(sampleReq, sampleEnv)
+	applyVariables(sampleReq, variables.Scopes{Environment: sampleEnv})
 
 	if sampleEnv.Values[0].Value == "{{randomUUID4}}" {
 		t.Errorf("expected randomUUID4 but got %s", sampleEnv.Values[0].Value)
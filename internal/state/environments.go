@@ -1,11 +1,16 @@
 package state
 
 import (
+	"errors"
+	"sort"
+
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/repository"
 	"github.com/chapar-rest/chapar/internal/safemap"
 )
 
+var ErrInheritanceCycle = errors.New("environment can not extend itself or one of its descendants")
+
 type (
 	EnvironmentChangeListener       func(environment *domain.Environment, source Source, action Action)
 	ActiveEnvironmentChangeListener func(*domain.Environment)
@@ -152,3 +157,150 @@ func (m *Environments) LoadEnvironmentsFromDisk() ([]*domain.Environment, error)
 
 	return envs, nil
 }
+
+// ValidateExtends returns an error when the environment can not extend the base environment.
+func (m *Environments) ValidateExtends(id, baseID string) error {
+	for baseID != "" {
+		if baseID == id {
+			return ErrInheritanceCycle
+		}
+
+		base, ok := m.environments.Get(baseID)
+		if !ok {
+			return ErrNotFound
+		}
+		baseID = base.Spec.Extends
+	}
+	return nil
+}
+
+// chain returns the environment and its base environments, the farthest base comes first.
+// bases which no longer exist are ignored.
+func (m *Environments) chain(id string) ([]*domain.Environment, error) {
+	env, ok := m.environments.Get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	visited := map[string]bool{id: true}
+	out := []*domain.Environment{env}
+	for baseID := env.Spec.Extends; baseID != ""; {
+		if visited[baseID] {
+			return nil, ErrInheritanceCycle
+		}
+		visited[baseID] = true
+
+		base, ok := m.environments.Get(baseID)
+		if !ok {
+			break
+		}
+
+		out = append([]*domain.Environment{base}, out...)
+		baseID = base.Spec.Extends
+	}
+
+	return out, nil
+}
+
+// EffectiveValues returns the values of the environment merged with the values of its base environments,
+// values are overridden by their key.
+func (m *Environments) EffectiveValues(id string) ([]domain.EffectiveValue, error) {
+	chain, err := m.chain(id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.EffectiveValue, 0)
+	index := make(map[string]int)
+	for _, env := range chain {
+		for _, v := range env.Spec.Values {
+			if v.Key == "" {
+				continue
+			}
+
+			i, ok := index[v.Key]
+			if !ok {
+				index[v.Key] = len(out)
+				out = append(out, domain.EffectiveValue{KeyValue: v, Source: env.MetaData.ID})
+				continue
+			}
+
+			out[i] = domain.EffectiveValue{
+				KeyValue:   v,
+				Source:     env.MetaData.ID,
+				Overridden: out[i].Overridden || out[i].Source != env.MetaData.ID,
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// GetEffectiveEnvironment returns a copy of the environment which has the inherited values of its base environments.
+func (m *Environments) GetEffectiveEnvironment(id string) (*domain.Environment, error) {
+	env, ok := m.environments.Get(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	values, err := m.EffectiveValues(id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &domain.Environment{
+		ApiVersion: env.ApiVersion,
+		Kind:       env.Kind,
+		MetaData:   env.MetaData,
+		Spec: domain.EnvSpec{
+			Extends: env.Spec.Extends,
+			Values:  make([]domain.KeyValue, len(values)),
+		},
+		FilePath: env.FilePath,
+	}
+
+	for i, v := range values {
+		out.Spec.Values[i] = v.KeyValue
+	}
+
+	return out, nil
+}
+
+// MissingKeys returns the sorted keys which the siblings of the environment define and it does not,
+// siblings are the environments which extend the same base environment.
+func (m *Environments) MissingKeys(id string) []string {
+	env, ok := m.environments.Get(id)
+	if !ok {
+		return nil
+	}
+
+	own := m.effectiveKeys(id)
+	missing := make(map[string]bool)
+	for _, sibling := range m.environments.Values() {
+		if sibling.MetaData.ID == id || sibling.Spec.Extends != env.Spec.Extends {
+			continue
+		}
+
+		for key := range m.effectiveKeys(sibling.MetaData.ID) {
+			if !own[key] {
+				missing[key] = true
+			}
+		}
+	}
+
+	out := make([]string, 0, len(missing))
+	for key := range missing {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (m *Environments) effectiveKeys(id string) map[string]bool {
+	values, _ := m.EffectiveValues(id)
+	out := make(map[string]bool, len(values))
+	for _, v := range values {
+		out[v.Key] = true
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package state
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func newTestEnvironment(id, extends string, values ...string) *domain.Environment {
+	env := domain.NewEnvironment(id)
+	env.MetaData.ID = id
+	env.Spec.Extends = extends
+	for i := 0; i+1 < len(values); i += 2 {
+		env.Spec.Values = append(env.Spec.Values, domain.KeyValue{Key: values[i], Value: values[i+1], Enable: true})
+	}
+	return env
+}
+
+func TestEnvironments_Inheritance(t *testing.T) {
+	envs := NewEnvironments(nil)
+	envs.AddEnvironment(newTestEnvironment("base", "", "host", "example.com", "port", "80", "user", "admin"), SourceController)
+	envs.AddEnvironment(newTestEnvironment("dev", "base", "host", "dev.example.com", "debug", "true"), SourceController)
+	envs.AddEnvironment(newTestEnvironment("prod", "base", "port", "443"), SourceController)
+
+	values, err := envs.EffectiveValues("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []domain.EffectiveValue{
+		{KeyValue: domain.KeyValue{Key: "host", Value: "dev.example.com", Enable: true}, Source: "dev", Overridden: true},
+		{KeyValue: domain.KeyValue{Key: "port", Value: "80", Enable: true}, Source: "base"},
+		{KeyValue: domain.KeyValue{Key: "user", Value: "admin", Enable: true}, Source: "base"},
+		{KeyValue: domain.KeyValue{Key: "debug", Value: "true", Enable: true}, Source: "dev"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("EffectiveValues() = %+v, want %+v", values, want)
+	}
+
+	if missing := envs.MissingKeys("prod"); !reflect.DeepEqual(missing, []string{"debug"}) {
+		t.Errorf("MissingKeys(prod) = %v, want [debug]", missing)
+	}
+
+	if err := envs.ValidateExtends("base", "dev"); !errors.Is(err, ErrInheritanceCycle) {
+		t.Errorf("ValidateExtends(base, dev) = %v, want %v", err, ErrInheritanceCycle)
+	}
+
+	if err := envs.ValidateExtends("prod", "dev"); err != nil {
+		t.Errorf("ValidateExtends(prod, dev) = %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package variables
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+var (
+	firstNames = []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth", "Ali", "Sara", "Reza", "Maryam", "Lucas", "Emma", "Noah", "Olivia", "Liam", "Sophia"}
+	lastNames  = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Martinez", "Lopez", "Wilson", "Anderson", "Taylor", "Thomas", "Moore", "Jackson", "Martin", "Lee", "Walker", "Hall"}
+	domains    = []string{"example.com", "example.org", "example.net", "mail.test", "test.dev"}
+	streets    = []string{"Main St", "Oak Ave", "Pine Rd", "Maple Dr", "Cedar Ln", "Elm St", "Park Ave", "Lake View", "Hill Rd", "River Rd"}
+	cities     = []string{"Amsterdam", "Berlin", "Tehran", "London", "New York", "Paris", "Tokyo", "Toronto", "Sydney", "Madrid"}
+	countries  = []string{"Netherlands", "Germany", "Iran", "United Kingdom", "United States", "France", "Japan", "Canada", "Australia", "Spain"}
+	companies  = []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli", "Stark Industries", "Wayne Enterprises", "Soylent", "Cyberdyne", "Wonka"}
+	words      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa"}
+)
+
+func pick(items []string) string {
+	return items[rand.Intn(len(items))]
+}
+
+func fakerFunc(f func() string) Func {
+	return func(args []string) (string, error) {
+		return f(), nil
+	}
+}
+
+func registerFakers() {
+	RegisterFunc("randomFirstName", "$randomFirstName, random first name", fakerFunc(func() string {
+		return pick(firstNames)
+	}))
+	RegisterFunc("randomLastName", "$randomLastName, random last name", fakerFunc(func() string {
+		return pick(lastNames)
+	}))
+	RegisterFunc("randomFullName", "$randomFullName, random first and last name", fakerFunc(func() string {
+		return pick(firstNames) + " " + pick(lastNames)
+	}))
+	RegisterFunc("randomUserName", "$randomUserName, random user name", fakerFunc(func() string {
+		return strings.ToLower(pick(firstNames)) + fmt.Sprintf("%d", rand.Intn(1000))
+	}))
+	RegisterFunc("randomEmail", "$randomEmail, random email address", fakerFunc(func() string {
+		return fmt.Sprintf("%s.%s@%s", strings.ToLower(pick(firstNames)), strings.ToLower(pick(lastNames)), pick(domains))
+	}))
+	RegisterFunc("randomPhoneNumber", "$randomPhoneNumber, random phone number", fakerFunc(func() string {
+		return fmt.Sprintf("+1-%03d-%03d-%04d", 200+rand.Intn(800), rand.Intn(1000), rand.Intn(10000))
+	}))
+	RegisterFunc("randomStreetAddress", "$randomStreetAddress, random street address", fakerFunc(func() string {
+		return fmt.Sprintf("%d %s", 1+rand.Intn(9999), pick(streets))
+	}))
+	RegisterFunc("randomCity", "$randomCity, random city", fakerFunc(func() string {
+		return pick(cities)
+	}))
+	RegisterFunc("randomCountry", "$randomCountry, random country", fakerFunc(func() string {
+		return pick(countries)
+	}))
+	RegisterFunc("randomZipCode", "$randomZipCode, random zip code", fakerFunc(func() string {
+		return fmt.Sprintf("%05d", rand.Intn(100000))
+	}))
+	RegisterFunc("randomCompanyName", "$randomCompanyName, random company name", fakerFunc(func() string {
+		return pick(companies)
+	}))
+	RegisterFunc("randomWord", "$randomWord, random word", fakerFunc(func() string {
+		return pick(words)
+	}))
+	RegisterFunc("randomBoolean", "$randomBoolean, true or false", fakerFunc(func() string {
+		return fmt.Sprintf("%t", rand.Intn(2) == 1)
+	}))
+}
@@ -0,0 +1,326 @@
+package variables
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/safemap"
+)
+
+// Func generates the value of a dynamic variable such as {{$randInt 10 500}} from its arguments.
+type Func func(args []string) (string, error)
+
+// Function is a registered dynamic variable, Usage is shown to the user when completing variables.
+type Function struct {
+	Name  string
+	Usage string
+	Func  Func
+
+	// dirFunc is called instead of Func by the functions which resolve paths against the directory of the engine.
+	dirFunc func(dir string, args []string) (string, error)
+}
+
+var functions = safemap.New[Function]()
+
+// RegisterFunc adds a dynamic variable, name is used without the leading $ and replaces any function with the same name.
+func RegisterFunc(name, usage string, f Func) {
+	functions.Set(name, Function{Name: name, Usage: usage, Func: f})
+}
+
+// Functions returns the registered dynamic variables sorted by name.
+func Functions() []Function {
+	out := functions.Values()
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func init() {
+	RegisterFunc("now", `$now ["layout"] ["+1h"], layout is a Go time layout, unix or unixMilli`, nowFunc)
+	RegisterFunc("timestamp", "$timestamp, unix timestamp in seconds", func(args []string) (string, error) {
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	})
+	RegisterFunc("uuid", "$uuid, random uuid v4", func(args []string) (string, error) {
+		return uuid.NewString(), nil
+	})
+	RegisterFunc("uuidv7", "$uuidv7, time ordered uuid v7", func(args []string) (string, error) {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return "", err
+		}
+		return id.String(), nil
+	})
+	RegisterFunc("randInt", "$randInt [min] max, random integer between min and max inclusive", randIntFunc)
+	RegisterFunc("randFloat", "$randFloat [min] [max], random float between min and max", randFloatFunc)
+	RegisterFunc("randString", "$randString [length], random alphanumeric string", randStringFunc)
+	RegisterFunc("base64", "$base64 value, standard base64 encoding of value", func(args []string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(strings.Join(args, " "))), nil
+	})
+	RegisterFunc("base64Decode", "$base64Decode value, decodes a standard base64 value", func(args []string) (string, error) {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(args, " "))
+		return string(data), err
+	})
+	RegisterFunc("md5", "$md5 value, hex encoded md5 of value", hashFunc(md5.New))
+	RegisterFunc("sha1", "$sha1 value, hex encoded sha1 of value", hashFunc(sha1.New))
+	RegisterFunc("sha256", "$sha256 value, hex encoded sha256 of value", hashFunc(sha256.New))
+	RegisterFunc("sha512", "$sha512 value, hex encoded sha512 of value", hashFunc(sha512.New))
+	RegisterFunc("hmac", "$hmac algorithm key message, hex encoded hmac with md5, sha1, sha256 or sha512", hmacFunc)
+	RegisterFunc("env", `$env "NAME", value of the environment variable of the system`, func(args []string) (string, error) {
+		if len(args) != 1 {
+			return "", errors.New("expected the name of the environment variable")
+		}
+		return os.Getenv(args[0]), nil
+	})
+	functions.Set("file", Function{
+		Name:    "file",
+		Usage:   `$file "path", content of the file, relative paths are in the collection or workspace directory`,
+		Func:    func(args []string) (string, error) { return fileFunc("", args) },
+		dirFunc: fileFunc,
+	})
+
+	registerFakers()
+}
+
+func fileFunc(dir string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("expected the path of the file")
+	}
+
+	path := args[0]
+	if !filepath.IsAbs(path) {
+		if dir == "" {
+			return "", fmt.Errorf("relative path %q needs a collection or workspace directory", path)
+		}
+		path = filepath.Join(dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+func nowFunc(args []string) (string, error) {
+	if len(args) > 2 {
+		return "", errors.New("expected at most a layout and an offset")
+	}
+
+	now := time.Now().UTC()
+	if len(args) == 2 {
+		offset, err := parseOffset(args[1])
+		if err != nil {
+			return "", err
+		}
+		now = now.Add(offset)
+	}
+
+	layout := time.RFC3339
+	if len(args) > 0 && args[0] != "" {
+		layout = args[0]
+	}
+
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(now.Unix(), 10), nil
+	case "unixMilli":
+		return strconv.FormatInt(now.UnixMilli(), 10), nil
+	}
+
+	return now.Format(layout), nil
+}
+
+// parseOffset parses a duration which may also use days, e.g. -2d or +1h30m.
+func parseOffset(v string) (time.Duration, error) {
+	if strings.HasSuffix(v, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %q", v)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(strings.TrimPrefix(v, "+"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset %q", v)
+	}
+	return d, nil
+}
+
+func intArgs(args []string) ([]int, error) {
+	out := make([]int, 0, len(args))
+	for _, a := range args {
+		n, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", a)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func randIntFunc(args []string) (string, error) {
+	nums, err := intArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	lo, hi := 0, 1000
+	switch len(nums) {
+	case 0:
+	case 1:
+		hi = nums[0]
+	case 2:
+		lo, hi = nums[0], nums[1]
+	default:
+		return "", errors.New("expected at most min and max")
+	}
+
+	if hi < lo {
+		return "", fmt.Errorf("max %d is less than min %d", hi, lo)
+	}
+
+	// the difference is exact as an unsigned number since hi >= lo, it is too large when it does not fit an int64
+	span := uint64(int64(hi)) - uint64(int64(lo))
+	if span >= math.MaxInt64 {
+		return "", fmt.Errorf("range %d to %d is too large", lo, hi)
+	}
+
+	return strconv.FormatInt(int64(lo)+rand.Int63n(int64(span)+1), 10), nil
+}
+
+func randFloatFunc(args []string) (string, error) {
+	lo, hi := 0.0, 1.0
+	bounds := make([]float64, 0, len(args))
+	for _, a := range args {
+		f, err := strconv.ParseFloat(a, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("invalid number %q", a)
+		}
+		bounds = append(bounds, f)
+	}
+
+	switch len(bounds) {
+	case 0:
+	case 1:
+		hi = bounds[0]
+	case 2:
+		lo, hi = bounds[0], bounds[1]
+	default:
+		return "", errors.New("expected at most min and max")
+	}
+
+	if hi < lo {
+		return "", fmt.Errorf("max %v is less than min %v", hi, lo)
+	}
+
+	return strconv.FormatFloat(lo+rand.Float64()*(hi-lo), 'f', 6, 64), nil
+}
+
+const alphaNumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// maxRandStringLength caps $randString so a typo in the length does not allocate a huge string.
+const maxRandStringLength = 1 << 16
+
+func randStringFunc(args []string) (string, error) {
+	length := 16
+	if len(args) > 0 {
+		nums, err := intArgs(args[:1])
+		if err != nil {
+			return "", err
+		}
+		length = nums[0]
+	}
+
+	if length <= 0 || length > maxRandStringLength {
+		return "", fmt.Errorf("length %d is not between 1 and %d", length, maxRandStringLength)
+	}
+
+	out := make([]byte, length)
+	for i := range out {
+		out[i] = alphaNumeric[rand.Intn(len(alphaNumeric))]
+	}
+	return string(out), nil
+}
+
+func hashFunc(h func() hash.Hash) Func {
+	return func(args []string) (string, error) {
+		sum := h()
+		sum.Write([]byte(strings.Join(args, " ")))
+		return hex.EncodeToString(sum.Sum(nil)), nil
+	}
+}
+
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func hmacFunc(args []string) (string, error) {
+	if len(args) != 3 {
+		return "", errors.New("expected algorithm, key and message")
+	}
+
+	h, ok := hashes[strings.ToLower(args[0])]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm %q", args[0])
+	}
+
+	mac := hmac.New(h, []byte(args[1]))
+	mac.Write([]byte(args[2]))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+type callArg struct {
+	Value string
+	// Quoted arguments are literals, other arguments may be the name of a variable.
+	Quoted bool
+}
+
+// splitArgs splits the arguments of a function call, quoted arguments use Go string syntax.
+func splitArgs(v string) ([]callArg, error) {
+	var args []callArg
+	for v = strings.TrimSpace(v); v != ""; v = strings.TrimSpace(v) {
+		if v[0] != '"' {
+			end := strings.IndexAny(v, " \t")
+			if end < 0 {
+				end = len(v)
+			}
+			args = append(args, callArg{Value: v[:end]})
+			v = v[end:]
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted argument %s", v)
+		}
+
+		arg, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, callArg{Value: arg, Quoted: true})
+		v = v[len(quoted):]
+	}
+
+	return args, nil
+}
@@ -0,0 +1,234 @@
+package variables
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/state"
+)
+
+type Scope string
+
+const (
+	ScopeBuiltIn      Scope = "built-in"
+	ScopeGlobal       Scope = "global"
+	ScopeEnvironment  Scope = "environment"
+	ScopeIteration    Scope = "iteration"
+	ScopePrerequisite Scope = "prerequisite"
+	ScopeCollection   Scope = "collection"
+	ScopeRequest      Scope = "request"
+)
+
+// Scopes are the variables available to a request. When a variable is defined in more than one scope
+// the most specific one wins, the precedence is request > collection > prerequisite > iteration > environment >
+// global > built-ins.
+type Scopes struct {
+	Global      []domain.KeyValue
+	Environment *domain.EnvSpec
+	// Iteration are the values of the data row of the current iteration of a run.
+	Iteration []domain.KeyValue
+	// Prerequisites are the values of the responses of the prerequisite requests.
+	Prerequisites []domain.KeyValue
+	Collection    []domain.KeyValue
+	Request       []domain.KeyValue
+
+	// Dir is the directory of the collection of the request, or of the workspace, relative paths are resolved against it.
+	Dir string
+}
+
+// Values are the variables a request is sent with on top of the ones of its scopes.
+type Values struct {
+	Iteration     []domain.KeyValue
+	Prerequisites []domain.KeyValue
+	// Environment replaces the active environment when set, such as the copy a load test is run with.
+	Environment *domain.EnvSpec
+}
+
+// SetValues sets the scopes of the values.
+func (s *Scopes) SetValues(v Values) {
+	s.Iteration = v.Iteration
+	s.Prerequisites = v.Prerequisites
+	if v.Environment != nil {
+		s.Environment = v.Environment
+	}
+}
+
+// Variables returns the variables of all the scopes and the scope each of them is taken from.
+func (s Scopes) Variables() (map[string]string, map[string]Scope) {
+	vars := GetVariables()
+	sources := make(map[string]Scope, len(vars))
+	for k := range vars {
+		sources[k] = ScopeBuiltIn
+	}
+
+	add := func(scope Scope, values []domain.KeyValue) {
+		for _, kv := range values {
+			if !kv.Enable || kv.Key == "" {
+				continue
+			}
+			vars[kv.Key] = kv.Value
+			sources[kv.Key] = scope
+		}
+	}
+
+	add(ScopeGlobal, s.Global)
+	if s.Environment != nil {
+		add(ScopeEnvironment, s.Environment.Values)
+	}
+	add(ScopeIteration, s.Iteration)
+	add(ScopePrerequisite, s.Prerequisites)
+	add(ScopeCollection, s.Collection)
+	add(ScopeRequest, s.Request)
+
+	return vars, sources
+}
+
+// Resolve returns an engine for the variables of the scopes. The values of the environment are
+// resolved in place so it should be a clone of the active environment.
+func (s Scopes) Resolve() (*Engine, []Warning) {
+	vars, _ := s.Variables()
+	engine := NewEngine(vars)
+	engine.SetDir(s.Dir)
+
+	if s.Environment == nil {
+		return engine, nil
+	}
+
+	var warnings []Warning
+	for i, kv := range s.Environment.Values {
+		value, ws := engine.Render(kv.Value)
+		s.Environment.Values[i].Value = value
+		if !kv.Enable {
+			continue
+		}
+
+		for j := range ws {
+			ws[j].Field = "environment." + kv.Key
+		}
+		warnings = append(warnings, ws...)
+	}
+
+	return engine, warnings
+}
+
+// SecretMask replaces the values of the secrets when they are described.
+const SecretMask = "••••••"
+
+// Describe returns the resolved value of the variable and the scope it is defined in, secrets are masked.
+func (s Scopes) Describe(name string) (string, Scope, bool) {
+	vars, sources := s.Variables()
+	scope, ok := sources[name]
+	if !ok {
+		return "", "", false
+	}
+
+	if s.isSecret(scope, name) {
+		return SecretMask, scope, true
+	}
+
+	engine := NewEngine(vars)
+	engine.SetDir(s.Dir)
+	value, _ := engine.Render(vars[name])
+	return value, scope, true
+}
+
+func (s Scopes) isSecret(scope Scope, name string) bool {
+	var values []domain.KeyValue
+	switch scope {
+	case ScopeGlobal:
+		values = s.Global
+	case ScopeEnvironment:
+		if s.Environment != nil {
+			values = s.Environment.Values
+		}
+	case ScopeIteration:
+		values = s.Iteration
+	case ScopePrerequisite:
+		values = s.Prerequisites
+	case ScopeCollection:
+		values = s.Collection
+	case ScopeRequest:
+		values = s.Request
+	}
+
+	for _, kv := range values {
+		if kv.Enable && kv.Key == name && kv.Secret {
+			return true
+		}
+	}
+	return false
+}
+
+// Provider collects the scopes of the requests from the state of the application.
+type Provider struct {
+	requests     *state.Requests
+	environments *state.Environments
+	workspaces   *state.Workspaces
+}
+
+func NewProvider(requests *state.Requests, environments *state.Environments, workspaces *state.Workspaces) *Provider {
+	return &Provider{
+		requests:     requests,
+		environments: environments,
+		workspaces:   workspaces,
+	}
+}
+
+// Scopes returns the scopes of the request, the environment is a clone of the given environment with its inherited values.
+func (p *Provider) Scopes(req *domain.Request, activeEnvironmentID string) Scopes {
+	var out Scopes
+	if p == nil || req == nil {
+		return out
+	}
+
+	if p.workspaces != nil {
+		if ws := p.workspaces.GetActiveWorkspace(); ws != nil {
+			if fresh := p.workspaces.GetWorkspace(ws.MetaData.ID); fresh != nil {
+				ws = fresh
+			}
+			out.Global = ws.Spec.Variables
+			out.Dir = configDir(ws.FilePath)
+		}
+	}
+
+	if activeEnvironmentID != "" && p.environments != nil {
+		env, err := p.environments.GetEffectiveEnvironment(activeEnvironmentID)
+		if errors.Is(err, state.ErrInheritanceCycle) {
+			env = p.environments.GetEnvironment(activeEnvironmentID)
+		}
+		if env != nil {
+			spec := env.Spec.Clone()
+			out.Environment = &spec
+		}
+	}
+
+	if req.CollectionID != "" && p.requests != nil {
+		if col := p.requests.GetCollection(req.CollectionID); col != nil {
+			out.Collection = col.Spec.Variables
+			if dir := configDir(col.FilePath); dir != "" {
+				out.Dir = dir
+			}
+		}
+	}
+
+	switch {
+	case req.Spec.HTTP != nil && req.Spec.HTTP.Request != nil:
+		out.Request = req.Spec.HTTP.Request.Variables
+	case req.Spec.GRPC != nil:
+		out.Request = req.Spec.GRPC.Variables
+	}
+
+	return out
+}
+
+// configDir returns the directory of a workspace or collection from the path of its file or directory.
+func configDir(path string) string {
+	if path == "" {
+		return ""
+	}
+	if filepath.Ext(path) == ".yaml" {
+		return filepath.Dir(path)
+	}
+	return path
+}
@@ -0,0 +1,74 @@
+package variables
+
+import (
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestScopes_Precedence(t *testing.T) {
+	scopes := Scopes{
+		Global: []domain.KeyValue{
+			{Key: "host", Value: "global.example.com", Enable: true},
+			{Key: "token", Value: "global-token", Enable: true},
+			{Key: "user", Value: "admin", Enable: true},
+		},
+		Environment: &domain.EnvSpec{Values: []domain.KeyValue{
+			{Key: "host", Value: "env.example.com", Enable: true},
+			{Key: "token", Value: "env-token", Enable: true},
+		}},
+		Collection: []domain.KeyValue{
+			{Key: "token", Value: "collection-token", Enable: true},
+			{Key: "user", Value: "disabled", Enable: false},
+		},
+		Request: []domain.KeyValue{
+			{Key: "token", Value: "{{user}}-token", Enable: true},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		scope Scope
+	}{
+		{name: "host", value: "env.example.com", scope: ScopeEnvironment},
+		{name: "token", value: "admin-token", scope: ScopeRequest},
+		{name: "user", value: "admin", scope: ScopeGlobal},
+		{name: "randInt100", scope: ScopeBuiltIn},
+	}
+
+	for _, tt := range tests {
+		value, scope, ok := scopes.Describe(tt.name)
+		if !ok || scope != tt.scope || tt.value != "" && value != tt.value {
+			t.Errorf("Describe(%s) = %q, %s, %t, want %q, %s", tt.name, value, scope, ok, tt.value, tt.scope)
+		}
+	}
+
+	if _, _, ok := scopes.Describe("missing"); ok {
+		t.Errorf("expected missing to be undefined")
+	}
+}
+
+func TestScopes_Iteration(t *testing.T) {
+	scopes := Scopes{
+		Environment: &domain.EnvSpec{Values: []domain.KeyValue{
+			{Key: "host", Value: "env.example.com", Enable: true},
+			{Key: "user", Value: "admin", Enable: true},
+		}},
+		Iteration: []domain.KeyValue{
+			{Key: "user", Value: "alice", Enable: true},
+			{Key: "token", Value: "row-token", Enable: true},
+		},
+		Collection: []domain.KeyValue{
+			{Key: "token", Value: "collection-token", Enable: true},
+		},
+	}
+
+	vars, sources := scopes.Variables()
+	if vars["user"] != "alice" || sources["user"] != ScopeIteration {
+		t.Errorf("user = %q from %s, want the iteration value", vars["user"], sources["user"])
+	}
+	if vars["token"] != "collection-token" || sources["host"] != ScopeEnvironment {
+		t.Errorf("token = %q, host from %s", vars["token"], sources["host"])
+	}
+}
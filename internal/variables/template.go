@@ -0,0 +1,357 @@
+package variables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type TokenKind int
+
+const (
+	TokenText TokenKind = iota
+	TokenVariable
+)
+
+// Token is a piece of a template, Value is the unescaped text or the name of the variable.
+// Start and End are the byte offsets of the token in the template.
+type Token struct {
+	Kind  TokenKind
+	Value string
+	Start int
+	End   int
+}
+
+// Tokenize splits the template into text and {{variable}} tokens. A backslash before {{ escapes it
+// so \{{name}} is rendered as the literal text {{name}}.
+func Tokenize(template string) []Token {
+	var (
+		tokens []Token
+		text   strings.Builder
+		start  int
+	)
+
+	flush := func(end int) {
+		if text.Len() > 0 {
+			tokens = append(tokens, Token{Kind: TokenText, Value: text.String(), Start: start, End: end})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(template); {
+		if strings.HasPrefix(template[i:], `\{{`) {
+			if text.Len() == 0 {
+				start = i
+			}
+			text.WriteString("{{")
+			i += 3
+			continue
+		}
+
+		if strings.HasPrefix(template[i:], "{{") {
+			if end := strings.Index(template[i+2:], "}}"); end >= 0 {
+				name := strings.TrimSpace(template[i+2 : i+2+end])
+				if isVariableName(name) {
+					flush(i)
+					tokens = append(tokens, Token{Kind: TokenVariable, Value: name, Start: i, End: i + end + 4})
+					i += end + 4
+					start = i
+					continue
+				}
+			}
+		}
+
+		if text.Len() == 0 {
+			start = i
+		}
+		text.WriteByte(template[i])
+		i++
+	}
+
+	flush(len(template))
+	return tokens
+}
+
+func isVariableName(name string) bool {
+	if strings.HasPrefix(name, "$") {
+		// function calls may have quoted arguments
+		return len(name) > 1 && !strings.ContainsAny(name, "{}\n\r")
+	}
+	return name != "" && !strings.ContainsAny(name, "{}\"\n\r")
+}
+
+// Warning is a variable that could not be resolved while rendering a template.
+type Warning struct {
+	// Field is the path of the field the variable is used in, empty when rendering a single template.
+	Field    string
+	Variable string
+	Message  string
+}
+
+func (w Warning) String() string {
+	if w.Field == "" {
+		return fmt.Sprintf("{{%s}}: %s", w.Variable, w.Message)
+	}
+	return fmt.Sprintf("%s: {{%s}}: %s", w.Field, w.Variable, w.Message)
+}
+
+// Engine renders templates using a set of variables. Values of the variables are templates
+// themselves so variables can reference other variables.
+type Engine struct {
+	vars     map[string]string
+	resolved map[string]string
+	// dir is the directory relative paths of functions such as $file are resolved against.
+	dir string
+	// literal inserts the values of the variables as they are, the templates they may hold are not rendered.
+	literal bool
+	// disabled are the functions which can not be called.
+	disabled map[string]bool
+}
+
+func NewEngine(vars map[string]string) *Engine {
+	return &Engine{
+		vars:     vars,
+		resolved: make(map[string]string),
+	}
+}
+
+// SetDir sets the directory relative paths of functions such as $file are resolved against.
+func (e *Engine) SetDir(dir string) {
+	e.dir = dir
+}
+
+// SetLiteral makes the engine insert the values of the variables as they are, for values which are not
+// trusted such as the data of a request sent to the mock server.
+func (e *Engine) SetLiteral(literal bool) {
+	e.literal = literal
+}
+
+// DisableFunctions makes the functions unavailable to the templates, such as $env and $file which read the system.
+func (e *Engine) DisableFunctions(names ...string) {
+	if e.disabled == nil {
+		e.disabled = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		e.disabled[name] = true
+	}
+}
+
+// Render replaces the variables of the template with their values. Unresolved variables and
+// variables referencing themselves are kept as they are and reported as warnings.
+func (e *Engine) Render(template string) (string, []Warning) {
+	return e.render(template, nil)
+}
+
+func (e *Engine) render(template string, stack []string) (string, []Warning) {
+	if !strings.Contains(template, "{{") {
+		return template, nil
+	}
+
+	var (
+		out      strings.Builder
+		warnings []Warning
+	)
+
+	for _, token := range Tokenize(template) {
+		if token.Kind == TokenText {
+			out.WriteString(token.Value)
+			continue
+		}
+
+		resolve := e.resolve
+		if strings.HasPrefix(token.Value, "$") {
+			resolve = e.call
+		}
+
+		value, ok, ws := resolve(token.Value, stack)
+		warnings = append(warnings, ws...)
+		if !ok {
+			// keep the variable so the user can see what is missing
+			out.WriteString(template[token.Start:token.End])
+			continue
+		}
+		out.WriteString(value)
+	}
+
+	return out.String(), warnings
+}
+
+func (e *Engine) resolve(name string, stack []string) (string, bool, []Warning) {
+	if value, ok := e.resolved[name]; ok {
+		return value, true, nil
+	}
+
+	for i, n := range stack {
+		if n == name {
+			cycle := append(append([]string{}, stack[i:]...), name)
+			return "", false, []Warning{{Variable: name, Message: "cyclic reference " + strings.Join(cycle, " -> ")}}
+		}
+	}
+
+	raw, ok := e.vars[name]
+	if !ok {
+		return "", false, []Warning{{Variable: name, Message: "variable is not defined"}}
+	}
+
+	if e.literal {
+		return raw, true, nil
+	}
+
+	// nested variables which can not be resolved are kept in the value
+	value, warnings := e.render(raw, append(stack, name))
+	if warnings == nil {
+		e.resolved[name] = value
+	}
+
+	return value, true, warnings
+}
+
+// call runs a dynamic variable such as $randInt 10 500. Arguments which are not quoted are replaced
+// with the value of the variable with the same name if there is one.
+func (e *Engine) call(expr string, stack []string) (string, bool, []Warning) {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(expr, "$"), " ")
+	name = strings.TrimSpace(name)
+
+	fn, ok := functions.Get(name)
+	if !ok {
+		return "", false, []Warning{{Variable: expr, Message: "function is not defined"}}
+	}
+
+	if e.disabled[name] {
+		return "", false, []Warning{{Variable: expr, Message: "function is not available"}}
+	}
+
+	args, err := splitArgs(rest)
+	if err != nil {
+		return "", false, []Warning{{Variable: expr, Message: err.Error()}}
+	}
+
+	var warnings []Warning
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg.Quoted {
+			values = append(values, arg.Value)
+			continue
+		}
+
+		if _, defined := e.vars[arg.Value]; !defined {
+			values = append(values, arg.Value)
+			continue
+		}
+
+		value, ok, ws := e.resolve(arg.Value, stack)
+		warnings = append(warnings, ws...)
+		if !ok {
+			return "", false, warnings
+		}
+		values = append(values, value)
+	}
+
+	var out string
+	if fn.dirFunc != nil {
+		out, err = fn.dirFunc(e.dir, values)
+	} else {
+		out, err = fn.Func(values)
+	}
+	if err != nil {
+		return "", false, append(warnings, Warning{Variable: expr, Message: err.Error()})
+	}
+
+	return out, true, warnings
+}
+
+// Apply renders every exported string field reachable from v, which must be a pointer to a struct.
+// Slices, maps and pointers are copied before they are modified so values shared with the
+// original of a shallow clone are left untouched. Items with an Enable field set to false are
+// skipped as they are not sent.
+func (e *Engine) Apply(v any) []Warning {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil
+	}
+
+	return e.walk(rv.Elem(), "")
+}
+
+func (e *Engine) walk(v reflect.Value, path string) []Warning {
+	switch v.Kind() {
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+
+		value, warnings := e.Render(v.String())
+		v.SetString(value)
+		for i := range warnings {
+			warnings[i].Field = path
+		}
+		return warnings
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.CanSet() {
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(v.Elem())
+			v.Set(elem)
+		}
+		return e.walk(v.Elem(), path)
+	case reflect.Struct:
+		if enable := v.FieldByName("Enable"); enable.IsValid() && enable.Kind() == reflect.Bool && !enable.Bool() {
+			return nil
+		}
+
+		var warnings []Warning
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			warnings = append(warnings, e.walk(v.Field(i), joinPath(path, v.Type().Field(i).Name))...)
+		}
+		return warnings
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.CanSet() {
+			clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			reflect.Copy(clone, v)
+			v.Set(clone)
+		}
+
+		var warnings []Warning
+		for i := 0; i < v.Len(); i++ {
+			warnings = append(warnings, e.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return warnings
+	case reflect.Map:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.String || !v.CanSet() {
+			return nil
+		}
+
+		var warnings []Warning
+		clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, ws := e.Render(iter.Value().String())
+			for i := range ws {
+				ws[i].Field = fmt.Sprintf("%s[%v]", path, iter.Key())
+			}
+			warnings = append(warnings, ws...)
+			clone.SetMapIndex(iter.Key(), reflect.ValueOf(value).Convert(v.Type().Elem()))
+		}
+		v.Set(clone)
+		return warnings
+	}
+
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,214 @@
+package variables
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestEngine_Render(t *testing.T) {
+	engine := NewEngine(map[string]string{
+		"host":    "localhost",
+		"port":    "8080",
+		"baseURL": "http://{{host}}:{{ port }}",
+		"a":       "{{b}}",
+		"b":       "{{a}}",
+		"partial": "{{host}}/{{missing}}",
+	})
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		warnings []string
+	}{
+		{name: "nested", template: "{{baseURL}}/users", want: "http://localhost:8080/users"},
+		{name: "escaped", template: `\{{host}} is {{host}}`, want: "{{host}} is localhost"},
+		{name: "json", template: `{"port": {{port}}}`, want: `{"port": 8080}`},
+		{name: "unresolved", template: "{{token}}", want: "{{token}}", warnings: []string{"token"}},
+		{name: "partially resolved", template: "{{partial}}", want: "localhost/{{missing}}", warnings: []string{"missing"}},
+		{name: "cycle", template: "{{a}}", want: "{{a}}", warnings: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := engine.Render(tt.template)
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+
+			if len(warnings) != len(tt.warnings) {
+				t.Fatalf("Render() warnings = %v, want %v", warnings, tt.warnings)
+			}
+
+			for i, w := range warnings {
+				if w.Variable != tt.warnings[i] {
+					t.Errorf("warning %d = %s, want %s", i, w, tt.warnings[i])
+				}
+			}
+		})
+	}
+
+	if _, warnings := engine.Render("{{a}}"); !strings.Contains(warnings[0].Message, "a -> b -> a") {
+		t.Errorf("expected the cycle in the warning, got %s", warnings[0])
+	}
+}
+
+func TestApplyToHTTPRequest(t *testing.T) {
+	original := &domain.HTTPRequestSpec{
+		URL: "{{url}}/users",
+		Request: &domain.HTTPRequest{
+			Headers: []domain.KeyValue{
+				{Key: "{{headerName}}", Value: "Bearer {{token}}", Enable: true},
+				{Key: "X-Disabled", Value: "{{unknown}}", Enable: false},
+			},
+			QueryParams: []domain.KeyValue{{Key: "q", Value: "{{query}}", Enable: true}},
+			Auth:        domain.Auth{APIKeyAuth: &domain.APIKeyAuth{Key: "key", Value: "{{token}}"}},
+		},
+	}
+
+	req := original.Clone()
+	warnings := ApplyToHTTPRequest(NewEngine(map[string]string{
+		"url":        "https://example.com",
+		"headerName": "Authorization",
+		"token":      "secret",
+	}), req)
+
+	if req.URL != "https://example.com/users" {
+		t.Errorf("unexpected url %s", req.URL)
+	}
+
+	if h := req.Request.Headers[0]; h.Key != "Authorization" || h.Value != "Bearer secret" {
+		t.Errorf("unexpected header %v", h)
+	}
+
+	if req.Request.Auth.APIKeyAuth.Value != "secret" {
+		t.Errorf("unexpected api key %s", req.Request.Auth.APIKeyAuth.Value)
+	}
+
+	if len(warnings) != 1 || warnings[0].Variable != "query" || warnings[0].Field != "QueryParams[0].Value" {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+
+	// the original shares the slices with the shallow clone and must stay untouched
+	if original.Request.Headers[0].Value != "Bearer {{token}}" || original.Request.Auth.APIKeyAuth.Value != "{{token}}" {
+		t.Errorf("original request is modified %v", original.Request)
+	}
+}
+
+func TestEngine_RenderFunctions(t *testing.T) {
+	t.Setenv("CHAPAR_TEST_ENV", "from-env")
+
+	engine := NewEngine(map[string]string{
+		"user":   "admin",
+		"secret": "key",
+	})
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		check    func(string) bool
+		warnings int
+	}{
+		{name: "base64 of variable", template: "{{$base64 user}}", want: "YWRtaW4="},
+		{name: "quoted literal", template: `{{$base64 "user"}}`, want: "dXNlcg=="},
+		{name: "sha256", template: "{{$sha256 user}}", want: "8c6976e5b5410415bde908bd4dee15dfb167a9c873fc4bb8a81f6f2ab448a918"},
+		{name: "hmac", template: `{{$hmac sha256 secret "message"}}`, want: "6e9ef29b75fffc5b7abae527d58fdadb2fe42e7219011976917343065f58ed4a"},
+		{name: "env", template: `{{$env "CHAPAR_TEST_ENV"}}`, want: "from-env"},
+		{name: "now with layout", template: `{{$now "2006" "+0h"}}`, check: func(s string) bool { return len(s) == 4 }},
+		{name: "randInt range", template: "{{$randInt 10 12}}", check: func(s string) bool { return s == "10" || s == "11" || s == "12" }},
+		{name: "uuidv7", template: "{{$uuidv7}}", check: func(s string) bool { return len(s) == 36 && s[14] == '7' }},
+		{name: "faker", template: "{{$randomEmail}}", check: func(s string) bool { return strings.Contains(s, "@") }},
+		{name: "unknown function", template: "{{$nope}}", want: "{{$nope}}", warnings: 1},
+		{name: "invalid argument", template: "{{$randInt a}}", want: "{{$randInt a}}", warnings: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := engine.Render(tt.template)
+			if tt.check != nil && !tt.check(got) || tt.check == nil && got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+
+			if len(warnings) != tt.warnings {
+				t.Errorf("Render() warnings = %v", warnings)
+			}
+		})
+	}
+}
+
+func TestEngine_RenderInvalidFunctionArguments(t *testing.T) {
+	engine := NewEngine(nil)
+
+	for _, template := range []string{
+		"{{$randString -1}}",
+		"{{$randString 0}}",
+		"{{$randString 999999999999}}",
+		"{{$randInt 10 5}}",
+		"{{$randInt -9223372036854775808 9223372036854775807}}",
+		"{{$randInt -1 9223372036854775807}}",
+		"{{$randInt 99999999999999999999}}",
+		"{{$randFloat 2 1}}",
+		"{{$randFloat NaN}}",
+	} {
+		t.Run(template, func(t *testing.T) {
+			got, warnings := engine.Render(template)
+			if got != template || len(warnings) != 1 {
+				t.Errorf("Render() = %q, %v; want the template kept with a warning", got, warnings)
+			}
+		})
+	}
+
+	if got, warnings := engine.Render("{{$randInt -9223372036854775806 0}}"); len(warnings) != 0 {
+		t.Errorf("Render() = %q, %v", got, warnings)
+	}
+	if got, _ := engine.Render("{{$randString 65536}}"); len(got) != maxRandStringLength {
+		t.Errorf("Render() length = %d, want %d", len(got), maxRandStringLength)
+	}
+}
+
+func TestEngine_RenderFileRelativeToDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine(nil)
+	if got, warnings := engine.Render(`{{$file "token.txt"}}`); len(warnings) != 1 || got != `{{$file "token.txt"}}` {
+		t.Errorf("Render() without a directory = %q, %v", got, warnings)
+	}
+
+	engine.SetDir(dir)
+	if got, warnings := engine.Render(`{{$file "token.txt"}}`); len(warnings) != 0 || got != "secret" {
+		t.Errorf("Render() = %q, %v", got, warnings)
+	}
+
+	abs := filepath.Join(dir, "token.txt")
+	if got, _ := NewEngine(map[string]string{"path": abs}).Render(`{{$file path}}`); got != "secret" {
+		t.Errorf("Render() with an absolute path = %q", got)
+	}
+}
+
+func TestEngine_RenderLiteral(t *testing.T) {
+	t.Setenv("CHAPAR_TEST_SECRET", "secret")
+
+	engine := NewEngine(map[string]string{
+		"host":  "localhost",
+		"query": `{{host}} {{$env "CHAPAR_TEST_SECRET"}}`,
+	})
+	engine.SetLiteral(true)
+	engine.DisableFunctions("env", "file")
+
+	if got, warnings := engine.Render("{{query}}"); len(warnings) != 0 || got != `{{host}} {{$env "CHAPAR_TEST_SECRET"}}` {
+		t.Errorf("Render() = %q, %v", got, warnings)
+	}
+
+	got, warnings := engine.Render(`{{$env "CHAPAR_TEST_SECRET"}} {{$file "/etc/hostname"}}`)
+	if len(warnings) != 2 || got != `{{$env "CHAPAR_TEST_SECRET"}} {{$file "/etc/hostname"}}` {
+		t.Errorf("Render() with disabled functions = %q, %v", got, warnings)
+	}
+}
@@ -1,9 +1,9 @@
 package variables
 
 import (
+	"encoding/json"
 	"math/rand"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,102 +27,66 @@ func GetVariables() map[string]string {
 	}
 }
 
-// ApplyToEnv apply variables to the environment
-func ApplyToEnv(variables map[string]string, env *domain.EnvSpec) {
-	if variables == nil {
-		variables = GetVariables()
-	}
-
-	if env == nil {
+// FlattenJSON adds the leaves of the JSON value to the variables, the keys of the objects and the indexes
+// of the arrays are joined with dots, e.g. prefix.user.roles.0. No more leaves are added once vars holds
+// limit variables, so a large document does not flood the variables.
+func FlattenJSON(prefix string, value any, vars map[string]string, limit int) {
+	if len(vars) >= limit {
 		return
 	}
 
-	// to through all the variables and replace them in the environment
-	for k, v := range variables {
-		for i, kv := range env.Values {
-			// if value contain the variable in double curly braces then replace it
-			if strings.Contains(kv.Value, "{{"+k+"}}") {
-				env.Values[i].Value = strings.ReplaceAll(kv.Value, "{{"+k+"}}", v)
-			}
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			FlattenJSON(prefix+"."+k, item, vars, limit)
 		}
-	}
-
-	// add env variables to variables
-	for _, kv := range env.Values {
-		if !kv.Enable {
-			continue
+	case []any:
+		for i, item := range v {
+			FlattenJSON(prefix+"."+strconv.Itoa(i), item, vars, limit)
 		}
-		variables[kv.Key] = kv.Value
+	case string:
+		vars[prefix] = v
+	default:
+		b, _ := json.Marshal(v)
+		vars[prefix] = string(b)
 	}
 }
 
-// ApplyToGRPCRequest apply variables to the request
-func ApplyToGRPCRequest(variables map[string]string, req *domain.GRPCRequestSpec) {
-	if variables == nil {
-		variables = GetVariables()
-	}
-
+// ApplyToHTTPRequest renders the variables in the url and in every string field of the request.
+func ApplyToHTTPRequest(engine *Engine, req *domain.HTTPRequestSpec) []Warning {
 	if req == nil {
-		return
+		return nil
 	}
 
-	// to through all the variables and replace them in the request
-	for k, v := range variables {
-		if strings.Contains(req.ServerInfo.Address, "{{"+k+"}}") {
-			req.ServerInfo.Address = strings.ReplaceAll(req.ServerInfo.Address, "{{"+k+"}}", v)
-		}
-
-		// if value contain the variable in double curly braces then replace it
-		if strings.Contains(req.Body, "{{"+k+"}}") {
-			req.Body = strings.ReplaceAll(req.Body, "{{"+k+"}}", v)
-		}
-
-		for i, kv := range req.Metadata {
-			if strings.Contains(kv.Value, "{{"+k+"}}") {
-				req.Metadata[i].Value = strings.ReplaceAll(kv.Value, "{{"+k+"}}", v)
-			}
-		}
+	url, warnings := engine.Render(req.URL)
+	req.URL = url
+	for i := range warnings {
+		warnings[i].Field = "URL"
+	}
 
-		if req.Auth != (domain.Auth{}) {
-			ApplyToAuth(variables, &req.Auth)
-		}
+	if req.Request != nil {
+		// copy the request so the original of a shallow clone is not modified
+		r := *req.Request
+		warnings = append(warnings, engine.Apply(&r)...)
+		req.Request = &r
 	}
+
+	return warnings
 }
 
-func ApplyToAuth(variables map[string]string, auth *domain.Auth) {
-	if variables == nil {
-		variables = GetVariables()
+// ApplyToGRPCRequest renders the variables in every string field of the request.
+func ApplyToGRPCRequest(engine *Engine, req *domain.GRPCRequestSpec) []Warning {
+	if req == nil {
+		return nil
 	}
 
+	return engine.Apply(req)
+}
+
+func ApplyToAuth(engine *Engine, auth *domain.Auth) []Warning {
 	if auth == nil {
-		return
+		return nil
 	}
 
-	for k, v := range variables {
-		if auth.TokenAuth != nil {
-			if strings.Contains(auth.TokenAuth.Token, "{{"+k+"}}") {
-				auth.TokenAuth.Token = strings.ReplaceAll(auth.TokenAuth.Token, "{{"+k+"}}", v)
-			}
-		}
-
-		if auth.BasicAuth != nil {
-			if strings.Contains(auth.BasicAuth.Username, "{{"+k+"}}") {
-				auth.BasicAuth.Username = strings.ReplaceAll(auth.BasicAuth.Username, "{{"+k+"}}", v)
-			}
-
-			if strings.Contains(auth.BasicAuth.Password, "{{"+k+"}}") {
-				auth.BasicAuth.Password = strings.ReplaceAll(auth.BasicAuth.Password, "{{"+k+"}}", v)
-			}
-		}
-
-		if auth.APIKeyAuth != nil {
-			if strings.Contains(auth.APIKeyAuth.Key, "{{"+k+"}}") {
-				auth.APIKeyAuth.Key = strings.ReplaceAll(auth.APIKeyAuth.Key, "{{"+k+"}}", v)
-			}
-
-			if strings.Contains(auth.APIKeyAuth.Value, "{{"+k+"}}") {
-				auth.APIKeyAuth.Value = strings.ReplaceAll(auth.APIKeyAuth.Value, "{{"+k+"}}", v)
-			}
-		}
-	}
+	return engine.Apply(auth)
 }
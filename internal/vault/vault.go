@@ -0,0 +1,237 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	fileVersion = 1
+
+	// scrypt parameters recommended for interactive logins.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+	saltLen = 16
+
+	// bounds of the scrypt parameters read from a vault file, so a tampered or corrupted file can not make
+	// unlocking allocate gigabytes of memory or run for hours.
+	maxScryptN      = 1 << 20
+	maxScryptR      = 32
+	maxScryptP      = 16
+	maxScryptMemory = 256 << 20
+)
+
+var (
+	ErrLocked            = errors.New("vault is locked")
+	ErrInvalidPassphrase = errors.New("invalid vault passphrase")
+	ErrEmptyPassphrase   = errors.New("vault passphrase can not be empty")
+	ErrInvalidParameters = errors.New("vault has invalid key derivation parameters")
+)
+
+// file is the encrypted format of the vault on disk.
+type file struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Vault keeps secret values encrypted with AES-256-GCM using a key derived from a passphrase with scrypt.
+type Vault struct {
+	path string
+	salt []byte
+	key  []byte
+
+	mx     sync.Mutex
+	values map[string]string
+}
+
+// Exists reports whether a vault is already created at the path.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Open decrypts the vault at the path with the passphrase, a new empty vault is returned when the file does not exist.
+func Open(path, passphrase string) (*Vault, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		salt := make([]byte, saltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+
+		key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLen)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Vault{path: path, salt: salt, key: key, values: make(map[string]string)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse vault, %w", err)
+	}
+
+	if f.Version != fileVersion || f.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported vault version %d", f.Version)
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), f.Salt, f.N, f.R, f.P, keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid vault nonce of %d bytes", len(f.Nonce))
+	}
+
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse vault values, %w", err)
+	}
+
+	return &Vault{path: path, salt: f.Salt, key: key, values: values}, nil
+}
+
+// validate checks the scrypt parameters and the salt before a key is derived with them.
+func (f *file) validate() error {
+	switch {
+	case f.N < 2 || f.N > maxScryptN || f.N&(f.N-1) != 0,
+		f.R < 1 || f.R > maxScryptR,
+		f.P < 1 || f.P > maxScryptP,
+		128*f.N*f.R > maxScryptMemory,
+		len(f.Salt) < 8 || len(f.Salt) > 64:
+		return fmt.Errorf("%w: n=%d r=%d p=%d salt of %d bytes", ErrInvalidParameters, f.N, f.R, f.P, len(f.Salt))
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (v *Vault) Get(key string) (string, bool) {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	value, ok := v.values[key]
+	return value, ok
+}
+
+func (v *Vault) Set(key, value string) {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	v.values[key] = value
+}
+
+func (v *Vault) Delete(key string) {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	delete(v.values, key)
+}
+
+// Keys returns the sorted keys which start with the prefix.
+func (v *Vault) Keys(prefix string) []string {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+
+	out := make([]string, 0)
+	for k := range v.values {
+		if strings.HasPrefix(k, prefix) {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Save encrypts the values with a new nonce and writes the vault to disk, only the owner can read the file.
+func (v *Vault) Save() error {
+	v.mx.Lock()
+	plain, err := json.Marshal(v.values)
+	v.mx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(v.key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file{
+		Version: fileVersion,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    v.salt,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a failed write does not corrupt the vault
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, v.path)
+}
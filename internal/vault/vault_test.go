@@ -0,0 +1,100 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.vault")
+
+	v, err := Open(path, "passphrase")
+	if err != nil {
+		t.Fatalf("failed to create vault, %v", err)
+	}
+
+	v.Set("env/token", "s3cr3t-value")
+	if err := v.Save(); err != nil {
+		t.Fatalf("failed to save vault, %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "s3cr3t-value") {
+		t.Errorf("vault is not encrypted, %s", data)
+	}
+
+	if _, err := Open(path, "wrong"); !errors.Is(err, ErrInvalidPassphrase) {
+		t.Errorf("expected invalid passphrase error, got %v", err)
+	}
+
+	reopened, err := Open(path, "passphrase")
+	if err != nil {
+		t.Fatalf("failed to open vault, %v", err)
+	}
+
+	if value, ok := reopened.Get("env/token"); !ok || value != "s3cr3t-value" {
+		t.Errorf("unexpected value %q", value)
+	}
+}
+
+func TestOpenRejectsInvalidParameters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.vault")
+
+	v, err := Open(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var saved file
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(f *file)
+	}{
+		{name: "huge n", tamper: func(f *file) { f.N = 1 << 40 }},
+		{name: "n not a power of two", tamper: func(f *file) { f.N = 3 << 10 }},
+		{name: "zero r", tamper: func(f *file) { f.R = 0 }},
+		{name: "huge p", tamper: func(f *file) { f.P = 1 << 30 }},
+		{name: "too much memory", tamper: func(f *file) { f.N, f.R = maxScryptN, maxScryptR }},
+		{name: "empty salt", tamper: func(f *file) { f.Salt = nil }},
+		{name: "short nonce", tamper: func(f *file) { f.Nonce = f.Nonce[:4] }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := saved
+			tt.tamper(&f)
+
+			data, err := json.Marshal(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Open(path, "passphrase"); err == nil || errors.Is(err, ErrInvalidPassphrase) {
+				t.Fatalf("Open() error = %v, want the parameters rejected", err)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/chapar-rest/chapar/internal/repository"
 	"github.com/chapar-rest/chapar/internal/rest"
 	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/variables"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/explorer"
 	"github.com/chapar-rest/chapar/ui/fonts"
@@ -54,6 +55,8 @@ type UI struct {
 	workspacesState   *state.Workspaces
 	protoFilesState   *state.ProtoFiles
 
+	grpcService *grpc.Service
+
 	repo repository.Repository
 }
 
@@ -95,8 +98,10 @@ func New(w *app.Window, serviceVersion string) (*UI, error) {
 		return nil, err
 	}
 
-	grpcService := grpc.NewService(u.requestsState, u.environmentsState, u.protoFilesState)
-	restService := rest.New(u.requestsState, u.environmentsState)
+	variablesProvider := variables.NewProvider(u.requestsState, u.environmentsState, u.workspacesState)
+	grpcService := grpc.NewService(u.requestsState, u.environmentsState, u.protoFilesState, variablesProvider)
+	u.grpcService = grpcService
+	restService := rest.New(u.requestsState, u.environmentsState, variablesProvider)
 
 	egressService := egress.New(u.requestsState, u.environmentsState, restService, grpcService)
 
@@ -237,6 +242,7 @@ func (u *UI) Run() error {
 			e.Frame(gtx.Ops)
 		// this is sent when the application is closed.
 		case app.DestroyEvent:
+			u.grpcService.CloseConnections()
 			return e.Err
 		}
 	}
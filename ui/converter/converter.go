@@ -13,6 +13,7 @@ func KeyValueFromWidgetItems(items []*widgets.KeyValueItem) []domain.KeyValue {
 			Key:    v.Key,
 			Value:  v.Value,
 			Enable: v.Active,
+			Secret: v.Secret,
 		})
 	}
 
@@ -22,7 +23,7 @@ func KeyValueFromWidgetItems(items []*widgets.KeyValueItem) []domain.KeyValue {
 func WidgetItemsFromKeyValue(items []domain.KeyValue) []*widgets.KeyValueItem {
 	out := make([]*widgets.KeyValueItem, 0, len(items))
 	for _, v := range items {
-		out = append(out, widgets.NewKeyValueItem(v.Key, v.Value, v.ID, v.Enable))
+		out = append(out, widgets.NewKeyValueItem(v.Key, v.Value, v.ID, v.Enable).SetSecret(v.Secret))
 	}
 
 	return out
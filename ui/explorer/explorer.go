@@ -67,3 +67,33 @@ func (e *Explorer) ChoseFile(onResult func(r Result), extensions ...string) {
 		onResult(Result{Data: data, FilePath: filePath, Error: nil})
 	}(onResult)
 }
+
+// SaveFile asks the user where to save the data, name is the suggested name of the file.
+func (e *Explorer) SaveFile(name string, data []byte, onResult func(r Result)) {
+	go func(onResult func(r Result)) {
+		defer e.w.Invalidate()
+
+		file, err := e.expl.CreateFile(name)
+		if err != nil {
+			onResult(Result{Error: fmt.Errorf("failed creating file: %w", err)})
+			return
+		}
+
+		filePath := ""
+		if f, ok := file.(*os.File); ok {
+			filePath = f.Name()
+		}
+
+		if _, err := file.Write(data); err != nil {
+			_ = file.Close()
+			onResult(Result{Error: fmt.Errorf("failed writing file: %w", err), FilePath: filePath})
+			return
+		}
+
+		if err := file.Close(); err != nil {
+			onResult(Result{Error: fmt.Errorf("failed closing file: %w", err), FilePath: filePath})
+			return
+		}
+		onResult(Result{Data: data, FilePath: filePath})
+	}(onResult)
+}
@@ -1,9 +1,13 @@
 package environments
 
 import (
+	"fmt"
+	"strings"
+
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"gioui.org/widget"
+	"gioui.org/widget/material"
 
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
@@ -20,19 +24,30 @@ type container struct {
 	SaveButton  widget.Clickable
 	Prompt      *widgets.Prompt
 	DataChanged bool
+
+	// inheritance
+	Extends     *widgets.DropDown
+	effective   []domain.EffectiveValue
+	sourceNames map[string]string
+	missingKeys []string
+	list        *widget.List
 }
 
-func newContainer(id, name string, items []domain.KeyValue) *container {
+func newContainer(id, name string, items []domain.KeyValue, theme *chapartheme.Theme) *container {
 	search := widgets.NewTextField("", "Search items")
 	search.SetIcon(widgets.SearchIcon, widgets.IconPositionEnd)
 
 	c := &container{
 		Identifier: id,
-		Items:      widgets.NewKeyValue(converter.WidgetItemsFromKeyValue(items)...),
+		Items:      widgets.NewKeyValue(converter.WidgetItemsFromKeyValue(items)...).WithSecrets(),
 		Title:      widgets.NewEditableLabel(name),
 		SearchBox:  search,
 		SaveButton: widget.Clickable{},
 		Prompt:     widgets.NewPrompt("Save", "", widgets.ModalTypeWarn),
+		Extends:    widgets.NewDropDown(theme),
+		list: &widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
 	}
 	c.Prompt.WithoutRememberBool()
 	return c
@@ -42,6 +57,27 @@ func (c *container) SetItems(items []domain.KeyValue) {
 	c.Items.SetItems(converter.WidgetItemsFromKeyValue(items))
 }
 
+// SetBaseEnvironments sets the environments which can be extended, selected is the id of the current base.
+func (c *container) SetBaseEnvironments(envs []*domain.Environment, selected string) {
+	opts := make([]*widgets.DropDownOption, 0, len(envs)+1)
+	opts = append(opts, widgets.NewDropDownOption("None").WithValue(""))
+	for _, env := range envs {
+		if env.MetaData.ID == c.Identifier {
+			continue
+		}
+		opts = append(opts, widgets.NewDropDownOption(env.MetaData.Name).WithValue(env.MetaData.ID))
+	}
+	c.Extends.SetOptions(opts...)
+	c.Extends.SetSelectedByValue(selected)
+}
+
+// SetEffectiveValues sets the values merged with the base environments, names maps the ids of the environments to their names.
+func (c *container) SetEffectiveValues(values []domain.EffectiveValue, names map[string]string, missingKeys []string) {
+	c.effective = values
+	c.sourceNames = names
+	c.missingKeys = missingKeys
+}
+
 func (c *container) Layout(gtx layout.Context, theme *chapartheme.Theme, selectedID string) layout.Dimensions {
 	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
@@ -75,8 +111,77 @@ func (c *container) Layout(gtx layout.Context, theme *chapartheme.Theme, selecte
 					)
 				})
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(material.Label(theme.Material(), theme.TextSize, "Extends").Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							c.Extends.MinWidth = unit.Dp(150)
+							return c.Extends.Layout(gtx, theme)
+						}),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if len(c.missingKeys) == 0 {
+					return layout.Dimensions{}
+				}
+
+				lb := material.Label(theme.Material(), theme.TextSize, fmt.Sprintf("Missing keys defined by the sibling environments: %s", strings.Join(c.missingKeys, ", ")))
+				lb.Color = theme.WarningColor
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, lb.Layout)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return c.Items.WithAddLayout(gtx, "", "Disabled items have no effect on your requests, locked items are secrets kept in the encrypted vault", theme)
+			}),
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-				return c.Items.WithAddLayout(gtx, "", "Disabled items have no effect on your requests", theme)
+				if c.Extends.GetSelected().GetValue() == "" {
+					return layout.Dimensions{}
+				}
+				return c.effectiveLayout(gtx, theme)
+			}),
+		)
+	})
+}
+
+// effectiveLayout shows the values of the environment after inheritance, marking the inherited and overridden ones.
+func (c *container) effectiveLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return layout.Inset{Top: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, material.Label(theme.Material(), unit.Sp(14), "Effective values").Layout)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.List(theme.Material(), c.list).Layout(gtx, len(c.effective), func(gtx layout.Context, i int) layout.Dimensions {
+					v := c.effective[i]
+					value := v.Value
+					if v.Secret {
+						value = "••••••"
+					}
+
+					origin := ""
+					originColor := theme.TextColor
+					switch {
+					case v.Source != c.Identifier:
+						origin = fmt.Sprintf("inherited from %s", c.sourceNames[v.Source])
+					case v.Overridden:
+						origin = "overridden"
+						originColor = theme.WarningColor
+					}
+
+					return layout.Inset{Top: unit.Dp(2), Bottom: unit.Dp(2)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+							layout.Flexed(0.3, material.Label(theme.Material(), theme.TextSize, v.Key).Layout),
+							layout.Flexed(0.4, material.Label(theme.Material(), theme.TextSize, value).Layout),
+							layout.Flexed(0.3, func(gtx layout.Context) layout.Dimensions {
+								lb := material.Label(theme.Material(), theme.TextSize, origin)
+								lb.Color = originColor
+								return lb.Layout(gtx)
+							}),
+						)
+					})
+				})
 			}),
 		)
 	})
@@ -3,8 +3,10 @@ package environments
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/exporter"
 	"github.com/chapar-rest/chapar/internal/importer"
 	"github.com/chapar-rest/chapar/internal/repository"
 	"github.com/chapar-rest/chapar/internal/state"
@@ -40,6 +42,9 @@ func NewController(view *View, repo repository.Repository, envState *state.Envir
 	view.SetOnSave(c.onSave)
 	view.SetOnTabClose(c.onTabClose)
 	view.SetOnTreeViewMenuClicked(c.onTreeViewMenuClicked)
+	view.SetOnUnlockVault(c.onUnlockVault)
+	view.SetOnExtendsChanged(c.onExtendsChanged)
+	view.SetVaultLocked(repo.IsVaultLocked())
 	envState.AddEnvironmentChangeListener(c.onEnvironmentChange)
 
 	return c
@@ -69,7 +74,8 @@ func (c *Controller) onImportEnvironment() {
 			return
 		}
 
-		if err := importer.ImportPostmanEnvironment(result.Data); err != nil {
+		imported, err := importer.ImportPostmanEnvironment(result.Data)
+		if err != nil {
 			c.view.showError(fmt.Errorf("failed to import postman environment %w", err))
 			return
 		}
@@ -79,6 +85,10 @@ func (c *Controller) onImportEnvironment() {
 			return
 		}
 
+		if !imported.Report.IsEmpty() {
+			c.view.showWarning("Import report", imported.Report.String())
+		}
+
 	}, "json")
 }
 
@@ -88,6 +98,8 @@ func (c *Controller) onEnvironmentChange(env *domain.Environment, source state.S
 		return
 	}
 
+	defer c.refreshInheritance()
+
 	switch action {
 	case state.ActionAdd:
 		c.view.AddTreeViewNode(env)
@@ -120,6 +132,7 @@ func (c *Controller) onTitleChanged(id string, title string) {
 	}
 
 	c.view.SetTabDirty(id, false)
+	c.refreshInheritance()
 }
 
 func (c *Controller) onTreeViewNodeDoubleClicked(id string) {
@@ -131,11 +144,13 @@ func (c *Controller) onTreeViewNodeDoubleClicked(id string) {
 	if c.view.IsTabOpen(id) {
 		c.view.SwitchToTab(env.MetaData.ID)
 		c.view.OpenContainer(env)
+		c.refreshInheritance()
 		return
 	}
 
 	c.view.OpenTab(env)
 	c.view.OpenContainer(env)
+	c.refreshInheritance()
 }
 
 func (c *Controller) LoadData() error {
@@ -148,6 +163,26 @@ func (c *Controller) LoadData() error {
 	return nil
 }
 
+func (c *Controller) onUnlockVault(passphrase string) {
+	if err := c.repo.UnlockVault(passphrase); err != nil {
+		c.view.showError(fmt.Errorf("failed to unlock the vault, %w", err))
+		return
+	}
+	c.view.SetVaultLocked(false)
+
+	// reload the environments to read their secrets from the vault
+	envs, err := c.state.LoadEnvironmentsFromDisk()
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to load environments, %w", err))
+		return
+	}
+
+	for _, env := range envs {
+		c.view.ReloadContainerData(env)
+	}
+	c.refreshInheritance()
+}
+
 func (c *Controller) onTabSelected(id string) {
 	if c.activeTabID == id {
 		return
@@ -156,6 +191,7 @@ func (c *Controller) onTabSelected(id string) {
 	env := c.state.GetEnvironment(id)
 	c.view.SwitchToTab(env.MetaData.ID)
 	c.view.OpenContainer(env)
+	c.refreshInheritance()
 }
 
 func (c *Controller) onItemsChanged(id string, items []domain.KeyValue) {
@@ -182,7 +218,63 @@ func (c *Controller) onItemsChanged(id string, items []domain.KeyValue) {
 		return
 	}
 
-	c.view.SetTabDirty(id, !domain.CompareKeyValues(env.Spec.Values, envFromFile.Spec.Values))
+	c.view.SetTabDirty(id, isEnvironmentChanged(env, envFromFile))
+	c.refreshInheritance()
+}
+
+func (c *Controller) onExtendsChanged(id, baseID string) {
+	env := c.state.GetEnvironment(id)
+	if env == nil || env.Spec.Extends == baseID {
+		return
+	}
+
+	if err := c.state.ValidateExtends(id, baseID); err != nil {
+		c.view.showError(fmt.Errorf("failed to extend environment, %w", err))
+		c.refreshInheritance()
+		return
+	}
+
+	env.Spec.Extends = baseID
+	if err := c.state.UpdateEnvironment(env, state.SourceController, true); err != nil {
+		c.view.showError(fmt.Errorf("failed to update environment, %w", err))
+		return
+	}
+
+	envFromFile, err := c.state.GetEnvironmentFromDisc(id)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to get environment from file %w", err))
+		return
+	}
+
+	c.view.SetTabDirty(id, isEnvironmentChanged(env, envFromFile))
+	c.refreshInheritance()
+}
+
+// refreshInheritance updates the base environments, effective values and missing keys of the open environments.
+func (c *Controller) refreshInheritance() {
+	envs := c.state.GetEnvironments()
+	sort.Slice(envs, func(i, j int) bool {
+		return envs[i].MetaData.Name < envs[j].MetaData.Name
+	})
+
+	names := make(map[string]string, len(envs))
+	for _, env := range envs {
+		names[env.MetaData.ID] = env.MetaData.Name
+	}
+
+	for _, env := range envs {
+		if !c.view.IsTabOpen(env.MetaData.ID) {
+			continue
+		}
+
+		// a cycle is rejected when the base is selected, so an error only leaves the effective values empty
+		values, _ := c.state.EffectiveValues(env.MetaData.ID)
+		c.view.SetInheritance(env.MetaData.ID, envs, env.Spec.Extends, values, names, c.state.MissingKeys(env.MetaData.ID))
+	}
+}
+
+func isEnvironmentChanged(env, envFromFile *domain.Environment) bool {
+	return env.Spec.Extends != envFromFile.Spec.Extends || !domain.CompareKeyValues(env.Spec.Values, envFromFile.Spec.Values)
 }
 
 func (c *Controller) onSave(id string) {
@@ -206,7 +298,7 @@ func (c *Controller) onTabClose(id string) {
 	}
 
 	// if data is not changed close the tab
-	if domain.CompareKeyValues(env.Spec.Values, envFromFile.Spec.Values) {
+	if !isEnvironmentChanged(env, envFromFile) {
 		c.view.CloseTab(id)
 		return
 	}
@@ -249,6 +341,10 @@ func (c *Controller) onTreeViewMenuClicked(id string, action string) {
 	switch action {
 	case Duplicate:
 		c.duplicateEnvironment(id)
+	case Extend:
+		c.extendEnvironment(id)
+	case ExportToPostman:
+		c.exportEnvironment(id)
 	case Delete:
 		c.deleteEnvironment(id)
 	}
@@ -270,6 +366,59 @@ func (c *Controller) duplicateEnvironment(id string) {
 	c.saveEnvironmentToDisc(newEnv.MetaData.ID)
 }
 
+// exportEnvironment saves the environment, with the values it inherits, as a Postman environment as Postman
+// environments can not extend each other. The values of the secrets are left out.
+func (c *Controller) exportEnvironment(id string) {
+	env, err := c.state.GetEffectiveEnvironment(id)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to export environment, %w", err))
+		return
+	}
+	if env == nil {
+		return
+	}
+
+	data, err := exporter.ExportPostmanEnvironment(env)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to export environment, %w", err))
+		return
+	}
+
+	c.explorer.SaveFile(env.MetaData.Name+".postman_environment.json", data, func(r explorer.Result) {
+		if r.Error != nil {
+			if !errors.Is(r.Error, explorer.ErrUserDecline) {
+				c.view.showError(r.Error)
+			}
+			return
+		}
+		c.view.showInfo("Export", fmt.Sprintf("%s is exported to %s, the values of its secrets are left out.", env.MetaData.Name, r.FilePath))
+	})
+}
+
+// extendEnvironment creates an empty environment which inherits the values of the environment.
+func (c *Controller) extendEnvironment(id string) {
+	base := c.state.GetEnvironment(id)
+	if base == nil {
+		return
+	}
+
+	env := domain.NewEnvironment(base.MetaData.Name + " (extended)")
+	env.Spec.Extends = base.MetaData.ID
+
+	filePath, err := c.repo.GetNewEnvironmentFilePath(env.MetaData.Name)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to get new environment file path %w", err))
+		return
+	}
+
+	env.FilePath = filePath.Path
+	env.MetaData.Name = filePath.NewName
+
+	c.state.AddEnvironment(env, state.SourceController)
+	c.view.AddTreeViewNode(env)
+	c.saveEnvironmentToDisc(env.MetaData.ID)
+}
+
 func (c *Controller) deleteEnvironment(id string) {
 	env := c.state.GetEnvironment(id)
 	if env == nil {
@@ -283,4 +432,17 @@ func (c *Controller) deleteEnvironment(id string) {
 
 	c.view.RemoveTreeViewNode(id)
 	c.view.CloseTab(id)
+
+	// the environments which extended the deleted one inherit from its base instead
+	for _, child := range c.state.GetEnvironments() {
+		if child.Spec.Extends != id {
+			continue
+		}
+
+		child.Spec.Extends = env.Spec.Extends
+		if err := c.state.UpdateEnvironment(child, state.SourceController, false); err != nil {
+			c.view.showError(fmt.Errorf("failed to update environment, %w", err))
+		}
+	}
+	c.refreshInheritance()
 }
@@ -17,13 +17,22 @@ import (
 )
 
 const (
-	Duplicate = "Duplicate"
-	Delete    = "Delete"
+	Duplicate       = "Duplicate"
+	Extend          = "Extend"
+	ExportToPostman = "Export to Postman"
+	Delete          = "Delete"
 )
 
 type View struct {
+	theme *chapartheme.Theme
+
 	newEnvButton widget.Clickable
 	importButton widget.Clickable
+	unlockButton widget.Clickable
+
+	vaultLocked     bool
+	showUnlockModal bool
+	unlockModal     *widgets.InputModal
 
 	treeViewSearchBox *widgets.TextField
 	treeView          *widgets.TreeView
@@ -44,6 +53,8 @@ type View struct {
 	onTreeViewNodeClicked func(id string)
 	onTreeViewMenuClicked func(id string, action string)
 	onTabSelected         func(id string)
+	onUnlockVault         func(passphrase string)
+	onExtendsChanged      func(id, baseID string)
 
 	// state
 	containers    *safemap.Map[*container]
@@ -63,6 +74,7 @@ func NewView(theme *chapartheme.Theme) *View {
 	itemsSearchBox.SetBorderColor(theme.BorderColor)
 
 	v := &View{
+		theme:             theme,
 		treeViewSearchBox: search,
 		tabHeader:         widgets.NewTabs([]*widgets.Tab{}, nil),
 		treeView:          widgets.NewTreeView([]*widgets.TreeNode{}),
@@ -76,9 +88,23 @@ func NewView(theme *chapartheme.Theme) *View {
 		openTabs:      safemap.New[*widgets.Tab](),
 		containers:    safemap.New[*container](),
 
-		tipsView: tips.New(),
+		tipsView:    tips.New(),
+		unlockModal: widgets.NewInputModal("Enter the passphrase of the vault of secrets, it is created on first use", "Passphrase"),
 	}
 
+	v.unlockModal.SubmitText = "Unlock"
+	v.unlockModal.SetSecret()
+	v.unlockModal.SetOnClose(func() {
+		v.showUnlockModal = false
+	})
+	v.unlockModal.SetOnAdd(func(text string) {
+		v.showUnlockModal = false
+		v.unlockModal.SetText("")
+		if v.onUnlockVault != nil {
+			v.onUnlockVault(text)
+		}
+	})
+
 	v.treeViewSearchBox.SetOnTextChange(func(text string) {
 		if v.treeViewNodes.Len() == 0 {
 			return
@@ -95,6 +121,20 @@ func (v *View) showError(err error) {
 	v.modal.Show()
 }
 
+func (v *View) showInfo(title, text string) {
+	v.modal = widgets.NewMessageModal(title, text, widgets.MessageModalTypeInfo, func(_ string) {
+		v.modal.Hide()
+	}, widgets.ModalOption{Text: "Ok"})
+	v.modal.Show()
+}
+
+func (v *View) showWarning(title, text string) {
+	v.modal = widgets.NewMessageModal(title, text, widgets.MessageModalTypeWarn, func(_ string) {
+		v.modal.Hide()
+	}, widgets.ModalOption{Text: "Ok"})
+	v.modal.Show()
+}
+
 func (v *View) PopulateTreeView(envs []*domain.Environment) {
 	treeViewNodes := make([]*widgets.TreeNode, 0)
 	for _, env := range envs {
@@ -105,7 +145,7 @@ func (v *View) PopulateTreeView(envs []*domain.Environment) {
 		node := &widgets.TreeNode{
 			Text:        env.MetaData.Name,
 			Identifier:  env.MetaData.ID,
-			MenuOptions: []string{Duplicate, Delete},
+			MenuOptions: []string{Duplicate, Extend, ExportToPostman, Delete},
 		}
 
 		treeViewNodes = append(treeViewNodes, node)
@@ -122,7 +162,7 @@ func (v *View) AddTreeViewNode(env *domain.Environment) {
 	node := &widgets.TreeNode{
 		Text:        env.MetaData.Name,
 		Identifier:  env.MetaData.ID,
-		MenuOptions: []string{Duplicate, Delete},
+		MenuOptions: []string{Duplicate, Extend, ExportToPostman, Delete},
 	}
 	v.treeView.AddNode(node)
 	v.treeViewNodes.Set(env.MetaData.ID, node)
@@ -142,6 +182,27 @@ func (v *View) RemoveTreeViewNode(id string) {
 	v.treeView.RemoveNode(id)
 }
 
+func (v *View) SetOnUnlockVault(onUnlockVault func(passphrase string)) {
+	v.onUnlockVault = onUnlockVault
+}
+
+// SetVaultLocked shows the button to unlock the vault of the secret values.
+func (v *View) SetVaultLocked(locked bool) {
+	v.vaultLocked = locked
+}
+
+func (v *View) SetOnExtendsChanged(onExtendsChanged func(id, baseID string)) {
+	v.onExtendsChanged = onExtendsChanged
+}
+
+// SetInheritance shows the base environment and the effective values of the environment if its container is open.
+func (v *View) SetInheritance(id string, bases []*domain.Environment, selected string, values []domain.EffectiveValue, names map[string]string, missingKeys []string) {
+	if ct, ok := v.containers.Get(id); ok {
+		ct.SetBaseEnvironments(bases, selected)
+		ct.SetEffectiveValues(values, names, missingKeys)
+	}
+}
+
 func (v *View) SetOnItemsChanged(onItemsChanged func(id string, items []domain.KeyValue)) {
 	v.onItemsChanged = onItemsChanged
 }
@@ -251,7 +312,7 @@ func (v *View) OpenContainer(env *domain.Environment) {
 		return
 	}
 
-	ct := newContainer(env.MetaData.ID, env.MetaData.Name, env.Spec.Values)
+	ct := newContainer(env.MetaData.ID, env.MetaData.Name, env.Spec.Values, v.theme)
 	ct.Title.SetOnChanged(func(text string) {
 		if v.onTitleChanged != nil {
 			v.onTitleChanged(env.MetaData.ID, text)
@@ -264,6 +325,12 @@ func (v *View) OpenContainer(env *domain.Environment) {
 		}
 	})
 
+	ct.Extends.SetOnChanged(func(baseID string) {
+		if v.onExtendsChanged != nil {
+			v.onExtendsChanged(env.MetaData.ID, baseID)
+		}
+	})
+
 	ct.SearchBox.SetOnTextChange(func(text string) {
 		if ct.Items == nil {
 			return
@@ -316,6 +383,20 @@ func (v *View) envList(gtx layout.Context, theme *chapartheme.Theme) layout.Dime
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				return layout.Inset{Left: unit.Dp(10), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceStart}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if !v.vaultLocked {
+								return layout.Dimensions{}
+							}
+
+							if v.unlockButton.Clicked(gtx) {
+								v.showUnlockModal = true
+							}
+							btn := widgets.Button(theme.Material(), &v.unlockButton, widgets.LockIcon, widgets.IconPositionStart, "Unlock")
+							btn.Color = theme.ButtonTextColor
+							return layout.Inset{Right: unit.Dp(2)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return btn.Layout(gtx, theme)
+							})
+						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							if v.importButton.Clicked(gtx) {
 								if v.onImportEnv != nil {
@@ -356,6 +437,9 @@ func (v *View) envList(gtx layout.Context, theme *chapartheme.Theme) layout.Dime
 
 func (v *View) containerHolder(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	v.modal.Layout(gtx, theme)
+	if v.showUnlockModal {
+		v.unlockModal.Layout(gtx, theme)
+	}
 
 	if v.onSave != nil {
 		keys.OnSaveCommand(gtx, v, func() {
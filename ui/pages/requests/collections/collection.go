@@ -6,7 +6,9 @@ import (
 	"gioui.org/widget"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/mock"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
 	"github.com/chapar-rest/chapar/ui/keys"
 	"github.com/chapar-rest/chapar/ui/widgets"
 )
@@ -14,6 +16,8 @@ import (
 type Collection struct {
 	collection *domain.Collection
 	Title      *widgets.EditableLabel
+	Variables  *widgets.KeyValue
+	Mock       *Mock
 
 	saveButton *widget.Clickable
 
@@ -26,6 +30,9 @@ type Collection struct {
 
 func (c *Collection) SetOnDataChanged(f func(id string, data any)) {
 	c.onDataChanged = f
+	c.Variables.SetOnChanged(func(items []*widgets.KeyValueItem) {
+		c.onDataChanged(c.collection.MetaData.ID, converter.KeyValueFromWidgetItems(items))
+	})
 }
 
 func (c *Collection) ShowPrompt(title, content, modalType string, onSubmit func(selectedOption string, remember bool), options ...widgets.Option) {
@@ -50,10 +57,12 @@ func (c *Collection) SetOnSave(f func(id string)) {
 	c.onSave = f
 }
 
-func New(collection *domain.Collection) *Collection {
+func New(collection *domain.Collection, theme *chapartheme.Theme) *Collection {
 	c := &Collection{
 		collection: collection,
 		Title:      widgets.NewEditableLabel(collection.MetaData.Name),
+		Variables:  widgets.NewKeyValue(converter.WidgetItemsFromKeyValue(collection.Spec.Variables)...),
+		Mock:       NewMock(theme),
 		prompt:     widgets.NewPrompt("", "", ""),
 		saveButton: new(widget.Clickable),
 	}
@@ -61,6 +70,26 @@ func New(collection *domain.Collection) *Collection {
 	return c
 }
 
+func (c *Collection) SetOnMockStart(f func(id, addr string, grpc bool, opts mock.Options)) {
+	c.Mock.SetOnStart(func(addr string, grpc bool, opts mock.Options) {
+		f(c.collection.MetaData.ID, addr, grpc, opts)
+	})
+}
+
+func (c *Collection) SetOnMockStop(f func(id string)) {
+	c.Mock.SetOnStop(func() {
+		f(c.collection.MetaData.ID)
+	})
+}
+
+func (c *Collection) SetMockRunning(running bool, addr string) {
+	c.Mock.SetRunning(running, addr)
+}
+
+func (c *Collection) AddMockLogLine(line string) {
+	c.Mock.AddLogLine(line)
+}
+
 func (c *Collection) SetOnTitleChanged(f func(string)) {
 	c.Title.SetOnChanged(f)
 }
@@ -103,6 +132,13 @@ func (c *Collection) Layout(gtx layout.Context, theme *chapartheme.Theme) layout
 					)
 				})
 			}),
+			layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+				return c.Variables.WithAddLayout(gtx, "Variables", "Shared by the requests of the collection, requests can override them", theme)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+			layout.Flexed(0.5, func(gtx layout.Context) layout.Dimensions {
+				return c.Mock.Layout(gtx, theme)
+			}),
 		)
 	})
 }
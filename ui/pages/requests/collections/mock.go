@@ -0,0 +1,176 @@
+package collections
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/mock"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Mock starts and stops the mock server of the collection and shows its request log.
+type Mock struct {
+	address   *widgets.LabeledInput
+	latency   *widgets.LabeledInput
+	errorRate *widgets.LabeledInput
+
+	template widget.Bool
+	grpc     widget.Bool
+
+	startButton widget.Clickable
+	running     bool
+	runningAddr string
+
+	log *widgets.CodeEditor
+
+	onStart func(addr string, grpc bool, opts mock.Options)
+	onStop  func()
+}
+
+func NewMock(theme *chapartheme.Theme) *Mock {
+	input := func(label, text, hint string) *widgets.LabeledInput {
+		l := &widgets.LabeledInput{
+			Label:          label,
+			SpaceBetween:   5,
+			MinEditorWidth: unit.Dp(150),
+			MinLabelWidth:  unit.Dp(80),
+			Editor:         widgets.NewPatternEditor(),
+			Hint:           hint,
+		}
+		l.SetText(text)
+		return l
+	}
+
+	m := &Mock{
+		address:   input("Address", "localhost:8090", "e.g. localhost:8090"),
+		latency:   input("Latency", "0ms", "e.g. 200ms"),
+		errorRate: input("Error rate", "0", "percent of failed requests"),
+		log:       widgets.NewCodeEditor("", widgets.CodeLanguageYAML, theme),
+	}
+	m.log.SetReadOnly(true)
+	return m
+}
+
+func (m *Mock) SetOnStart(f func(addr string, grpc bool, opts mock.Options)) {
+	m.onStart = f
+}
+
+func (m *Mock) SetOnStop(f func()) {
+	m.onStop = f
+}
+
+func (m *Mock) SetRunning(running bool, addr string) {
+	m.running = running
+	m.runningAddr = addr
+}
+
+func (m *Mock) AddLogLine(line string) {
+	m.log.SetCode(m.log.Code() + line + "\n")
+}
+
+// options returns the options of the inputs, the latency is a duration and the error rate a percentage.
+func (m *Mock) options() (mock.Options, error) {
+	opts := mock.Options{Template: m.template.Value}
+
+	if text := strings.TrimSpace(m.latency.Text()); text != "" {
+		latency, err := time.ParseDuration(text)
+		if err != nil {
+			return opts, fmt.Errorf("invalid latency, %w", err)
+		}
+		opts.Latency = latency
+	}
+
+	if text := strings.TrimSuffix(strings.TrimSpace(m.errorRate.Text()), "%"); text != "" {
+		rate, err := strconv.ParseFloat(text, 64)
+		if err != nil || rate < 0 || rate > 100 {
+			return opts, fmt.Errorf("invalid error rate %q, it must be between 0 and 100", text)
+		}
+		opts.ErrorRate = rate / 100
+	}
+	return opts, nil
+}
+
+func (m *Mock) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if m.startButton.Clicked(gtx) {
+		if m.running {
+			if m.onStop != nil {
+				go m.onStop()
+			}
+		} else if m.onStart != nil {
+			if opts, err := m.options(); err != nil {
+				m.AddLogLine(err.Error())
+			} else {
+				go m.onStart(strings.TrimSpace(m.address.Text()), m.grpc.Value, opts)
+			}
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return material.Label(theme.Material(), theme.TextSize, "Mock server").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						title := "Start"
+						if m.running {
+							title = "Stop"
+						}
+
+						btn := widgets.Button(theme.Material(), &m.startButton, nil, widgets.IconPositionStart, title)
+						btn.Color = theme.ButtonTextColor
+						return btn.Layout(gtx, theme)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !m.running {
+							return layout.Dimensions{}
+						}
+						return material.Label(theme.Material(), theme.TextSize, "Listening on "+m.runningAddr).Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return m.address.Layout(gtx, theme)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return m.latency.Layout(gtx, theme)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return m.errorRate.Layout(gtx, theme)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return widgets.CheckBox(theme.Material(), &m.template, "Render {{request.*}} variables in examples").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return widgets.CheckBox(theme.Material(), &m.grpc, "Serve gRPC requests").Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return m.log.Layout(gtx, theme, "")
+		}),
+	)
+}
@@ -70,6 +70,10 @@ func (a *AddressBar) SetOnSubmit(onSubmit func()) {
 	a.url.SetOnSubmit(onSubmit)
 }
 
+func (a *AddressBar) SetVariableHints(hints *widgets.VariableHints) {
+	a.url.SetVariableHints(hints)
+}
+
 func (a *AddressBar) SetURL(url string) {
 	a.url.SetText(url)
 }
@@ -1,11 +1,13 @@
 package component
 
 import (
+	"slices"
 	"sort"
 	"strconv"
 
 	"gioui.org/layout"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
 
 	"github.com/chapar-rest/chapar/internal/domain"
@@ -14,6 +16,7 @@ import (
 )
 
 type PrePostRequest struct {
+	theme    *chapartheme.Theme
 	dropDown *widgets.DropDown
 	script   *widgets.CodeEditor
 
@@ -24,9 +27,14 @@ type PrePostRequest struct {
 
 	triggerRequestForm     *TriggerRequestForm
 	onTriggerRequestChange func(collectionID, requestID string)
+	onPrerequisitesChange  func(alias, cacheFor string, prerequisites []domain.TriggerRequest)
+	onClearCache           func()
 
 	setEnvForm          *SetEnvForm
 	onSetEnvFormChanged func(statusCode int, item, from, fromKey string)
+
+	nextRequestForm     *NextRequestForm
+	onNextRequestChange func(statusCode int, requestID string)
 }
 
 type SetEnvForm struct {
@@ -40,6 +48,29 @@ type SetEnvForm struct {
 type TriggerRequestForm struct {
 	collectionsDropDown *widgets.DropDown
 	requestDropDown     *widgets.DropDown
+	aliasEditor         *widgets.LabeledInput
+	cacheEditor         *widgets.LabeledInput
+
+	// requests are the requests the other prerequisites can be picked from.
+	requests         []*domain.Request
+	prerequisites    []*prerequisiteItem
+	addButton        widget.Clickable
+	clearCacheButton widget.Clickable
+	list             widget.List
+}
+
+// prerequisiteItem is a request sent before the request, after the triggered one.
+type prerequisiteItem struct {
+	requestDropDown *widgets.DropDown
+	aliasEditor     *widgets.LabeledInput
+	cacheEditor     *widgets.LabeledInput
+	deleteButton    widget.Clickable
+}
+
+// NextRequestForm sets the request a collection run continues with after the request.
+type NextRequestForm struct {
+	statusCodeEditor *widgets.LabeledInput
+	requestDropDown  *widgets.DropDown
 }
 
 const (
@@ -49,6 +80,7 @@ const (
 	TypeK8sTunnel      = "kubectl_tunnel"
 	TypeSSHTunnel      = "ssh_tunnel"
 	TypeTriggerRequest = "trigger_request"
+	TypeSetNextRequest = "set_next_request"
 )
 
 type Option struct {
@@ -60,6 +92,7 @@ type Option struct {
 
 func NewPrePostRequest(actions []Option, setFormFromDropDown *widgets.DropDown, theme *chapartheme.Theme) *PrePostRequest {
 	p := &PrePostRequest{
+		theme:               theme,
 		dropDown:            widgets.NewDropDown(theme),
 		script:              widgets.NewCodeEditor("", widgets.CodeLanguagePython, theme),
 		actionDropDownItems: actions,
@@ -91,6 +124,22 @@ func NewPrePostRequest(actions []Option, setFormFromDropDown *widgets.DropDown,
 		triggerRequestForm: &TriggerRequestForm{
 			collectionsDropDown: widgets.NewDropDown(theme),
 			requestDropDown:     widgets.NewDropDown(theme),
+			aliasEditor:         newAliasInput(),
+			cacheEditor:         newCacheInput(),
+			list: widget.List{
+				List: layout.List{Axis: layout.Vertical},
+			},
+		},
+		nextRequestForm: &NextRequestForm{
+			statusCodeEditor: &widgets.LabeledInput{
+				Label:          "Status Code",
+				SpaceBetween:   5,
+				MinEditorWidth: unit.Dp(150),
+				MinLabelWidth:  unit.Dp(80),
+				Editor:         widgets.NewPatternEditor(),
+				Hint:           "0 for any status",
+			},
+			requestDropDown: widgets.NewDropDown(theme),
 		},
 	}
 
@@ -111,6 +160,28 @@ func NewPrePostRequest(actions []Option, setFormFromDropDown *widgets.DropDown,
 	return p
 }
 
+func newAliasInput() *widgets.LabeledInput {
+	return &widgets.LabeledInput{
+		Label:          "Alias",
+		SpaceBetween:   5,
+		MinEditorWidth: unit.Dp(150),
+		MinLabelWidth:  unit.Dp(80),
+		Editor:         widgets.NewPatternEditor(),
+		Hint:           "e.g. login, used as {{login.body.token}}",
+	}
+}
+
+func newCacheInput() *widgets.LabeledInput {
+	return &widgets.LabeledInput{
+		Label:          "Cache for",
+		SpaceBetween:   5,
+		MinEditorWidth: unit.Dp(150),
+		MinLabelWidth:  unit.Dp(80),
+		Editor:         widgets.NewPatternEditor(),
+		Hint:           "e.g. 10m, empty to send every time",
+	}
+}
+
 func (p *PrePostRequest) SetCollections(collections []*domain.Collection, selectedID string) {
 	sort.Slice(collections, func(i, j int) bool {
 		return collections[i].MetaData.Name < collections[j].MetaData.Name
@@ -139,6 +210,143 @@ func (p *PrePostRequest) SetRequests(requests []*domain.Request, selectedID stri
 	p.triggerRequestForm.requestDropDown.SetSelectedByValue(selectedID)
 }
 
+// SetNextRequests sets the requests the run can continue with, in the order they are run.
+func (p *PrePostRequest) SetNextRequests(requests []*domain.Request, selectedID string) {
+	opts := make([]*widgets.DropDownOption, 0, len(requests)+1)
+	opts = append(opts, widgets.NewDropDownOption("End iteration").WithValue(domain.NextRequestNone))
+	for _, r := range requests {
+		opts = append(opts, widgets.NewDropDownOption(r.MetaData.Name).WithValue(r.MetaData.ID))
+	}
+	p.nextRequestForm.requestDropDown.SetOptions(opts...)
+	if selectedID != "" {
+		p.nextRequestForm.requestDropDown.SetSelectedByValue(selectedID)
+	}
+}
+
+func (p *PrePostRequest) SetNextRequestValues(next *domain.NextRequest) {
+	if next == nil {
+		return
+	}
+	p.nextRequestForm.statusCodeEditor.SetText(strconv.Itoa(next.StatusCode))
+	p.nextRequestForm.requestDropDown.SetSelectedByValue(next.RequestID)
+}
+
+func (p *PrePostRequest) SetOnNextRequestChanged(f func(statusCode int, requestID string)) {
+	p.onNextRequestChange = f
+
+	changed := func() {
+		statusCode, _ := strconv.Atoi(p.nextRequestForm.statusCodeEditor.Text())
+		p.onNextRequestChange(statusCode, p.nextRequestForm.requestDropDown.GetSelected().GetValue())
+	}
+	p.nextRequestForm.requestDropDown.SetOnChanged(func(_ string) {
+		changed()
+	})
+	p.nextRequestForm.statusCodeEditor.SetOnChanged(func(_ string) {
+		p.enforceNumericEditor(p.nextRequestForm.statusCodeEditor.Editor)
+		changed()
+	})
+}
+
+// SetTriggerRequestValues sets the alias and the cache duration of the triggered request.
+func (p *PrePostRequest) SetTriggerRequestValues(trigger *domain.TriggerRequest) {
+	if trigger == nil {
+		return
+	}
+	p.triggerRequestForm.aliasEditor.SetText(trigger.Alias)
+	p.triggerRequestForm.cacheEditor.SetText(trigger.CacheFor)
+}
+
+// SetPrerequisites sets the requests sent after the triggered one and the requests they can be picked from.
+func (p *PrePostRequest) SetPrerequisites(requests []*domain.Request, prerequisites []domain.TriggerRequest) {
+	requests = slices.Clone(requests)
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].MetaData.Name < requests[j].MetaData.Name
+	})
+
+	form := p.triggerRequestForm
+	form.requests = requests
+	form.prerequisites = form.prerequisites[:0]
+	for _, t := range prerequisites {
+		item := p.addPrerequisite()
+		item.requestDropDown.SetSelectedByValue(t.RequestID)
+		item.aliasEditor.SetText(t.Alias)
+		item.cacheEditor.SetText(t.CacheFor)
+	}
+}
+
+func (p *PrePostRequest) addPrerequisite() *prerequisiteItem {
+	item := &prerequisiteItem{
+		requestDropDown: widgets.NewDropDown(p.theme),
+		aliasEditor:     newAliasInput(),
+		cacheEditor:     newCacheInput(),
+	}
+	item.requestDropDown.MaxWidth = unit.Dp(150)
+
+	opts := make([]*widgets.DropDownOption, 0, len(p.triggerRequestForm.requests)+1)
+	opts = append(opts, widgets.NewDropDownOption("None").WithValue(domain.PrePostTypeNone))
+	for _, r := range p.triggerRequestForm.requests {
+		title := r.MetaData.Name
+		if r.CollectionName != "" {
+			title = r.CollectionName + " / " + title
+		}
+		opts = append(opts, widgets.NewDropDownOption(title).WithValue(r.MetaData.ID))
+	}
+	item.requestDropDown.SetOptions(opts...)
+
+	item.requestDropDown.SetOnChanged(func(_ string) {
+		p.prerequisitesChanged()
+	})
+	item.aliasEditor.SetOnChanged(func(_ string) {
+		p.prerequisitesChanged()
+	})
+	item.cacheEditor.SetOnChanged(func(_ string) {
+		p.prerequisitesChanged()
+	})
+
+	p.triggerRequestForm.prerequisites = append(p.triggerRequestForm.prerequisites, item)
+	return item
+}
+
+func (p *PrePostRequest) prerequisitesChanged() {
+	if p.onPrerequisitesChange == nil {
+		return
+	}
+
+	form := p.triggerRequestForm
+	prerequisites := make([]domain.TriggerRequest, 0, len(form.prerequisites))
+	for _, item := range form.prerequisites {
+		t := domain.TriggerRequest{
+			RequestID: item.requestDropDown.GetSelected().GetValue(),
+			Alias:     item.aliasEditor.Text(),
+			CacheFor:  item.cacheEditor.Text(),
+		}
+		for _, r := range form.requests {
+			if r.MetaData.ID == t.RequestID {
+				t.CollectionID = r.CollectionID
+			}
+		}
+		prerequisites = append(prerequisites, t)
+	}
+	p.onPrerequisitesChange(form.aliasEditor.Text(), form.cacheEditor.Text(), prerequisites)
+}
+
+// SetOnPrerequisitesChanged sets the callback called when the alias or the cache duration of the triggered
+// request, or the requests sent after it, are changed.
+func (p *PrePostRequest) SetOnPrerequisitesChanged(f func(alias, cacheFor string, prerequisites []domain.TriggerRequest)) {
+	p.onPrerequisitesChange = f
+	p.triggerRequestForm.aliasEditor.SetOnChanged(func(_ string) {
+		p.prerequisitesChanged()
+	})
+	p.triggerRequestForm.cacheEditor.SetOnChanged(func(_ string) {
+		p.prerequisitesChanged()
+	})
+}
+
+// SetOnClearCache sets the callback called to drop the cached responses of the prerequisites.
+func (p *PrePostRequest) SetOnClearCache(f func()) {
+	p.onClearCache = f
+}
+
 func (p *PrePostRequest) SetOnTriggerRequestChanged(f func(collectionID, requestID string)) {
 	p.onTriggerRequestChange = f
 	p.triggerRequestForm.collectionsDropDown.SetOnChanged(func(selected string) {
@@ -235,6 +443,8 @@ func (p *PrePostRequest) Layout(gtx layout.Context, theme *chapartheme.Theme) la
 					return p.SetEnvForm(gtx, theme)
 				case TypeTriggerRequest:
 					return p.TriggerRequestForm(gtx, theme)
+				case TypeSetNextRequest:
+					return p.NextRequestForm(gtx, theme)
 				}
 				return layout.Dimensions{}
 			}),
@@ -292,7 +502,7 @@ func (p *PrePostRequest) SetEnvForm(gtx layout.Context, theme *chapartheme.Theme
 			return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				label := "Key"
 				hint := "e.g. name"
-				if p.setEnvForm.fromDropDown.GetSelected().Value == domain.PostRequestSetFromResponseBody {
+				if from := p.setEnvForm.fromDropDown.GetSelected().Value; from == domain.PostRequestSetFromResponseBody || from == domain.PostRequestSetFromResponseErrorDetails {
 					label = "JSON Path"
 					hint = "e.g. $.data[0].name"
 				}
@@ -317,9 +527,25 @@ func (p *PrePostRequest) SetEnvForm(gtx layout.Context, theme *chapartheme.Theme
 
 func (p *PrePostRequest) TriggerRequestForm(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	topButtonInset := layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(4)}
+	form := p.triggerRequestForm
 
-	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+	if form.addButton.Clicked(gtx) {
+		p.addPrerequisite()
+		p.prerequisitesChanged()
+	}
+	if form.clearCacheButton.Clicked(gtx) && p.onClearCache != nil {
+		p.onClearCache()
+	}
+	for i, item := range form.prerequisites {
+		if item.deleteButton.Clicked(gtx) {
+			form.prerequisites = slices.Delete(form.prerequisites, i, i+1)
+			p.prerequisitesChanged()
+			break
+		}
+	}
+
+	children := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{
 				Axis:      layout.Horizontal,
 				Alignment: layout.Middle,
@@ -335,8 +561,8 @@ func (p *PrePostRequest) TriggerRequestForm(gtx layout.Context, theme *chaparthe
 					})
 				}),
 			)
-		}),
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		},
+		func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{
 				Axis:      layout.Horizontal,
 				Alignment: layout.Middle,
@@ -352,6 +578,124 @@ func (p *PrePostRequest) TriggerRequestForm(gtx layout.Context, theme *chaparthe
 					})
 				}),
 			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return form.aliasEditor.Layout(gtx, theme)
+			})
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return form.cacheEditor.Layout(gtx, theme)
+			})
+		},
+	}
+
+	for _, item := range form.prerequisites {
+		children = append(children, func(gtx layout.Context) layout.Dimensions {
+			return p.prerequisiteLayout(gtx, theme, item)
+		})
+	}
+
+	children = append(children, func(gtx layout.Context) layout.Dimensions {
+		return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := widgets.Button(theme.Material(), &form.addButton, widgets.PlusIcon, widgets.IconPositionStart, "Add prerequisite")
+					btn.Color = theme.ButtonTextColor
+					return btn.Layout(gtx, theme)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := widgets.Button(theme.Material(), &form.clearCacheButton, widgets.CleanIcon, widgets.IconPositionStart, "Clear cache")
+					btn.Color = theme.ButtonTextColor
+					return btn.Layout(gtx, theme)
+				}),
+			)
+		})
+	})
+
+	// the form grows with the prerequisites so it is scrollable
+	return material.List(theme.Material(), &form.list).Layout(gtx, len(children), func(gtx layout.Context, i int) layout.Dimensions {
+		return children[i](gtx)
+	})
+}
+
+func (p *PrePostRequest) prerequisiteLayout(gtx layout.Context, theme *chapartheme.Theme, item *prerequisiteItem) layout.Dimensions {
+	topButtonInset := layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(4)}
+
+	return layout.Inset{Top: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Min.X = gtx.Dp(85)
+						return material.Label(theme.Material(), theme.TextSize, "Then").Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						item.requestDropDown.MinWidth = unit.Dp(162)
+						return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return item.requestDropDown.Layout(gtx, theme)
+						})
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						ib := widgets.IconButton{
+							Icon:      widgets.DeleteIcon,
+							Size:      unit.Dp(20),
+							Color:     theme.TextColor,
+							Clickable: &item.deleteButton,
+						}
+						return ib.Layout(gtx, theme)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return item.aliasEditor.Layout(gtx, theme)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return item.cacheEditor.Layout(gtx, theme)
+				})
+			}),
+		)
+	})
+}
+
+func (p *PrePostRequest) NextRequestForm(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	topButtonInset := layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(4)}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return p.nextRequestForm.statusCodeEditor.Layout(gtx, theme)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:      layout.Horizontal,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Dp(85)
+					return material.Label(theme.Material(), theme.TextSize, "Next Request").Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.nextRequestForm.requestDropDown.MinWidth = unit.Dp(162)
+					return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return p.nextRequestForm.requestDropDown.Layout(gtx, theme)
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return material.Label(theme.Material(), theme.TextSize, "When the collection is run, continue with this request after the response.").Layout(gtx)
+			})
 		}),
 	)
 }
@@ -4,6 +4,9 @@ import (
 	"gioui.org/layout"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/loadtest"
+	"github.com/chapar-rest/chapar/internal/mock"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/widgets"
 )
@@ -11,6 +14,17 @@ import (
 const (
 	TypeRequest    = "request"
 	TypeCollection = "collection"
+	TypeRecorder   = "recorder"
+	TypeLoadTest   = "loadtest"
+	TypeRunner     = "runner"
+
+	// RecorderID is the id of the tab of the traffic recorder, there is a single one.
+	RecorderID = "traffic-recorder"
+
+	// LoadTestPrefix prefixes the id of the request or collection to get the id of its load test tab.
+	LoadTestPrefix = "loadtest-"
+	// RunnerPrefix prefixes the id of the request or collection to get the id of its runner tab.
+	RunnerPrefix = "run-"
 
 	TypeMeta = "Type"
 )
@@ -25,6 +39,50 @@ type Container interface {
 	HidePrompt()
 }
 
+type CollectionContainer interface {
+	Container
+	SetOnMockStart(f func(id, addr string, grpc bool, opts mock.Options))
+	SetOnMockStop(f func(id string))
+	SetMockRunning(running bool, addr string)
+	AddMockLogLine(line string)
+}
+
+type RecorderContainer interface {
+	Container
+	SetOnStart(f func(addr string))
+	SetOnStop(f func())
+	SetOnSaveCA(f func())
+	SetOnClear(f func())
+	SetOnSaveExchanges(f func(collectionID string, exchangeIDs []string))
+	SetRunning(running bool, addr string)
+	SetCollections(collections []*domain.Collection)
+	AddExchange(id, text string)
+}
+
+type LoadTestContainer interface {
+	Container
+	SetOnStart(f func(id string, opts loadtest.Options))
+	SetOnStop(f func(id string))
+	SetOnSaveReport(f func(id, format string))
+	SetRunning(running bool)
+	SetReport(report *loadtest.Report)
+}
+
+type RunnerContainer interface {
+	Container
+	SetOnRun(f func(id string, opts egress.RunOptions))
+	SetOnStop(f func(id string))
+	SetOnSelectData(f func(id string))
+	SetOnClearData(f func(id string))
+	SetOnExport(f func(id, reporter string))
+	SetRunning(running bool)
+	SetData(name string, rows int)
+	SetRequests(requests []*domain.Request)
+	SetProgress(iteration, iterations int, requestID string)
+	SetRequestResult(result *egress.RequestResult)
+	AddResult(line string)
+}
+
 type GrpcContainer interface {
 	Container
 	SetOnReload(func(id string))
@@ -36,27 +94,42 @@ type GrpcContainer interface {
 	GetResponse() *domain.GRPCResponseDetail
 	SetOnLoadRequestExample(f func(id string))
 	SetRequestBody(body string)
+	SetOnBodyCompletion(f func(id, body string, caret int) (string, []widgets.Completion))
+	SetBodyDiagnostics(diagnostics []widgets.Diagnostic)
+	SetOnDiagnose(f func(id string))
+	SetOnWatchHealth(f func(id string, watch bool))
+	SetDiagnosticsLoading(loading bool)
+	SetDiagnosticsResult(result string)
+	AppendDiagnosticsResult(line string)
+	SetHealthWatching(watching bool)
 	ShowRequestPrompt(title, content, modalType string, onSubmit func(selectedOption string, remember bool), options ...widgets.Option)
 	HideRequestPrompt()
 	SetPostRequestSetValues(set domain.PostRequestSet)
 	SetOnPostRequestSetChanged(f func(id string, statusCode int, item, from, fromKey string))
 	SetPreRequestCollections(collections []*domain.Collection, selectedID string)
 	SetPreRequestRequests(requests []*domain.Request, selectedID string)
+	SetNextRequests(requests []*domain.Request, selectedID string)
+	SetPrerequisites(requests []*domain.Request, prerequisites []domain.TriggerRequest)
 	SetOnSetOnTriggerRequestChanged(f func(id, collectionID, requestID string))
+	SetOnClearPrerequisiteCache(f func())
 	SetPostRequestSetPreview(preview string)
 	SetOnRequestTabChange(f func(id, tab string))
+	SetOnDescribeVariable(f func(id, name string) string)
+	SetOnListVariables(f func(id string) []widgets.Completion)
 }
 
 type RestContainer interface {
 	Container
 	SetHTTPResponse(response domain.HTTPResponseDetail)
 	GetHTTPResponse() *domain.HTTPResponseDetail
+	SetOnSaveResponseBody(f func(id string, body []byte, contentType string))
 	SetPostRequestSetPreview(preview string)
 	ShowSendingRequestLoading()
 	HideSendingRequestLoading()
 	SetQueryParams(params []domain.KeyValue)
 	SetPathParams(params []domain.KeyValue)
 	SetURL(url string)
+	SetOnCurlPasted(f func(id, command string))
 	SetPostRequestSetValues(set domain.PostRequestSet)
 	SetOnPostRequestSetChanged(f func(id string, statusCode int, item, from, fromKey string))
 	SetOnBinaryFileSelect(f func(id string))
@@ -65,6 +138,11 @@ type RestContainer interface {
 	AddFileToFormData(fieldId, filePath string)
 	SetPreRequestCollections(collections []*domain.Collection, selectedID string)
 	SetPreRequestRequests(requests []*domain.Request, selectedID string)
+	SetNextRequests(requests []*domain.Request, selectedID string)
+	SetPrerequisites(requests []*domain.Request, prerequisites []domain.TriggerRequest)
 	SetOnSetOnTriggerRequestChanged(f func(id, collectionID, requestID string))
+	SetOnClearPrerequisiteCache(f func())
 	SetOnRequestTabChange(f func(id, tab string))
+	SetOnDescribeVariable(f func(id, name string) string)
+	SetOnListVariables(f func(id string) []widgets.Completion)
 }
@@ -1,25 +1,43 @@
 package requests
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"gioui.org/io/clipboard"
 	"gioui.org/layout"
+	"google.golang.org/protobuf/reflect/protoregistry"
 
+	"github.com/chapar-rest/chapar/internal/codegen"
+	"github.com/chapar-rest/chapar/internal/convert"
+	"github.com/chapar-rest/chapar/internal/curl"
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/exporter"
 	"github.com/chapar-rest/chapar/internal/grpc"
 	"github.com/chapar-rest/chapar/internal/importer"
 	"github.com/chapar-rest/chapar/internal/jsonpath"
+	"github.com/chapar-rest/chapar/internal/loadtest"
+	"github.com/chapar-rest/chapar/internal/mock"
+	"github.com/chapar-rest/chapar/internal/proxy"
+	"github.com/chapar-rest/chapar/internal/reporter"
 	"github.com/chapar-rest/chapar/internal/repository"
 	"github.com/chapar-rest/chapar/internal/rest"
+	"github.com/chapar-rest/chapar/internal/safemap"
 	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/variables"
 	"github.com/chapar-rest/chapar/ui/explorer"
+	"github.com/chapar-rest/chapar/ui/pages/requests/loadrunner"
 	"github.com/chapar-rest/chapar/ui/widgets"
 )
 
@@ -35,6 +53,31 @@ type Controller struct {
 
 	grpcService   *grpc.Service
 	egressService *egress.Service
+
+	// healthWatchers holds the cancel functions of the running health watches by request id
+	healthWatchers *safemap.Map[context.CancelFunc]
+
+	// mockServers holds the running mock servers by collection id
+	mockServers *safemap.Map[mock.Runner]
+
+	// recorder is the traffic recording proxy, it is created when it is first started
+	recorder   *proxy.Proxy
+	recorderCA *proxy.CA
+	recorderMu sync.Mutex
+
+	// loadTests holds the cancel functions of the running load tests, loadTestRunners the last run of each
+	// load test to save its report, both by request or collection id
+	loadTests       *safemap.Map[context.CancelFunc]
+	loadTestRunners *safemap.Map[*loadtest.Runner]
+
+	// runs holds the cancel functions of the running runs, runData the iteration data of the runners and
+	// runResults the results of their last runs, all by request or collection id
+	runs       *safemap.Map[context.CancelFunc]
+	runData    *safemap.Map[[][]domain.KeyValue]
+	runResults *safemap.Map[*egress.RunResult]
+
+	// codeLanguage is the id of the last language code was generated in
+	codeLanguage string
 }
 
 func NewController(view *View, repo repository.Repository, model *state.Requests, envState *state.Environments, explorer *explorer.Explorer, egressService *egress.Service, grpcService *grpc.Service) *Controller {
@@ -48,6 +91,16 @@ func NewController(view *View, repo repository.Repository, model *state.Requests
 
 		egressService: egressService,
 		grpcService:   grpcService,
+
+		healthWatchers: safemap.New[context.CancelFunc](),
+		mockServers:    safemap.New[mock.Runner](),
+
+		loadTests:       safemap.New[context.CancelFunc](),
+		loadTestRunners: safemap.New[*loadtest.Runner](),
+
+		runs:       safemap.New[context.CancelFunc](),
+		runData:    safemap.New[[][]domain.KeyValue](),
+		runResults: safemap.New[*egress.RunResult](),
 	}
 
 	view.SetOnNewRequest(c.onNewRequest)
@@ -56,6 +109,7 @@ func NewController(view *View, repo repository.Repository, model *state.Requests
 	view.SetOnTitleChanged(c.onTitleChanged)
 	view.SetOnTreeViewNodeClicked(c.onTreeViewNodeClicked)
 	view.SetOnTreeViewMenuClicked(c.onTreeViewMenuClicked)
+	view.SetOnTreeViewNodeMoved(c.onTreeViewNodeMoved)
 	view.SetOnTabClose(c.onTabClose)
 	view.SetOnDataChanged(c.onDataChanged)
 	view.SetOnSave(c.onSave)
@@ -67,8 +121,32 @@ func NewController(view *View, repo repository.Repository, model *state.Requests
 	view.SetOnServerInfoReload(c.onServerInfoReload)
 	view.SetOnGrpcInvoke(c.onGrpcInvoke)
 	view.SetOnGrpcLoadRequestExample(c.onLoadRequestExample)
+	view.SetOnGrpcBodyCompletion(c.onGrpcBodyCompletion)
+	view.SetOnGrpcDiagnose(c.onGrpcDiagnose)
+	view.SetOnGrpcWatchHealth(c.onGrpcWatchHealth)
 	view.SetOnSetOnTriggerRequestChanged(c.onSetOnTriggerRequestChanged)
 	view.SetOnRequestTabChange(c.onRequestTabChange)
+	view.SetOnDescribeVariable(c.onDescribeVariable)
+	view.SetOnListVariables(c.onListVariables)
+	view.SetOnCurlPasted(c.onCurlPasted)
+	view.SetOnSaveResponseBody(c.onSaveResponseBody)
+	view.SetOnMockStart(c.onMockStart)
+	view.SetOnMockStop(c.stopMock)
+	view.SetOnOpenRecorder(c.onOpenRecorder)
+	view.SetOnRecorderStart(c.onRecorderStart)
+	view.SetOnRecorderStop(c.stopRecorder)
+	view.SetOnRecorderSaveCA(c.onRecorderSaveCA)
+	view.SetOnRecorderClear(c.onRecorderClear)
+	view.SetOnRecorderSave(c.onRecorderSave)
+	view.SetOnClearPrerequisiteCache(c.onClearPrerequisiteCache)
+	view.SetOnLoadTestStart(c.onLoadTestStart)
+	view.SetOnLoadTestStop(c.stopLoadTest)
+	view.SetOnLoadTestSaveReport(c.onLoadTestSaveReport)
+	view.SetOnRun(c.onRun)
+	view.SetOnRunStop(c.stopRun)
+	view.SetOnRunSelectData(c.onRunSelectData)
+	view.SetOnRunClearData(c.onRunClearData)
+	view.SetOnRunExport(c.onRunExport)
 	return c
 }
 
@@ -138,17 +216,35 @@ func (c *Controller) onServerInfoReload(id string) {
 
 	c.view.HideGRPCRequestError(id)
 	c.view.SetGRPCServices(id, res)
+
+	if req := c.model.GetRequest(id); req != nil {
+		c.validateGrpcBody(id, req)
+	}
 }
 
 func (c *Controller) onGrpcInvoke(id string) {
 	c.view.SetSendingRequestLoading(id)
 	defer c.view.SetSendingRequestLoaded(id)
 
+	c.warnUnresolvedVariables(id)
+
 	res, err := c.egressService.Send(id, c.getActiveEnvID())
 	if err != nil {
-		c.view.SetGRPCResponse(id, domain.GRPCResponseDetail{
+		detail := domain.GRPCResponseDetail{
 			Error: err,
-		})
+		}
+
+		// keep the status details, metadata and trailers the server sent along with the error
+		if resp, ok := res.(*grpc.Response); ok && resp != nil {
+			detail.ErrorDetails = resp.ErrorDetails
+			detail.Metadata = resp.Metadata
+			detail.Trailers = resp.Trailers
+			detail.StatusCode = resp.StatueCode
+			detail.Status = resp.Status
+			detail.Duration = resp.TimePassed
+		}
+
+		c.view.SetGRPCResponse(id, detail)
 		return
 	}
 
@@ -184,6 +280,607 @@ func (c *Controller) onLoadRequestExample(id string) {
 	c.view.SetSetGrpcRequestBody(id, example)
 }
 
+func (c *Controller) onGrpcBodyCompletion(id, body string, caret int) (string, []widgets.Completion) {
+	req := c.model.GetRequest(id)
+	if req == nil || req.Spec.GRPC == nil {
+		return "", nil
+	}
+
+	prefix, items := c.grpcService.CompleteRequestBody(id, req.Spec.GRPC.LasSelectedMethod, body, caret)
+	out := make([]widgets.Completion, 0, len(items))
+	for _, item := range items {
+		out = append(out, widgets.Completion{Text: item.Text, Detail: item.Detail})
+	}
+
+	return prefix, out
+}
+
+func (c *Controller) validateGrpcBody(id string, req *domain.Request) {
+	if req.MetaData.Type != domain.RequestTypeGRPC || req.Spec.GRPC == nil {
+		return
+	}
+
+	issues := c.grpcService.ValidateRequestBody(id, req.Spec.GRPC.LasSelectedMethod, req.Spec.GRPC.Body)
+	diagnostics := make([]widgets.Diagnostic, 0, len(issues))
+	for _, issue := range issues {
+		diagnostics = append(diagnostics, widgets.Diagnostic{Start: issue.Start, End: issue.End, Message: issue.String()})
+	}
+
+	c.view.SetGRPCBodyDiagnostics(id, diagnostics)
+}
+
+func (c *Controller) onGrpcDiagnose(id string) {
+	c.view.SetGRPCDiagnosticsLoading(id, true)
+	defer c.view.SetGRPCDiagnosticsLoading(id, false)
+
+	res, err := c.grpcService.Diagnose(id, c.getActiveEnvID())
+	if err != nil {
+		c.view.SetGRPCDiagnosticsResult(id, fmt.Sprintf("error: %s\n", err))
+		return
+	}
+
+	c.view.SetGRPCDiagnosticsResult(id, formatDiagnostics(res))
+}
+
+func (c *Controller) onGrpcWatchHealth(id string, watch bool) {
+	if !watch {
+		c.stopHealthWatch(id)
+		return
+	}
+
+	req := c.model.GetRequest(id)
+	if req == nil || req.Spec.GRPC == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.healthWatchers.Set(id, cancel)
+	defer c.stopHealthWatch(id)
+
+	// watch the server overall health and each of the services
+	services := []string{""}
+	for _, svc := range req.Spec.GRPC.Services {
+		services = append(services, svc.FullName())
+	}
+
+	c.view.AppendGRPCDiagnosticsResult(id, "# watching health")
+
+	var wg sync.WaitGroup
+	for _, service := range services {
+		wg.Add(1)
+		go func(service string) {
+			defer wg.Done()
+
+			err := c.grpcService.WatchHealth(ctx, id, c.getActiveEnvID(), service, func(st grpc.HealthStatus) {
+				c.view.AppendGRPCDiagnosticsResult(id, fmt.Sprintf("%s %s: %s", time.Now().Format(time.TimeOnly), healthServiceName(st.Service), st.Status))
+			})
+			if err != nil {
+				c.view.AppendGRPCDiagnosticsResult(id, fmt.Sprintf("%s %s: %s", time.Now().Format(time.TimeOnly), healthServiceName(service), err))
+			}
+		}(service)
+	}
+	wg.Wait()
+}
+
+func (c *Controller) stopHealthWatch(id string) {
+	if cancel, ok := c.healthWatchers.Get(id); ok {
+		cancel()
+		c.healthWatchers.Delete(id)
+		c.view.SetGRPCHealthWatching(id, false)
+	}
+}
+
+// onMockStart serves the http requests of the collection with their saved examples, or the methods of its
+// grpc requests with example messages.
+func (c *Controller) onMockStart(id, addr string, isGRPC bool, opts mock.Options) {
+	col := c.model.GetCollection(id)
+	if col == nil {
+		return
+	}
+
+	var srv mock.Runner
+	if isGRPC {
+		var registries []*protoregistry.Files
+		for _, req := range col.Spec.Requests {
+			if req.MetaData.Type != domain.RequestTypeGRPC {
+				continue
+			}
+
+			files, err := c.grpcService.Registry(req.MetaData.ID, c.getActiveEnvID())
+			if err != nil {
+				c.view.AddCollectionMockLogLine(id, fmt.Sprintf("skipping %s: %s", req.MetaData.Name, err))
+				continue
+			}
+			registries = append(registries, files)
+		}
+
+		if len(registries) == 0 {
+			c.view.showError(errors.New("no grpc services to mock, the grpc requests of the collection need proto files or server reflection"))
+			return
+		}
+		srv = mock.NewGRPCServer(mock.MergeFiles(registries...), nil, opts)
+	} else {
+		srv = mock.NewServer(col, opts)
+	}
+
+	srv.Log().SetOnEntry(func(entry mock.LogEntry) {
+		c.view.AddCollectionMockLogLine(id, entry.String())
+	})
+
+	if err := srv.Start(addr); err != nil {
+		c.view.showError(fmt.Errorf("failed to start mock server, %w", err))
+		return
+	}
+
+	c.mockServers.Set(id, srv)
+	c.view.SetCollectionMockRunning(id, true, srv.Addr())
+	c.view.AddCollectionMockLogLine(id, "# listening on "+srv.Addr())
+}
+
+func (c *Controller) stopMock(id string) {
+	srv, ok := c.mockServers.Get(id)
+	if !ok {
+		return
+	}
+
+	c.mockServers.Delete(id)
+	if err := srv.Stop(); err != nil {
+		c.view.showError(fmt.Errorf("failed to stop mock server, %w", err))
+	}
+	c.view.SetCollectionMockRunning(id, false, "")
+	c.view.AddCollectionMockLogLine(id, "# stopped")
+}
+
+func (c *Controller) onOpenRecorder() {
+	if !c.view.IsTabOpen(RecorderID) {
+		c.view.OpenTab(RecorderID, "Traffic Recorder", TypeRecorder)
+	}
+	c.view.OpenRecorderContainer(c.model.GetCollections())
+	c.view.SwitchToTab(RecorderID)
+}
+
+// getRecorder returns the recording proxy, the certificate authority intercepting the https requests is
+// loaded from the config directory or created on the first use.
+func (c *Controller) getRecorder() (*proxy.Proxy, *proxy.CA, error) {
+	c.recorderMu.Lock()
+	defer c.recorderMu.Unlock()
+
+	if c.recorder != nil {
+		return c.recorder, c.recorderCA, nil
+	}
+
+	dir, err := repository.GetConfigDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ca, err := proxy.LoadOrCreateCA(filepath.Join(dir, "ca"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load certificate authority, %w", err)
+	}
+
+	c.recorder, c.recorderCA = proxy.New(ca), ca
+	c.recorder.SetOnExchange(func(ex *proxy.Exchange) {
+		c.view.AddRecorderExchange(ex.ID, ex.String())
+	})
+	return c.recorder, c.recorderCA, nil
+}
+
+func (c *Controller) onRecorderStart(addr string) {
+	recorder, _, err := c.getRecorder()
+	if err != nil {
+		c.view.showError(err)
+		return
+	}
+
+	if err := recorder.Start(addr); err != nil {
+		c.view.showError(fmt.Errorf("failed to start traffic recorder, %w", err))
+		return
+	}
+	c.view.SetRecorderRunning(true, recorder.Addr())
+}
+
+func (c *Controller) stopRecorder() {
+	c.recorderMu.Lock()
+	recorder := c.recorder
+	c.recorderMu.Unlock()
+
+	if recorder == nil {
+		return
+	}
+
+	if err := recorder.Stop(); err != nil {
+		c.view.showError(fmt.Errorf("failed to stop traffic recorder, %w", err))
+	}
+	c.view.SetRecorderRunning(false, "")
+}
+
+func (c *Controller) onRecorderClear() {
+	c.recorderMu.Lock()
+	defer c.recorderMu.Unlock()
+
+	if c.recorder != nil {
+		c.recorder.Clear()
+	}
+}
+
+func (c *Controller) onRecorderSaveCA() {
+	_, ca, err := c.getRecorder()
+	if err != nil {
+		c.view.showError(err)
+		return
+	}
+
+	c.explorer.SaveFile("chapar-ca.pem", ca.CertPEM(), func(r explorer.Result) {
+		if r.Error != nil {
+			if !errors.Is(r.Error, explorer.ErrUserDecline) {
+				c.view.showError(r.Error)
+			}
+			return
+		}
+		c.view.showNotification("Certificate saved, add it to the trusted certificates of the clients", 3*time.Second)
+	})
+}
+
+// onRecorderSave saves the recorded exchanges as requests of the collection, their responses are saved as examples.
+func (c *Controller) onRecorderSave(collectionID string, exchangeIDs []string) {
+	col := c.model.GetCollection(collectionID)
+	if col == nil {
+		c.view.showError(errors.New("select the collection to save the requests to"))
+		return
+	}
+
+	recorder, _, err := c.getRecorder()
+	if err != nil {
+		c.view.showError(err)
+		return
+	}
+
+	saved := 0
+	for _, id := range exchangeIDs {
+		ex := recorder.Exchange(id)
+		if ex == nil {
+			continue
+		}
+
+		req := proxy.ToRequest(ex)
+		name := strings.NewReplacer("/", "-", "\\", "-").Replace(req.MetaData.Name)
+		newFilePath, err := c.repo.GetCollectionRequestNewFilePath(col, name)
+		if err != nil {
+			c.view.showError(fmt.Errorf("failed to get new file path, err %w", err))
+			return
+		}
+
+		req.FilePath = newFilePath.Path
+		if newFilePath.NewName != name {
+			req.MetaData.Name = newFilePath.NewName
+		}
+		req.CollectionID = col.MetaData.ID
+		req.CollectionName = col.MetaData.Name
+
+		c.model.AddRequest(req)
+		c.view.AddChildTreeViewNode(col.MetaData.ID, req)
+		c.saveRequestToDisc(req.MetaData.ID)
+		c.model.AddRequestToCollection(col, req)
+		saved++
+	}
+
+	c.view.ExpandTreeViewNode(col.MetaData.ID)
+	c.view.showNotification(fmt.Sprintf("%d requests saved to %s", saved, col.MetaData.Name), 2*time.Second)
+}
+
+// openRunner opens the runner tab of the request or collection.
+func (c *Controller) openRunner(id, nodeType string) {
+	var (
+		name     string
+		requests []*domain.Request
+	)
+	if nodeType == TypeCollection {
+		if col := c.model.GetCollection(id); col != nil {
+			name, requests = col.MetaData.Name, col.Spec.Requests
+		}
+	} else if req := c.model.GetRequest(id); req != nil {
+		name, requests = req.MetaData.Name, []*domain.Request{req}
+	}
+	if name == "" {
+		return
+	}
+
+	tabID := RunnerPrefix + id
+	if !c.view.IsTabOpen(tabID) {
+		c.view.OpenTab(tabID, "Run: "+name, TypeRunner)
+	}
+	c.view.OpenRunnerContainer(tabID, id, name)
+	// the requests may have been reordered since the runner was opened
+	c.view.SetRunnerRequests(tabID, requests)
+	c.view.SwitchToTab(tabID)
+}
+
+// onRun runs the request or collection with the active environment, once per row of its iteration data.
+func (c *Controller) onRun(id string, opts egress.RunOptions) {
+	if _, ok := c.runs.Get(id); ok {
+		return
+	}
+
+	tabID := RunnerPrefix + id
+	opts.EnvironmentID = c.getActiveEnvID()
+	opts.Data, _ = c.runData.Get(id)
+	iterations := max(len(opts.Data), 1)
+	opts.OnRequest = func(iteration int, requestID string) {
+		c.view.SetRunnerProgress(tabID, iteration, iterations, requestID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.runs.Set(id, cancel)
+	c.view.SetRunnerRunning(tabID, true)
+	defer func() {
+		cancel()
+		c.runs.Delete(id)
+		c.view.SetRunnerRunning(tabID, false)
+	}()
+
+	result, err := c.egressService.Run(ctx, id, opts, func(r *egress.RequestResult) {
+		c.view.SetRunnerRequestResult(tabID, r)
+		c.view.AddRunnerResult(tabID, r.String())
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		c.view.showError(fmt.Errorf("failed to run, %w", err))
+	}
+	if result == nil {
+		return
+	}
+	c.runResults.Set(id, result)
+
+	if result.Stopped != "" {
+		c.view.AddRunnerResult(tabID, "\nRun "+result.Stopped)
+	}
+	c.view.AddRunnerResult(tabID, fmt.Sprintf("\n%d iterations in %s, %d failed requests", len(result.Iterations), result.Duration.Round(time.Millisecond), result.Failures()))
+	if len(opts.Data) == 0 {
+		return
+	}
+	for _, it := range result.Iterations {
+		status := "passed"
+		if failed := it.Failures(); failed > 0 {
+			status = fmt.Sprintf("%d failed", failed)
+		}
+
+		values := make([]string, 0, len(it.Data))
+		for _, kv := range it.Data {
+			values = append(values, kv.Key+"="+kv.Value)
+		}
+		c.view.AddRunnerResult(tabID, fmt.Sprintf("#%d (%s) %s", it.Index+1, strings.Join(values, ", "), status))
+	}
+}
+
+func (c *Controller) stopRun(id string) {
+	if cancel, ok := c.runs.Get(id); ok {
+		cancel()
+	}
+}
+
+// onRunSelectData loads the iteration data of the runner from a CSV or JSON file.
+func (c *Controller) onRunSelectData(id string) {
+	c.explorer.ChoseFile(func(result explorer.Result) {
+		if result.Error != nil {
+			if !errors.Is(result.Error, explorer.ErrUserDecline) {
+				c.view.showError(fmt.Errorf("failed to get file, %w", result.Error))
+			}
+			return
+		}
+
+		rows, err := egress.ParseIterationData(result.Data)
+		if err != nil {
+			c.view.showError(fmt.Errorf("failed to load iteration data, %w", err))
+			return
+		}
+
+		name := "iteration data"
+		if result.FilePath != "" {
+			name = filepath.Base(result.FilePath)
+		}
+		c.runData.Set(id, rows)
+		c.view.SetRunnerData(RunnerPrefix+id, name, len(rows))
+	}, ".csv", ".json")
+}
+
+func (c *Controller) onRunClearData(id string) {
+	c.runData.Delete(id)
+}
+
+// onRunExport saves the report of the last run of the runner in the format of the reporter.
+func (c *Controller) onRunExport(id, name string) {
+	result, ok := c.runResults.Get(id)
+	if !ok {
+		c.view.showError(errors.New("run the requests to export their report"))
+		return
+	}
+
+	r, err := reporter.Get(name)
+	if err != nil {
+		c.view.showError(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := r.Report(&buf, result); err != nil {
+		c.view.showError(fmt.Errorf("failed to create report, %w", err))
+		return
+	}
+
+	fileName := strings.NewReplacer("/", "-", "\\", "-").Replace(result.Name) + "-report" + r.Extension()
+	c.explorer.SaveFile(fileName, buf.Bytes(), func(r explorer.Result) {
+		if r.Error != nil {
+			if !errors.Is(r.Error, explorer.ErrUserDecline) {
+				c.view.showError(r.Error)
+			}
+			return
+		}
+		c.view.showNotification("Report saved", 2*time.Second)
+	})
+}
+
+// openLoadTest opens the load test tab of the request or collection.
+func (c *Controller) openLoadTest(id, nodeType string) {
+	name := ""
+	if nodeType == TypeCollection {
+		if col := c.model.GetCollection(id); col != nil {
+			name = col.MetaData.Name
+		}
+	} else if req := c.model.GetRequest(id); req != nil {
+		name = req.MetaData.Name
+	}
+	if name == "" {
+		return
+	}
+
+	tabID := LoadTestPrefix + id
+	if !c.view.IsTabOpen(tabID) {
+		c.view.OpenTab(tabID, "Load test: "+name, TypeLoadTest)
+	}
+	c.view.OpenLoadTestContainer(tabID, id, name)
+	c.view.SwitchToTab(tabID)
+}
+
+// loadTestTargets returns the request with the id, or the requests of the collection with the id in order.
+func (c *Controller) loadTestTargets(id string) []loadtest.Target {
+	if req := c.model.GetRequest(id); req != nil {
+		return []loadtest.Target{{ID: req.MetaData.ID, Name: req.MetaData.Name}}
+	}
+
+	col := c.model.GetCollection(id)
+	if col == nil {
+		return nil
+	}
+
+	targets := make([]loadtest.Target, 0, len(col.Spec.Requests))
+	for _, req := range col.Spec.Requests {
+		targets = append(targets, loadtest.Target{ID: req.MetaData.ID, Name: req.MetaData.Name})
+	}
+	return targets
+}
+
+// onLoadTestStart runs the load test through the egress service with a copy of the active environment, the
+// report is updated while it runs.
+func (c *Controller) onLoadTestStart(id string, opts loadtest.Options) {
+	if _, ok := c.loadTests.Get(id); ok {
+		return
+	}
+
+	env, err := c.egressService.NewRunEnvironment(c.getActiveEnvID())
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to run load test, %w", err))
+		return
+	}
+
+	tabID := LoadTestPrefix + id
+	runner := loadtest.New(func(requestID string) (any, error) {
+		return c.egressService.SendWithEnvironment(requestID, env)
+	}, c.loadTestTargets(id), opts)
+	runner.SetOnProgress(func(r *loadtest.Report) {
+		c.view.SetLoadTestReport(tabID, r)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.loadTests.Set(id, cancel)
+	c.loadTestRunners.Set(id, runner)
+	c.view.SetLoadTestRunning(tabID, true)
+	defer func() {
+		cancel()
+		c.loadTests.Delete(id)
+		c.view.SetLoadTestRunning(tabID, false)
+	}()
+
+	report, err := runner.Run(ctx)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to run load test, %w", err))
+		return
+	}
+	c.view.SetLoadTestReport(tabID, report)
+}
+
+func (c *Controller) onClearPrerequisiteCache() {
+	c.egressService.ClearPrerequisiteCache()
+	c.view.showNotification("Cached prerequisite responses cleared", 2*time.Second)
+}
+
+func (c *Controller) stopLoadTest(id string) {
+	if cancel, ok := c.loadTests.Get(id); ok {
+		cancel()
+	}
+}
+
+func (c *Controller) onLoadTestSaveReport(id, format string) {
+	runner, ok := c.loadTestRunners.Get(id)
+	if !ok {
+		c.view.showError(errors.New("run the load test to save its report"))
+		return
+	}
+
+	report := runner.Snapshot()
+	data, err := report.JSON()
+	if format == loadrunner.FormatHTML {
+		data, err = report.HTML()
+	}
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to create report, %w", err))
+		return
+	}
+
+	c.explorer.SaveFile("load-test-report."+format, data, func(r explorer.Result) {
+		if r.Error != nil {
+			if !errors.Is(r.Error, explorer.ErrUserDecline) {
+				c.view.showError(r.Error)
+			}
+			return
+		}
+		c.view.showNotification("Report saved", 2*time.Second)
+	})
+}
+
+func healthServiceName(service string) string {
+	if service == "" {
+		return "server"
+	}
+	return service
+}
+
+func formatDiagnostics(d *grpc.Diagnostics) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "address: %s\n", d.Address)
+	fmt.Fprintf(&sb, "state: %s\n", d.State)
+
+	if d.TLS == nil {
+		sb.WriteString("tls: none\n")
+	} else {
+		sb.WriteString("tls:\n")
+		fmt.Fprintf(&sb, "  version: %s\n", d.TLS.Version)
+		fmt.Fprintf(&sb, "  cipherSuite: %s\n", d.TLS.CipherSuite)
+		fmt.Fprintf(&sb, "  alpn: %s\n", d.TLS.NegotiatedProtocol)
+		fmt.Fprintf(&sb, "  serverName: %s\n", d.TLS.ServerName)
+		sb.WriteString("  certificates:\n")
+		for _, cert := range d.TLS.Certificates {
+			fmt.Fprintf(&sb, "    - subject: %s\n", cert.Subject)
+			fmt.Fprintf(&sb, "      issuer: %s\n", cert.Issuer)
+			if len(cert.DNSNames) > 0 {
+				fmt.Fprintf(&sb, "      dnsNames: %s\n", strings.Join(cert.DNSNames, ", "))
+			}
+			fmt.Fprintf(&sb, "      notBefore: %s\n", cert.NotBefore.Format(time.RFC3339))
+			fmt.Fprintf(&sb, "      notAfter: %s\n", cert.NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	sb.WriteString("health:\n")
+	for _, h := range d.Health {
+		if h.Error != nil {
+			fmt.Fprintf(&sb, "  %s: %s (%s)\n", healthServiceName(h.Service), h.Status, h.Error)
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s: %s\n", healthServiceName(h.Service), h.Status)
+	}
+
+	return sb.String()
+}
+
 func (c *Controller) onPostRequestSetChanged(id string, statusCode int, item, from, fromKey string) {
 	req := c.model.GetRequest(id)
 	if req == nil {
@@ -218,6 +915,7 @@ func (c *Controller) onPostRequestSetChanged(id string, statusCode int, item, fr
 	var (
 		responseFrom string
 		response     string
+		errorDetails string
 		headers      []domain.KeyValue
 		cookies      []domain.KeyValue
 		metaData     []domain.KeyValue
@@ -236,10 +934,11 @@ func (c *Controller) onPostRequestSetChanged(id string, statusCode int, item, fr
 		responseFrom = clone.Spec.HTTP.Request.PostRequest.PostRequestSet.From
 	case domain.RequestTypeGRPC:
 		responseData := c.view.GetGRPCResponse(id)
-		if responseData == nil || responseData.Response == "" {
+		if responseData == nil || (responseData.Response == "" && responseData.ErrorDetails == "") {
 			return
 		}
 		response = responseData.Response
+		errorDetails = responseData.ErrorDetails
 		headers = responseData.Metadata
 		metaData = responseData.Metadata
 		trailers = responseData.Trailers
@@ -257,6 +956,8 @@ func (c *Controller) onPostRequestSetChanged(id string, statusCode int, item, fr
 		c.setPreviewFromKeyValue(id, metaData, fromKey)
 	case domain.PostRequestSetFromResponseTrailers:
 		c.setPreviewFromKeyValue(id, trailers, fromKey)
+	case domain.PostRequestSetFromResponseErrorDetails:
+		c.setPreviewFromResponse(id, errorDetails, fromKey)
 	}
 }
 
@@ -274,6 +975,10 @@ func (c *Controller) onSetOnTriggerRequestChanged(id, collectionID, requestID st
 		CollectionID: collectionID,
 		RequestID:    requestID,
 	}
+	// keep the alias and the cache duration of the triggered request
+	if prev := req.Spec.GetPreRequest().TriggerRequest; prev != nil {
+		triggerRequest.Alias, triggerRequest.CacheFor = prev.Alias, prev.CacheFor
+	}
 
 	// Assign the PostRequestSet based on request type
 	switch req.MetaData.Type {
@@ -329,8 +1034,11 @@ func (c *Controller) onTitleChanged(id string, title, containerType string) {
 
 func (c *Controller) onSave(id string) {
 	tabType := c.view.GetTabType(id)
-	if tabType == TypeRequest {
+	switch tabType {
+	case TypeRequest:
 		c.saveRequestToDisc(id)
+	case TypeCollection:
+		c.saveCollectionToDisc(id)
 	}
 }
 
@@ -352,6 +1060,8 @@ func (c *Controller) onSubmitRequest(id string) {
 	c.view.SetSendingRequestLoading(id)
 	defer c.view.SetSendingRequestLoaded(id)
 
+	c.warnUnresolvedVariables(id)
+
 	egRes, err := c.egressService.Send(id, c.getActiveEnvID())
 	if err != nil {
 		c.view.SetHTTPResponse(id, domain.HTTPResponseDetail{
@@ -371,15 +1081,94 @@ func (c *Controller) onSubmitRequest(id string) {
 	}
 
 	c.view.SetHTTPResponse(id, domain.HTTPResponseDetail{
-		Response:   resp,
-		Headers:    mapToKeyValue(res.Headers),
-		Cookies:    cookieToKeyValue(res.Cookies),
-		StatusCode: res.StatusCode,
-		Duration:   res.TimePassed,
-		Size:       len(res.Body),
+		Response:    resp,
+		Headers:     mapToKeyValue(res.Headers),
+		Cookies:     cookieToKeyValue(res.Cookies),
+		StatusCode:  res.StatusCode,
+		Duration:    res.TimePassed,
+		Size:        len(res.Body),
+		Body:        res.Body,
+		ContentType: res.Headers["Content-Type"],
 	})
 }
 
+// onSaveResponseBody saves the body of the response as received, named after the request.
+func (c *Controller) onSaveResponseBody(id string, body []byte, contentType string) {
+	name := "response"
+	if req := c.model.GetRequest(id); req != nil {
+		name = strings.NewReplacer("/", "-", "\\", "-").Replace(req.MetaData.Name)
+	}
+
+	c.explorer.SaveFile(name+rest.FileExtension(contentType, body), body, func(r explorer.Result) {
+		if r.Error != nil {
+			if !errors.Is(r.Error, explorer.ErrUserDecline) {
+				c.view.showError(r.Error)
+			}
+			return
+		}
+		c.view.showNotification("Response saved", 2*time.Second)
+	})
+}
+
+// onDescribeVariable returns the text of the tooltip of a variable used in the request.
+func (c *Controller) onDescribeVariable(id, name string) string {
+	if fn, ok := strings.CutPrefix(name, "$"); ok {
+		fn, _, _ = strings.Cut(fn, " ")
+		for _, f := range variables.Functions() {
+			if f.Name == fn {
+				return f.Usage
+			}
+		}
+		return fmt.Sprintf("{{%s}} is not a known function", name)
+	}
+
+	value, scope, ok := c.egressService.DescribeVariable(id, c.getActiveEnvID(), name)
+	if !ok {
+		return fmt.Sprintf("{{%s}} is not defined", name)
+	}
+	return fmt.Sprintf("{{%s}} = %s (%s)", name, value, scope)
+}
+
+// onListVariables returns the variables and functions known to the request, used for completion and highlighting.
+func (c *Controller) onListVariables(id string) []widgets.Completion {
+	scopes := c.egressService.VariableScopes(id, c.getActiveEnvID())
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	functions := variables.Functions()
+	out := make([]widgets.Completion, 0, len(names)+len(functions))
+	for _, name := range names {
+		out = append(out, widgets.Completion{Text: name, Detail: string(scopes[name])})
+	}
+	for _, f := range functions {
+		out = append(out, widgets.Completion{Text: "$" + f.Name, Detail: "function"})
+	}
+	return out
+}
+
+// warnUnresolvedVariables notifies the user about the variables of the request that are sent as they are.
+func (c *Controller) warnUnresolvedVariables(id string) {
+	warnings, err := c.egressService.UnresolvedVariables(id, c.getActiveEnvID())
+	if err != nil || len(warnings) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		if seen[w.Variable] {
+			continue
+		}
+		seen[w.Variable] = true
+		names = append(names, "{{"+w.Variable+"}}")
+	}
+
+	c.view.showNotification(fmt.Sprintf("Unresolved variables: %s", strings.Join(names, ", ")), 4*time.Second)
+}
+
 func cookieToKeyValue(cookies []*http.Cookie) []domain.KeyValue {
 	var kvs = make([]domain.KeyValue, 0, len(cookies))
 	for _, c := range cookies {
@@ -397,6 +1186,9 @@ func mapToKeyValue(m map[string]string) []domain.KeyValue {
 }
 
 func (c *Controller) onTabClose(id string) {
+	c.stopHealthWatch(id)
+	c.stopMock(id)
+
 	// get Tab to check if it's a request or collection
 	tabType := c.view.GetTabType(id)
 	if tabType == TypeRequest {
@@ -406,6 +1198,21 @@ func (c *Controller) onTabClose(id string) {
 	if tabType == TypeCollection {
 		c.onCollectionTabClose(id)
 	}
+
+	if tabType == TypeRecorder {
+		c.stopRecorder()
+		c.view.CloseTab(id)
+	}
+
+	if tabType == TypeLoadTest {
+		c.stopLoadTest(strings.TrimPrefix(id, LoadTestPrefix))
+		c.view.CloseTab(id)
+	}
+
+	if tabType == TypeRunner {
+		c.stopRun(strings.TrimPrefix(id, RunnerPrefix))
+		c.view.CloseTab(id)
+	}
 }
 
 func (c *Controller) onCollectionTabClose(id string) {
@@ -417,7 +1224,7 @@ func (c *Controller) onDataChanged(id string, data any, containerType string) {
 	case TypeRequest:
 		c.onRequestDataChanged(id, data)
 	case TypeCollection:
-		c.onCollectionDataChanged(id)
+		c.onCollectionDataChanged(id, data)
 	}
 }
 
@@ -440,6 +1247,7 @@ func (c *Controller) onRequestDataChanged(id string, data any) {
 
 	c.checkForPreRequestParams(id, req, inComingRequest)
 	c.checkForHTTPRequestParams(req, inComingRequest)
+	prerequisitesChanged := !slices.Equal(req.Spec.GetPreRequest().Prerequisites(), inComingRequest.Spec.GetPreRequest().Prerequisites())
 
 	// break the reference
 	clone := inComingRequest.Clone()
@@ -450,6 +1258,12 @@ func (c *Controller) onRequestDataChanged(id string, data any) {
 		return
 	}
 
+	if prerequisitesChanged {
+		if err := c.egressService.CheckPrerequisites(id); err != nil {
+			c.view.showError(err)
+		}
+	}
+
 	// set tab dirty if the in memory data is different from the file
 	reqFromFile, err := c.model.GetRequestFromDisc(id)
 	if err != nil {
@@ -458,6 +1272,7 @@ func (c *Controller) onRequestDataChanged(id string, data any) {
 	}
 	c.view.SetTabDirty(id, !domain.CompareRequests(req, reqFromFile))
 	c.view.SetTreeViewNodePrefix(id, req)
+	c.validateGrpcBody(id, req)
 }
 
 func (c *Controller) checkForPreRequestParams(id string, req *domain.Request, inComingRequest *domain.Request) {
@@ -568,12 +1383,24 @@ func (c *Controller) getUrlParams(newURL string) []domain.KeyValue {
 	return domain.ParseQueryParams(urlParams[1])
 }
 
-func (c *Controller) onCollectionDataChanged(id string) {
+func (c *Controller) onCollectionDataChanged(id string, data any) {
 	col := c.model.GetCollection(id)
 	if col == nil {
 		c.view.showError(fmt.Errorf("failed to get collection, %s", id))
 		return
 	}
+
+	vars, ok := data.([]domain.KeyValue)
+	if !ok || domain.CompareKeyValues(col.Spec.Variables, vars) {
+		return
+	}
+
+	col.Spec.Variables = vars
+	if err := c.model.UpdateCollection(col, true); err != nil {
+		c.view.showError(fmt.Errorf("failed to update collection, %w", err))
+		return
+	}
+	c.view.SetTabDirty(id, true)
 }
 
 func (c *Controller) onRequestTabClose(id string) {
@@ -661,6 +1488,18 @@ func (c *Controller) onCollectionTitleChange(id, title string) {
 	c.view.UpdateTabTitle(col.MetaData.ID, col.MetaData.Name)
 }
 
+// onTreeViewNodeMoved stores the new order of the requests of the collection, it is the order they are run in.
+func (c *Controller) onTreeViewNodeMoved(collectionID, id string, index int) {
+	col := c.model.GetCollection(collectionID)
+	if col == nil || !col.MoveRequest(id, index) {
+		return
+	}
+
+	if err := c.model.UpdateCollection(col, false); err != nil {
+		c.view.showError(fmt.Errorf("failed to update collection, %w", err))
+	}
+}
+
 func (c *Controller) onNewRequest(requestType string) {
 	var req *domain.Request
 	if requestType == domain.RequestTypeHTTP {
@@ -695,17 +1534,56 @@ func (c *Controller) onImport() {
 			return
 		}
 
-		if err := importer.ImportPostmanCollection(result.Data); err != nil {
-			c.view.showError(fmt.Errorf("failed to import postman collection, %w", err))
+		if importer.IsHAR(result.Data) {
+			har, err := importer.ParseHAR(result.Data)
+			if err != nil {
+				c.view.showError(fmt.Errorf("failed to import har, %w", err))
+				return
+			}
+
+			items := make([]string, 0, len(har.Log.Entries))
+			for _, e := range har.Log.Entries {
+				items = append(items, e.String())
+			}
+			c.view.showSelect("Select the requests to import", items, func(selected []int) {
+				c.importResult(importer.ImportHAR(result.Data, selected))
+			})
 			return
 		}
 
-		if err := c.LoadData(); err != nil {
-			c.view.showError(fmt.Errorf("failed to load collections, %w", err))
-			return
+		switch {
+		case filepath.Base(result.FilePath) == "bruno.json":
+			// bruno collections are directories, the user selects the bruno.json at their root
+			c.importResult(importer.ImportBrunoCollection(filepath.Dir(result.FilePath)))
+		case importer.IsOpenAPI(result.Data):
+			c.importResult(importer.ImportOpenAPI(result.Data))
+		case importer.IsInsomnia(result.Data):
+			c.importResult(importer.ImportInsomnia(result.Data))
+		default:
+			c.importResult(importer.ImportPostmanCollection(result.Data))
 		}
+	}, "json", "yaml", "yml", "har")
+}
+
+// importResult loads the imported collections and environments and shows what could not be imported.
+func (c *Controller) importResult(imported *importer.Result, err error) {
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to import, %w", err))
+		return
+	}
 
-	}, "json")
+	for _, env := range imported.Environments {
+		c.envState.AddEnvironment(env, state.SourceFile)
+	}
+
+	if err := c.LoadData(); err != nil {
+		c.view.showError(fmt.Errorf("failed to load collections, %w", err))
+		return
+	}
+
+	if !imported.Report.IsEmpty() {
+		c.view.showWarning("Import report", imported.Report.String())
+	}
 }
 
 func (c *Controller) onNewCollection() {
@@ -789,9 +1667,169 @@ func (c *Controller) onTreeViewMenuClicked(id, action string) {
 		} else {
 			c.viewRequest(id)
 		}
+	case MenuCopyAsCurl, MenuCopyAsGrpcurl:
+		c.copyAsCommand(id)
+	case MenuGenerateCode:
+		c.generateCode(id)
+	case MenuExportPostman, MenuExportOpenAPI:
+		if nodeType == TypeCollection {
+			c.exportCollection(id, action)
+		}
+	case MenuRun:
+		c.openRunner(id, nodeType)
+	case MenuLoadTest:
+		c.openLoadTest(id, nodeType)
 	}
 }
 
+// exportCollection saves the collection as a Postman collection or as an OpenAPI document, what can not be
+// exported is shown once the file is saved.
+func (c *Controller) exportCollection(id, action string) {
+	col := c.model.GetCollection(id)
+	if col == nil {
+		return
+	}
+
+	var (
+		data   []byte
+		report *convert.Report
+		err    error
+		name   string
+	)
+	if action == MenuExportPostman {
+		data, report, err = exporter.ExportPostmanCollection(col)
+		name = col.MetaData.Name + ".postman_collection.json"
+	} else {
+		data, report, err = exporter.ExportOpenAPI(col)
+		name = col.MetaData.Name + ".openapi.json"
+	}
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to export collection, %w", err))
+		return
+	}
+
+	c.explorer.SaveFile(name, data, func(r explorer.Result) {
+		if r.Error != nil {
+			if !errors.Is(r.Error, explorer.ErrUserDecline) {
+				c.view.showError(r.Error)
+			}
+			return
+		}
+
+		if !report.IsEmpty() {
+			c.view.showWarning("Export report", report.String())
+			return
+		}
+		c.view.showNotification("Collection exported to "+r.FilePath, 2*time.Second)
+	})
+}
+
+// generateCode shows the request, with the variables of the active environment resolved, as code. http requests
+// can be switched between the supported languages while grpc requests are shown as a Go client.
+func (c *Controller) generateCode(id string) {
+	req := c.model.GetRequest(id)
+	if req == nil {
+		return
+	}
+
+	if req.MetaData.Type == domain.RequestTypeGRPC {
+		code, err := c.egressService.GenerateCode(id, c.getActiveEnvID(), "")
+		if err != nil {
+			c.view.showError(fmt.Errorf("failed to generate code, %w", err))
+			return
+		}
+		c.view.showCode("Generate code", code, "go", nil, 0, nil)
+		return
+	}
+
+	generators := codegen.Generators()
+	selected := 0
+	options := make([]*widgets.DropDownOption, 0, len(generators))
+	for i, g := range generators {
+		if g.ID == c.codeLanguage {
+			selected = i
+		}
+		options = append(options, widgets.NewDropDownOption(g.Name).WithValue(g.ID))
+	}
+
+	code, err := c.egressService.GenerateCode(id, c.getActiveEnvID(), generators[selected].ID)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to generate code, %w", err))
+		return
+	}
+
+	c.view.showCode("Generate code", code, generators[selected].Lexer, options, selected, func(value string) {
+		for _, g := range generators {
+			if g.ID != value {
+				continue
+			}
+
+			code, err := c.egressService.GenerateCode(id, c.getActiveEnvID(), g.ID)
+			if err != nil {
+				c.view.showError(fmt.Errorf("failed to generate code, %w", err))
+				return
+			}
+			c.codeLanguage = g.ID
+			c.view.setCode(code, g.Lexer)
+		}
+	})
+}
+
+// copyAsCommand copies the request, with the variables of the active environment resolved, as a curl or grpcurl command.
+func (c *Controller) copyAsCommand(id string) {
+	command, err := c.egressService.Command(id, c.getActiveEnvID())
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to create command, %w", err))
+		return
+	}
+
+	c.view.copyToClipboard(command)
+	c.view.showNotification("Command copied to clipboard", 2*time.Second)
+}
+
+// onCurlPasted replaces the request with the one of the curl command pasted to its address bar, the scripts,
+// variables and responses of the request are kept.
+func (c *Controller) onCurlPasted(id, command string) {
+	parsed, err := curl.Parse(command)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to parse curl command, %w", err))
+		return
+	}
+
+	req := c.model.GetRequest(id)
+	if req == nil || req.Spec.HTTP == nil {
+		c.view.showError(fmt.Errorf("failed to get request, %s", id))
+		return
+	}
+
+	spec := parsed.Spec.HTTP
+	spec.LastUsedEnvironment = req.Spec.HTTP.LastUsedEnvironment
+	spec.Responses = req.Spec.HTTP.Responses
+	if req.Spec.HTTP.Request != nil {
+		spec.Request.PreRequest = req.Spec.HTTP.Request.PreRequest
+		spec.Request.PostRequest = req.Spec.HTTP.Request.PostRequest
+		spec.Request.Variables = req.Spec.HTTP.Request.Variables
+	}
+	req.Spec.HTTP = spec
+
+	if err := c.model.UpdateRequest(req, true); err != nil {
+		c.view.showError(fmt.Errorf("failed to update request, %w", err))
+		return
+	}
+
+	reqFromFile, err := c.model.GetRequestFromDisc(id)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to get request from file, %w", err))
+		return
+	}
+
+	clone, _ := domain.Clone[domain.Request](req)
+	clone.MetaData.ID = id
+	c.view.ReloadRequestContainer(clone)
+	c.view.SetTabDirty(id, !domain.CompareRequests(req, reqFromFile))
+	c.view.SetTreeViewNodePrefix(id, req)
+}
+
 func (c *Controller) addRequestToCollection(id string, requestType string) {
 	var req *domain.Request
 	if requestType == domain.RequestTypeHTTP {
@@ -938,8 +1976,39 @@ func (c *Controller) deleteRequest(id string) {
 		return
 	}
 
+	c.stopLoadTest(id)
+	c.stopRun(id)
 	c.view.RemoveTreeViewNode(id)
 	c.view.CloseTab(id)
+	c.view.CloseTab(LoadTestPrefix + id)
+	c.view.CloseTab(RunnerPrefix + id)
+}
+
+// setNextRequests sets the requests the collection run can continue with after the request, the requests of
+// its collection or the request itself when it is not in one.
+func (c *Controller) setNextRequests(id string) {
+	req := c.model.GetRequest(id)
+	if req == nil {
+		return
+	}
+
+	requests := []*domain.Request{req}
+	if col := c.model.GetCollection(req.CollectionID); col != nil {
+		requests = col.Spec.Requests
+	}
+
+	var next *domain.NextRequest
+	if req.MetaData.Type == domain.RequestTypeHTTP {
+		next = req.Spec.HTTP.Request.PostRequest.NextRequest
+	} else if req.MetaData.Type == domain.RequestTypeGRPC {
+		next = req.Spec.GRPC.PostRequest.NextRequest
+	}
+
+	selectedID := ""
+	if next != nil {
+		selectedID = next.RequestID
+	}
+	c.view.SetNextRequests(id, requests, selectedID)
 }
 
 func (c *Controller) deleteCollection(id string) {
@@ -951,11 +2020,21 @@ func (c *Controller) deleteCollection(id string) {
 		c.view.showError(fmt.Errorf("failed to remove collection, %w", err))
 		return
 	}
+	c.stopMock(id)
+	c.stopLoadTest(id)
+	c.stopRun(id)
 	c.view.RemoveTreeViewNode(id)
 	c.view.CloseTab(id)
+	c.view.CloseTab(LoadTestPrefix + id)
+	c.view.CloseTab(RunnerPrefix + id)
 }
 
 func (c *Controller) onRequestTabChange(id, tab string) {
+	if tab == "Post Request" {
+		c.setNextRequests(id)
+		return
+	}
+
 	if tab != "Pre Request" {
 		return
 	}
@@ -994,4 +2073,13 @@ func (c *Controller) onRequestTabChange(id, tab string) {
 	} else {
 		c.view.SetPreRequestRequests(id, c.model.GetRequests(), requestID)
 	}
+
+	// any other request can be a prerequisite, a cycle is reported when it is set
+	prerequisites := make([]*domain.Request, 0)
+	for _, r := range c.model.GetRequests() {
+		if r.MetaData.ID != id {
+			prerequisites = append(prerequisites, r)
+		}
+	}
+	c.view.SetPrerequisites(id, prerequisites, preRequest.TriggerRequests)
 }
@@ -47,6 +47,10 @@ func (a *AddressBar) GetServerAddress() string {
 	return a.serverAddress.Text()
 }
 
+func (a *AddressBar) SetVariableHints(hints *widgets.VariableHints) {
+	a.serverAddress.SetVariableHints(hints)
+}
+
 func (a *AddressBar) SetServices(services []domain.GRPCService) {
 	opts := make([]*widgets.DropDownOption, 0, len(services))
 	for i, srv := range services {
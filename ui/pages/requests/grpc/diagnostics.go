@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Diagnostics shows the connection state, TLS details and health of the server.
+type Diagnostics struct {
+	checkButton widget.Clickable
+	watchButton widget.Clickable
+
+	IsLoading bool
+	watching  bool
+
+	result *widgets.CodeEditor
+
+	onCheck func()
+	onWatch func(watch bool)
+}
+
+func NewDiagnostics(theme *chapartheme.Theme) *Diagnostics {
+	d := &Diagnostics{
+		result: widgets.NewCodeEditor("", widgets.CodeLanguageYAML, theme),
+	}
+
+	d.result.SetReadOnly(true)
+	return d
+}
+
+func (d *Diagnostics) SetOnCheck(f func()) {
+	d.onCheck = f
+}
+
+func (d *Diagnostics) SetOnWatch(f func(watch bool)) {
+	d.onWatch = f
+}
+
+func (d *Diagnostics) SetResult(result string) {
+	d.result.SetCode(result)
+}
+
+func (d *Diagnostics) AppendResult(line string) {
+	d.result.SetCode(d.result.Code() + line + "\n")
+}
+
+func (d *Diagnostics) SetWatching(watching bool) {
+	d.watching = watching
+}
+
+func (d *Diagnostics) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if d.checkButton.Clicked(gtx) && d.onCheck != nil && !d.IsLoading {
+		go d.onCheck()
+	}
+
+	if d.watchButton.Clicked(gtx) && d.onWatch != nil {
+		d.watching = !d.watching
+		go d.onWatch(d.watching)
+	}
+
+	inset := layout.Inset{Top: unit.Dp(10)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Vertical,
+			Alignment: layout.Start,
+		}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := widgets.Button(theme.Material(), &d.checkButton, widgets.RefreshIcon, widgets.IconPositionStart, "Check connection")
+						btn.Color = theme.ButtonTextColor
+						return btn.Layout(gtx, theme)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						title := "Watch health"
+						if d.watching {
+							title = "Stop watching"
+						}
+
+						btn := widgets.Button(theme.Material(), &d.watchButton, nil, widgets.IconPositionStart, title)
+						btn.Color = theme.ButtonTextColor
+						return btn.Layout(gtx, theme)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !d.IsLoading {
+							return layout.Dimensions{}
+						}
+
+						gtx.Constraints.Max.X = gtx.Dp(24)
+						gtx.Constraints.Max.Y = gtx.Dp(24)
+						l := material.Loader(theme.Material())
+						l.Color = theme.LoaderColor
+						return l.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return d.result.Layout(gtx, theme, "")
+			}),
+		)
+	})
+}
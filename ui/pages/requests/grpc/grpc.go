@@ -26,6 +26,9 @@ type Grpc struct {
 
 	split widgets.SplitView
 
+	// hints are the variables known to the request, shared by its editors
+	hints *widgets.VariableHints
+
 	onSave        func(id string)
 	onDataChanged func(id string, data any)
 	onInvoke      func(id string)
@@ -53,7 +56,10 @@ func New(req *domain.Request, theme *chapartheme.Theme, explorer *explorer.Explo
 		AddressBar: NewAddressBar(theme, req.Spec.GRPC.ServerInfo.Address, req.Spec.GRPC.LasSelectedMethod, req.Spec.GRPC.Services),
 		Request:    NewRequest(req, theme, explorer),
 		Response:   NewResponse(theme),
+		hints:      &widgets.VariableHints{},
 	}
+	r.AddressBar.SetVariableHints(r.hints)
+	r.Request.SetVariableHints(r.hints)
 
 	r.setupHooks()
 
@@ -95,6 +101,11 @@ func (r *Grpc) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.Variables.SetOnChanged(func(items []*widgets.KeyValueItem) {
+		r.Req.Spec.GRPC.Variables = converter.KeyValueFromWidgetItems(items)
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Request.Settings.SetOnChange(func(values map[string]any) {
 		r.Req.Spec.GRPC.Settings = convertSettingsToItems(values)
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
@@ -105,11 +116,27 @@ func (r *Grpc) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.PreRequest.SetOnPrerequisitesChanged(func(alias, cacheFor string, prerequisites []domain.TriggerRequest) {
+		trigger := &domain.TriggerRequest{CollectionID: domain.PrePostTypeNone}
+		if r.Req.Spec.GRPC.PreRequest.TriggerRequest != nil {
+			*trigger = *r.Req.Spec.GRPC.PreRequest.TriggerRequest
+		}
+		trigger.Alias, trigger.CacheFor = alias, cacheFor
+		r.Req.Spec.GRPC.PreRequest.TriggerRequest = trigger
+		r.Req.Spec.GRPC.PreRequest.TriggerRequests = prerequisites
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Request.PostRequest.SetOnDropDownChanged(func(selected string) {
 		r.Req.Spec.GRPC.PostRequest.Type = selected
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.PostRequest.SetOnNextRequestChanged(func(statusCode int, requestID string) {
+		r.Req.Spec.GRPC.PostRequest.NextRequest = &domain.NextRequest{StatusCode: statusCode, RequestID: requestID}
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Request.ServerInfo.FileSelector.SetOnChanged(func(filePath string) {
 		protoFiles := r.Req.Spec.GRPC.ServerInfo.ProtoFiles
 		if r.Req.Spec.GRPC.ServerInfo.ProtoFiles == nil || filePath == "" {
@@ -124,6 +151,7 @@ func (r *Grpc) setupHooks() {
 
 	r.Request.ServerInfo.SetOnChanged(func() {
 		r.Req.Spec.GRPC.ServerInfo.ServerReflection = r.Request.ServerInfo.definitionFrom.Value == "reflection"
+		r.Req.Spec.GRPC.ServerInfo.Protocol = r.Request.ServerInfo.Protocol()
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 }
@@ -149,6 +177,42 @@ func convertSettingsToItems(values map[string]any) domain.Settings {
 		out.TimeoutMilliseconds = v.(int)
 	}
 
+	if v, ok := values["strictMode"]; ok {
+		out.StrictMode = v.(bool)
+	}
+
+	if v, ok := values["waitForReady"]; ok {
+		out.WaitForReady = v.(bool)
+	}
+
+	if v, ok := values["compression"]; ok {
+		out.Compression = v.(string)
+	}
+
+	if v, ok := values["maxSendMessageSize"]; ok {
+		out.MaxSendMessageSize = v.(int)
+	}
+
+	if v, ok := values["maxReceiveMessageSize"]; ok {
+		out.MaxReceiveMessageSize = v.(int)
+	}
+
+	if v, ok := values["authority"]; ok {
+		out.Authority = v.(string)
+	}
+
+	if v, ok := values["keepaliveTimeMilliseconds"]; ok {
+		out.KeepaliveTimeMilliseconds = v.(int)
+	}
+
+	if v, ok := values["keepaliveTimeoutMilliseconds"]; ok {
+		out.KeepaliveTimeoutMilliseconds = v.(int)
+	}
+
+	if v, ok := values["keepalivePermitWithoutStream"]; ok {
+		out.KeepalivePermitWithoutStream = v.(bool)
+	}
+
 	if v, ok := values["nameOverride"]; ok {
 		out.NameOverride = v.(string)
 	}
@@ -182,10 +246,22 @@ func (r *Grpc) SetPreRequestCollections(collections []*domain.Collection, select
 	r.Request.PreRequest.SetCollections(collections, selectedID)
 }
 
+func (r *Grpc) SetNextRequests(requests []*domain.Request, selectedID string) {
+	r.Request.PostRequest.SetNextRequests(requests, selectedID)
+}
+
+func (r *Grpc) SetPrerequisites(requests []*domain.Request, prerequisites []domain.TriggerRequest) {
+	r.Request.PreRequest.SetPrerequisites(requests, prerequisites)
+}
+
 func (r *Grpc) SetPreRequestRequests(requests []*domain.Request, selectedID string) {
 	r.Request.PreRequest.SetRequests(requests, selectedID)
 }
 
+func (r *Grpc) SetOnClearPrerequisiteCache(f func()) {
+	r.Request.PreRequest.SetOnClearCache(f)
+}
+
 func (r *Grpc) SetOnSetOnTriggerRequestChanged(f func(id, collectionID, requestID string)) {
 	r.Request.PreRequest.SetOnTriggerRequestChanged(func(collectionID, requestID string) {
 		f(r.Req.MetaData.ID, collectionID, requestID)
@@ -210,6 +286,18 @@ func (r *Grpc) SetOnDataChanged(f func(id string, data any)) {
 	r.onDataChanged = f
 }
 
+func (r *Grpc) SetOnDescribeVariable(f func(id, name string) string) {
+	r.hints.Describe = func(name string) string {
+		return f(r.Req.MetaData.ID, name)
+	}
+}
+
+func (r *Grpc) SetOnListVariables(f func(id string) []widgets.Completion) {
+	r.hints.Names = func() []widgets.Completion {
+		return f(r.Req.MetaData.ID)
+	}
+}
+
 func (r *Grpc) SetOnInvoke(f func(id string)) {
 	r.onInvoke = f
 }
@@ -229,6 +317,44 @@ func (r *Grpc) SetOnLoadRequestExample(f func(id string)) {
 	})
 }
 
+func (r *Grpc) SetOnBodyCompletion(f func(id, body string, caret int) (string, []widgets.Completion)) {
+	r.Request.Body.SetCompletionProvider(func(text string, caret int) (string, []widgets.Completion) {
+		return f(r.Req.MetaData.ID, text, caret)
+	})
+}
+
+func (r *Grpc) SetBodyDiagnostics(diagnostics []widgets.Diagnostic) {
+	r.Request.Body.SetDiagnostics(diagnostics)
+}
+
+func (r *Grpc) SetOnDiagnose(f func(id string)) {
+	r.Request.Diagnostics.SetOnCheck(func() {
+		f(r.Req.MetaData.ID)
+	})
+}
+
+func (r *Grpc) SetOnWatchHealth(f func(id string, watch bool)) {
+	r.Request.Diagnostics.SetOnWatch(func(watch bool) {
+		f(r.Req.MetaData.ID, watch)
+	})
+}
+
+func (r *Grpc) SetDiagnosticsLoading(loading bool) {
+	r.Request.Diagnostics.IsLoading = loading
+}
+
+func (r *Grpc) SetDiagnosticsResult(result string) {
+	r.Request.Diagnostics.SetResult(result)
+}
+
+func (r *Grpc) AppendDiagnosticsResult(line string) {
+	r.Request.Diagnostics.AppendResult(line)
+}
+
+func (r *Grpc) SetHealthWatching(watching bool) {
+	r.Request.Diagnostics.SetWatching(watching)
+}
+
 func (r *Grpc) SetOnCopyResponse(f func(gtx layout.Context, dataType, data string)) {
 	r.Response.SetOnCopyResponse(f)
 }
@@ -246,14 +372,16 @@ func (r *Grpc) SetResponse(detail domain.GRPCResponseDetail) {
 	r.Response.SetMetadata(detail.Metadata)
 	r.Response.SetTrailers(detail.Trailers)
 	r.Response.SetError(detail.Error)
+	r.Response.SetErrorDetails(detail.ErrorDetails)
 	r.Response.SetStatusParams(detail.StatusCode, detail.Status, detail.Duration, detail.Size)
 }
 
 func (r *Grpc) GetResponse() *domain.GRPCResponseDetail {
 	return &domain.GRPCResponseDetail{
-		Response: r.Response.response,
-		Metadata: domain.TextToKeyValue(r.Response.Metadata.Code()),
-		Trailers: domain.TextToKeyValue(r.Response.Trailers.Code()),
+		Response:     r.Response.response,
+		Metadata:     domain.TextToKeyValue(r.Response.Metadata.Code()),
+		Trailers:     domain.TextToKeyValue(r.Response.Trailers.Code()),
+		ErrorDetails: r.Response.ErrorDetails.Code(),
 	}
 }
 
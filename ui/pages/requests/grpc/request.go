@@ -20,9 +20,12 @@ type Request struct {
 	ServerInfo *ServerInfo
 	Body       *widgets.CodeEditor
 	Metadata   *widgets.KeyValue
+	Variables  *widgets.KeyValue
 	Auth       *component.Auth
 	Settings   *widgets.Settings
 
+	Diagnostics *Diagnostics
+
 	PreRequest  *component.PrePostRequest
 	PostRequest *component.PrePostRequest
 
@@ -35,6 +38,16 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 		return !values["insecure"].(bool)
 	}
 
+	keepaliveVisibility := func(values map[string]any) bool {
+		v, ok := values["keepaliveTimeMilliseconds"].(int)
+		return ok && v > 0
+	}
+
+	compression := req.Spec.GRPC.Settings.Compression
+	if compression == "" {
+		compression = domain.CompressionNone
+	}
+
 	certExt := []string{"pem", "crt"}
 
 	postRequestDropDown := widgets.NewDropDown(
@@ -42,6 +55,7 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 		widgets.NewDropDownOption("From Response").WithValue(domain.PostRequestSetFromResponseBody),
 		widgets.NewDropDownOption("From Metadata").WithValue(domain.PostRequestSetFromResponseMetaData),
 		widgets.NewDropDownOption("From Trailers").WithValue(domain.PostRequestSetFromResponseTrailers),
+		widgets.NewDropDownOption("From Error Details").WithValue(domain.PostRequestSetFromResponseErrorDetails),
 	)
 
 	r := &Request{
@@ -51,16 +65,22 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 			{Title: "Body"},
 			{Title: "Auth"},
 			{Title: "Meta Data"},
+			{Title: "Variables"},
 			{Title: "Settings"},
 			{Title: "Pre Request"},
 			{Title: "Post Request"},
+			{Title: "Diagnostics"},
 		}, nil),
 		ServerInfo: NewServerInfo(explorer, req.Spec.GRPC.ServerInfo),
 		Body:       widgets.NewCodeEditor(req.Spec.GRPC.Body, widgets.CodeLanguageJSON, theme),
 		Metadata: widgets.NewKeyValue(
 			converter.WidgetItemsFromKeyValue(req.Spec.GRPC.Metadata)...,
 		),
-		Auth: component.NewAuth(req.Spec.GRPC.Auth, theme),
+		Variables: widgets.NewKeyValue(
+			converter.WidgetItemsFromKeyValue(req.Spec.GRPC.Variables)...,
+		),
+		Auth:        component.NewAuth(req.Spec.GRPC.Auth, theme),
+		Diagnostics: NewDiagnostics(theme),
 		Settings: widgets.NewSettings([]*widgets.SettingItem{
 			widgets.NewBoolItem("Plain Text", "insecure", "Insecure connection", req.Spec.GRPC.Settings.Insecure),
 			widgets.NewFileItem(explorer, "Trusted Root certificate", "root_cert", "x509 pem trusted root certificate", req.Spec.GRPC.Settings.RootCertFile, certExt...).SetVisibleWhen(visibilityFunc),
@@ -68,6 +88,15 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 			widgets.NewFileItem(explorer, "Client key", "client_private_key", "Private key", req.Spec.GRPC.Settings.ClientKeyFile, certExt...).SetVisibleWhen(visibilityFunc),
 			widgets.NewTextItem("Overwrite server name for certificate verification", "nameOverride", "The value used to validate the common name in the server certificate.", req.Spec.GRPC.Settings.NameOverride).SetVisibleWhen(visibilityFunc),
 			widgets.NewNumberItem("Timeout", "timeoutMilliseconds", "Timeout for the request in milliseconds", req.Spec.GRPC.Settings.TimeoutMilliseconds),
+			widgets.NewBoolItem("Strict mode", "strictMode", "Reject the body if it has fields unknown to the method", req.Spec.GRPC.Settings.StrictMode),
+			widgets.NewBoolItem("Wait for ready", "waitForReady", "Wait for the connection to be ready instead of failing fast, native gRPC only", req.Spec.GRPC.Settings.WaitForReady),
+			widgets.NewDropDownItem(theme, "Compression", "compression", "Compressor used for the request messages", compression, domain.CompressionNone, domain.CompressionGzip),
+			widgets.NewNumberItem("Max send message size", "maxSendMessageSize", "Maximum size of a request message in bytes, 0 uses the default", req.Spec.GRPC.Settings.MaxSendMessageSize),
+			widgets.NewNumberItem("Max receive message size", "maxReceiveMessageSize", "Maximum size of a response message in bytes, 0 uses the default", req.Spec.GRPC.Settings.MaxReceiveMessageSize),
+			widgets.NewTextItem("Authority", "authority", "Value of the :authority header, empty uses the server address", req.Spec.GRPC.Settings.Authority),
+			widgets.NewNumberItem("Keepalive time", "keepaliveTimeMilliseconds", "Interval of the keepalive pings in milliseconds, 0 disables keepalive, native gRPC only", req.Spec.GRPC.Settings.KeepaliveTimeMilliseconds),
+			widgets.NewNumberItem("Keepalive timeout", "keepaliveTimeoutMilliseconds", "Time to wait for a ping ack in milliseconds", req.Spec.GRPC.Settings.KeepaliveTimeoutMilliseconds).SetVisibleWhen(keepaliveVisibility),
+			widgets.NewBoolItem("Keepalive without calls", "keepalivePermitWithoutStream", "Send keepalive pings even without active calls", req.Spec.GRPC.Settings.KeepalivePermitWithoutStream).SetVisibleWhen(keepaliveVisibility),
 		}),
 		PreRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
@@ -80,13 +109,15 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 		PostRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Set Environment Variable", Value: domain.PrePostTypeSetEnv, Type: component.TypeSetEnv, Hint: "Set environment variable"},
+			{Title: "Set Next Request", Value: domain.PrePostTypeSetNextRequest, Type: component.TypeSetNextRequest, Hint: "Set the next request of the collection run"},
 			//	{Title: "Python", Value: domain.PostRequestTypePythonScript, Type: component.TypeScript, Hint: "Write your post request python script here"},
 			//	{Title: "Shell Script", Value: domain.PostRequestTypeShellScript, Type: component.TypeScript, Hint: "Write your post request shell script here"},
 		}, postRequestDropDown, theme),
 	}
 
-	if req.Spec.GRPC.PreRequest != (domain.PreRequest{}) {
+	if !req.Spec.GRPC.PreRequest.IsZero() {
 		r.PreRequest.SetSelectedDropDown(req.Spec.GRPC.PreRequest.Type)
+		r.PreRequest.SetTriggerRequestValues(req.Spec.GRPC.PreRequest.TriggerRequest)
 	}
 
 	if req.Spec.GRPC.PostRequest != (domain.PostRequest{}) {
@@ -95,11 +126,18 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 		if req.Spec.GRPC.PostRequest.PostRequestSet != (domain.PostRequestSet{}) {
 			r.PostRequest.SetPostRequestSetValues(req.Spec.GRPC.PostRequest.PostRequestSet)
 		}
+		r.PostRequest.SetNextRequestValues(req.Spec.GRPC.PostRequest.NextRequest)
 	}
 
 	return r
 }
 
+func (r *Request) SetVariableHints(hints *widgets.VariableHints) {
+	r.Body.SetVariableHints(hints)
+	r.Metadata.SetVariableHints(hints)
+	r.Variables.SetVariableHints(hints)
+}
+
 func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	inset := layout.Inset{Top: unit.Dp(10)}
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -133,6 +171,10 @@ func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Di
 					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 						return r.Metadata.WithAddLayout(gtx, "", "", theme)
 					})
+				case "Variables":
+					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return r.Variables.WithAddLayout(gtx, "", "Override the collection and environment variables", theme)
+					})
 				case "Auth":
 					return r.Auth.Layout(gtx, theme)
 				case "Settings":
@@ -141,6 +183,10 @@ func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Di
 					return r.PreRequest.Layout(gtx, theme)
 				case "Post Request":
 					return r.PostRequest.Layout(gtx, theme)
+				case "Diagnostics":
+					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return r.Diagnostics.Layout(gtx, theme)
+					})
 				default:
 					return layout.Dimensions{}
 				}
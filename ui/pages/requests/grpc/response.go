@@ -29,6 +29,12 @@ type Response struct {
 
 	Metadata *widgets.CodeEditor
 	Trailers *widgets.CodeEditor
+	// ErrorDetails shows the google.rpc.Status details of a failed call as JSON.
+	ErrorDetails *widgets.CodeEditor
+
+	tabs            []*widgets.Tab
+	errorDetailsTab *widgets.Tab
+	hasErrorDetails bool
 
 	response string
 	message  string
@@ -53,18 +59,22 @@ func NewResponse(theme *chapartheme.Theme) *Response {
 			IconPosition:    widgets.FlatButtonIconEnd,
 			SpaceBetween:    unit.Dp(5),
 		},
-		Tabs: widgets.NewTabs([]*widgets.Tab{
+		tabs: []*widgets.Tab{
 			{Title: "Body"},
 			{Title: "Metadata"},
 			{Title: "Trailers"},
-		}, nil),
-		jsonViewer: widgets.NewJsonViewer(),
-		Metadata:   widgets.NewCodeEditor("", widgets.CodeLanguageProperties, theme),
-		Trailers:   widgets.NewCodeEditor("", widgets.CodeLanguageProperties, theme),
+		},
+		errorDetailsTab: &widgets.Tab{Title: "Error Details"},
+		jsonViewer:      widgets.NewJsonViewer(),
+		Metadata:        widgets.NewCodeEditor("", widgets.CodeLanguageProperties, theme),
+		Trailers:        widgets.NewCodeEditor("", widgets.CodeLanguageProperties, theme),
+		ErrorDetails:    widgets.NewCodeEditor("", widgets.CodeLanguageJSON, theme),
 	}
 
+	r.Tabs = widgets.NewTabs(r.tabs, nil)
 	r.Metadata.SetReadOnly(true)
 	r.Trailers.SetReadOnly(true)
+	r.ErrorDetails.SetReadOnly(true)
 
 	return r
 }
@@ -104,8 +114,26 @@ func (r *Response) SetError(err error) {
 	r.err = err
 }
 
+// SetErrorDetails shows the details tab when the failed call carried status details and hides it otherwise.
+func (r *Response) SetErrorDetails(details string) {
+	r.ErrorDetails.SetCode(details)
+	r.hasErrorDetails = details != ""
+
+	if !r.hasErrorDetails {
+		r.Tabs.SetTabs(r.tabs)
+		if r.Tabs.Selected() >= len(r.tabs) {
+			r.Tabs.SetSelected(0)
+		}
+		return
+	}
+
+	r.Tabs.SetTabs(append(append([]*widgets.Tab{}, r.tabs...), r.errorDetailsTab))
+	r.Tabs.SetSelected(len(r.tabs))
+	r.responseIsAvailable = true
+}
+
 func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
-	if r.err != nil {
+	if r.err != nil && !r.hasErrorDetails {
 		return component.Message(gtx, component.MessageTypeError, theme, r.err.Error())
 	}
 
@@ -139,6 +167,10 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 								TextSize: theme.TextSize,
 								Shaper:   theme.Shaper,
 							}
+							if r.err != nil {
+								l.Text = r.err.Error()
+								l.Color = theme.ErrorColor
+							}
 							l.Font.Typeface = theme.Face
 							return l.Layout(gtx)
 						})
@@ -166,8 +198,10 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 					})
 				case 1:
 					return r.Metadata.Layout(gtx, theme, "")
-				default:
+				case 2:
 					return r.Trailers.Layout(gtx, theme, "")
+				default:
+					return r.ErrorDetails.Layout(gtx, theme, "")
 				}
 			}),
 		)
@@ -188,7 +222,9 @@ func (r *Response) handleCopy(gtx layout.Context) {
 		r.onCopyResponse(gtx, "Response", r.response)
 	case 1:
 		r.onCopyResponse(gtx, "Metadata", r.Metadata.Code())
-	default:
+	case 2:
 		r.onCopyResponse(gtx, "Trailers", r.Trailers.Code())
+	default:
+		r.onCopyResponse(gtx, "Error Details", r.ErrorDetails.Code())
 	}
 }
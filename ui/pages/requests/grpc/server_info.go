@@ -14,6 +14,7 @@ import (
 
 type ServerInfo struct {
 	definitionFrom *widget.Enum
+	protocol       *widget.Enum
 
 	ReloadButton *widget.Clickable
 	FileSelector *widgets.FileSelector
@@ -33,6 +34,7 @@ func NewServerInfo(explorer *explorer.Explorer, info domain.ServerInfo) *ServerI
 
 	s := &ServerInfo{
 		definitionFrom: new(widget.Enum),
+		protocol:       new(widget.Enum),
 		FileSelector:   widgets.NewFileSelector(fileName, explorer, ".proto"),
 		ReloadButton:   new(widget.Clickable),
 		IsLoading:      false,
@@ -44,9 +46,18 @@ func NewServerInfo(explorer *explorer.Explorer, info domain.ServerInfo) *ServerI
 		s.definitionFrom.Value = "proto_files"
 	}
 
+	s.protocol.Value = info.Protocol
+	if s.protocol.Value == "" {
+		s.protocol.Value = domain.GRPCProtocolGRPC
+	}
+
 	return s
 }
 
+func (s *ServerInfo) Protocol() string {
+	return s.protocol.Value
+}
+
 func (s *ServerInfo) SetOnChanged(f func()) {
 	s.onChanged = f
 }
@@ -56,7 +67,7 @@ func (s *ServerInfo) SetOnReload(f func()) {
 }
 
 func (s *ServerInfo) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
-	if s.definitionFrom.Update(gtx) {
+	if s.definitionFrom.Update(gtx) || s.protocol.Update(gtx) {
 		if s.onChanged != nil {
 			s.onChanged()
 		}
@@ -68,6 +79,35 @@ func (s *ServerInfo) Layout(gtx layout.Context, theme *chapartheme.Theme) layout
 			Axis:      layout.Vertical,
 			Alignment: layout.Start,
 		}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.Label(theme.Material(), unit.Sp(14), "Protocol:").Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						r := widgets.RadioButton(theme.Material(), s.protocol, domain.GRPCProtocolGRPC, "gRPC")
+						r.IconColor = theme.CheckBoxColor
+						return r.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(20)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						r := widgets.RadioButton(theme.Material(), s.protocol, domain.GRPCProtocolGRPCWeb, "gRPC-Web")
+						r.IconColor = theme.CheckBoxColor
+						return r.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(20)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						r := widgets.RadioButton(theme.Material(), s.protocol, domain.GRPCProtocolConnect, "Connect")
+						r.IconColor = theme.CheckBoxColor
+						return r.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(20)}.Layout),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				return material.Label(theme.Material(), unit.Sp(14), "Server definition from:").Layout(gtx)
 			}),
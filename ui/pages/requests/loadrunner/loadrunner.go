@@ -0,0 +1,244 @@
+package loadrunner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/loadtest"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+const (
+	FormatJSON = "json"
+	FormatHTML = "html"
+)
+
+// LoadRunner runs a load test of a request or a collection and shows its statistics while it runs.
+type LoadRunner struct {
+	id   string
+	name string
+
+	virtualUsers *widgets.LabeledInput
+	rampUp       *widgets.LabeledInput
+	duration     *widgets.LabeledInput
+	iterations   *widgets.LabeledInput
+	rateLimit    *widgets.LabeledInput
+
+	startButton    widget.Clickable
+	saveJSONButton widget.Clickable
+	saveHTMLButton widget.Clickable
+	running        bool
+
+	stats *widgets.CodeEditor
+
+	prompt *widgets.Prompt
+
+	onStart      func(id string, opts loadtest.Options)
+	onStop       func(id string)
+	onSaveReport func(id, format string)
+}
+
+func New(id, name string, theme *chapartheme.Theme) *LoadRunner {
+	input := func(label, text, hint string) *widgets.LabeledInput {
+		l := &widgets.LabeledInput{
+			Label:          label,
+			SpaceBetween:   5,
+			MinEditorWidth: unit.Dp(100),
+			MinLabelWidth:  unit.Dp(80),
+			Editor:         widgets.NewPatternEditor(),
+			Hint:           hint,
+		}
+		l.SetText(text)
+		return l
+	}
+
+	r := &LoadRunner{
+		id:           id,
+		name:         name,
+		virtualUsers: input("Virtual users", "10", "e.g. 10"),
+		rampUp:       input("Ramp-up", "0s", "e.g. 10s"),
+		duration:     input("Duration", "30s", "e.g. 1m, empty to run the iterations"),
+		iterations:   input("Iterations", "", "over all users"),
+		rateLimit:    input("Rate limit", "", "requests per second"),
+		stats:        widgets.NewCodeEditor("", widgets.CodeLanguageYAML, theme),
+		prompt:       widgets.NewPrompt("", "", ""),
+	}
+	r.stats.SetReadOnly(true)
+	r.prompt.WithoutRememberBool()
+	return r
+}
+
+func (r *LoadRunner) SetOnStart(f func(id string, opts loadtest.Options)) {
+	r.onStart = f
+}
+
+func (r *LoadRunner) SetOnStop(f func(id string)) {
+	r.onStop = f
+}
+
+func (r *LoadRunner) SetOnSaveReport(f func(id, format string)) {
+	r.onSaveReport = f
+}
+
+func (r *LoadRunner) SetRunning(running bool) {
+	r.running = running
+}
+
+func (r *LoadRunner) SetReport(report *loadtest.Report) {
+	state := "Running"
+	if report.Finished {
+		state = "Finished"
+	}
+	r.stats.SetCode(state + "\n\n" + report.String())
+}
+
+// The load test has no data to save, the container methods are no-ops.
+
+func (r *LoadRunner) SetOnDataChanged(func(id string, data any)) {}
+func (r *LoadRunner) SetOnTitleChanged(func(title string))       {}
+func (r *LoadRunner) SetDataChanged(bool)                        {}
+func (r *LoadRunner) SetOnSave(func(id string))                  {}
+
+func (r *LoadRunner) ShowPrompt(title, content, modalType string, onSubmit func(selectedOption string, remember bool), options ...widgets.Option) {
+	r.prompt.Type = modalType
+	r.prompt.Title = title
+	r.prompt.Content = content
+	r.prompt.SetOptions(options...)
+	r.prompt.WithoutRememberBool()
+	r.prompt.SetOnSubmit(onSubmit)
+	r.prompt.Show()
+}
+
+func (r *LoadRunner) HidePrompt() {
+	r.prompt.Hide()
+}
+
+// options returns the options of the inputs, the empty inputs are left unset.
+func (r *LoadRunner) options() (loadtest.Options, error) {
+	var opts loadtest.Options
+
+	vus, err := strconv.Atoi(strings.TrimSpace(r.virtualUsers.Text()))
+	if err != nil {
+		return opts, fmt.Errorf("invalid number of virtual users, %w", err)
+	}
+	opts.VirtualUsers = vus
+
+	for _, d := range []struct {
+		input *widgets.LabeledInput
+		value *time.Duration
+	}{{r.rampUp, &opts.RampUp}, {r.duration, &opts.Duration}} {
+		if text := strings.TrimSpace(d.input.Text()); text != "" {
+			if *d.value, err = time.ParseDuration(text); err != nil {
+				return opts, fmt.Errorf("invalid %s, %w", strings.ToLower(d.input.Label), err)
+			}
+		}
+	}
+
+	if text := strings.TrimSpace(r.iterations.Text()); text != "" {
+		if opts.Iterations, err = strconv.Atoi(text); err != nil {
+			return opts, fmt.Errorf("invalid iterations, %w", err)
+		}
+	}
+
+	if text := strings.TrimSpace(r.rateLimit.Text()); text != "" {
+		if opts.RateLimit, err = strconv.ParseFloat(text, 64); err != nil {
+			return opts, fmt.Errorf("invalid rate limit, %w", err)
+		}
+	}
+	return opts, opts.Validate()
+}
+
+func (r *LoadRunner) handleClicks(gtx layout.Context) {
+	if r.startButton.Clicked(gtx) {
+		if r.running {
+			if r.onStop != nil {
+				go r.onStop(r.id)
+			}
+		} else if r.onStart != nil {
+			if opts, err := r.options(); err != nil {
+				r.stats.SetCode(err.Error())
+			} else {
+				go r.onStart(r.id, opts)
+			}
+		}
+	}
+
+	if r.saveJSONButton.Clicked(gtx) && r.onSaveReport != nil {
+		go r.onSaveReport(r.id, FormatJSON)
+	}
+
+	if r.saveHTMLButton.Clicked(gtx) && r.onSaveReport != nil {
+		go r.onSaveReport(r.id, FormatHTML)
+	}
+}
+
+func (r *LoadRunner) button(gtx layout.Context, theme *chapartheme.Theme, clickable *widget.Clickable, icon *widget.Icon, title string) layout.Dimensions {
+	btn := widgets.Button(theme.Material(), clickable, icon, widgets.IconPositionStart, title)
+	btn.Color = theme.ButtonTextColor
+	return btn.Layout(gtx, theme)
+}
+
+func (r *LoadRunner) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	r.handleClicks(gtx)
+
+	inputs := []*widgets.LabeledInput{r.virtualUsers, r.rampUp, r.duration, r.iterations, r.rateLimit}
+
+	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return r.prompt.Layout(gtx, theme)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return material.Label(theme.Material(), theme.TextSize, "Load test "+r.name+" with the selected environment, "+
+						"the variables are resolved again for each request.").Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				children := make([]layout.FlexChild, 0, len(inputs)*2)
+				for _, in := range inputs {
+					children = append(children,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return in.Layout(gtx, theme)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					)
+				}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				title := "Start"
+				if r.running {
+					title = "Stop"
+				}
+
+				return layout.Inset{Top: unit.Dp(10), Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.startButton, nil, title)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.saveJSONButton, widgets.SaveIcon, "Save JSON report")
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.saveHTMLButton, widgets.SaveIcon, "Save HTML report")
+						}),
+					)
+				})
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return r.stats.Layout(gtx, theme, "")
+			}),
+		)
+	})
+}
@@ -0,0 +1,277 @@
+package recorder
+
+import (
+	"strings"
+	"sync"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Recorder runs the recording proxy and lets the user save the recorded exchanges to a collection.
+type Recorder struct {
+	address *widgets.LabeledInput
+
+	startButton  widget.Clickable
+	caButton     widget.Clickable
+	clearButton  widget.Clickable
+	saveButton   widget.Clickable
+	selectAll    widget.Bool
+	lastSelected bool
+
+	running     bool
+	runningAddr string
+
+	mu        sync.Mutex
+	exchanges []*exchange
+	list      widget.List
+
+	collections *widgets.DropDown
+
+	prompt *widgets.Prompt
+
+	onStart  func(addr string)
+	onStop   func()
+	onSaveCA func()
+	onClear  func()
+	onSave   func(collectionID string, exchangeIDs []string)
+}
+
+type exchange struct {
+	id       string
+	text     string
+	selected widget.Bool
+}
+
+func New(theme *chapartheme.Theme) *Recorder {
+	r := &Recorder{
+		address: &widgets.LabeledInput{
+			Label:          "Address",
+			SpaceBetween:   5,
+			MinEditorWidth: unit.Dp(150),
+			MinLabelWidth:  unit.Dp(80),
+			Editor:         widgets.NewPatternEditor(),
+			Hint:           "e.g. localhost:8888",
+		},
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+		collections: widgets.NewDropDown(theme),
+		prompt:      widgets.NewPrompt("", "", ""),
+	}
+	r.address.SetText("localhost:8888")
+	r.collections.MaxWidth = unit.Dp(250)
+	r.prompt.WithoutRememberBool()
+	return r
+}
+
+func (r *Recorder) SetOnStart(f func(addr string)) {
+	r.onStart = f
+}
+
+func (r *Recorder) SetOnStop(f func()) {
+	r.onStop = f
+}
+
+func (r *Recorder) SetOnSaveCA(f func()) {
+	r.onSaveCA = f
+}
+
+func (r *Recorder) SetOnClear(f func()) {
+	r.onClear = f
+}
+
+func (r *Recorder) SetOnSaveExchanges(f func(collectionID string, exchangeIDs []string)) {
+	r.onSave = f
+}
+
+func (r *Recorder) SetRunning(running bool, addr string) {
+	r.running = running
+	r.runningAddr = addr
+}
+
+// SetCollections sets the collections the exchanges can be saved to.
+func (r *Recorder) SetCollections(collections []*domain.Collection) {
+	selected := r.collections.GetSelected().GetValue()
+
+	options := make([]*widgets.DropDownOption, 0, len(collections))
+	for _, c := range collections {
+		options = append(options, widgets.NewDropDownOption(c.MetaData.Name).WithValue(c.MetaData.ID))
+	}
+	r.collections.SetOptions(options...)
+	if selected != "" {
+		r.collections.SetSelectedByValue(selected)
+	}
+}
+
+func (r *Recorder) AddExchange(id, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, &exchange{id: id, text: text})
+}
+
+func (r *Recorder) ClearExchanges() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = nil
+}
+
+func (r *Recorder) selectedExchanges() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for _, ex := range r.exchanges {
+		if ex.selected.Value {
+			ids = append(ids, ex.id)
+		}
+	}
+	return ids
+}
+
+// The recorder has no data to save, the container methods are no-ops.
+
+func (r *Recorder) SetOnDataChanged(func(id string, data any)) {}
+func (r *Recorder) SetOnTitleChanged(func(title string))       {}
+func (r *Recorder) SetDataChanged(bool)                        {}
+func (r *Recorder) SetOnSave(func(id string))                  {}
+
+func (r *Recorder) ShowPrompt(title, content, modalType string, onSubmit func(selectedOption string, remember bool), options ...widgets.Option) {
+	r.prompt.Type = modalType
+	r.prompt.Title = title
+	r.prompt.Content = content
+	r.prompt.SetOptions(options...)
+	r.prompt.WithoutRememberBool()
+	r.prompt.SetOnSubmit(onSubmit)
+	r.prompt.Show()
+}
+
+func (r *Recorder) HidePrompt() {
+	r.prompt.Hide()
+}
+
+func (r *Recorder) handleClicks(gtx layout.Context) {
+	if r.startButton.Clicked(gtx) {
+		if r.running {
+			if r.onStop != nil {
+				go r.onStop()
+			}
+		} else if r.onStart != nil {
+			go r.onStart(strings.TrimSpace(r.address.Text()))
+		}
+	}
+
+	if r.caButton.Clicked(gtx) && r.onSaveCA != nil {
+		go r.onSaveCA()
+	}
+
+	if r.clearButton.Clicked(gtx) && r.onClear != nil {
+		r.ClearExchanges()
+		go r.onClear()
+	}
+
+	if r.saveButton.Clicked(gtx) && r.onSave != nil {
+		ids := r.selectedExchanges()
+		collectionID := r.collections.GetSelected().GetValue()
+		if len(ids) > 0 {
+			go r.onSave(collectionID, ids)
+		}
+	}
+
+	if r.selectAll.Value != r.lastSelected {
+		r.lastSelected = r.selectAll.Value
+		r.mu.Lock()
+		for _, ex := range r.exchanges {
+			ex.selected.Value = r.selectAll.Value
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *Recorder) button(gtx layout.Context, theme *chapartheme.Theme, clickable *widget.Clickable, icon *widget.Icon, title string) layout.Dimensions {
+	btn := widgets.Button(theme.Material(), clickable, icon, widgets.IconPositionStart, title)
+	btn.Color = theme.ButtonTextColor
+	return btn.Layout(gtx, theme)
+}
+
+func (r *Recorder) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	r.handleClicks(gtx)
+
+	r.mu.Lock()
+	exchanges := append([]*exchange(nil), r.exchanges...)
+	r.mu.Unlock()
+
+	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return r.prompt.Layout(gtx, theme)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return material.Label(theme.Material(), theme.TextSize, "Record the traffic of the clients using the address as their http and https proxy, "+
+						"https is intercepted with a local certificate authority the clients have to trust.").Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				title := "Start"
+				if r.running {
+					title = "Stop"
+				}
+
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.address.Layout(gtx, theme)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.button(gtx, theme, &r.startButton, nil, title)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.button(gtx, theme, &r.caButton, widgets.SaveIcon, "Save CA certificate")
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !r.running {
+							return layout.Dimensions{}
+						}
+						return material.Label(theme.Material(), theme.TextSize, "Listening on "+r.runningAddr).Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(10), Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return widgets.CheckBox(theme.Material(), &r.selectAll, "Select all").Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.collections.Layout(gtx, theme)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.saveButton, widgets.SaveIcon, "Save selected")
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.clearButton, widgets.DeleteIcon, "Clear")
+						}),
+					)
+				})
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.List(theme.Material(), &r.list).Layout(gtx, len(exchanges), func(gtx layout.Context, i int) layout.Dimensions {
+					ex := exchanges[i]
+					return widgets.CheckBox(theme.Material(), &ex.selected, ex.text).Layout(gtx)
+				})
+			}),
+		)
+	})
+}
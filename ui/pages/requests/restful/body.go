@@ -57,6 +57,11 @@ func NewBody(body domain.Body, theme *chapartheme.Theme, explorer *explorer.Expl
 	return b
 }
 
+func (b *Body) SetVariableHints(hints *widgets.VariableHints) {
+	b.urlencoded.SetVariableHints(hints)
+	b.script.SetVariableHints(hints)
+}
+
 func (b *Body) SetOnChange(f func(body domain.Body)) {
 	b.onChange = f
 
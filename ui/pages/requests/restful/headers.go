@@ -28,6 +28,10 @@ func (h *Headers) SetHeaders(headers []domain.KeyValue) {
 	h.values.SetItems(converter.WidgetItemsFromKeyValue(headers))
 }
 
+func (h *Headers) SetVariableHints(hints *widgets.VariableHints) {
+	h.values.SetVariableHints(hints)
+}
+
 func (h *Headers) SetOnChange(f func(values []domain.KeyValue)) {
 	h.onChange = f
 
@@ -36,6 +36,11 @@ func (p *Params) SetPathParams(pathParams []domain.KeyValue) {
 	p.pathParams.SetItems(converter.WidgetItemsFromKeyValue(pathParams))
 }
 
+func (p *Params) SetVariableHints(hints *widgets.VariableHints) {
+	p.queryParams.SetVariableHints(hints)
+	p.pathParams.SetVariableHints(hints)
+}
+
 func (p *Params) SetOnChange(f func(queryParams []domain.KeyValue, pathParams []domain.KeyValue)) {
 	p.onChange = f
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
 	"github.com/chapar-rest/chapar/ui/explorer"
 	"github.com/chapar-rest/chapar/ui/pages/requests/component"
 	"github.com/chapar-rest/chapar/ui/widgets"
@@ -17,10 +18,11 @@ type Request struct {
 	PreRequest  *component.PrePostRequest
 	PostRequest *component.PrePostRequest
 
-	Body    *Body
-	Params  *Params
-	Headers *Headers
-	Auth    *component.Auth
+	Body      *Body
+	Params    *Params
+	Headers   *Headers
+	Auth      *component.Auth
+	Variables *widgets.KeyValue
 
 	currentTab  string
 	OnTabChange func(title string)
@@ -41,6 +43,7 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 			{Title: "Body"},
 			{Title: "Auth"},
 			{Title: "Headers"},
+			{Title: "Variables"},
 			{Title: "Pre Request"},
 			{Title: "Post Request"},
 		}, nil),
@@ -55,23 +58,27 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 		PostRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Set Environment Variable", Value: domain.PrePostTypeSetEnv, Type: component.TypeSetEnv, Hint: "Set environment variable"},
+			{Title: "Set Next Request", Value: domain.PrePostTypeSetNextRequest, Type: component.TypeSetNextRequest, Hint: "Set the next request of the collection run"},
 			//	{Title: "Python", Value: domain.PostRequestTypePythonScript, Type: component.TypeScript, Hint: "Write your post request python script here"},
 			//	{Title: "Shell Script", Value: domain.PostRequestTypeShellScript, Type: component.TypeScript, Hint: "Write your post request shell script here"},
 		}, postRequestDropDown, theme),
 
-		Body:    NewBody(req.Spec.HTTP.Request.Body, theme, explorer),
-		Params:  NewParams(nil, nil),
-		Headers: NewHeaders(nil),
-		Auth:    component.NewAuth(req.Spec.HTTP.Request.Auth, theme),
+		Body:      NewBody(req.Spec.HTTP.Request.Body, theme, explorer),
+		Params:    NewParams(nil, nil),
+		Headers:   NewHeaders(nil),
+		Auth:      component.NewAuth(req.Spec.HTTP.Request.Auth, theme),
+		Variables: widgets.NewKeyValue(),
 	}
 
 	if req.Spec != (domain.RequestSpec{}) && req.Spec.HTTP != nil && req.Spec.HTTP.Request != nil {
 		r.Params.SetQueryParams(req.Spec.HTTP.Request.QueryParams)
 		r.Params.SetPathParams(req.Spec.HTTP.Request.PathParams)
 		r.Headers.SetHeaders(req.Spec.HTTP.Request.Headers)
+		r.Variables.SetItems(converter.WidgetItemsFromKeyValue(req.Spec.HTTP.Request.Variables))
 
-		if req.Spec.HTTP.Request.PreRequest != (domain.PreRequest{}) {
+		if !req.Spec.HTTP.Request.PreRequest.IsZero() {
 			r.PreRequest.SetSelectedDropDown(req.Spec.HTTP.Request.PreRequest.Type)
+			r.PreRequest.SetTriggerRequestValues(req.Spec.HTTP.Request.PreRequest.TriggerRequest)
 			//	r.PreRequest.SetCode(req.Spec.HTTP.Request.PreRequest.Script)
 		}
 
@@ -83,11 +90,19 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 		if req.Spec.HTTP.Request.PostRequest.PostRequestSet != (domain.PostRequestSet{}) {
 			r.PostRequest.SetPostRequestSetValues(req.Spec.HTTP.Request.PostRequest.PostRequestSet)
 		}
+		r.PostRequest.SetNextRequestValues(req.Spec.HTTP.Request.PostRequest.NextRequest)
 	}
 
 	return r
 }
 
+func (r *Request) SetVariableHints(hints *widgets.VariableHints) {
+	r.Params.SetVariableHints(hints)
+	r.Headers.SetVariableHints(hints)
+	r.Body.SetVariableHints(hints)
+	r.Variables.SetVariableHints(hints)
+}
+
 func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	inset := layout.Inset{Top: unit.Dp(10)}
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -117,6 +132,10 @@ func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Di
 					return r.Headers.Layout(gtx, theme)
 				case "Auth":
 					return r.Auth.Layout(gtx, theme)
+				case "Variables":
+					return layout.Inset{Top: unit.Dp(15), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return r.Variables.WithAddLayout(gtx, "Variables", "Override the collection and environment variables", theme)
+					})
 				case "Body":
 					return r.Body.Layout(gtx, theme)
 				default:
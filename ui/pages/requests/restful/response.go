@@ -6,12 +6,14 @@ import (
 	"time"
 
 	"gioui.org/layout"
+	"gioui.org/op/paint"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"github.com/dustin/go-humanize"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/rest"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/pages/requests/component"
 	"github.com/chapar-rest/chapar/ui/widgets"
@@ -21,7 +23,9 @@ type Response struct {
 	copyButton *widgets.FlatButton
 	Tabs       *widgets.Tabs
 
-	copyClickable widget.Clickable
+	copyClickable        widget.Clickable
+	saveExampleClickable widget.Clickable
+	saveBodyClickable    widget.Clickable
 
 	responseCode int
 	duration     time.Duration
@@ -30,17 +34,53 @@ type Response struct {
 	responseHeaders *widgets.CodeEditor
 	responseCookies *widgets.CodeEditor
 
+	headers []domain.KeyValue
+	cookies []domain.KeyValue
+
 	response string
 	message  string
 	err      error
 
+	// body is the body as received, response is its text.
+	body        []byte
+	contentType string
+	kind        string
+	// viewText is the text shown by the text viewer in the selected view.
+	viewText  string
+	truncated bool
+	showXML   bool
+	image     *paint.ImageOp
+	imageErr  error
+
 	onCopyResponse func(gtx layout.Context, dataType, data string)
+	onSaveExample  func(example domain.HTTPResponse)
+	onSaveBody     func(body []byte, contentType string)
 
 	isResponseUpdated   bool
 	responseIsAvailable bool
 	jsonViewer          *widgets.JsonViewer
+	xmlViewer           *widgets.XMLViewer
+	viewDropDown        *widgets.DropDown
+	views               int
 }
 
+// Views of the body, the ones offered depend on the kind of the content.
+const (
+	viewPretty  = "pretty"
+	viewRaw     = "raw"
+	viewPreview = "preview"
+	viewHex     = "hex"
+)
+
+const (
+	// maxTextViewSize is the part of a body shown as text, larger ones are truncated to keep the ui responsive.
+	maxTextViewSize = 1 << 20
+	// maxHexViewSize is the part of a body shown in the hex view.
+	maxHexViewSize = 64 << 10
+	// maxImageSize is the largest image body which is decoded for the preview.
+	maxImageSize = 32 << 20
+)
+
 func NewResponse(theme *chapartheme.Theme) *Response {
 	r := &Response{
 		copyButton: &widgets.FlatButton{
@@ -59,12 +99,18 @@ func NewResponse(theme *chapartheme.Theme) *Response {
 			{Title: "Cookies"},
 		}, nil),
 		jsonViewer:      widgets.NewJsonViewer(),
+		xmlViewer:       widgets.NewXMLViewer(),
+		viewDropDown:    widgets.NewDropDown(theme),
 		responseHeaders: widgets.NewCodeEditor("", widgets.CodeLanguageProperties, theme),
 		responseCookies: widgets.NewCodeEditor("", widgets.CodeLanguageProperties, theme),
 	}
 
 	r.responseHeaders.SetReadOnly(true)
 	r.responseCookies.SetReadOnly(true)
+	r.viewDropDown.MaxWidth = unit.Dp(110)
+	r.viewDropDown.SetOnChanged(func(_ string) {
+		r.isResponseUpdated = false
+	})
 
 	return r
 }
@@ -73,6 +119,16 @@ func (r *Response) SetOnCopyResponse(f func(gtx layout.Context, dataType, data s
 	r.onCopyResponse = f
 }
 
+// SetOnSaveExample sets the function called with the current response when the user saves it as an example.
+func (r *Response) SetOnSaveExample(f func(example domain.HTTPResponse)) {
+	r.onSaveExample = f
+}
+
+// SetOnSaveBody sets the function called with the body when the user saves it to a file.
+func (r *Response) SetOnSaveBody(f func(body []byte, contentType string)) {
+	r.onSaveBody = f
+}
+
 func (r *Response) SetResponse(response string) {
 	r.response = response
 	r.err = nil
@@ -81,6 +137,34 @@ func (r *Response) SetResponse(response string) {
 	r.responseIsAvailable = true
 }
 
+// SetBody sets the body as received, the viewers offered for it depend on its content type.
+func (r *Response) SetBody(body []byte, contentType string) {
+	r.body = body
+	r.contentType = contentType
+	r.kind = rest.DetectContent(contentType, body)
+	r.image, r.imageErr = nil, nil
+	r.isResponseUpdated = false
+
+	var views []*widgets.DropDownOption
+	switch r.kind {
+	case rest.ContentJSON, rest.ContentXML:
+		views = append(views, widgets.NewDropDownOption("Pretty").WithValue(viewPretty), widgets.NewDropDownOption("Raw").WithValue(viewRaw))
+	case rest.ContentHTML:
+		views = append(views, widgets.NewDropDownOption("Preview").WithValue(viewPreview), widgets.NewDropDownOption("Source").WithValue(viewRaw))
+	case rest.ContentImage:
+		views = append(views, widgets.NewDropDownOption("Preview").WithValue(viewPreview), widgets.NewDropDownOption("Hex").WithValue(viewHex))
+	case rest.ContentPDF, rest.ContentBinary:
+		views = append(views, widgets.NewDropDownOption("Hex").WithValue(viewHex))
+	default:
+		views = append(views, widgets.NewDropDownOption("Raw").WithValue(viewRaw))
+	}
+	// keep the view of the previous response if it is offered for this one
+	previous := r.viewDropDown.GetSelected().GetValue()
+	r.viewDropDown.SetOptions(views...)
+	r.viewDropDown.SetSelectedByValue(previous)
+	r.views = len(views)
+}
+
 func (r *Response) SetStatusParams(code int, duration time.Duration, size int) {
 	r.responseCode = code
 	r.duration = duration
@@ -88,6 +172,7 @@ func (r *Response) SetStatusParams(code int, duration time.Duration, size int) {
 }
 
 func (r *Response) SetHeaders(headers []domain.KeyValue) {
+	r.headers = headers
 	r.responseHeaders.SetCode(domain.KeyValuesToText(headers))
 }
 
@@ -100,6 +185,7 @@ func (r *Response) SetError(err error) {
 }
 
 func (r *Response) SetCookies(cookies []domain.KeyValue) {
+	r.cookies = cookies
 	r.responseCookies.SetCode(domain.KeyValuesToText(cookies))
 }
 
@@ -120,6 +206,19 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 		r.handleCopy(gtx)
 	}
 
+	if r.saveBodyClickable.Clicked(gtx) && r.onSaveBody != nil {
+		r.onSaveBody(r.body, r.contentType)
+	}
+
+	if r.saveExampleClickable.Clicked(gtx) && r.onSaveExample != nil {
+		r.onSaveExample(domain.HTTPResponse{
+			StatusCode: r.responseCode,
+			Headers:    append([]domain.KeyValue(nil), r.headers...),
+			Body:       r.response,
+			Cookies:    append([]domain.KeyValue(nil), r.cookies...),
+		})
+	}
+
 	inset := layout.Inset{Top: unit.Dp(10)}
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{
@@ -142,6 +241,33 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 							return l.Layout(gtx)
 						})
 					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if r.Tabs.Selected() != 0 || r.views < 2 {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Right: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return r.viewDropDown.Layout(gtx, theme)
+						})
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if r.onSaveBody == nil {
+							return layout.Dimensions{}
+						}
+						btn := widgets.Button(theme.Material(), &r.saveBodyClickable, widgets.FileFolderIcon, widgets.IconPositionStart, "Save to file")
+						btn.Color = theme.ButtonTextColor
+						return layout.Inset{Right: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return btn.Layout(gtx, theme)
+						})
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if r.onSaveExample == nil {
+							return layout.Dimensions{}
+						}
+						btn := widgets.Button(theme.Material(), &r.saveExampleClickable, widgets.SaveIcon, widgets.IconPositionStart, "Save as example")
+						btn.Color = theme.ButtonTextColor
+						return btn.Layout(gtx, theme)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						btn := widgets.Button(theme.Material(), &r.copyClickable, widgets.CopyIcon, widgets.IconPositionStart, "Copy")
 						btn.Color = theme.ButtonTextColor
@@ -157,12 +283,7 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 					return r.responseCookies.Layout(gtx, theme, "")
 				default:
 					return layout.Inset{Left: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-						if !r.isResponseUpdated {
-							r.jsonViewer.SetData(r.response)
-							r.isResponseUpdated = true
-						}
-
-						return r.jsonViewer.Layout(gtx, theme)
+						return r.bodyLayout(gtx, theme)
 					})
 				}
 			}),
@@ -170,6 +291,106 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 	})
 }
 
+// bodyLayout lays out the body in the selected view, the viewer is updated when the body or the view changes.
+func (r *Response) bodyLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	view := r.viewDropDown.GetSelected().GetValue()
+	if !r.isResponseUpdated {
+		r.updateView(view)
+		r.isResponseUpdated = true
+	}
+
+	switch {
+	case r.showXML:
+		return r.xmlViewer.Layout(gtx, theme)
+	case view == viewPreview && r.kind == rest.ContentImage:
+		if r.imageErr != nil {
+			return component.Message(gtx, component.MessageTypeWarning, theme, r.imageErr.Error())
+		}
+		return widget.Image{Src: *r.image, Fit: widget.ScaleDown, Position: layout.N}.Layout(gtx)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			note := ""
+			switch {
+			case r.kind == rest.ContentPDF:
+				note = "PDF document, save it to a file to open it. "
+			case r.kind == rest.ContentBinary:
+				note = "Binary content, save it to a file to open it. "
+			}
+			if r.truncated {
+				limit := maxTextViewSize
+				if view == viewHex {
+					limit = maxHexViewSize
+				}
+				note += fmt.Sprintf("Showing the first %s of %s.", humanize.Bytes(uint64(limit)), humanize.Bytes(uint64(len(r.body))))
+			}
+			if note == "" {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, material.Body2(theme.Material(), note).Layout)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return r.jsonViewer.Layout(gtx, theme)
+		}),
+	)
+}
+
+func (r *Response) updateView(view string) {
+	r.viewText, r.truncated, r.showXML = "", false, false
+
+	switch view {
+	case viewHex:
+		r.truncated = len(r.body) > maxHexViewSize
+		r.viewText = rest.HexDump(r.body, maxHexViewSize)
+	case viewPreview:
+		if r.kind == rest.ContentHTML {
+			r.viewText = rest.HTMLText(r.body)
+		} else if r.image == nil && r.imageErr == nil {
+			r.decodeImage()
+		}
+	case viewPretty:
+		if r.kind == rest.ContentJSON {
+			r.viewText = r.response
+		} else if nodes, err := rest.ParseXML(r.body); err == nil && len(r.body) <= maxTextViewSize {
+			r.xmlViewer.SetNodes(nodes)
+			r.showXML = true
+		} else {
+			r.viewText = r.text()
+		}
+	default:
+		r.viewText = r.text()
+	}
+
+	if len(r.viewText) > maxTextViewSize {
+		r.viewText, r.truncated = r.viewText[:maxTextViewSize], true
+	}
+	r.jsonViewer.SetData(r.viewText)
+}
+
+// text returns the body as it is received, or the response when there is no body.
+func (r *Response) text() string {
+	if len(r.body) == 0 {
+		return r.response
+	}
+	return string(r.body)
+}
+
+func (r *Response) decodeImage() {
+	if len(r.body) > maxImageSize {
+		r.imageErr = fmt.Errorf("image is too large to preview, %s", humanize.Bytes(uint64(len(r.body))))
+		return
+	}
+
+	img, err := rest.DecodeImage(r.body)
+	if err != nil {
+		r.imageErr = fmt.Errorf("failed to decode the image, %w", err)
+		return
+	}
+	op := paint.NewImageOp(img)
+	r.image = &op
+}
+
 func formatStatus(statueCode int, duration time.Duration, size uint64) string {
 	return fmt.Sprintf("%d %s, %s, %s", statueCode, http.StatusText(statueCode), duration, humanize.Bytes(size))
 }
@@ -181,6 +402,10 @@ func (r *Response) handleCopy(gtx layout.Context) {
 	case 2:
 		r.onCopyResponse(gtx, "Cookies", r.responseCookies.Code())
 	default:
-		r.onCopyResponse(gtx, "Response", r.response)
+		if r.kind == rest.ContentJSON || r.kind == rest.ContentText {
+			r.onCopyResponse(gtx, "Response", r.response)
+			return
+		}
+		r.onCopyResponse(gtx, "Response", r.viewText)
 	}
 }
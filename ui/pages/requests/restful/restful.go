@@ -5,8 +5,10 @@ import (
 	"gioui.org/unit"
 	giox "gioui.org/x/component"
 
+	"github.com/chapar-rest/chapar/internal/curl"
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
 	"github.com/chapar-rest/chapar/ui/explorer"
 	"github.com/chapar-rest/chapar/ui/pages/requests/component"
 	"github.com/chapar-rest/chapar/ui/widgets"
@@ -24,9 +26,13 @@ type Restful struct {
 
 	split widgets.SplitView
 
+	// hints are the variables known to the request, shared by its editors
+	hints *widgets.VariableHints
+
 	onSave        func(id string)
 	onDataChanged func(id string, data any)
 	onSubmit      func(id string)
+	onCurlPasted  func(id, command string)
 }
 
 func New(req *domain.Request, theme *chapartheme.Theme, explorer *explorer.Explorer) *Restful {
@@ -43,7 +49,10 @@ func New(req *domain.Request, theme *chapartheme.Theme, explorer *explorer.Explo
 		},
 		Response: NewResponse(theme),
 		Request:  NewRequest(req, explorer, theme),
+		hints:    &widgets.VariableHints{},
 	}
+	r.AddressBar.SetVariableHints(r.hints)
+	r.Request.SetVariableHints(r.hints)
 	r.setupHooks()
 
 	return r
@@ -53,10 +62,22 @@ func (r *Restful) SetPreRequestCollections(collections []*domain.Collection, sel
 	r.Request.PreRequest.SetCollections(collections, selectedID)
 }
 
+func (r *Restful) SetNextRequests(requests []*domain.Request, selectedID string) {
+	r.Request.PostRequest.SetNextRequests(requests, selectedID)
+}
+
+func (r *Restful) SetPrerequisites(requests []*domain.Request, prerequisites []domain.TriggerRequest) {
+	r.Request.PreRequest.SetPrerequisites(requests, prerequisites)
+}
+
 func (r *Restful) SetPreRequestRequests(requests []*domain.Request, selectedID string) {
 	r.Request.PreRequest.SetRequests(requests, selectedID)
 }
 
+func (r *Restful) SetOnClearPrerequisiteCache(f func()) {
+	r.Request.PreRequest.SetOnClearCache(f)
+}
+
 func (r *Restful) SetOnSetOnTriggerRequestChanged(f func(id, collectionID, requestID string)) {
 	r.Request.PreRequest.SetOnTriggerRequestChanged(func(collectionID, requestID string) {
 		f(r.Req.MetaData.ID, collectionID, requestID)
@@ -85,6 +106,23 @@ func (r *Restful) SetOnSubmit(f func(id string)) {
 	r.onSubmit = f
 }
 
+// SetOnCurlPasted sets the callback called instead of changing the url when a curl command is pasted to the address bar.
+func (r *Restful) SetOnCurlPasted(f func(id, command string)) {
+	r.onCurlPasted = f
+}
+
+func (r *Restful) SetOnDescribeVariable(f func(id, name string) string) {
+	r.hints.Describe = func(name string) string {
+		return f(r.Req.MetaData.ID, name)
+	}
+}
+
+func (r *Restful) SetOnListVariables(f func(id string) []widgets.Completion) {
+	r.hints.Names = func() []widgets.Completion {
+		return f(r.Req.MetaData.ID)
+	}
+}
+
 func (r *Restful) SetURL(url string) {
 	r.AddressBar.SetURL(url)
 }
@@ -121,6 +159,12 @@ func (r *Restful) SetOnCopyResponse(f func(gtx layout.Context, dataType, data st
 	r.Response.SetOnCopyResponse(f)
 }
 
+func (r *Restful) SetOnSaveResponseBody(f func(id string, body []byte, contentType string)) {
+	r.Response.SetOnSaveBody(func(body []byte, contentType string) {
+		f(r.Req.MetaData.ID, body, contentType)
+	})
+}
+
 func (r *Restful) SetHTTPResponse(detail domain.HTTPResponseDetail) {
 	if detail.Error != nil {
 		r.Response.SetError(detail.Error)
@@ -128,6 +172,11 @@ func (r *Restful) SetHTTPResponse(detail domain.HTTPResponseDetail) {
 	}
 
 	r.Response.SetResponse(detail.Response)
+	body := detail.Body
+	if body == nil {
+		body = []byte(detail.Response)
+	}
+	r.Response.SetBody(body, detail.ContentType)
 	r.Response.SetHeaders(detail.Headers)
 	r.Response.SetCookies(detail.Cookies)
 	r.Response.SetStatusParams(detail.StatusCode, detail.Duration, detail.Size)
@@ -168,6 +217,13 @@ func (r *Restful) HidePrompt() {
 }
 
 func (r *Restful) setupHooks() {
+	r.Response.SetOnSaveExample(func(example domain.HTTPResponse) {
+		// copy the examples as the slice may be shared with the request in the state
+		responses := append([]domain.HTTPResponse(nil), r.Req.Spec.HTTP.Responses...)
+		r.Req.Spec.HTTP.Responses = append(responses, example)
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Breadcrumb.SetOnSave(func(id string) {
 		r.onSave(id)
 	})
@@ -179,6 +235,11 @@ func (r *Restful) setupHooks() {
 	})
 
 	r.AddressBar.SetOnURLChanged(func(url string) {
+		if r.onCurlPasted != nil && curl.IsCommand(url) {
+			r.onCurlPasted(r.Req.MetaData.ID, url)
+			return
+		}
+
 		r.Req.Spec.HTTP.URL = url
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
@@ -198,6 +259,11 @@ func (r *Restful) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.Variables.SetOnChanged(func(items []*widgets.KeyValueItem) {
+		r.Req.Spec.HTTP.Request.Variables = converter.KeyValueFromWidgetItems(items)
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Request.Body.BinaryFile.SetOnChanged(func(filePath string) {
 		r.Req.Spec.HTTP.Request.Body.BinaryFilePath = filePath
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
@@ -213,6 +279,17 @@ func (r *Restful) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.PreRequest.SetOnPrerequisitesChanged(func(alias, cacheFor string, prerequisites []domain.TriggerRequest) {
+		trigger := &domain.TriggerRequest{CollectionID: domain.PrePostTypeNone}
+		if r.Req.Spec.HTTP.Request.PreRequest.TriggerRequest != nil {
+			*trigger = *r.Req.Spec.HTTP.Request.PreRequest.TriggerRequest
+		}
+		trigger.Alias, trigger.CacheFor = alias, cacheFor
+		r.Req.Spec.HTTP.Request.PreRequest.TriggerRequest = trigger
+		r.Req.Spec.HTTP.Request.PreRequest.TriggerRequests = prerequisites
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	// r.Request.PreRequest.SetOnScriptChanged(func(code string) {
 	//	r.Req.Spec.HTTP.Request.PreRequest.Script = code
 	//	r.onDataChanged(r.Req.MetaData.ID, r.Req)
@@ -223,6 +300,11 @@ func (r *Restful) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.PostRequest.SetOnNextRequestChanged(func(statusCode int, requestID string) {
+		r.Req.Spec.HTTP.Request.PostRequest.NextRequest = &domain.NextRequest{StatusCode: statusCode, RequestID: requestID}
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Request.PostRequest.SetOnScriptChanged(func(code string) {
 		r.Req.Spec.HTTP.Request.PostRequest.Script = code
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
@@ -0,0 +1,450 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/reporter"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Runner runs a request or the requests of a collection once per row of the iteration data and shows
+// the progress of the run and the result of each request.
+type Runner struct {
+	id   string
+	name string
+
+	delay         *widgets.LabeledInput
+	retries       *widgets.LabeledInput
+	retryStatuses *widgets.LabeledInput
+	backoff       *widgets.LabeledInput
+	stopOnFailure widget.Bool
+
+	requests          []*runRequest
+	requestsList      widget.List
+	progress          string
+	progressIteration int
+
+	runButton       widget.Clickable
+	dataButton      widget.Clickable
+	clearDataButton widget.Clickable
+	exportButton    widget.Clickable
+	running         bool
+
+	reporters *widgets.DropDown
+
+	dataName string
+	dataRows int
+
+	results *widgets.CodeEditor
+
+	prompt *widgets.Prompt
+
+	onRun        func(id string, opts egress.RunOptions)
+	onStop       func(id string)
+	onSelectData func(id string)
+	onClearData  func(id string)
+	onExport     func(id, reporter string)
+}
+
+func New(id, name string, theme *chapartheme.Theme) *Runner {
+	options := make([]*widgets.DropDownOption, 0)
+	for _, name := range reporter.Names() {
+		options = append(options, widgets.NewDropDownOption(name).WithValue(name))
+	}
+
+	input := func(label, text, hint string) *widgets.LabeledInput {
+		l := &widgets.LabeledInput{
+			Label:          label,
+			SpaceBetween:   5,
+			MinEditorWidth: unit.Dp(80),
+			MinLabelWidth:  unit.Dp(60),
+			Editor:         widgets.NewPatternEditor(),
+			Hint:           hint,
+		}
+		l.SetText(text)
+		return l
+	}
+
+	r := &Runner{
+		id:            id,
+		name:          name,
+		delay:         input("Delay", "0s", "e.g. 500ms"),
+		retries:       input("Retries", "0", "e.g. 3"),
+		retryStatuses: input("Retry on", "502, 503, 504", "statuses"),
+		backoff:       input("Backoff", "1s", "e.g. 1s"),
+		requestsList: widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+		reporters: widgets.NewDropDown(theme, options...),
+		results:   widgets.NewCodeEditor("", widgets.CodeLanguageYAML, theme),
+		prompt:    widgets.NewPrompt("", "", ""),
+	}
+	r.reporters.MaxWidth = unit.Dp(100)
+	r.results.SetReadOnly(true)
+	r.prompt.WithoutRememberBool()
+	return r
+}
+
+// runRequest is a request of the run, it can be skipped or be one of the only requests run.
+type runRequest struct {
+	id   string
+	name string
+
+	skip widget.Bool
+	only widget.Bool
+
+	running bool
+	status  string
+	failed  bool
+}
+
+func (r *Runner) SetOnRun(f func(id string, opts egress.RunOptions)) {
+	r.onRun = f
+}
+
+func (r *Runner) SetOnStop(f func(id string)) {
+	r.onStop = f
+}
+
+func (r *Runner) SetOnSelectData(f func(id string)) {
+	r.onSelectData = f
+}
+
+func (r *Runner) SetOnClearData(f func(id string)) {
+	r.onClearData = f
+}
+
+// SetOnExport sets the function exporting the results of the last run with the reporter.
+func (r *Runner) SetOnExport(f func(id, reporter string)) {
+	r.onExport = f
+}
+
+func (r *Runner) SetRunning(running bool) {
+	r.running = running
+	if running {
+		return
+	}
+
+	for _, req := range r.requests {
+		req.running = false
+	}
+	if r.progress != "" {
+		r.progress = "Finished"
+	}
+}
+
+// SetData shows the iteration data file, an empty name means there is none.
+func (r *Runner) SetData(name string, rows int) {
+	r.dataName = name
+	r.dataRows = rows
+}
+
+// SetRequests sets the requests of the run in the order they are run, the toggles of the requests which
+// were already listed are kept.
+func (r *Runner) SetRequests(requests []*domain.Request) {
+	existing := make(map[string]*runRequest, len(r.requests))
+	for _, req := range r.requests {
+		existing[req.id] = req
+	}
+
+	r.requests = make([]*runRequest, 0, len(requests))
+	for _, req := range requests {
+		item, ok := existing[req.MetaData.ID]
+		if !ok {
+			item = &runRequest{id: req.MetaData.ID}
+		}
+		item.name = req.MetaData.Name
+		r.requests = append(r.requests, item)
+	}
+}
+
+// SetProgress shows the request which is running in the iteration, the results of the previous iteration
+// are cleared when a new one starts.
+func (r *Runner) SetProgress(iteration, iterations int, requestID string) {
+	newIteration := iteration != r.progressIteration
+	r.progressIteration = iteration
+
+	for _, req := range r.requests {
+		req.running = req.id == requestID
+		if newIteration {
+			req.status, req.failed = "", false
+		}
+		if req.running {
+			r.progress = fmt.Sprintf("Iteration %d of %d, running %s", iteration+1, iterations, req.name)
+		}
+	}
+}
+
+// SetRequestResult shows the status of the request in the list of requests.
+func (r *Runner) SetRequestResult(result *egress.RequestResult) {
+	for _, req := range r.requests {
+		if req.id == result.RequestID {
+			req.running = false
+			req.status = fmt.Sprintf("%s  %s", result.Status, result.Duration.Round(time.Millisecond))
+			if result.Attempts > 1 {
+				req.status += fmt.Sprintf(" (%d attempts)", result.Attempts)
+			}
+			req.failed = result.Failed
+		}
+	}
+}
+
+func (r *Runner) ClearResults() {
+	r.results.SetCode("")
+	r.progress = ""
+	r.progressIteration = 0
+	for _, req := range r.requests {
+		req.running, req.status, req.failed = false, "", false
+	}
+}
+
+func (r *Runner) AddResult(line string) {
+	r.results.SetCode(r.results.Code() + line + "\n")
+}
+
+// The runner has no data to save, the container methods are no-ops.
+
+func (r *Runner) SetOnDataChanged(func(id string, data any)) {}
+func (r *Runner) SetOnTitleChanged(func(title string))       {}
+func (r *Runner) SetDataChanged(bool)                        {}
+func (r *Runner) SetOnSave(func(id string))                  {}
+
+func (r *Runner) ShowPrompt(title, content, modalType string, onSubmit func(selectedOption string, remember bool), options ...widgets.Option) {
+	r.prompt.Type = modalType
+	r.prompt.Title = title
+	r.prompt.Content = content
+	r.prompt.SetOptions(options...)
+	r.prompt.WithoutRememberBool()
+	r.prompt.SetOnSubmit(onSubmit)
+	r.prompt.Show()
+}
+
+func (r *Runner) HidePrompt() {
+	r.prompt.Hide()
+}
+
+// options returns the run options of the inputs and toggles.
+func (r *Runner) options() (egress.RunOptions, error) {
+	var opts egress.RunOptions
+	var err error
+
+	for _, d := range []struct {
+		input *widgets.LabeledInput
+		value *time.Duration
+	}{{r.delay, &opts.Delay}, {r.backoff, &opts.Backoff}} {
+		if text := strings.TrimSpace(d.input.Text()); text != "" {
+			if *d.value, err = time.ParseDuration(text); err != nil {
+				return opts, fmt.Errorf("invalid %s, %w", strings.ToLower(d.input.Label), err)
+			}
+		}
+	}
+
+	if text := strings.TrimSpace(r.retries.Text()); text != "" {
+		if opts.Retries, err = strconv.Atoi(text); err != nil || opts.Retries < 0 {
+			return opts, fmt.Errorf("invalid retries, %s", text)
+		}
+	}
+
+	for _, status := range strings.Split(r.retryStatuses.Text(), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			opts.RetryStatuses = append(opts.RetryStatuses, status)
+		}
+	}
+
+	opts.StopOnFailure = r.stopOnFailure.Value
+	for _, req := range r.requests {
+		if req.skip.Value {
+			opts.Skip = append(opts.Skip, req.id)
+		}
+		if req.only.Value {
+			opts.Only = append(opts.Only, req.id)
+		}
+	}
+	return opts, nil
+}
+
+func (r *Runner) handleClicks(gtx layout.Context) {
+	if r.runButton.Clicked(gtx) {
+		if r.running {
+			if r.onStop != nil {
+				go r.onStop(r.id)
+			}
+		} else if r.onRun != nil {
+			r.ClearResults()
+			if opts, err := r.options(); err != nil {
+				r.results.SetCode(err.Error())
+			} else {
+				go r.onRun(r.id, opts)
+			}
+		}
+	}
+
+	if r.dataButton.Clicked(gtx) && r.onSelectData != nil {
+		go r.onSelectData(r.id)
+	}
+
+	if r.exportButton.Clicked(gtx) && r.onExport != nil {
+		go r.onExport(r.id, r.reporters.GetSelected().GetValue())
+	}
+
+	if r.clearDataButton.Clicked(gtx) && r.onClearData != nil {
+		r.SetData("", 0)
+		go r.onClearData(r.id)
+	}
+}
+
+func (r *Runner) button(gtx layout.Context, theme *chapartheme.Theme, clickable *widget.Clickable, icon *widget.Icon, title string) layout.Dimensions {
+	btn := widgets.Button(theme.Material(), clickable, icon, widgets.IconPositionStart, title)
+	btn.Color = theme.ButtonTextColor
+	return btn.Layout(gtx, theme)
+}
+
+func (r *Runner) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	r.handleClicks(gtx)
+
+	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return r.prompt.Layout(gtx, theme)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return material.Label(theme.Material(), theme.TextSize, "Run "+r.name+" with the selected environment, once per row of the "+
+						"iteration data with its columns as variables. The requests run in the order of the collection, drag them in the tree to reorder them.").Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				title := "Run"
+				if r.running {
+					title = "Stop"
+				}
+
+				data := "No iteration data, the requests run once"
+				if r.dataName != "" {
+					data = fmt.Sprintf("%s, %d iterations", r.dataName, r.dataRows)
+				}
+
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.runButton, nil, title)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.dataButton, widgets.UploadIcon, "Iteration data")
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if r.dataName == "" {
+								return layout.Dimensions{}
+							}
+							return r.button(gtx, theme, &r.clearDataButton, widgets.DeleteIcon, "Clear")
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return material.Label(theme.Material(), theme.TextSize, data).Layout(gtx)
+						}),
+						layout.Flexed(1, layout.Spacer{}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.reporters.Layout(gtx, theme)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.button(gtx, theme, &r.exportButton, widgets.SaveIcon, "Export report")
+						}),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return r.settingsLayout(gtx, theme)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if r.progress == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, material.Label(theme.Material(), theme.TextSize, r.progress).Layout)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(0.4, func(gtx layout.Context) layout.Dimensions {
+						return layout.Inset{Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return r.requestsLayout(gtx, theme)
+						})
+					}),
+					layout.Flexed(0.6, func(gtx layout.Context) layout.Dimensions {
+						return r.results.Layout(gtx, theme, "")
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (r *Runner) settingsLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	inputs := []*widgets.LabeledInput{r.delay, r.retries, r.retryStatuses, r.backoff}
+
+	children := make([]layout.FlexChild, 0, len(inputs)*2+1)
+	for _, in := range inputs {
+		children = append(children,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return in.Layout(gtx, theme)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+		)
+	}
+	children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return widgets.CheckBox(theme.Material(), &r.stopOnFailure, "Stop on failure").Layout(gtx)
+	}))
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+// requestsLayout lists the requests in the order they are run with their toggles and their status in the
+// current iteration.
+func (r *Runner) requestsLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return material.List(theme.Material(), &r.requestsList).Layout(gtx, len(r.requests), func(gtx layout.Context, i int) layout.Dimensions {
+		req := r.requests[i]
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return widgets.CheckBox(theme.Material(), &req.skip, "Skip").Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return widgets.CheckBox(theme.Material(), &req.only, "Only").Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), theme.TextSize, fmt.Sprintf("%d. %s", i+1, req.name))
+				lb.MaxLines = 1
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				status := req.status
+				if req.running {
+					status = "running"
+				}
+				lb := material.Label(theme.Material(), theme.TextSize, status)
+				switch {
+				case req.running:
+					lb.Color = theme.WarningColor
+				case req.failed:
+					lb.Color = theme.ErrorColor
+				case req.status != "":
+					lb.Color = theme.ResponseStatusColor
+				}
+				return lb.Layout(gtx)
+			}),
+		)
+	})
+}
@@ -2,9 +2,12 @@ package requests
 
 import (
 	"image"
+	"io"
+	"strings"
 	"time"
 
 	"gioui.org/app"
+	"gioui.org/io/clipboard"
 	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/unit"
@@ -17,11 +20,17 @@ import (
 	"github.com/chapar-rest/chapar/ui/pages/requests/grpc"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/loadtest"
+	"github.com/chapar-rest/chapar/internal/mock"
 	"github.com/chapar-rest/chapar/internal/safemap"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/keys"
 	"github.com/chapar-rest/chapar/ui/pages/requests/collections"
+	"github.com/chapar-rest/chapar/ui/pages/requests/loadrunner"
+	"github.com/chapar-rest/chapar/ui/pages/requests/recorder"
 	"github.com/chapar-rest/chapar/ui/pages/requests/restful"
+	"github.com/chapar-rest/chapar/ui/pages/requests/runner"
 	"github.com/chapar-rest/chapar/ui/pages/tips"
 	"github.com/chapar-rest/chapar/ui/widgets"
 )
@@ -32,6 +41,13 @@ const (
 	MenuAddHTTPRequest = "Add HTTP Request"
 	MenuAddGRPCRequest = "Add GRPC Request"
 	MenuView           = "View"
+	MenuCopyAsCurl     = "Copy as cURL"
+	MenuCopyAsGrpcurl  = "Copy as grpcurl"
+	MenuGenerateCode   = "Generate code"
+	MenuExportPostman  = "Export to Postman"
+	MenuExportOpenAPI  = "Export to OpenAPI"
+	MenuLoadTest       = "Load test"
+	MenuRun            = "Run"
 )
 
 type View struct {
@@ -40,9 +56,16 @@ type View struct {
 
 	// modal is used to show error and messages to the user
 	modal *widgets.MessageModal
+	// selectModal lets the user pick the items to import
+	selectModal *widgets.SelectModal
+	// codeModal shows the code generated for a request
+	codeModal *widgets.CodeModal
 
 	notify *widgets.Notification
 
+	// clipboardText is written to the clipboard on the next frame
+	clipboardText string
+
 	// add menu
 	newRequestButton     widget.Clickable
 	importButton         widget.Clickable
@@ -52,6 +75,7 @@ type View struct {
 	newHttpRequestButton widget.Clickable
 	newGrpcRequestButton widget.Clickable
 	newCollectionButton  widget.Clickable
+	recordTrafficButton  widget.Clickable
 
 	treeViewSearchBox *widgets.TextField
 	treeView          *widgets.TreeView
@@ -64,10 +88,25 @@ type View struct {
 	onNewRequest                   func(requestType string)
 	onImport                       func()
 	onNewCollection                func()
+	onOpenRecorder                 func()
+	onRecorderStart                func(addr string)
+	onRecorderStop                 func()
+	onRecorderSaveCA               func()
+	onRecorderClear                func()
+	onRecorderSave                 func(collectionID string, exchangeIDs []string)
+	onLoadTestStart                func(id string, opts loadtest.Options)
+	onLoadTestStop                 func(id string)
+	onLoadTestSaveReport           func(id, format string)
+	onRun                          func(id string, opts egress.RunOptions)
+	onRunStop                      func(id string)
+	onRunSelectData                func(id string)
+	onRunClearData                 func(id string)
+	onRunExport                    func(id, reporter string)
 	onTabClose                     func(id string)
 	onTreeViewNodeDoubleClicked    func(id string)
 	onTreeViewNodeClicked          func(id string)
 	onTreeViewMenuClicked          func(id string, action string)
+	onTreeViewNodeMoved            func(parentID, id string, index int)
 	onTabSelected                  func(id string)
 	onSave                         func(id string)
 	onSubmit                       func(id, containerType string)
@@ -75,12 +114,22 @@ type View struct {
 	onCopyResponse                 func(gtx layout.Context, dataType, data string)
 	onOnPostRequestSetChanged      func(id string, statusCode int, item, from, fromKey string)
 	onOnSetOnTriggerRequestChanged func(id, collectionID, requestID string)
+	onClearPrerequisiteCache       func()
 	onBinaryFileSelect             func(id string)
 	onFromDataFileSelect           func(requestID, fieldID string)
 	onServerInfoReload             func(id string)
 	onGrpcInvoke                   func(id string)
 	onGrpcLoadRequestExample       func(id string)
+	onGrpcBodyCompletion           func(id, body string, caret int) (string, []widgets.Completion)
+	onDescribeVariable             func(id, name string) string
+	onListVariables                func(id string) []widgets.Completion
+	onGrpcDiagnose                 func(id string)
+	onGrpcWatchHealth              func(id string, watch bool)
+	onMockStart                    func(id, addr string, isGRPC bool, opts mock.Options)
+	onMockStop                     func(id string)
 	onRequestTabChanged            func(id string, tab string)
+	onCurlPasted                   func(id, command string)
+	onSaveResponseBody             func(id string, body []byte, contentType string)
 
 	// state
 	containers    *safemap.Map[Container]
@@ -134,6 +183,22 @@ func NewView(w *app.Window, theme *chapartheme.Theme, explorer *explorer.Explore
 	return v
 }
 
+func (v *View) SetOnDescribeVariable(f func(id, name string) string) {
+	v.onDescribeVariable = f
+}
+
+func (v *View) SetOnListVariables(f func(id string) []widgets.Completion) {
+	v.onListVariables = f
+}
+
+func (v *View) SetOnCurlPasted(f func(id, command string)) {
+	v.onCurlPasted = f
+}
+
+func (v *View) SetOnSaveResponseBody(f func(id string, body []byte, contentType string)) {
+	v.onSaveResponseBody = f
+}
+
 func (v *View) SetOnRequestTabChange(f func(id string, tab string)) {
 	v.onRequestTabChanged = f
 }
@@ -164,10 +229,40 @@ func (v *View) SetPreRequestRequests(id string, requests []*domain.Request, sele
 	}
 }
 
+func (v *View) SetNextRequests(id string, requests []*domain.Request, selectedID string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(RestContainer); ok {
+			ct.SetNextRequests(requests, selectedID)
+			return
+		}
+
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetNextRequests(requests, selectedID)
+		}
+	}
+}
+
+func (v *View) SetPrerequisites(id string, requests []*domain.Request, prerequisites []domain.TriggerRequest) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(RestContainer); ok {
+			ct.SetPrerequisites(requests, prerequisites)
+			return
+		}
+
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetPrerequisites(requests, prerequisites)
+		}
+	}
+}
+
 func (v *View) SetOnSetOnTriggerRequestChanged(f func(id, collectionID, requestID string)) {
 	v.onOnSetOnTriggerRequestChanged = f
 }
 
+func (v *View) SetOnClearPrerequisiteCache(f func()) {
+	v.onClearPrerequisiteCache = f
+}
+
 func (v *View) showNotification(text string, duration time.Duration) {
 	v.notify.Show(text, duration)
 }
@@ -179,6 +274,45 @@ func (v *View) showError(err error) {
 	v.modal.Show()
 }
 
+// copyToClipboard copies the text to the clipboard on the next frame, as writing to it needs the layout context.
+func (v *View) copyToClipboard(text string) {
+	v.clipboardText = text
+	v.window.Invalidate()
+}
+
+func (v *View) showSelect(title string, items []string, onSubmit func(selected []int)) {
+	v.selectModal = widgets.NewSelectModal(title, items, onSubmit)
+	v.selectModal.Show()
+}
+
+// showCode shows the snippet in a modal, languages lets the user switch the language of the snippet.
+func (v *View) showCode(title, code, lang string, languages []*widgets.DropDownOption, selected int, onLanguageChanged func(value string)) {
+	v.codeModal = widgets.NewCodeModal(v.theme, title, code, lang, languages...)
+	v.codeModal.SetSelectedLanguage(selected)
+	v.codeModal.SetOnLanguageChanged(onLanguageChanged)
+	v.codeModal.SetOnCopy(func(code string) {
+		v.copyToClipboard(code)
+		v.showNotification("Code copied to clipboard", 2*time.Second)
+	})
+	v.codeModal.Show()
+}
+
+// setCode replaces the snippet of the code modal.
+func (v *View) setCode(code, lang string) {
+	if v.codeModal == nil {
+		return
+	}
+	v.codeModal.SetCode(code, lang)
+	v.window.Invalidate()
+}
+
+func (v *View) showWarning(title, text string) {
+	v.modal = widgets.NewMessageModal(title, text, widgets.MessageModalTypeWarn, func(_ string) {
+		v.modal.Hide()
+	}, widgets.ModalOption{Text: "Ok"})
+	v.modal.Show()
+}
+
 func (v *View) SetOnPostRequestSetChanged(f func(id string, statusCode int, item, from, fromKey string)) {
 	v.onOnPostRequestSetChanged = f
 }
@@ -213,7 +347,7 @@ func (v *View) AddRequestTreeViewNode(req *domain.Request) {
 	node := &widgets.TreeNode{
 		Text:        req.MetaData.Name,
 		Identifier:  req.MetaData.ID,
-		MenuOptions: []string{MenuView, MenuDuplicate, MenuDelete},
+		MenuOptions: []string{MenuView, MenuDuplicate, copyAsMenu(req), MenuGenerateCode, MenuRun, MenuLoadTest, MenuDelete},
 		Meta:        safemap.New[string](),
 	}
 
@@ -229,7 +363,7 @@ func (v *View) AddCollectionTreeViewNode(collection *domain.Collection) {
 		Text:        collection.MetaData.Name,
 		Identifier:  collection.MetaData.ID,
 		Children:    make([]*widgets.TreeNode, 0),
-		MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuDuplicate, MenuView, MenuDelete},
+		MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuDuplicate, MenuView, MenuExportPostman, MenuExportOpenAPI, MenuRun, MenuLoadTest, MenuDelete},
 		Meta:        safemap.New[string](),
 	}
 
@@ -267,6 +401,280 @@ func (v *View) SetOnGrpcLoadRequestExample(f func(id string)) {
 	v.onGrpcLoadRequestExample = f
 }
 
+func (v *View) SetOnGrpcBodyCompletion(f func(id, body string, caret int) (string, []widgets.Completion)) {
+	v.onGrpcBodyCompletion = f
+}
+
+func (v *View) SetOnGrpcDiagnose(f func(id string)) {
+	v.onGrpcDiagnose = f
+}
+
+func (v *View) SetOnGrpcWatchHealth(f func(id string, watch bool)) {
+	v.onGrpcWatchHealth = f
+}
+
+func (v *View) SetOnMockStart(f func(id, addr string, isGRPC bool, opts mock.Options)) {
+	v.onMockStart = f
+}
+
+func (v *View) SetOnMockStop(f func(id string)) {
+	v.onMockStop = f
+}
+
+func (v *View) SetCollectionMockRunning(id string, running bool, addr string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(CollectionContainer); ok {
+			ct.SetMockRunning(running, addr)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AddCollectionMockLogLine(id, line string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(CollectionContainer); ok {
+			ct.AddMockLogLine(line)
+			v.window.Invalidate()
+		}
+	}
+}
+
+// OpenRecorderContainer opens the traffic recorder, the recorded exchanges can be saved to the collections.
+func (v *View) OpenRecorderContainer(collections []*domain.Collection) {
+	if ct, ok := v.containers.Get(RecorderID); ok {
+		if ct, ok := ct.(RecorderContainer); ok {
+			ct.SetCollections(collections)
+		}
+		return
+	}
+
+	ct := recorder.New(v.theme)
+	ct.SetCollections(collections)
+	ct.SetOnStart(func(addr string) {
+		if v.onRecorderStart != nil {
+			v.onRecorderStart(addr)
+		}
+	})
+	ct.SetOnStop(func() {
+		if v.onRecorderStop != nil {
+			v.onRecorderStop()
+		}
+	})
+	ct.SetOnSaveCA(func() {
+		if v.onRecorderSaveCA != nil {
+			v.onRecorderSaveCA()
+		}
+	})
+	ct.SetOnClear(func() {
+		if v.onRecorderClear != nil {
+			v.onRecorderClear()
+		}
+	})
+	ct.SetOnSaveExchanges(func(collectionID string, exchangeIDs []string) {
+		if v.onRecorderSave != nil {
+			v.onRecorderSave(collectionID, exchangeIDs)
+		}
+	})
+
+	v.containers.Set(RecorderID, ct)
+}
+
+func (v *View) SetRecorderRunning(running bool, addr string) {
+	if ct, ok := v.containers.Get(RecorderID); ok {
+		if ct, ok := ct.(RecorderContainer); ok {
+			ct.SetRunning(running, addr)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AddRecorderExchange(id, text string) {
+	if ct, ok := v.containers.Get(RecorderID); ok {
+		if ct, ok := ct.(RecorderContainer); ok {
+			ct.AddExchange(id, text)
+			v.window.Invalidate()
+		}
+	}
+}
+
+// OpenLoadTestContainer opens the load test of the request or collection with the id in the tab.
+func (v *View) OpenLoadTestContainer(tabID, id, name string) {
+	if _, ok := v.containers.Get(tabID); ok {
+		return
+	}
+
+	ct := loadrunner.New(id, name, v.theme)
+	ct.SetOnStart(func(id string, opts loadtest.Options) {
+		if v.onLoadTestStart != nil {
+			v.onLoadTestStart(id, opts)
+		}
+	})
+	ct.SetOnStop(func(id string) {
+		if v.onLoadTestStop != nil {
+			v.onLoadTestStop(id)
+		}
+	})
+	ct.SetOnSaveReport(func(id, format string) {
+		if v.onLoadTestSaveReport != nil {
+			v.onLoadTestSaveReport(id, format)
+		}
+	})
+
+	v.containers.Set(tabID, ct)
+}
+
+func (v *View) SetLoadTestRunning(tabID string, running bool) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(LoadTestContainer); ok {
+			ct.SetRunning(running)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetLoadTestReport(tabID string, report *loadtest.Report) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(LoadTestContainer); ok {
+			ct.SetReport(report)
+			v.window.Invalidate()
+		}
+	}
+}
+
+// OpenRunnerContainer opens the runner of the request or collection with the id in the tab.
+func (v *View) OpenRunnerContainer(tabID, id, name string) {
+	if _, ok := v.containers.Get(tabID); ok {
+		return
+	}
+
+	ct := runner.New(id, name, v.theme)
+	ct.SetOnRun(func(id string, opts egress.RunOptions) {
+		if v.onRun != nil {
+			v.onRun(id, opts)
+		}
+	})
+	ct.SetOnStop(func(id string) {
+		if v.onRunStop != nil {
+			v.onRunStop(id)
+		}
+	})
+	ct.SetOnSelectData(func(id string) {
+		if v.onRunSelectData != nil {
+			v.onRunSelectData(id)
+		}
+	})
+	ct.SetOnClearData(func(id string) {
+		if v.onRunClearData != nil {
+			v.onRunClearData(id)
+		}
+	})
+	ct.SetOnExport(func(id, reporter string) {
+		if v.onRunExport != nil {
+			v.onRunExport(id, reporter)
+		}
+	})
+
+	v.containers.Set(tabID, ct)
+}
+
+func (v *View) SetRunnerRunning(tabID string, running bool) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(RunnerContainer); ok {
+			ct.SetRunning(running)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetRunnerData(tabID, name string, rows int) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(RunnerContainer); ok {
+			ct.SetData(name, rows)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetRunnerRequests(tabID string, requests []*domain.Request) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(RunnerContainer); ok {
+			ct.SetRequests(requests)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetRunnerProgress(tabID string, iteration, iterations int, requestID string) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(RunnerContainer); ok {
+			ct.SetProgress(iteration, iterations, requestID)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetRunnerRequestResult(tabID string, result *egress.RequestResult) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(RunnerContainer); ok {
+			ct.SetRequestResult(result)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AddRunnerResult(tabID, line string) {
+	if ct, ok := v.containers.Get(tabID); ok {
+		if ct, ok := ct.(RunnerContainer); ok {
+			ct.AddResult(line)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetGRPCDiagnosticsLoading(id string, loading bool) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetDiagnosticsLoading(loading)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetGRPCDiagnosticsResult(id, result string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetDiagnosticsResult(result)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AppendGRPCDiagnosticsResult(id, line string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.AppendDiagnosticsResult(line)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetGRPCHealthWatching(id string, watching bool) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetHealthWatching(watching)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetGRPCBodyDiagnostics(id string, diagnostics []widgets.Diagnostic) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetBodyDiagnostics(diagnostics)
+		}
+	}
+}
+
 func (v *View) SetSetGrpcRequestBody(id, body string) {
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(GrpcContainer); ok {
@@ -323,6 +731,62 @@ func (v *View) SetOnNewCollection(onNewCollection func()) {
 	v.onNewCollection = onNewCollection
 }
 
+func (v *View) SetOnOpenRecorder(f func()) {
+	v.onOpenRecorder = f
+}
+
+func (v *View) SetOnRecorderStart(f func(addr string)) {
+	v.onRecorderStart = f
+}
+
+func (v *View) SetOnRecorderStop(f func()) {
+	v.onRecorderStop = f
+}
+
+func (v *View) SetOnRecorderSaveCA(f func()) {
+	v.onRecorderSaveCA = f
+}
+
+func (v *View) SetOnRecorderClear(f func()) {
+	v.onRecorderClear = f
+}
+
+func (v *View) SetOnRecorderSave(f func(collectionID string, exchangeIDs []string)) {
+	v.onRecorderSave = f
+}
+
+func (v *View) SetOnLoadTestStart(f func(id string, opts loadtest.Options)) {
+	v.onLoadTestStart = f
+}
+
+func (v *View) SetOnLoadTestStop(f func(id string)) {
+	v.onLoadTestStop = f
+}
+
+func (v *View) SetOnLoadTestSaveReport(f func(id, format string)) {
+	v.onLoadTestSaveReport = f
+}
+
+func (v *View) SetOnRun(f func(id string, opts egress.RunOptions)) {
+	v.onRun = f
+}
+
+func (v *View) SetOnRunStop(f func(id string)) {
+	v.onRunStop = f
+}
+
+func (v *View) SetOnRunSelectData(f func(id string)) {
+	v.onRunSelectData = f
+}
+
+func (v *View) SetOnRunClearData(f func(id string)) {
+	v.onRunClearData = f
+}
+
+func (v *View) SetOnRunExport(f func(id, reporter string)) {
+	v.onRunExport = f
+}
+
 func (v *View) SetOnSubmit(f func(id, containerType string)) {
 	v.onSubmit = f
 }
@@ -356,6 +820,14 @@ func (v *View) SetOnTreeViewMenuClicked(onTreeViewMenuClicked func(id, action st
 	})
 }
 
+// SetOnTreeViewNodeMoved sets the function called when a request is dragged to the index in its collection.
+func (v *View) SetOnTreeViewNodeMoved(onTreeViewNodeMoved func(parentID, id string, index int)) {
+	v.onTreeViewNodeMoved = onTreeViewNodeMoved
+	v.treeView.OnNodeMove(func(parent, node *widgets.TreeNode, index int) {
+		v.onTreeViewNodeMoved(parent.Identifier, node.Identifier, index)
+	})
+}
+
 func (v *View) SetOnTabSelected(onTabSelected func(id string)) {
 	v.onTabSelected = onTabSelected
 }
@@ -478,6 +950,13 @@ func (v *View) OpenRequestContainer(req *domain.Request) {
 	}
 }
 
+// ReloadRequestContainer replaces the container of the request with a new one showing the request.
+func (v *View) ReloadRequestContainer(req *domain.Request) {
+	v.containers.Delete(req.MetaData.ID)
+	v.OpenRequestContainer(req)
+	v.window.Invalidate()
+}
+
 func (v *View) createGrpcContainer(req *domain.Request) Container {
 	ct := grpc.New(req, v.theme, v.explorer)
 
@@ -517,6 +996,12 @@ func (v *View) createGrpcContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnClearPrerequisiteCache(func() {
+		if v.onClearPrerequisiteCache != nil {
+			v.onClearPrerequisiteCache()
+		}
+	})
+
 	ct.SetOnPostRequestSetChanged(func(id string, statusCode int, item, from, fromKey string) {
 		if v.onOnPostRequestSetChanged != nil {
 			v.onOnPostRequestSetChanged(id, statusCode, item, from, fromKey)
@@ -529,6 +1014,25 @@ func (v *View) createGrpcContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnDiagnose(func(id string) {
+		if v.onGrpcDiagnose != nil {
+			v.onGrpcDiagnose(id)
+		}
+	})
+
+	ct.SetOnWatchHealth(func(id string, watch bool) {
+		if v.onGrpcWatchHealth != nil {
+			v.onGrpcWatchHealth(id, watch)
+		}
+	})
+
+	ct.SetOnBodyCompletion(func(id, body string, caret int) (string, []widgets.Completion) {
+		if v.onGrpcBodyCompletion != nil {
+			return v.onGrpcBodyCompletion(id, body, caret)
+		}
+		return "", nil
+	})
+
 	ct.SetOnCopyResponse(func(gtx layout.Context, dataType, data string) {
 		if v.onCopyResponse != nil {
 			v.onCopyResponse(gtx, dataType, data)
@@ -541,6 +1045,20 @@ func (v *View) createGrpcContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnDescribeVariable(func(id, name string) string {
+		if v.onDescribeVariable != nil {
+			return v.onDescribeVariable(id, name)
+		}
+		return ""
+	})
+
+	ct.SetOnListVariables(func(id string) []widgets.Completion {
+		if v.onListVariables != nil {
+			return v.onListVariables(id)
+		}
+		return nil
+	})
+
 	return ct
 }
 
@@ -589,6 +1107,12 @@ func (v *View) createRestfulContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnClearPrerequisiteCache(func() {
+		if v.onClearPrerequisiteCache != nil {
+			v.onClearPrerequisiteCache()
+		}
+	})
+
 	ct.SetOnBinaryFileSelect(func(id string) {
 		if v.onBinaryFileSelect != nil {
 			v.onBinaryFileSelect(id)
@@ -607,6 +1131,32 @@ func (v *View) createRestfulContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnDescribeVariable(func(id, name string) string {
+		if v.onDescribeVariable != nil {
+			return v.onDescribeVariable(id, name)
+		}
+		return ""
+	})
+
+	ct.SetOnListVariables(func(id string) []widgets.Completion {
+		if v.onListVariables != nil {
+			return v.onListVariables(id)
+		}
+		return nil
+	})
+
+	ct.SetOnCurlPasted(func(id, command string) {
+		if v.onCurlPasted != nil {
+			v.onCurlPasted(id, command)
+		}
+	})
+
+	ct.SetOnSaveResponseBody(func(id string, body []byte, contentType string) {
+		if v.onSaveResponseBody != nil {
+			v.onSaveResponseBody(id, body, contentType)
+		}
+	})
+
 	return ct
 }
 
@@ -665,13 +1215,37 @@ func (v *View) OpenCollectionContainer(collection *domain.Collection) {
 		return
 	}
 
-	ct := collections.New(collection)
+	ct := collections.New(collection, v.theme)
 	ct.Title.SetOnChanged(func(text string) {
 		if v.onTitleChanged != nil {
 			v.onTitleChanged(collection.MetaData.ID, text, TypeCollection)
 		}
 	})
 
+	ct.SetOnDataChanged(func(id string, data any) {
+		if v.onDataChanged != nil {
+			v.onDataChanged(id, data, TypeCollection)
+		}
+	})
+
+	ct.SetOnSave(func(id string) {
+		if v.onSave != nil {
+			v.onSave(id)
+		}
+	})
+
+	ct.SetOnMockStart(func(id, addr string, isGRPC bool, opts mock.Options) {
+		if v.onMockStart != nil {
+			v.onMockStart(id, addr, isGRPC, opts)
+		}
+	})
+
+	ct.SetOnMockStop(func(id string) {
+		if v.onMockStop != nil {
+			v.onMockStop(id)
+		}
+	})
+
 	v.containers.Set(collection.MetaData.ID, ct)
 }
 
@@ -766,7 +1340,7 @@ func (v *View) PopulateTreeView(requests []*domain.Request, collections []*domai
 			Text:        cl.MetaData.Name,
 			Identifier:  cl.MetaData.ID,
 			Children:    make([]*widgets.TreeNode, 0),
-			MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuDuplicate, MenuView, MenuDelete},
+			MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuDuplicate, MenuView, MenuExportPostman, MenuExportOpenAPI, MenuRun, MenuLoadTest, MenuDelete},
 			Meta:        safemap.New[string](),
 		}
 		parentNode.Meta.Set(TypeMeta, TypeCollection)
@@ -775,7 +1349,7 @@ func (v *View) PopulateTreeView(requests []*domain.Request, collections []*domai
 			node := &widgets.TreeNode{
 				Text:        req.MetaData.Name,
 				Identifier:  req.MetaData.ID,
-				MenuOptions: []string{MenuView, MenuDuplicate, MenuDelete},
+				MenuOptions: []string{MenuView, MenuDuplicate, copyAsMenu(req), MenuGenerateCode, MenuRun, MenuLoadTest, MenuDelete},
 				Meta:        safemap.New[string](),
 			}
 
@@ -794,7 +1368,7 @@ func (v *View) PopulateTreeView(requests []*domain.Request, collections []*domai
 		node := &widgets.TreeNode{
 			Text:        req.MetaData.Name,
 			Identifier:  req.MetaData.ID,
-			MenuOptions: []string{MenuView, MenuDuplicate, MenuDelete},
+			MenuOptions: []string{MenuView, MenuDuplicate, copyAsMenu(req), MenuGenerateCode, MenuRun, MenuLoadTest, MenuDelete},
 			Meta:        safemap.New[string](),
 		}
 
@@ -831,7 +1405,7 @@ func (v *View) addTreeViewNode(parentID string, req *domain.Request) {
 	node := &widgets.TreeNode{
 		Text:        req.MetaData.Name,
 		Identifier:  req.MetaData.ID,
-		MenuOptions: []string{MenuDuplicate, MenuDelete},
+		MenuOptions: []string{MenuDuplicate, copyAsMenu(req), MenuGenerateCode, MenuRun, MenuLoadTest, MenuDelete},
 		Meta:        safemap.New[string](),
 	}
 
@@ -846,6 +1420,14 @@ func (v *View) addTreeViewNode(parentID string, req *domain.Request) {
 	v.treeViewNodes.Set(req.MetaData.ID, node)
 }
 
+// copyAsMenu returns the menu option copying the request as a command.
+func copyAsMenu(req *domain.Request) string {
+	if req.MetaData.Type == domain.RequestTypeGRPC {
+		return MenuCopyAsGrpcurl
+	}
+	return MenuCopyAsCurl
+}
+
 func setNodePrefix(req *domain.Request, node *widgets.TreeNode) {
 	if req.MetaData.Type == domain.RequestTypeGRPC {
 		node.Prefix = "gRPC"
@@ -876,6 +1458,8 @@ func (v *View) requestList(gtx layout.Context, theme *chapartheme.Theme) layout.
 				component.MenuItem(theme.Material(), &v.newGrpcRequestButton, "GRPC Request").Layout,
 				component.Divider(theme.Material()).Layout,
 				component.MenuItem(theme.Material(), &v.newCollectionButton, "Collection").Layout,
+				component.Divider(theme.Material()).Layout,
+				component.MenuItem(theme.Material(), &v.recordTrafficButton, "Traffic Recorder").Layout,
 			},
 		}
 	}
@@ -898,6 +1482,12 @@ func (v *View) requestList(gtx layout.Context, theme *chapartheme.Theme) layout.
 		}
 	}
 
+	if v.recordTrafficButton.Clicked(gtx) {
+		if v.onOpenRecorder != nil {
+			v.onOpenRecorder()
+		}
+	}
+
 	return layout.Inset{Top: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -956,7 +1546,14 @@ func (v *View) requestList(gtx layout.Context, theme *chapartheme.Theme) layout.
 }
 
 func (v *View) containerHolder(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if v.clipboardText != "" {
+		gtx.Execute(clipboard.WriteCmd{Data: io.NopCloser(strings.NewReader(v.clipboardText))})
+		v.clipboardText = ""
+	}
+
 	v.modal.Layout(gtx, theme)
+	v.selectModal.Layout(gtx, theme)
+	v.codeModal.Layout(gtx, theme)
 
 	v.notify.Layout(gtx, theme)
 
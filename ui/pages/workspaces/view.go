@@ -13,6 +13,7 @@ import (
 
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
 	"github.com/chapar-rest/chapar/ui/widgets"
 )
 
@@ -36,11 +37,16 @@ type View struct {
 }
 
 type Item struct {
-	deleteButton widget.Clickable
+	deleteButton    widget.Clickable
+	variablesButton widget.Clickable
 
 	Name     *widgets.EditableLabel
 	readOnly bool
 
+	// Variables are the global variables of the workspace.
+	Variables     *widgets.KeyValue
+	showVariables bool
+
 	w *domain.Workspace
 }
 
@@ -140,7 +146,15 @@ func (v *View) AddItem(item *domain.Workspace) {
 		}
 	})
 
-	v.items = append(v.items, &Item{w: item, Name: nameEditable, readOnly: readonly})
+	vars := widgets.NewKeyValue(converter.WidgetItemsFromKeyValue(item.Spec.Variables)...)
+	vars.SetOnChanged(func(items []*widgets.KeyValueItem) {
+		if v.onUpdate != nil {
+			item.Spec.Variables = converter.KeyValueFromWidgetItems(items)
+			v.onUpdate(item)
+		}
+	})
+
+	v.items = append(v.items, &Item{w: item, Name: nameEditable, readOnly: readonly, Variables: vars})
 
 	sort.Slice(v.items, func(i, j int) bool {
 		return v.items[i].w.MetaData.Name < v.items[j].w.MetaData.Name
@@ -154,6 +168,18 @@ func (v *View) itemLayout(gtx layout.Context, theme *chapartheme.Theme, item *It
 				gtx.Constraints.Min.X = gtx.Dp(100)
 				return item.Name.Layout(gtx, theme)
 			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				if item.variablesButton.Clicked(gtx) {
+					item.showVariables = !item.showVariables
+				}
+
+				return layout.E.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					btn := widgets.Button(theme.Material(), &item.variablesButton, widgets.ExpandIcon, widgets.IconPositionEnd, "Variables")
+					btn.Color = theme.TextColor
+					btn.Background = theme.Bg
+					return btn.Layout(gtx, theme)
+				})
+			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				if item.readOnly {
 					return layout.Dimensions{}
@@ -181,6 +207,16 @@ func (v *View) itemLayout(gtx layout.Context, theme *chapartheme.Theme, item *It
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return content
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !item.showVariables {
+				return layout.Dimensions{}
+			}
+
+			gtx.Constraints.Max.Y = gtx.Dp(250)
+			return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return item.Variables.WithAddLayout(gtx, "Global variables", "Available to all the requests of the workspace", theme)
+			})
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			// only if it's not the last item
 			if isLast {
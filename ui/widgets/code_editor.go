@@ -1,6 +1,7 @@
 package widgets
 
 import (
+	"fmt"
 	"image/color"
 
 	"gioui.org/font"
@@ -10,6 +11,7 @@ import (
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
+	"gioui.org/widget/material"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/lexers"
@@ -28,6 +30,9 @@ const (
 	CodeLanguageProperties = "properties"
 )
 
+// maxCompletions is the number of suggestions shown under the editor.
+const maxCompletions = 8
+
 type CodeEditor struct {
 	editor *giovieweditor.Editor
 	code   string
@@ -51,6 +56,32 @@ type CodeEditor struct {
 
 	onBeautify    func()
 	onLoadExample func()
+
+	diagnostics  []Diagnostic
+	diagnosticBg op.CallOp
+	errorColor   color.NRGBA
+
+	completionProvider func(text string, caret int) (string, []Completion)
+	completionPrefix   string
+	completions        []Completion
+	completionButtons  []widget.Clickable
+
+	hints        *VariableHints
+	hintsVersion int
+	unknownColor op.CallOp
+}
+
+// Diagnostic is a problem in the editor content, Start and End are rune offsets of the text to mark.
+type Diagnostic struct {
+	Start   int
+	End     int
+	Message string
+}
+
+// Completion is a suggestion offered to the user for the word under the caret.
+type Completion struct {
+	Text   string
+	Detail string
 }
 
 func NewCodeEditor(code string, lang string, theme *chapartheme.Theme) *CodeEditor {
@@ -76,6 +107,12 @@ func NewCodeEditor(code string, lang string, theme *chapartheme.Theme) *CodeEdit
 
 	c.codeStyle = style
 
+	c.errorColor = theme.ErrorColor
+	bg := theme.ErrorColor
+	bg.A = 0x55
+	c.diagnosticBg = nRGBAColorToOp(bg)
+	c.unknownColor = nRGBAColorToOp(theme.ErrorColor)
+
 	c.editor.WrapPolicy = text.WrapGraphemes
 	c.editor.SetText(code, false)
 
@@ -107,6 +144,25 @@ func (c *CodeEditor) SetOnLoadExample(f func()) {
 	c.onLoadExample = f
 }
 
+// SetDiagnostics marks the given ranges of the content and lists their messages under the editor.
+func (c *CodeEditor) SetDiagnostics(diagnostics []Diagnostic) {
+	c.diagnostics = diagnostics
+	// force restyling so the marks follow the new diagnostics
+	c.styledCode = ""
+	c.editor.UpdateTextStyles(c.stylingText(c.editor.Text()))
+}
+
+// SetCompletionProvider sets the function used to get suggestions for the caret position after each change.
+// It returns the prefix before the caret that a selected suggestion replaces.
+func (c *CodeEditor) SetCompletionProvider(f func(text string, caret int) (string, []Completion)) {
+	c.completionProvider = f
+}
+
+// SetVariableHints sets the known variables used to complete {{ names and highlight the unresolved ones.
+func (c *CodeEditor) SetVariableHints(hints *VariableHints) {
+	c.hints = hints
+}
+
 func (c *CodeEditor) SetCode(code string) {
 	c.editor.SetText(code, false)
 	c.code = code
@@ -124,6 +180,12 @@ func (c *CodeEditor) Code() string {
 }
 
 func (c *CodeEditor) Layout(gtx layout.Context, theme *chapartheme.Theme, hint string) layout.Dimensions {
+	if version := c.hints.refresh(); version != c.hintsVersion {
+		// the known variables changed, restyle the unresolved ones
+		c.hintsVersion = version
+		c.styledCode = ""
+	}
+
 	if c.styledCode == "" {
 		// First time styling
 		c.editor.UpdateTextStyles(c.stylingText(c.editor.Text()))
@@ -136,6 +198,7 @@ func (c *CodeEditor) Layout(gtx layout.Context, theme *chapartheme.Theme, hint s
 				c.onChange(c.editor.Text())
 				c.code = c.editor.Text()
 			}
+			c.updateCompletions()
 		}
 	}
 
@@ -219,9 +282,116 @@ func (c *CodeEditor) Layout(gtx layout.Context, theme *chapartheme.Theme, hint s
 				)
 			})
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.layoutCompletions(gtx, theme)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return c.layoutDiagnostics(gtx, theme)
+		}),
 	)
 }
 
+func (c *CodeEditor) updateCompletions() {
+	if (c.completionProvider == nil && c.hints == nil) || c.editor.ReadOnly {
+		return
+	}
+
+	start, end := c.editor.Selection()
+	if start != end {
+		c.completions = nil
+		return
+	}
+
+	if prefix, ok := variablePrefix(c.editor.Text(), start); ok && c.hints != nil {
+		after := string([]rune(c.editor.Text())[start:])
+		c.completionPrefix, c.completions = prefix, c.hints.complete(prefix)
+		for i := range c.completions {
+			c.completions[i].Text = variableCompletion(c.completions[i].Text, after)
+		}
+	} else if c.completionProvider != nil {
+		c.completionPrefix, c.completions = c.completionProvider(c.editor.Text(), start)
+	} else {
+		c.completions = nil
+	}
+
+	if len(c.completions) > maxCompletions {
+		c.completions = c.completions[:maxCompletions]
+	}
+	if len(c.completionButtons) < len(c.completions) {
+		c.completionButtons = make([]widget.Clickable, len(c.completions))
+	}
+}
+
+func (c *CodeEditor) applyCompletion(item Completion) {
+	caret, _ := c.editor.Selection()
+	prefixLen := len([]rune(c.completionPrefix))
+	if prefixLen > caret {
+		prefixLen = caret
+	}
+
+	c.editor.SetCaret(caret-prefixLen, caret)
+	c.editor.Insert(item.Text)
+	c.completions = nil
+}
+
+func (c *CodeEditor) layoutCompletions(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if len(c.completions) == 0 {
+		return layout.Dimensions{}
+	}
+
+	for i := range c.completions {
+		if c.completionButtons[i].Clicked(gtx) {
+			c.applyCompletion(c.completions[i])
+			return layout.Dimensions{}
+		}
+	}
+
+	items := make([]layout.FlexChild, 0, len(c.completions))
+	for i := range c.completions {
+		i := i
+		items = append(items, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := c.completions[i].Text
+			if c.completions[i].Detail != "" {
+				label = fmt.Sprintf("%s  %s", label, c.completions[i].Detail)
+			}
+
+			btn := Button(theme.Material(), &c.completionButtons[i], nil, IconPositionStart, label)
+			btn.Color = theme.ButtonTextColor
+			btn.Inset = layout.Inset{
+				Top: unit.Dp(4), Bottom: unit.Dp(4),
+				Left: unit.Dp(4), Right: unit.Dp(4),
+			}
+			return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return btn.Layout(gtx, theme)
+			})
+		}))
+	}
+
+	return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, items...)
+	})
+}
+
+func (c *CodeEditor) layoutDiagnostics(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if len(c.diagnostics) == 0 {
+		return layout.Dimensions{}
+	}
+
+	items := make([]layout.FlexChild, 0, len(c.diagnostics))
+	for _, d := range c.diagnostics {
+		msg := d.Message
+		items = append(items, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lb := material.Label(theme.Material(), unit.Sp(12), msg)
+			lb.Color = c.errorColor
+			return lb.Layout(gtx)
+		}))
+	}
+
+	return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, items...)
+	})
+}
+
 func (c *CodeEditor) stylingText(text string) []*giovieweditor.TextStyle {
 	if c.styledCode == text {
 		return c.styles
@@ -230,6 +400,15 @@ func (c *CodeEditor) stylingText(text string) []*giovieweditor.TextStyle {
 	// nolint:prealloc
 	var textStyles []*giovieweditor.TextStyle
 
+	// the first matching style is used, so the unresolved variables come first
+	for _, r := range c.hints.unresolved(text) {
+		textStyles = append(textStyles, &giovieweditor.TextStyle{
+			Start: r[0],
+			End:   r[1],
+			Color: c.unknownColor,
+		})
+	}
+
 	offset := 0
 
 	iterator, err := c.lexer.Tokenise(nil, text)
@@ -249,6 +428,13 @@ func (c *CodeEditor) stylingText(text string) []*giovieweditor.TextStyle {
 			textStyle.Color = chromaColorToOp(entry.Colour)
 		}
 
+		for _, d := range c.diagnostics {
+			if textStyle.Start < d.End && d.Start < textStyle.End {
+				textStyle.Background = c.diagnosticBg
+				break
+			}
+		}
+
 		textStyles = append(textStyles, textStyle)
 		offset = textStyle.End
 	}
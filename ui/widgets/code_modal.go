@@ -0,0 +1,151 @@
+package widgets
+
+import (
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+)
+
+// CodeModal shows a read only snippet of code, with an optional drop down to switch its language.
+type CodeModal struct {
+	Title   string
+	Visible bool
+
+	languages *DropDown
+	editor    *CodeEditor
+
+	copyBtn  widget.Clickable
+	closeBtn widget.Clickable
+
+	onCopy func(code string)
+}
+
+// NewCodeModal creates the modal, the drop down is hidden when no languages are given.
+func NewCodeModal(theme *chapartheme.Theme, title, code, lang string, languages ...*DropDownOption) *CodeModal {
+	c := &CodeModal{
+		Title:  title,
+		editor: NewCodeEditor(code, lang, theme),
+	}
+	c.editor.SetReadOnly(true)
+
+	if len(languages) > 0 {
+		c.languages = NewDropDown(theme, languages...)
+		c.languages.MaxWidth = unit.Dp(200)
+	}
+	return c
+}
+
+// SetOnLanguageChanged sets the function called with the value of the selected language.
+func (c *CodeModal) SetOnLanguageChanged(f func(value string)) {
+	if c.languages != nil {
+		c.languages.SetOnChanged(f)
+	}
+}
+
+func (c *CodeModal) SetSelectedLanguage(index int) {
+	if c.languages != nil {
+		c.languages.SetSelected(index)
+	}
+}
+
+func (c *CodeModal) SetOnCopy(f func(code string)) {
+	c.onCopy = f
+}
+
+// SetCode replaces the snippet and the language used to highlight it.
+func (c *CodeModal) SetCode(code, lang string) {
+	c.editor.SetLanguage(lang)
+	c.editor.SetCode(code)
+}
+
+func (c *CodeModal) Show() {
+	c.Visible = true
+}
+
+func (c *CodeModal) Hide() {
+	c.Visible = false
+}
+
+func (c *CodeModal) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if c.closeBtn.Clicked(gtx) {
+		c.Hide()
+	}
+
+	if c.copyBtn.Clicked(gtx) && c.onCopy != nil {
+		c.onCopy(c.editor.Code())
+	}
+
+	border := widget.Border{
+		Color:        theme.TableBorderColor,
+		CornerRadius: unit.Dp(4),
+		Width:        unit.Dp(1),
+	}
+
+	return layout.N.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: unit.Dp(80)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return border.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = gtx.Dp(800)
+				gtx.Constraints.Max.Y = gtx.Dp(550)
+				gtx.Constraints.Min = gtx.Constraints.Max
+
+				return component.NewModalSheet(component.NewModal()).Layout(gtx, theme.Material(), &component.VisibilityAnimation{}, func(gtx layout.Context) layout.Dimensions {
+					return layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+									layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+										return material.Label(theme.Material(), unit.Sp(14), c.Title).Layout(gtx)
+									}),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										if c.languages == nil {
+											return layout.Dimensions{}
+										}
+										return c.languages.Layout(gtx, theme)
+									}),
+								)
+							}),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return c.editor.Layout(gtx, theme, "")
+							}),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle, Spacing: layout.SpaceStart}.Layout(gtx,
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										closeBtn := Button(theme.Material(), &c.closeBtn, CloseIcon, IconPositionStart, "Close")
+										closeBtn.Color = theme.ButtonTextColor
+										return closeBtn.Layout(gtx, theme)
+									}),
+									layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										copyBtn := Button(theme.Material(), &c.copyBtn, CopyIcon, IconPositionStart, "Copy")
+										copyBtn.Color = theme.ButtonTextColor
+										copyBtn.Background = theme.SendButtonBgColor
+										return copyBtn.Layout(gtx, theme)
+									}),
+								)
+							}),
+						)
+					})
+				})
+			})
+		})
+	})
+}
+
+func (c *CodeModal) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if c == nil || !c.Visible {
+		return layout.Dimensions{}
+	}
+
+	ops := op.Record(gtx.Ops)
+	dims := c.layout(gtx, theme)
+	defer op.Defer(gtx.Ops, ops.Stop())
+
+	return dims
+}
@@ -160,3 +160,13 @@ var CleanIcon *widget.Icon = func() *widget.Icon {
 	icon, _ := widget.NewIcon(icons.EditorFormatColorText)
 	return icon
 }()
+
+var LockIcon *widget.Icon = func() *widget.Icon {
+	icon, _ := widget.NewIcon(icons.ActionLock)
+	return icon
+}()
+
+var LockOpenIcon *widget.Icon = func() *widget.Icon {
+	icon, _ := widget.NewIcon(icons.ActionLockOpen)
+	return icon
+}()
@@ -16,7 +16,8 @@ type InputModal struct {
 	addBtn    widget.Clickable
 	closeBtn  widget.Clickable
 
-	Title string
+	Title      string
+	SubmitText string
 
 	onClose func()
 	onAdd   func(text string)
@@ -26,11 +27,18 @@ func NewInputModal(title, placeholder string) *InputModal {
 	ed := NewTextField("", placeholder)
 	ed.SetIcon(FileFolderIcon, IconPositionStart)
 	return &InputModal{
-		textField: ed,
-		Title:     title,
+		textField:  ed,
+		Title:      title,
+		SubmitText: "Add",
 	}
 }
 
+// SetSecret masks the input and sets the icon for entering a password.
+func (i *InputModal) SetSecret() {
+	i.textField.SetMask('•')
+	i.textField.SetIcon(LockIcon, IconPositionStart)
+}
+
 func (i *InputModal) SetOnClose(f func()) {
 	i.onClose = f
 }
@@ -84,7 +92,7 @@ func (i *InputModal) layout(gtx layout.Context, theme *chapartheme.Theme) layout
 									}),
 									layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
 									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-										addBtn := Button(theme.Material(), &i.addBtn, PlusIcon, IconPositionStart, "Add")
+										addBtn := Button(theme.Material(), &i.addBtn, PlusIcon, IconPositionStart, i.SubmitText)
 										addBtn.Color = theme.ButtonTextColor
 										addBtn.Background = theme.SendButtonBgColor
 										return addBtn.Layout(gtx, theme)
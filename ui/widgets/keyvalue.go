@@ -22,6 +22,11 @@ type KeyValue struct {
 
 	addButton *IconButton
 
+	// secrets shows the button to mark the values as secret.
+	secrets bool
+
+	hints *VariableHints
+
 	mx *sync.Mutex
 
 	list *widget.List
@@ -36,12 +41,15 @@ type KeyValueItem struct {
 	Key        string
 	Value      string
 	Active     bool
+	Secret     bool
 
-	keyEditor   *widget.Editor
-	valueEditor *PatternEditor
+	keyEditor    *widget.Editor
+	valueEditor  *PatternEditor
+	secretEditor *widget.Editor
 
 	activeBool   *widget.Bool
 	deleteButton *widget.Clickable
+	secretButton *widget.Clickable
 }
 
 func NewKeyValue(items ...*KeyValueItem) *KeyValue {
@@ -72,6 +80,23 @@ func NewKeyValue(items ...*KeyValueItem) *KeyValue {
 	return kv
 }
 
+// WithSecrets lets the user mark the values as secret, secret values are masked.
+func (kv *KeyValue) WithSecrets() *KeyValue {
+	kv.secrets = true
+	return kv
+}
+
+// SetVariableHints sets the known variables for the value editors of the items.
+func (kv *KeyValue) SetVariableHints(hints *VariableHints) {
+	kv.mx.Lock()
+	defer kv.mx.Unlock()
+
+	kv.hints = hints
+	for _, item := range kv.Items {
+		item.valueEditor.SetVariableHints(hints)
+	}
+}
+
 func NewKeyValueItem(key, value, identifier string, active bool) *KeyValueItem {
 	k := &widget.Editor{SingleLine: true}
 	k.SetText(key)
@@ -84,7 +109,9 @@ func NewKeyValueItem(key, value, identifier string, active bool) *KeyValueItem {
 		Active:       active,
 		keyEditor:    k,
 		valueEditor:  v,
+		secretEditor: &widget.Editor{SingleLine: true, Mask: '•'},
 		deleteButton: &widget.Clickable{},
+		secretButton: &widget.Clickable{},
 		activeBool:   &widget.Bool{Value: active},
 	}
 
@@ -93,6 +120,17 @@ func NewKeyValueItem(key, value, identifier string, active bool) *KeyValueItem {
 	return kv
 }
 
+// SetSecret masks the value of the item.
+func (item *KeyValueItem) SetSecret(secret bool) *KeyValueItem {
+	item.Secret = secret
+	if secret {
+		item.secretEditor.SetText(item.Value)
+	} else {
+		item.valueEditor.SetText(item.Value)
+	}
+	return item
+}
+
 func (kv *KeyValue) Filter(text string) {
 	kv.mx.Lock()
 	defer kv.mx.Unlock()
@@ -106,7 +144,7 @@ func (kv *KeyValue) Filter(text string) {
 
 	var items []*KeyValueItem
 	for _, item := range kv.Items {
-		if strings.Contains(item.Key, text) || strings.Contains(item.Value, text) {
+		if strings.Contains(item.Key, text) || !item.Secret && strings.Contains(item.Value, text) {
 			items = append(items, item)
 		}
 	}
@@ -125,6 +163,7 @@ func (kv *KeyValue) AddItem(item *KeyValueItem) {
 		item.Value = text
 		kv.triggerChanged()
 	})
+	item.valueEditor.SetVariableHints(kv.hints)
 
 	item.index = len(kv.Items)
 	kv.Items = append(kv.Items, item)
@@ -139,6 +178,7 @@ func (kv *KeyValue) SetItems(items []*KeyValueItem) {
 			items[i].Value = text
 			kv.triggerChanged()
 		})
+		items[i].valueEditor.SetVariableHints(kv.hints)
 	}
 	kv.Items = items
 }
@@ -191,6 +231,24 @@ func (kv *KeyValue) itemLayout(gtx layout.Context, theme *chapartheme.Theme, ind
 		}
 	}
 
+	if kv.secrets {
+		if item.secretButton.Clicked(gtx) {
+			item.SetSecret(!item.Secret)
+			kv.triggerChanged()
+		}
+
+		for {
+			event, ok := item.secretEditor.Update(gtx)
+			if !ok {
+				break
+			}
+			if _, ok := event.(widget.ChangeEvent); ok {
+				item.Value = item.secretEditor.Text()
+				kv.triggerChanged()
+			}
+		}
+	}
+
 	leftPadding := layout.Inset{Left: unit.Dp(8)}
 
 	content := layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
@@ -214,11 +272,34 @@ func (kv *KeyValue) itemLayout(gtx layout.Context, theme *chapartheme.Theme, ind
 				DrawLineFlex(theme.TableBorderColor, unit.Dp(35), unit.Dp(1)),
 				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 					return leftPadding.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						if item.Secret {
+							ed := material.Editor(theme.Material(), item.secretEditor, "Secret value")
+							ed.SelectionColor = theme.TextSelectionColor
+							return ed.Layout(gtx)
+						}
 						return item.valueEditor.Layout(gtx, theme, "Value")
 					})
 				}),
 			)
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !kv.secrets {
+				return layout.Dimensions{}
+			}
+
+			icon, color := LockOpenIcon, theme.TextColor
+			if item.Secret {
+				icon, color = LockIcon, theme.WarningColor
+			}
+
+			ib := IconButton{
+				Icon:      icon,
+				Size:      unit.Dp(18),
+				Color:     color,
+				Clickable: item.secretButton,
+			}
+			return ib.Layout(gtx, theme)
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			ib := IconButton{
 				Icon:      DeleteIcon,
@@ -1,15 +1,25 @@
 package widgets
 
 import (
+	"fmt"
+	"image"
 	"image/color"
 	"regexp"
+	"unicode/utf8"
 
+	"gioui.org/f32"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
 	giovieweditor "github.com/oligo/gioview/editor"
 
+	"github.com/chapar-rest/chapar/internal/variables"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 )
 
@@ -27,13 +37,30 @@ type PatternEditor struct {
 
 	onChange func(text string)
 	onSubmit func()
+
+	hints        *VariableHints
+	hintsVersion int
+	unknownColor color.NRGBA
+	hovering     bool
+	hoverPos     f32.Point
+	// hoverToken is the start offset of the hovered variable, -1 when no variable is hovered.
+	hoverToken  int
+	hoverText   string
+	hoverBounds image.Rectangle
+
+	completionPrefix  string
+	completions       []Completion
+	completionButtons []widget.Clickable
 }
 
 // NewPatternEditor creates a new PatternEditor
 func NewPatternEditor() *PatternEditor {
 	pe := &PatternEditor{
-		Editor: new(giovieweditor.Editor),
-		Keys:   make(map[string]string),
+		Editor:     new(giovieweditor.Editor),
+		Keys:       make(map[string]string),
+		hoverToken: -1,
+		// replaced by the error color of the theme on layout
+		unknownColor: chapartheme.LightRed,
 	}
 
 	pe.Editor.SingleLine = true
@@ -54,7 +81,20 @@ func (p *PatternEditor) SetOnChanged(onChange func(text string)) {
 	p.onChange = onChange
 }
 
+// SetVariableHints sets the known variables used to complete {{ names, highlight the unresolved ones and
+// show the value of the variable under the pointer as a tooltip.
+func (p *PatternEditor) SetVariableHints(hints *VariableHints) {
+	p.hints = hints
+}
+
 func (p *PatternEditor) Layout(gtx layout.Context, theme *chapartheme.Theme, hint string) layout.Dimensions {
+	p.unknownColor = theme.ErrorColor
+	if version := p.hints.refresh(); version != p.hintsVersion {
+		// the known variables changed, restyle the unresolved ones
+		p.hintsVersion = version
+		p.styledText = ""
+	}
+
 	if p.styledText == "" {
 		p.updateStyles(p.Editor.Text())
 	}
@@ -84,13 +124,195 @@ func (p *PatternEditor) Layout(gtx layout.Context, theme *chapartheme.Theme, hin
 		// on change event
 		case giovieweditor.ChangeEvent:
 			p.UpdateStyles()
+			p.hoverToken = -1
+			p.updateCompletions()
 			if p.onChange != nil {
 				p.onChange(p.Editor.Text())
 			}
 		}
 	}
+	tooltipGtx := gtx
 	gtx.Constraints.Max.Y = gtx.Dp(20)
-	return giovieweditor.NewEditor(p.Editor, editorConf, hint).Layout(gtx)
+	dims := giovieweditor.NewEditor(p.Editor, editorConf, hint).Layout(gtx)
+	if p.hints == nil {
+		return dims
+	}
+
+	if len(p.completions) > 0 {
+		p.layoutCompletions(tooltipGtx, theme, dims)
+	}
+
+	p.updateHover(gtx)
+
+	area := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	event.Op(gtx.Ops, p)
+	pass.Pop()
+	area.Pop()
+
+	if p.hovering && p.hoverToken >= 0 && p.hoverText != "" {
+		p.layoutTooltip(tooltipGtx, theme)
+	}
+
+	return dims
+}
+
+func (p *PatternEditor) updateHover(gtx layout.Context) {
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: p, Kinds: pointer.Move | pointer.Enter | pointer.Leave})
+		if !ok {
+			break
+		}
+
+		e, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		switch e.Kind {
+		case pointer.Enter, pointer.Move:
+			p.hovering = true
+			p.hoverPos = e.Position
+		case pointer.Leave:
+			p.hovering = false
+		}
+	}
+
+	if !p.hovering {
+		p.hoverToken = -1
+		return
+	}
+
+	text := p.Editor.Text()
+	pos := p.hoverPos.Round()
+	for _, t := range variables.Tokenize(text) {
+		if t.Kind != variables.TokenVariable {
+			continue
+		}
+
+		start := utf8.RuneCountInString(text[:t.Start])
+		end := start + utf8.RuneCountInString(text[t.Start:t.End])
+		for _, r := range p.Editor.Regions(start, end, nil) {
+			if !pos.In(r.Bounds) {
+				continue
+			}
+
+			if p.hoverToken != t.Start {
+				p.hoverToken = t.Start
+				p.hoverText = p.hints.describe(t.Value)
+			}
+			p.hoverBounds = r.Bounds
+			return
+		}
+	}
+
+	p.hoverToken = -1
+}
+
+func (p *PatternEditor) layoutTooltip(gtx layout.Context, theme *chapartheme.Theme) {
+	macro := op.Record(gtx.Ops)
+	op.Offset(image.Pt(p.hoverBounds.Min.X, p.hoverBounds.Max.Y+gtx.Dp(4))).Add(gtx.Ops)
+	gtx.Constraints.Min = image.Point{}
+	gtx.Constraints.Max = image.Pt(gtx.Dp(400), gtx.Dp(100))
+	layout.Background{}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			defer clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, gtx.Dp(4)).Push(gtx.Ops).Pop()
+			paint.Fill(gtx.Ops, theme.NotificationBgColor)
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				lb := material.Body2(theme.Material(), p.hoverText)
+				lb.Color = theme.NotificationTextColor
+				return lb.Layout(gtx)
+			})
+		},
+	)
+	op.Defer(gtx.Ops, macro.Stop())
+}
+
+func (p *PatternEditor) updateCompletions() {
+	p.completions = nil
+	if p.hints == nil {
+		return
+	}
+
+	start, end := p.Editor.Selection()
+	if start != end {
+		return
+	}
+
+	prefix, ok := variablePrefix(p.Editor.Text(), start)
+	if !ok {
+		return
+	}
+
+	p.completionPrefix = prefix
+	p.completions = p.hints.complete(prefix)
+	if len(p.completionButtons) < len(p.completions) {
+		p.completionButtons = make([]widget.Clickable, len(p.completions))
+	}
+}
+
+func (p *PatternEditor) applyCompletion(item Completion) {
+	caret, _ := p.Editor.Selection()
+	prefixLen := len([]rune(p.completionPrefix))
+	if prefixLen > caret {
+		prefixLen = caret
+	}
+
+	after := string([]rune(p.Editor.Text())[caret:])
+	p.Editor.SetCaret(caret-prefixLen, caret)
+	p.Editor.Insert(variableCompletion(item.Text, after))
+	p.completions = nil
+}
+
+// layoutCompletions shows the matching variables under the editor on top of the other widgets.
+func (p *PatternEditor) layoutCompletions(gtx layout.Context, theme *chapartheme.Theme, dims layout.Dimensions) {
+	for i := range p.completions {
+		if p.completionButtons[i].Clicked(gtx) {
+			p.applyCompletion(p.completions[i])
+			return
+		}
+	}
+
+	macro := op.Record(gtx.Ops)
+	op.Offset(image.Pt(0, dims.Size.Y+gtx.Dp(4))).Add(gtx.Ops)
+	gtx.Constraints.Min = image.Point{}
+	gtx.Constraints.Max = image.Pt(gtx.Dp(400), gtx.Dp(300))
+
+	items := make([]layout.FlexChild, 0, len(p.completions))
+	for i := range p.completions {
+		i := i
+		items = append(items, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := p.completions[i].Text
+			if p.completions[i].Detail != "" {
+				label = fmt.Sprintf("%s  %s", label, p.completions[i].Detail)
+			}
+
+			return material.Clickable(gtx, &p.completionButtons[i], func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					lb := material.Body2(theme.Material(), label)
+					lb.Color = theme.NotificationTextColor
+					return lb.Layout(gtx)
+				})
+			})
+		}))
+	}
+
+	layout.Background{}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			defer clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, gtx.Dp(4)).Push(gtx.Ops).Pop()
+			paint.Fill(gtx.Ops, theme.NotificationBgColor)
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(2)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx, items...)
+			})
+		},
+	)
+	op.Defer(gtx.Ops, macro.Stop())
 }
 
 func (p *PatternEditor) UpdateStyles() {
@@ -104,6 +326,15 @@ func (p *PatternEditor) updateStyles(text string) {
 
 	var styles []*giovieweditor.TextStyle
 
+	// the first matching style is used, so the unresolved variables come first
+	for _, r := range p.hints.unresolved(text) {
+		styles = append(styles, &giovieweditor.TextStyle{
+			Start: r[0],
+			End:   r[1],
+			Color: nRGBAColorToOp(p.unknownColor),
+		})
+	}
+
 	keyColor := color.NRGBA{R: 255, G: 165, B: 0, A: 255}
 	// Apply styles based on matches
 	applyStyles := func(re *regexp.Regexp) {
@@ -0,0 +1,153 @@
+package widgets
+
+import (
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+)
+
+// SelectModal lets the user pick items of a list, all the items are selected at first.
+type SelectModal struct {
+	Title      string
+	SubmitText string
+	Visible    bool
+
+	items    []string
+	selected []widget.Bool
+	list     widget.List
+
+	toggleAllBtn widget.Clickable
+	closeBtn     widget.Clickable
+	submitBtn    widget.Clickable
+
+	onSubmit func(selected []int)
+}
+
+func NewSelectModal(title string, items []string, onSubmit func(selected []int)) *SelectModal {
+	s := &SelectModal{
+		Title:      title,
+		SubmitText: "Import",
+		items:      items,
+		selected:   make([]widget.Bool, len(items)),
+		list: widget.List{
+			List: layout.List{
+				Axis: layout.Vertical,
+			},
+		},
+		onSubmit: onSubmit,
+	}
+
+	for i := range s.selected {
+		s.selected[i].Value = true
+	}
+	return s
+}
+
+func (s *SelectModal) Show() {
+	s.Visible = true
+}
+
+func (s *SelectModal) Hide() {
+	s.Visible = false
+}
+
+func (s *SelectModal) selectedIndexes() []int {
+	out := make([]int, 0, len(s.selected))
+	for i := range s.selected {
+		if s.selected[i].Value {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (s *SelectModal) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if s.closeBtn.Clicked(gtx) {
+		s.Hide()
+	}
+
+	if s.submitBtn.Clicked(gtx) {
+		s.Hide()
+		if s.onSubmit != nil {
+			s.onSubmit(s.selectedIndexes())
+		}
+	}
+
+	if s.toggleAllBtn.Clicked(gtx) {
+		all := len(s.selectedIndexes()) != len(s.selected)
+		for i := range s.selected {
+			s.selected[i].Value = all
+		}
+	}
+
+	border := widget.Border{
+		Color:        theme.TableBorderColor,
+		CornerRadius: unit.Dp(4),
+		Width:        unit.Dp(1),
+	}
+
+	return layout.N.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: unit.Dp(80)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return border.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = gtx.Dp(700)
+				gtx.Constraints.Max.Y = gtx.Dp(450)
+
+				return component.NewModalSheet(component.NewModal()).Layout(gtx, theme.Material(), &component.VisibilityAnimation{}, func(gtx layout.Context) layout.Dimensions {
+					return layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return material.Label(theme.Material(), unit.Sp(14), s.Title).Layout(gtx)
+							}),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return material.List(theme.Material(), &s.list).Layout(gtx, len(s.items), func(gtx layout.Context, i int) layout.Dimensions {
+									return CheckBox(theme.Material(), &s.selected[i], s.items[i]).Layout(gtx)
+								})
+							}),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle, Spacing: layout.SpaceStart}.Layout(gtx,
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										btn := Button(theme.Material(), &s.toggleAllBtn, nil, IconPositionStart, "Toggle all")
+										btn.Color = theme.ButtonTextColor
+										return btn.Layout(gtx, theme)
+									}),
+									layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										closeBtn := Button(theme.Material(), &s.closeBtn, CloseIcon, IconPositionStart, "Close")
+										closeBtn.Color = theme.ButtonTextColor
+										return closeBtn.Layout(gtx, theme)
+									}),
+									layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										submitBtn := Button(theme.Material(), &s.submitBtn, UploadIcon, IconPositionStart, s.SubmitText)
+										submitBtn.Color = theme.ButtonTextColor
+										submitBtn.Background = theme.SendButtonBgColor
+										return submitBtn.Layout(gtx, theme)
+									}),
+								)
+							}),
+						)
+					})
+				})
+			})
+		})
+	})
+}
+
+func (s *SelectModal) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if s == nil || !s.Visible {
+		return layout.Dimensions{}
+	}
+
+	ops := op.Record(gtx.Ops)
+	dims := s.layout(gtx, theme)
+	defer op.Defer(gtx.Ops, ops.Stop())
+
+	return dims
+}
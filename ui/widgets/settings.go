@@ -15,10 +15,11 @@ import (
 )
 
 const (
-	ItemTypeText    = "text"
-	ItemTypeFile    = "file"
-	ItemTypeBool    = "bool"
-	ItemTypeLNumber = "number"
+	ItemTypeText     = "text"
+	ItemTypeFile     = "file"
+	ItemTypeBool     = "bool"
+	ItemTypeLNumber  = "number"
+	ItemTypeDropDown = "dropdown"
 )
 
 type Settings struct {
@@ -64,6 +65,8 @@ func (s *Settings) getValues() map[string]any {
 			values[i.Key] = v
 		case ItemTypeFile:
 			values[i.Key] = i.FileSelector.GetFilePath()
+		case ItemTypeDropDown:
+			values[i.Key] = i.dropDown.GetSelected().GetValue()
 		default:
 			values[i.Key] = i.editor.Text()
 		}
@@ -90,6 +93,7 @@ type SettingItem struct {
 	editor    *widget.Editor
 
 	FileSelector *FileSelector
+	dropDown     *DropDown
 
 	visible     bool
 	visibleWhen func(values map[string]any) bool
@@ -154,6 +158,32 @@ func NewNumberItem(title, key, description string, value int) *SettingItem {
 	return i
 }
 
+// NewDropDownItem creates an item to select one of the given options, options are used as both title and value.
+func NewDropDownItem(theme *chapartheme.Theme, title, key, description string, value string, options ...string) *SettingItem {
+	opts := make([]*DropDownOption, 0, len(options))
+	for _, o := range options {
+		opts = append(opts, NewDropDownOption(o).WithValue(o))
+	}
+
+	i := &SettingItem{
+		Title:       title,
+		Key:         key,
+		Description: description,
+		Type:        ItemTypeDropDown,
+		Value:       value,
+		dropDown:    NewDropDown(theme, opts...),
+		visible:     true,
+	}
+
+	i.dropDown.SetSelectedByValue(value)
+	i.dropDown.SetOnChanged(func(value string) {
+		if i.onChange != nil {
+			i.onChange()
+		}
+	})
+	return i
+}
+
 func (i *SettingItem) SetVisibleWhen(f func(values map[string]any) bool) *SettingItem {
 	i.visibleWhen = f
 	return i
@@ -195,6 +225,8 @@ func (i *SettingItem) Layout(gtx layout.Context, theme *chapartheme.Theme) layou
 						return i.editorLayout(gtx, theme)
 					case ItemTypeFile:
 						return i.fileLayout(gtx, theme)
+					case ItemTypeDropDown:
+						return i.dropDown.Layout(gtx, theme)
 					default:
 						return layout.Dimensions{}
 					}
@@ -54,6 +54,11 @@ func (t *TextField) SetText(text string) {
 	t.textEditor.SetText(text)
 }
 
+// SetMask replaces the visible characters with the mask, e.g. for passwords.
+func (t *TextField) SetMask(mask rune) {
+	t.textEditor.Mask = mask
+}
+
 func (t *TextField) SetIcon(icon *widget.Icon, position int) {
 	t.Icon = icon
 	t.IconPosition = position
@@ -3,10 +3,12 @@ package widgets
 import (
 	"image"
 	"image/color"
+	"math"
 	"sort"
 	"strings"
 
 	"gioui.org/font"
+	"gioui.org/gesture"
 	"gioui.org/io/input"
 	"gioui.org/io/pointer"
 	"gioui.org/layout"
@@ -33,6 +35,7 @@ type TreeView struct {
 
 	onNodeDoubleClick func(tr *TreeNode)
 	onNodeClick       func(tr *TreeNode)
+	onNodeMove        func(parent, tr *TreeNode, index int)
 }
 
 type TreeNode struct {
@@ -52,6 +55,13 @@ type TreeNode struct {
 	isChild  bool
 	expanded bool
 
+	// drag moves the child node among the children of its parent, dragOffset is how far it is dragged
+	// and height the height of the node when it was laid out.
+	drag       gesture.Drag
+	dragStart  float32
+	dragOffset float32
+	height     int
+
 	Meta *safemap.Map[string]
 }
 
@@ -82,6 +92,12 @@ func (t *TreeView) OnNodeClick(fn func(tr *TreeNode)) {
 	t.onNodeClick = fn
 }
 
+// OnNodeMove sets the function called when a child node is dragged to the index among the children of
+// its parent, the children are already reordered when it is called.
+func (t *TreeView) OnNodeMove(fn func(parent, tr *TreeNode, index int)) {
+	t.onNodeMove = fn
+}
+
 func (t *TreeView) SetNodes(nodes []*TreeNode) {
 	t.nodes = nodes
 }
@@ -143,6 +159,86 @@ func (t *TreeView) RemoveNode(identifier string) {
 	}
 }
 
+// moveNode moves the child node by the number of rows it was dragged.
+func (t *TreeView) moveNode(node *TreeNode, rows int) {
+	for _, parent := range t.nodes {
+		from := -1
+		for i, c := range parent.Children {
+			if c == node {
+				from = i
+				break
+			}
+		}
+		if from == -1 {
+			continue
+		}
+
+		to := max(0, min(from+rows, len(parent.Children)-1))
+		if to == from {
+			return
+		}
+
+		parent.Children = append(parent.Children[:from], parent.Children[from+1:]...)
+		parent.Children = append(parent.Children[:to], append([]*TreeNode{node}, parent.Children[to:]...)...)
+		if t.onNodeMove != nil {
+			go t.onNodeMove(parent, node, to)
+		}
+		return
+	}
+}
+
+// draggedRows returns the number of rows the node is dragged by.
+func (node *TreeNode) draggedRows() int {
+	if node.height == 0 {
+		return 0
+	}
+	return int(math.Round(float64(node.dragOffset) / float64(node.height)))
+}
+
+func (t *TreeView) handleDrag(gtx layout.Context, node *TreeNode) {
+	for {
+		e, ok := node.drag.Update(gtx.Metric, gtx.Source, gesture.Vertical)
+		if !ok {
+			break
+		}
+
+		switch e.Kind {
+		case pointer.Press:
+			node.dragStart = e.Position.Y
+			node.dragOffset = 0
+		case pointer.Drag:
+			node.dragOffset = e.Position.Y - node.dragStart
+		case pointer.Release:
+			if rows := node.draggedRows(); rows != 0 {
+				t.moveNode(node, rows)
+			}
+			node.dragOffset = 0
+		case pointer.Cancel:
+			node.dragOffset = 0
+		}
+	}
+}
+
+// dropIndicator draws a line where the dragged node is dropped, it is deferred to be drawn above the
+// other nodes.
+func (t *TreeView) dropIndicator(gtx layout.Context, theme *chapartheme.Theme, node *TreeNode) {
+	rows := node.draggedRows()
+	if rows == 0 {
+		return
+	}
+
+	y := rows * node.height
+	if rows > 0 {
+		y += node.height
+	}
+
+	macro := op.Record(gtx.Ops)
+	stack := op.Offset(image.Pt(0, y)).Push(gtx.Ops)
+	paint.FillShape(gtx.Ops, theme.Palette.ContrastBg, clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(2))}.Op())
+	stack.Pop()
+	op.Defer(gtx.Ops, macro.Stop())
+}
+
 func (t *TreeView) Filter(text string) {
 	t.filterText = text
 
@@ -235,6 +331,25 @@ func (t *TreeView) itemLayout(gtx layout.Context, theme *chapartheme.Theme, node
 		node.DiscloserState.Appear(gtx.Now)
 	}
 
+	// children are reordered by dragging them, unless the tree is filtered
+	if node.isChild && t.filterText == "" {
+		t.handleDrag(gtx, node)
+
+		dims := t.nodeLayout(gtx, theme, node, leftPadding)
+		node.height = dims.Size.Y
+
+		// pass the events through to the clickable of the node below
+		defer pointer.PassOp{}.Push(gtx.Ops).Pop()
+		defer clip.Rect{Max: dims.Size}.Push(gtx.Ops).Pop()
+		node.drag.Add(gtx.Ops)
+		t.dropIndicator(gtx, theme, node)
+		return dims
+	}
+
+	return t.nodeLayout(gtx, theme, node, leftPadding)
+}
+
+func (t *TreeView) nodeLayout(gtx layout.Context, theme *chapartheme.Theme, node *TreeNode, leftPadding int) layout.Dimensions {
 	return t.clickableWrap(gtx, theme, node, func(gtx layout.Context) layout.Dimensions {
 		return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8), Left: unit.Dp(8 + leftPadding)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
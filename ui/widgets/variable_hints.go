@@ -0,0 +1,154 @@
+package widgets
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+// variablesRefreshInterval is how often the known variables are fetched again while the editors are drawn.
+const variablesRefreshInterval = time.Second
+
+// VariableHints gives the editors the variables known to a request, they are used to complete {{ names,
+// highlight the unresolved ones and describe the hovered ones. One instance is shared by the editors of a request.
+type VariableHints struct {
+	// Names returns the known variables and functions, functions are prefixed with $.
+	Names func() []Completion
+	// Describe returns the text shown when the pointer is over a variable.
+	Describe func(name string) string
+
+	mx        sync.Mutex
+	known     []Completion
+	knownSet  map[string]bool
+	fetchedAt time.Time
+	version   int
+}
+
+// refresh fetches the known variables when they are older than the refresh interval and returns
+// a version which changes whenever the known variables change.
+func (h *VariableHints) refresh() int {
+	if h == nil || h.Names == nil {
+		return 0
+	}
+
+	h.mx.Lock()
+	if time.Since(h.fetchedAt) < variablesRefreshInterval {
+		defer h.mx.Unlock()
+		return h.version
+	}
+	h.fetchedAt = time.Now()
+	h.mx.Unlock()
+
+	names := h.Names()
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n.Text] = true
+	}
+
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	if !sameKeys(h.knownSet, set) {
+		h.version++
+	}
+	h.known = names
+	h.knownSet = set
+	return h.version
+}
+
+func sameKeys(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *VariableHints) describe(name string) string {
+	if h == nil || h.Describe == nil {
+		return ""
+	}
+	return h.Describe(name)
+}
+
+// isKnown reports whether the variable, or the function of a call like {{$randomInt 1 10}}, is known.
+func (h *VariableHints) isKnown(name string) bool {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	if fields := strings.Fields(name); len(fields) > 0 && strings.HasPrefix(fields[0], "$") {
+		name = fields[0]
+	}
+	return h.knownSet[name]
+}
+
+// complete returns the known variables which start with the prefix.
+func (h *VariableHints) complete(prefix string) []Completion {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	out := make([]Completion, 0)
+	for _, n := range h.known {
+		if strings.HasPrefix(strings.ToLower(n.Text), strings.ToLower(prefix)) {
+			out = append(out, n)
+		}
+		if len(out) == maxCompletions {
+			break
+		}
+	}
+	return out
+}
+
+// unresolved returns the rune ranges of the variables of the text which are not known.
+func (h *VariableHints) unresolved(text string) [][2]int {
+	if h == nil || h.Names == nil {
+		return nil
+	}
+
+	var out [][2]int
+	for _, t := range variables.Tokenize(text) {
+		if t.Kind != variables.TokenVariable || h.isKnown(t.Value) {
+			continue
+		}
+
+		start := len([]rune(text[:t.Start]))
+		out = append(out, [2]int{start, start + len([]rune(text[t.Start:t.End]))})
+	}
+	return out
+}
+
+// variablePrefix returns the partial name typed after an unclosed {{ before the caret, caret is a rune offset.
+func variablePrefix(text string, caret int) (string, bool) {
+	runes := []rune(text)
+	if caret > len(runes) {
+		caret = len(runes)
+	}
+
+	before := string(runes[:caret])
+	open := strings.LastIndex(before, "{{")
+	if open < 0 {
+		return "", false
+	}
+
+	prefix := before[open+2:]
+	for _, r := range prefix {
+		if r != '$' && r != '_' && r != '-' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", false
+		}
+	}
+	return prefix, true
+}
+
+// variableCompletion returns the text inserted for the completion, the closing braces are added if missing.
+func variableCompletion(name, after string) string {
+	if strings.HasPrefix(after, "}}") {
+		return name
+	}
+	return name + "}}"
+}
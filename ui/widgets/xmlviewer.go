@@ -0,0 +1,145 @@
+package widgets
+
+import (
+	"image"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/rest"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+)
+
+// XMLViewer shows an indented xml document, the elements with children can be collapsed.
+type XMLViewer struct {
+	nodes []*xmlNode
+	lines []xmlLine
+
+	list *widget.List
+}
+
+type xmlNode struct {
+	line      string
+	end       string
+	children  []*xmlNode
+	collapsed bool
+
+	toggle        widget.Clickable
+	selectable    widget.Selectable
+	endSelectable widget.Selectable
+}
+
+// xmlLine is a visible line of the document, the start of a node or the end of an expanded element.
+type xmlLine struct {
+	node  *xmlNode
+	depth int
+	end   bool
+}
+
+func NewXMLViewer() *XMLViewer {
+	return &XMLViewer{
+		list: &widget.List{
+			List: layout.List{
+				Axis: layout.Vertical,
+			},
+		},
+	}
+}
+
+func (x *XMLViewer) SetNodes(nodes []*rest.XMLNode) {
+	var convert func(nodes []*rest.XMLNode) []*xmlNode
+	convert = func(nodes []*rest.XMLNode) []*xmlNode {
+		out := make([]*xmlNode, 0, len(nodes))
+		for _, n := range nodes {
+			out = append(out, &xmlNode{line: n.Line(), end: n.End, children: convert(n.Children)})
+		}
+		return out
+	}
+
+	x.nodes = convert(nodes)
+	x.list.Position = layout.Position{}
+	x.updateLines()
+}
+
+func (x *XMLViewer) updateLines() {
+	x.lines = x.lines[:0]
+
+	var add func(nodes []*xmlNode, depth int)
+	add = func(nodes []*xmlNode, depth int) {
+		for _, n := range nodes {
+			x.lines = append(x.lines, xmlLine{node: n, depth: depth})
+			if len(n.children) > 0 && !n.collapsed {
+				add(n.children, depth+1)
+				x.lines = append(x.lines, xmlLine{node: n, depth: depth, end: true})
+			}
+		}
+	}
+	add(x.nodes, 0)
+}
+
+func (x *XMLViewer) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	changed := false
+	for _, l := range x.lines {
+		if !l.end && l.node.toggle.Clicked(gtx) {
+			l.node.collapsed = !l.node.collapsed
+			changed = true
+		}
+	}
+	if changed {
+		x.updateLines()
+	}
+
+	border := widget.Border{
+		Color:        theme.BorderColor,
+		Width:        unit.Dp(1),
+		CornerRadius: unit.Dp(4),
+	}
+
+	return border.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.UniformInset(3).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return material.List(theme.Material(), x.list).Layout(gtx, len(x.lines), func(gtx layout.Context, i int) layout.Dimensions {
+				return x.lineLayout(gtx, theme, x.lines[i])
+			})
+		})
+	})
+}
+
+func (x *XMLViewer) lineLayout(gtx layout.Context, theme *chapartheme.Theme, l xmlLine) layout.Dimensions {
+	n := l.node
+	text, selectable := n.line, &n.selectable
+	if l.end {
+		text, selectable = n.end, &n.endSelectable
+	} else if n.collapsed {
+		text += " … " + n.end
+	}
+
+	return layout.Inset{Left: unit.Dp(10 + 16*l.depth)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				size := gtx.Dp(16)
+				if l.end || len(n.children) == 0 {
+					return layout.Dimensions{Size: image.Pt(size, size)}
+				}
+
+				icon := ExpandIcon
+				if n.collapsed {
+					icon = ForwardIcon
+				}
+				return n.toggle.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X, gtx.Constraints.Max.X = size, size
+					return icon.Layout(gtx, theme.TextColor)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(theme.Material(), unit.Sp(14), text)
+					lbl.State = selectable
+					lbl.SelectionColor = theme.TextSelectionColor
+					return lbl.Layout(gtx)
+				})
+			}),
+		)
+	})
+}